@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	syncTypes "github.com/filecoin-project/venus/pkg/chainsync/types"
 	pstate "github.com/filecoin-project/venus/pkg/state"
 	"io"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-jsonrpc/auth"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	acrypto "github.com/filecoin-project/go-state-types/crypto"
@@ -23,146 +25,212 @@ import (
 	chainApiTypes "github.com/filecoin-project/venus/app/submodule/chain"
 	"github.com/filecoin-project/venus/app/submodule/chain/cst"
 	mineApiTypes "github.com/filecoin-project/venus/app/submodule/mining"
+	multisigApiTypes "github.com/filecoin-project/venus/app/submodule/multisig"
+	networkApiTypes "github.com/filecoin-project/venus/app/submodule/network"
+	paychApiTypes "github.com/filecoin-project/venus/app/submodule/paych"
 	syncApiTypes "github.com/filecoin-project/venus/app/submodule/syncer"
 	"github.com/filecoin-project/venus/pkg/block"
 	"github.com/filecoin-project/venus/pkg/chain"
 	"github.com/filecoin-project/venus/pkg/crypto"
 	"github.com/filecoin-project/venus/pkg/messagepool"
 	"github.com/filecoin-project/venus/pkg/net"
+	"github.com/filecoin-project/venus/pkg/paychmgr"
 	"github.com/filecoin-project/venus/pkg/specactors/builtin/miner"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/paych"
 	"github.com/filecoin-project/venus/pkg/specactors/builtin/power"
 	"github.com/filecoin-project/venus/pkg/types"
 	"github.com/filecoin-project/venus/pkg/vm"
+	vmstate "github.com/filecoin-project/venus/pkg/vm/state"
 	"github.com/filecoin-project/venus/pkg/wallet"
 )
 
+// FullNode is the JSON-RPC API of the node. The perm tag of each method is the
+// permission a token needs to call it, see jwtauth.PermissionedProxy.
 type FullNode struct {
-	DAGGetNode     func(context.Context, string) (interface{}, error)
-	DAGGetFileSize func(context.Context, cid.Cid) (uint64, error)
-	DAGCat         func(context.Context, cid.Cid) (io.Reader, error)
-	DAGImportData  func(context.Context, io.Reader) (ipld.Node, error)
+	DAGGetNode     func(context.Context, string) (interface{}, error)  `perm:"read"`
+	DAGGetFileSize func(context.Context, cid.Cid) (uint64, error)      `perm:"read"`
+	DAGCat         func(context.Context, cid.Cid) (io.Reader, error)   `perm:"read"`
+	DAGImportData  func(context.Context, io.Reader) (ipld.Node, error) `perm:"write"`
 
-	BlockTime                     func() time.Duration
-	ChainList                     func(context.Context, block.TipSetKey, int) ([]block.TipSetKey, error)
-	ProtocolParameters            func(context.Context) (*chainApiTypes.ProtocolParams, error)
-	ChainHead                     func(context.Context) (*block.TipSet, error)
-	ChainSetHead                  func(context.Context, block.TipSetKey) error
-	ChainGetTipSet                func(block.TipSetKey) (*block.TipSet, error)
-	ChainGetTipSetByHeight        func(context.Context, abi.ChainEpoch, block.TipSetKey) (*block.TipSet, error)
-	GetActor                      func(context.Context, address.Address) (*types.Actor, error)
-	ChainGetBlock                 func(context.Context, cid.Cid) (*block.Block, error)
-	ChainGetMessage               func(context.Context, cid.Cid) (*types.UnsignedMessage, error)
-	ChainGetBlockMessages         func(context.Context, cid.Cid) (*chainApiTypes.BlockMessages, error)
-	ChainGetReceipts              func(context.Context, cid.Cid) ([]types.MessageReceipt, error)
-	GetFullBlock                  func(context.Context, cid.Cid) (*block.FullBlock, error)
-	ResolveToKeyAddr              func(context.Context, address.Address, *block.TipSet) (address.Address, error)
-	ChainNotify                   func(context.Context) chan []*chain.HeadChange
-	GetEntry                      func(context.Context, abi.ChainEpoch, uint64) (*block.BeaconEntry, error)
-	VerifyEntry                   func(*block.BeaconEntry, abi.ChainEpoch) bool
-	StateNetworkName              func(context.Context) (chainApiTypes.NetworkName, error)
-	ChainGetRandomnessFromBeacon  func(context.Context, block.TipSetKey, acrypto.DomainSeparationTag, abi.ChainEpoch, []byte) (abi.Randomness, error)
-	ChainGetRandomnessFromTickets func(context.Context, block.TipSetKey, acrypto.DomainSeparationTag, abi.ChainEpoch, []byte) (abi.Randomness, error)
-	StateNetworkVersion           func(context.Context, block.TipSetKey) (network.Version, error)
-	MessageWait                   func(context.Context, cid.Cid, abi.ChainEpoch) (*cst.ChainMessage, error)
-	StateSearchMsg                func(context.Context, cid.Cid) (*cst.MsgLookup, error)
-	StateWaitMsg                  func(context.Context, cid.Cid, abi.ChainEpoch) (*cst.MsgLookup, error)
-	StateGetReceipt               func(context.Context, cid.Cid, block.TipSetKey) (*types.MessageReceipt, error)
+	BlockTime                     func(context.Context) time.Duration                                                                                 `perm:"read"`
+	ChainList                     func(context.Context, block.TipSetKey, int) ([]block.TipSetKey, error)                                              `perm:"read"`
+	ProtocolParameters            func(context.Context) (*chainApiTypes.ProtocolParams, error)                                                        `perm:"read"`
+	ChainHead                     func(context.Context) (*block.TipSet, error)                                                                        `perm:"read"`
+	ChainSetHead                  func(context.Context, block.TipSetKey) error                                                                        `perm:"admin"`
+	ChainGetTipSet                func(context.Context, block.TipSetKey) (*block.TipSet, error)                                                       `perm:"read"`
+	ChainGetTipSetByHeight        func(context.Context, abi.ChainEpoch, block.TipSetKey) (*block.TipSet, error)                                       `perm:"read"`
+	ChainGetPath                  func(context.Context, block.TipSetKey, block.TipSetKey) ([]*chain.HeadChange, error)                                `perm:"read"`
+	GetActor                      func(context.Context, address.Address) (*types.Actor, error)                                                        `perm:"read"`
+	ChainGetBlock                 func(context.Context, cid.Cid) (*block.Block, error)                                                                `perm:"read"`
+	ChainGetMessage               func(context.Context, cid.Cid) (*types.UnsignedMessage, error)                                                      `perm:"read"`
+	ChainGetBlockMessages         func(context.Context, cid.Cid) (*chainApiTypes.BlockMessages, error)                                                `perm:"read"`
+	ChainGetReceipts              func(context.Context, cid.Cid) ([]types.MessageReceipt, error)                                                      `perm:"read"`
+	ChainGetParentMessages        func(context.Context, cid.Cid) ([]chainApiTypes.Message, error)                                                     `perm:"read"`
+	ChainGetParentReceipts        func(context.Context, cid.Cid) ([]*types.MessageReceipt, error)                                                     `perm:"read"`
+	GetFullBlock                  func(context.Context, cid.Cid) (*block.FullBlock, error)                                                            `perm:"read"`
+	ResolveToKeyAddr              func(context.Context, address.Address, *block.TipSet) (address.Address, error)                                      `perm:"read"`
+	ChainNotify                   func(context.Context) chan []*chain.HeadChange                                                                      `perm:"read"`
+	ChainNotifyFrom               func(context.Context, block.TipSetKey, abi.ChainEpoch, *chain.CoalesceOptions) (chan []*chain.HeadChange, error)    `perm:"read"`
+	GetEntry                      func(context.Context, abi.ChainEpoch, uint64) (*block.BeaconEntry, error)                                           `perm:"read"`
+	VerifyEntry                   func(context.Context, *block.BeaconEntry, *block.BeaconEntry, abi.ChainEpoch) bool                                  `perm:"read"`
+	StateNetworkName              func(context.Context) (chainApiTypes.NetworkName, error)                                                            `perm:"read"`
+	ChainGetRandomnessFromBeacon  func(context.Context, block.TipSetKey, acrypto.DomainSeparationTag, abi.ChainEpoch, []byte) (abi.Randomness, error) `perm:"read"`
+	ChainGetRandomnessFromTickets func(context.Context, block.TipSetKey, acrypto.DomainSeparationTag, abi.ChainEpoch, []byte) (abi.Randomness, error) `perm:"read"`
+	StateNetworkVersion           func(context.Context, block.TipSetKey) (network.Version, error)                                                     `perm:"read"`
+	MessageWait                   func(context.Context, cid.Cid, abi.ChainEpoch, abi.ChainEpoch) (*cst.ChainMessage, error)                           `perm:"read"`
+	StateSearchMsg                func(context.Context, cid.Cid) (*cst.MsgLookup, error)                                                              `perm:"read"`
+	StateListMessages             func(context.Context, *chainApiTypes.MessageMatch, block.TipSetKey, abi.ChainEpoch) ([]cid.Cid, error)              `perm:"read"`
+	StateWaitMsg                  func(context.Context, cid.Cid, abi.ChainEpoch) (*cst.MsgLookup, error)                                              `perm:"read"`
+	StateGetReceipt               func(context.Context, cid.Cid, block.TipSetKey) (*types.MessageReceipt, error)                                      `perm:"read"`
+	ChainExport                   func(context.Context, abi.ChainEpoch, bool, block.TipSetKey) (<-chan []byte, error)                                 `perm:"read"`
 
-	StateMinerSectorAllocated          func(context.Context, address.Address, abi.SectorNumber, block.TipSetKey) (bool, error)
-	StateSectorPreCommitInfo           func(context.Context, address.Address, abi.SectorNumber, block.TipSetKey) (miner.SectorPreCommitOnChainInfo, error)
-	StateSectorGetInfo                 func(context.Context, address.Address, abi.SectorNumber, block.TipSetKey) (*miner.SectorOnChainInfo, error)
-	StateSectorPartition               func(context.Context, address.Address, abi.SectorNumber, block.TipSetKey) (*miner.SectorLocation, error)
-	StateMinerSectorSize               func(context.Context, address.Address, block.TipSetKey) (abi.SectorSize, error)
-	StateMinerInfo                     func(context.Context, address.Address, block.TipSetKey) (miner.MinerInfo, error)
-	StateMinerWorkerAddress            func(context.Context, address.Address, block.TipSetKey) (address.Address, error)
-	StateMinerRecoveries               func(context.Context, address.Address, block.TipSetKey) (bitfield.BitField, error)
-	StateMinerFaults                   func(context.Context, address.Address, block.TipSetKey) (bitfield.BitField, error)
-	StateMinerProvingDeadline          func(context.Context, address.Address, block.TipSetKey) (*dline.Info, error)
-	StateMinerPartitions               func(context.Context, address.Address, uint64, block.TipSetKey) ([]chainApiTypes.Partition, error)
-	StateMinerDeadlines                func(context.Context, address.Address, block.TipSetKey) ([]chainApiTypes.Deadline, error)
-	StateMinerSectors                  func(context.Context, address.Address, *bitfield.BitField, block.TipSetKey) ([]*miner.SectorOnChainInfo, error)
-	StateMarketStorageDeal             func(context.Context, abi.DealID, block.TipSetKey) (*chainApiTypes.MarketDeal, error)
-	StateMinerPreCommitDepositForPower func(context.Context, address.Address, miner.SectorPreCommitInfo, block.TipSetKey) (big.Int, error)
-	StateMinerInitialPledgeCollateral  func(context.Context, address.Address, miner.SectorPreCommitInfo, block.TipSetKey) (big.Int, error)
-	StateVMCirculatingSupplyInternal   func(context.Context, block.TipSetKey) (chain.CirculatingSupply, error)
-	StateCirculatingSupply             func(context.Context, block.TipSetKey) (abi.TokenAmount, error)
-	StateMarketDeals                   func(context.Context, block.TipSetKey) (map[string]pstate.MarketDeal, error)
-	StateMinerActiveSectors            func(context.Context, address.Address, block.TipSetKey) ([]*miner.SectorOnChainInfo, error)
-	StateLookupID                      func(context.Context, address.Address, block.TipSetKey) (address.Address, error)
-	StateListMiners                    func(context.Context, block.TipSetKey) ([]address.Address, error)
-	StateListActors                    func(context.Context, block.TipSetKey) ([]address.Address, error)
-	StateMinerPower                    func(context.Context, address.Address, block.TipSetKey) (*power.MinerPower, error)
-	StateMinerAvailableBalance         func(context.Context, address.Address, block.TipSetKey) (big.Int, error)
-	StateSectorExpiration              func(context.Context, address.Address, abi.SectorNumber, block.TipSetKey) (*miner.SectorExpiration, error)
-	StateMinerSectorCount              func(context.Context, address.Address, block.TipSetKey) (chainApiTypes.MinerSectors, error)
-	StateMarketBalance                 func(context.Context, address.Address, block.TipSetKey) (chainApiTypes.MarketBalance, error)
+	StateMinerSectorAllocated          func(context.Context, address.Address, abi.SectorNumber, block.TipSetKey) (bool, error)                             `perm:"read"`
+	StateSectorPreCommitInfo           func(context.Context, address.Address, abi.SectorNumber, block.TipSetKey) (miner.SectorPreCommitOnChainInfo, error) `perm:"read"`
+	StateSectorGetInfo                 func(context.Context, address.Address, abi.SectorNumber, block.TipSetKey) (*miner.SectorOnChainInfo, error)         `perm:"read"`
+	StateSectorPartition               func(context.Context, address.Address, abi.SectorNumber, block.TipSetKey) (*miner.SectorLocation, error)            `perm:"read"`
+	StateMinerSectorSize               func(context.Context, address.Address, block.TipSetKey) (abi.SectorSize, error)                                     `perm:"read"`
+	StateMinerInfo                     func(context.Context, address.Address, block.TipSetKey) (miner.MinerInfo, error)                                    `perm:"read"`
+	StateMinerWorkerAddress            func(context.Context, address.Address, block.TipSetKey) (address.Address, error)                                    `perm:"read"`
+	StateMinerRecoveries               func(context.Context, address.Address, block.TipSetKey) (bitfield.BitField, error)                                  `perm:"read"`
+	StateMinerFaults                   func(context.Context, address.Address, block.TipSetKey) (bitfield.BitField, error)                                  `perm:"read"`
+	StateMinerProvingDeadline          func(context.Context, address.Address, block.TipSetKey) (*dline.Info, error)                                        `perm:"read"`
+	StateMinerPartitions               func(context.Context, address.Address, uint64, block.TipSetKey) ([]chainApiTypes.Partition, error)                  `perm:"read"`
+	StateMinerDeadlines                func(context.Context, address.Address, block.TipSetKey) ([]chainApiTypes.Deadline, error)                           `perm:"read"`
+	StateMinerSectors                  func(context.Context, address.Address, *bitfield.BitField, block.TipSetKey) ([]*miner.SectorOnChainInfo, error)     `perm:"read"`
+	StateMarketStorageDeal             func(context.Context, abi.DealID, block.TipSetKey) (*chainApiTypes.MarketDeal, error)                               `perm:"read"`
+	StateMinerPreCommitDepositForPower func(context.Context, address.Address, miner.SectorPreCommitInfo, block.TipSetKey) (big.Int, error)                 `perm:"read"`
+	StateMinerInitialPledgeCollateral  func(context.Context, address.Address, miner.SectorPreCommitInfo, block.TipSetKey) (big.Int, error)                 `perm:"read"`
+	StateVMCirculatingSupplyInternal   func(context.Context, block.TipSetKey) (chain.CirculatingSupply, error)                                             `perm:"read"`
+	StateCirculatingSupply             func(context.Context, block.TipSetKey) (abi.TokenAmount, error)                                                     `perm:"read"`
+	StateMarketDeals                   func(context.Context, block.TipSetKey) (map[string]pstate.MarketDeal, error)                                        `perm:"read"`
+	StateMinerActiveSectors            func(context.Context, address.Address, block.TipSetKey) ([]*miner.SectorOnChainInfo, error)                         `perm:"read"`
+	StateLookupID                      func(context.Context, address.Address, block.TipSetKey) (address.Address, error)                                    `perm:"read"`
+	StateListMiners                    func(context.Context, block.TipSetKey) ([]address.Address, error)                                                   `perm:"read"`
+	StateListActors                    func(context.Context, block.TipSetKey) ([]address.Address, error)                                                   `perm:"read"`
+	StateMinerPower                    func(context.Context, address.Address, block.TipSetKey) (*power.MinerPower, error)                                  `perm:"read"`
+	StateMinerAvailableBalance         func(context.Context, address.Address, block.TipSetKey) (big.Int, error)                                            `perm:"read"`
+	StateSectorExpiration              func(context.Context, address.Address, abi.SectorNumber, block.TipSetKey) (*miner.SectorExpiration, error)          `perm:"read"`
+	StateMinerSectorCount              func(context.Context, address.Address, block.TipSetKey) (chainApiTypes.MinerSectors, error)                         `perm:"read"`
+	StateMarketBalance                 func(context.Context, address.Address, block.TipSetKey) (chainApiTypes.MarketBalance, error)                        `perm:"read"`
 
-	ConfigSet func(string, string) error
-	ConfigGet func(string) (interface{}, error)
+	ConfigSet func(context.Context, string, string) error        `perm:"admin"`
+	ConfigGet func(context.Context, string) (interface{}, error) `perm:"admin"`
 
-	SyncerTracker            func() *syncTypes.TargetTracker
-	ChainTipSetWeight        func(context.Context, block.TipSetKey) (big.Int, error)
-	ChainSyncHandleNewTipSet func(*block.ChainInfo) error
-	SyncSubmitBlock          func(context.Context, *block.BlockMsg) error
-	StateCall                func(context.Context, *types.UnsignedMessage, block.TipSetKey) (*syncApiTypes.InvocResult, error)
-	SyncState                func(context.Context) (*syncApiTypes.SyncState, error)
+	SyncerTracker            func(context.Context) *syncTypes.TargetTracker                                                                             `perm:"read"`
+	SetConcurrent            func(context.Context, int64) error                                                                                         `perm:"admin"`
+	ChainTipSetWeight        func(context.Context, block.TipSetKey) (big.Int, error)                                                                    `perm:"read"`
+	ChainSyncHandleNewTipSet func(context.Context, *block.ChainInfo) error                                                                              `perm:"write"`
+	SyncSubmitBlock          func(context.Context, *block.BlockMsg) error                                                                               `perm:"write"`
+	StateCall                func(context.Context, *types.UnsignedMessage, block.TipSetKey) (*syncApiTypes.InvocResult, error)                          `perm:"read"`
+	StateReplay              func(context.Context, block.TipSetKey, cid.Cid) (*syncApiTypes.InvocResult, error)                                         `perm:"read"`
+	StateCompute             func(context.Context, abi.ChainEpoch, []*types.UnsignedMessage, block.TipSetKey) (*syncApiTypes.ComputeStateOutput, error) `perm:"read"`
+	ChainValidate            func(context.Context, abi.ChainEpoch, abi.ChainEpoch) (*syncApiTypes.ChainValidateResult, error)                           `perm:"admin"`
+	SyncState                func(context.Context) (*syncApiTypes.SyncState, error)                                                                     `perm:"read"`
+	SyncMarkBad              func(context.Context, block.TipSetKey, string) error                                                                       `perm:"admin"`
+	SyncUnmarkBad            func(context.Context, block.TipSetKey) error                                                                               `perm:"admin"`
+	SyncCheckBad             func(context.Context, block.TipSetKey) (*syncTypes.BadTipSetInfo, error)                                                   `perm:"read"`
 
-	DeleteByAdress          func(context.Context, address.Address) error
-	MpoolPublish            func(context.Context, address.Address) error
-	MpoolPush               func(context.Context, *types.SignedMessage) (cid.Cid, error)
-	MpoolGetConfig          func(context.Context) (*messagepool.MpoolConfig, error)
-	MpoolSetConfig          func(context.Context, *messagepool.MpoolConfig) error
-	MpoolSelect             func(context.Context, block.TipSetKey, float64) ([]*types.SignedMessage, error)
-	MpoolPending            func(context.Context, block.TipSetKey) ([]*types.SignedMessage, error)
-	MpoolClear              func(context.Context, bool) error
-	MpoolPushUntrusted      func(context.Context, *types.SignedMessage) (cid.Cid, error)
-	MpoolPushMessage        func(context.Context, *types.UnsignedMessage, *types.MessageSendSpec) (*types.SignedMessage, error)
-	MpoolBatchPush          func(context.Context, []*types.SignedMessage) ([]cid.Cid, error)
-	MpoolBatchPushUntrusted func(context.Context, []*types.SignedMessage) ([]cid.Cid, error)
-	MpoolBatchPushMessage   func(context.Context, []*types.UnsignedMessage, *types.MessageSendSpec) ([]*types.SignedMessage, error)
-	MpoolGetNonce           func(context.Context, address.Address) (uint64, error)
-	MpoolSub                func(context.Context) (chan messagepool.MpoolUpdate, error)
-	SendMsg                 func(context.Context, address.Address, abi.MethodNum, abi.TokenAmount, []byte) (cid.Cid, error)
-	GasEstimateMessageGas   func(context.Context, *types.UnsignedMessage, *types.MessageSendSpec, block.TipSetKey) (*types.UnsignedMessage, error)
-	GasEstimateFeeCap       func(context.Context, *types.UnsignedMessage, int64, block.TipSetKey) (big.Int, error)
-	GasEstimateGasPremium   func(context.Context, uint64, address.Address, int64, block.TipSetKey) (big.Int, error)
-	WalletSign              func(context.Context, address.Address, []byte) (*crypto.Signature, error)
-
-	NetworkGetBandwidthStats  func() metrics.Stats
-	NetworkGetPeerAddresses   func() []ma.Multiaddr
-	NetworkGetPeerID          func() peer.ID
-	NetworkFindProvidersAsync func(context.Context, cid.Cid, int) chan peer.AddrInfo
-	NetworkGetClosestPeers    func(context.Context, string) (chan peer.ID, error)
-	NetworkFindPeer           func(context.Context, peer.ID) (peer.AddrInfo, error)
-	NetworkConnect            func(context.Context, []string) (chan net.ConnectionResult, error)
-	NetworkPeers              func(context.Context, bool) (*net.SwarmConnInfos, error)
-	Version                   func(context.Context) (network.Version, error)
-	NetAddrsListen            func(context.Context) (peer.AddrInfo, error)
+	DeleteByAdress             func(context.Context, address.Address) error                                                                                      `perm:"admin"`
+	MpoolPublish               func(context.Context, address.Address) error                                                                                      `perm:"write"`
+	MpoolPush                  func(context.Context, *types.SignedMessage) (cid.Cid, error)                                                                      `perm:"write"`
+	MpoolGetConfig             func(context.Context) (*messagepool.MpoolConfig, error)                                                                           `perm:"read"`
+	MpoolSetConfig             func(context.Context, *messagepool.MpoolConfig) error                                                                             `perm:"admin"`
+	MpoolSelect                func(context.Context, block.TipSetKey, float64) ([]*types.SignedMessage, error)                                                   `perm:"read"`
+	MpoolPending               func(context.Context, block.TipSetKey) ([]*types.SignedMessage, error)                                                            `perm:"read"`
+	MpoolClear                 func(context.Context, bool) error                                                                                                 `perm:"admin"`
+	MpoolPushUntrusted         func(context.Context, *types.SignedMessage) (cid.Cid, error)                                                                      `perm:"write"`
+	MpoolPushMessage           func(context.Context, *types.UnsignedMessage, *types.MessageSendSpec) (*types.SignedMessage, error)                               `perm:"sign"`
+	MpoolBatchPush             func(context.Context, []*types.SignedMessage) ([]cid.Cid, error)                                                                  `perm:"write"`
+	MpoolBatchPushUntrusted    func(context.Context, []*types.SignedMessage) ([]cid.Cid, error)                                                                  `perm:"write"`
+	MpoolBatchPushMessage      func(context.Context, []*types.UnsignedMessage, *types.MessageSendSpec) ([]*types.SignedMessage, error)                           `perm:"sign"`
+	MpoolGetNonce              func(context.Context, address.Address) (uint64, error)                                                                            `perm:"read"`
+	MpoolSub                   func(context.Context) (<-chan messagepool.MpoolUpdate, error)                                                                     `perm:"read"`
+	MpoolCheckMessages         func(context.Context, []*messagepool.MessagePrototype) ([][]messagepool.MessageCheckStatus, error)                                `perm:"read"`
+	MpoolCheckPendingMessages  func(context.Context, address.Address) ([][]messagepool.MessageCheckStatus, error)                                                `perm:"read"`
+	MpoolCheckReplaceMessages  func(context.Context, []*types.UnsignedMessage) ([][]messagepool.MessageCheckStatus, error)                                       `perm:"read"`
+	SendMsg                    func(context.Context, address.Address, address.Address, abi.MethodNum, abi.TokenAmount, abi.TokenAmount, []byte) (cid.Cid, error) `perm:"sign"`
+	GasEstimateMessageGas      func(context.Context, *types.UnsignedMessage, *types.MessageSendSpec, block.TipSetKey) (*types.UnsignedMessage, error)            `perm:"read"`
+	GasBatchEstimateMessageGas func(context.Context, []*messagepool.EstimateMessage, uint64, block.TipSetKey) ([]*messagepool.EstimateResult, error)             `perm:"read"`
+	GasEstimateFeeCap          func(context.Context, *types.UnsignedMessage, int64, block.TipSetKey) (big.Int, error)                                            `perm:"read"`
+	GasEstimateGasPremium      func(context.Context, uint64, address.Address, int64, block.TipSetKey) (big.Int, error)                                           `perm:"read"`
+	ChainGetFeeHistory         func(context.Context, int, []float64) (*messagepool.FeeHistory, error)                                                            `perm:"read"`
+	WalletSign                 func(context.Context, address.Address, []byte) (*crypto.Signature, error)                                                         `perm:"sign"`
 
-	WalletBalance        func(context.Context, address.Address) (abi.TokenAmount, error)
-	WalletHas            func(context.Context, address.Address) (bool, error)
-	WalletDefaultAddress func() (address.Address, error)
-	WalletAddresses      func() []address.Address
-	WalletSetDefault     func(context.Context, address.Address) error
-	WalletNewAddress     func(address.Protocol) (address.Address, error)
-	WalletImport         func(*crypto.KeyInfo) (address.Address, error)
-	WalletExport         func([]address.Address) ([]*crypto.KeyInfo, error)
-	WalletSignMessage    func(context.Context, address.Address, *types.UnsignedMessage) (*types.SignedMessage, error)
+	NetworkGetBandwidthStats  func(context.Context) metrics.Stats                                  `perm:"read"`
+	NetworkGetPeerAddresses   func(context.Context) []ma.Multiaddr                                 `perm:"read"`
+	NetworkGetPeerID          func(context.Context) peer.ID                                        `perm:"read"`
+	NetworkFindProvidersAsync func(context.Context, cid.Cid, int) <-chan peer.AddrInfo             `perm:"read"`
+	NetworkGetClosestPeers    func(context.Context, string) (<-chan peer.ID, error)                `perm:"read"`
+	NetworkFindPeer           func(context.Context, peer.ID) (peer.AddrInfo, error)                `perm:"read"`
+	NetworkConnect            func(context.Context, []string) (<-chan net.ConnectionResult, error) `perm:"write"`
+	NetworkPeers              func(context.Context, bool, bool, bool) (*net.SwarmConnInfos, error) `perm:"read"`
+	Version                   func(context.Context) (networkApiTypes.Version, error)               `perm:"read"`
+	NetAddrsListen            func(context.Context) (peer.AddrInfo, error)                         `perm:"read"`
 
-	ChainReadObj func(context.Context, cid.Cid) ([]byte, error)
-	ChainHasObj  func(context.Context, cid.Cid) (bool, error)
-	ChainExport  func(context.Context, block.TipSetKey, io.Writer) error
+	WalletBalance        func(context.Context, address.Address) (abi.TokenAmount, error)                              `perm:"read"`
+	WalletHas            func(context.Context, address.Address) (bool, error)                                         `perm:"read"`
+	WalletDefaultAddress func(context.Context) (address.Address, error)                                               `perm:"write"`
+	WalletAddresses      func(context.Context) []address.Address                                                      `perm:"read"`
+	WalletSetDefault     func(context.Context, address.Address) error                                                 `perm:"write"`
+	WalletNewAddress     func(context.Context, address.Protocol) (address.Address, error)                             `perm:"write"`
+	WalletImport         func(context.Context, *crypto.KeyInfo) (address.Address, error)                              `perm:"admin"`
+	WalletExport         func(context.Context, []address.Address) ([]*crypto.KeyInfo, error)                          `perm:"admin"`
+	WalletSignMessage    func(context.Context, address.Address, *types.UnsignedMessage) (*types.SignedMessage, error) `perm:"sign"`
+	WalletSetPassphrase  func(context.Context, []byte) error                                                          `perm:"admin"`
+	WalletUnlock         func(context.Context, []byte, time.Duration) error                                           `perm:"admin"`
+	WalletLock           func(context.Context) error                                                                  `perm:"admin"`
+	WalletLocked         func(context.Context) (bool, error)                                                          `perm:"read"`
 
-	StateAccountKey func(context.Context, address.Address, block.TipSetKey) (address.Address, error)
+	ChainReadObj func(context.Context, cid.Cid) ([]byte, error) `perm:"read"`
+	ChainHasObj  func(context.Context, cid.Cid) (bool, error)   `perm:"read"`
 
-	StateGetActor     func(context.Context, address.Address, block.TipSetKey) (*types.Actor, error)
-	ActorGetSignature func(context.Context, address.Address, abi.MethodNum) (vm.ActorMethodSignature, error)
-	ListActor         func(context.Context) (map[address.Address]*types.Actor, error)
+	StateAccountKey func(context.Context, address.Address, block.TipSetKey) (address.Address, error) `perm:"read"`
 
-	BeaconGetEntry func(context.Context, abi.ChainEpoch) (*block.BeaconEntry, error)
+	StateGetActor          func(context.Context, address.Address, block.TipSetKey) (*types.Actor, error)                           `perm:"read"`
+	ActorGetSignature      func(context.Context, address.Address, abi.MethodNum) (vm.ActorMethodSignature, error)                  `perm:"read"`
+	ListActor              func(context.Context) (map[address.Address]*types.Actor, error)                                         `perm:"read"`
+	StateChangedActors     func(context.Context, cid.Cid, cid.Cid) (*vmstate.ActorChanges, error)                                  `perm:"read"`
+	StateDiffActorState    func(context.Context, address.Address, cid.Cid, cid.Cid) (*pstate.ActorStateChanges, error)             `perm:"read"`
+	StateReadState         func(context.Context, address.Address, block.TipSetKey) (*chainApiTypes.ActorState, error)              `perm:"read"`
+	StateReadStateExpanded func(context.Context, address.Address, block.TipSetKey) (*chainApiTypes.ActorState, error)              `perm:"read"`
+	StateDecodeParams      func(context.Context, address.Address, abi.MethodNum, []byte, block.TipSetKey) (interface{}, error)     `perm:"read"`
+	StateDecodeReturn      func(context.Context, address.Address, abi.MethodNum, []byte, block.TipSetKey) (interface{}, error)     `perm:"read"`
+	StateEncodeParams      func(context.Context, address.Address, abi.MethodNum, json.RawMessage, block.TipSetKey) ([]byte, error) `perm:"read"`
+	StateGetMethodNum      func(context.Context, address.Address, string, block.TipSetKey) (abi.MethodNum, error)                  `perm:"read"`
 
-	MinerGetBaseInfo func(context.Context, address.Address, abi.ChainEpoch, block.TipSetKey) (*block.MiningBaseInfo, error)
-	MinerCreateBlock func(context.Context, *mineApiTypes.BlockTemplate) (*block.BlockMsg, error)
+	BeaconGetEntry func(context.Context, abi.ChainEpoch) (*block.BeaconEntry, error) `perm:"read"`
+
+	MinerGetBaseInfo func(context.Context, address.Address, abi.ChainEpoch, block.TipSetKey) (*block.MiningBaseInfo, error) `perm:"read"`
+	MinerCreateBlock func(context.Context, *mineApiTypes.BlockTemplate) (*block.BlockMsg, error)                            `perm:"sign"`
+
+	MsigCreate              func(context.Context, uint64, []address.Address, abi.ChainEpoch, abi.TokenAmount, address.Address) (cid.Cid, error)                                 `perm:"sign"`
+	MsigPropose             func(context.Context, address.Address, address.Address, abi.TokenAmount, address.Address, uint64, []byte) (cid.Cid, error)                          `perm:"sign"`
+	MsigApprove             func(context.Context, address.Address, uint64, address.Address, address.Address, abi.TokenAmount, address.Address, uint64, []byte) (cid.Cid, error) `perm:"sign"`
+	MsigCancel              func(context.Context, address.Address, uint64, address.Address, abi.TokenAmount, address.Address, uint64, []byte) (cid.Cid, error)                  `perm:"sign"`
+	MsigAddSigner           func(context.Context, address.Address, address.Address, address.Address, bool) (cid.Cid, error)                                                     `perm:"sign"`
+	MsigSwapSigner          func(context.Context, address.Address, address.Address, address.Address, address.Address) (cid.Cid, error)                                          `perm:"sign"`
+	MsigRemoveSigner        func(context.Context, address.Address, address.Address, address.Address, bool) (cid.Cid, error)                                                     `perm:"sign"`
+	MsigGetPending          func(context.Context, address.Address, block.TipSetKey) ([]*multisigApiTypes.MsigTransaction, error)                                                `perm:"read"`
+	MsigGetAvailableBalance func(context.Context, address.Address, block.TipSetKey) (abi.TokenAmount, error)                                                                    `perm:"read"`
+	MsigGetVested           func(context.Context, address.Address, block.TipSetKey, block.TipSetKey) (abi.TokenAmount, error)                                                   `perm:"read"`
+
+	PaychGet                    func(context.Context, address.Address, address.Address, big.Int) (*paychApiTypes.ChannelInfo, error) `perm:"sign"`
+	PaychGetWaitReady           func(context.Context, cid.Cid) (address.Address, error)                                              `perm:"sign"`
+	PaychAvailableFunds         func(context.Context, address.Address) (*paychmgr.ChannelAvailableFunds, error)                      `perm:"sign"`
+	PaychAvailableFundsByFromTo func(context.Context, address.Address, address.Address) (*paychmgr.ChannelAvailableFunds, error)     `perm:"sign"`
+	PaychList                   func(context.Context) ([]address.Address, error)                                                     `perm:"read"`
+	PaychStatus                 func(context.Context, address.Address) (*paychApiTypes.PaychStatus, error)                           `perm:"read"`
+	PaychAllocateLane           func(context.Context, address.Address) (uint64, error)                                               `perm:"sign"`
+	PaychVoucherCreate          func(context.Context, address.Address, big.Int, uint64) (*paychmgr.VoucherCreateResult, error)       `perm:"sign"`
+	PaychVoucherCheckValid      func(context.Context, address.Address, *paych.SignedVoucher) error                                   `perm:"read"`
+	PaychVoucherAdd             func(context.Context, address.Address, *paych.SignedVoucher, []byte, big.Int) (big.Int, error)       `perm:"write"`
+	PaychVoucherList            func(context.Context, address.Address) ([]*paych.SignedVoucher, error)                               `perm:"write"`
+	PaychVoucherSubmit          func(context.Context, address.Address, *paych.SignedVoucher, []byte) (cid.Cid, error)                `perm:"sign"`
+	PaychSettle                 func(context.Context, address.Address) (cid.Cid, error)                                              `perm:"sign"`
+	PaychCollect                func(context.Context, address.Address) (cid.Cid, error)                                              `perm:"sign"`
+
+	AuthVerify func(context.Context, string) ([]auth.Permission, error) `perm:"read"`
+	AuthNew    func(context.Context, []auth.Permission) ([]byte, error) `perm:"admin"`
 }
 
 type AccountAPI struct {
@@ -170,9 +238,17 @@ type AccountAPI struct {
 }
 
 type ActorAPI struct {
-	StateGetActor     func(context.Context, address.Address, block.TipSetKey) (*types.Actor, error)
-	ActorGetSignature func(context.Context, address.Address, abi.MethodNum) (vm.ActorMethodSignature, error)
-	ListActor         func(context.Context) (map[address.Address]*types.Actor, error)
+	StateGetActor          func(context.Context, address.Address, block.TipSetKey) (*types.Actor, error)
+	ActorGetSignature      func(context.Context, address.Address, abi.MethodNum) (vm.ActorMethodSignature, error)
+	ListActor              func(context.Context) (map[address.Address]*types.Actor, error)
+	StateChangedActors     func(context.Context, cid.Cid, cid.Cid) (*vmstate.ActorChanges, error)
+	StateDiffActorState    func(context.Context, address.Address, cid.Cid, cid.Cid) (*pstate.ActorStateChanges, error)
+	StateReadState         func(context.Context, address.Address, block.TipSetKey) (*chainApiTypes.ActorState, error)
+	StateReadStateExpanded func(context.Context, address.Address, block.TipSetKey) (*chainApiTypes.ActorState, error)
+	StateDecodeParams      func(context.Context, address.Address, abi.MethodNum, []byte, block.TipSetKey) (interface{}, error)
+	StateDecodeReturn      func(context.Context, address.Address, abi.MethodNum, []byte, block.TipSetKey) (interface{}, error)
+	StateEncodeParams      func(context.Context, address.Address, abi.MethodNum, json.RawMessage, block.TipSetKey) ([]byte, error)
+	StateGetMethodNum      func(context.Context, address.Address, string, block.TipSetKey) (abi.MethodNum, error)
 }
 
 type BeaconAPI struct {
@@ -187,7 +263,6 @@ type MiningAPI struct {
 type DbAPI struct {
 	ChainReadObj func(context.Context, cid.Cid) ([]byte, error)
 	ChainHasObj  func(context.Context, cid.Cid) (bool, error)
-	ChainExport  func(context.Context, block.TipSetKey, io.Writer) error
 }
 
 type MinerStateAPI struct {
@@ -222,66 +297,81 @@ type MinerStateAPI struct {
 }
 
 type ConfigAPI struct {
-	ConfigSet func(string, string) error
-	ConfigGet func(string) (interface{}, error)
+	ConfigSet func(context.Context, string, string) error
+	ConfigGet func(context.Context, string) (interface{}, error)
 }
 
 type SyncerAPI struct {
-	SyncerTracker            func() *syncTypes.TargetTracker
+	SyncerTracker            func(context.Context) *syncTypes.TargetTracker
 	ChainTipSetWeight        func(context.Context, block.TipSetKey) (big.Int, error)
-	ChainSyncHandleNewTipSet func(*block.ChainInfo) error
+	ChainSyncHandleNewTipSet func(context.Context, *block.ChainInfo) error
 	SyncSubmitBlock          func(context.Context, *block.BlockMsg) error
 	StateCall                func(context.Context, *types.UnsignedMessage, block.TipSetKey) (*syncApiTypes.InvocResult, error)
+	StateReplay              func(context.Context, block.TipSetKey, cid.Cid) (*syncApiTypes.InvocResult, error)
+	StateCompute             func(context.Context, abi.ChainEpoch, []*types.UnsignedMessage, block.TipSetKey) (*syncApiTypes.ComputeStateOutput, error)
+	ChainValidate            func(context.Context, abi.ChainEpoch, abi.ChainEpoch) (*syncApiTypes.ChainValidateResult, error)
 	SyncState                func(context.Context) (*syncApiTypes.SyncState, error)
+	SyncMarkBad              func(context.Context, block.TipSetKey, string) error
+	SyncUnmarkBad            func(context.Context, block.TipSetKey) error
+	SyncCheckBad             func(context.Context, block.TipSetKey) (*syncTypes.BadTipSetInfo, error)
 }
 
 type MessagePoolAPI struct {
-	DeleteByAdress          func(context.Context, address.Address) error
-	MpoolPublish            func(context.Context, address.Address) error
-	MpoolPush               func(context.Context, *types.SignedMessage) (cid.Cid, error)
-	MpoolGetConfig          func(context.Context) (*messagepool.MpoolConfig, error)
-	MpoolSetConfig          func(context.Context, *messagepool.MpoolConfig) error
-	MpoolSelect             func(context.Context, block.TipSetKey, float64) ([]*types.SignedMessage, error)
-	MpoolPending            func(context.Context, block.TipSetKey) ([]*types.SignedMessage, error)
-	MpoolClear              func(context.Context, bool) error
-	MpoolPushUntrusted      func(context.Context, *types.SignedMessage) (cid.Cid, error)
-	MpoolPushMessage        func(context.Context, *types.UnsignedMessage, *types.MessageSendSpec) (*types.SignedMessage, error)
-	MpoolBatchPush          func(context.Context, []*types.SignedMessage) ([]cid.Cid, error)
-	MpoolBatchPushUntrusted func(context.Context, []*types.SignedMessage) ([]cid.Cid, error)
-	MpoolBatchPushMessage   func(context.Context, []*types.UnsignedMessage, *types.MessageSendSpec) ([]*types.SignedMessage, error)
-	MpoolGetNonce           func(context.Context, address.Address) (uint64, error)
-	MpoolSub                func(context.Context) (chan messagepool.MpoolUpdate, error)
-	SendMsg                 func(context.Context, address.Address, abi.MethodNum, abi.TokenAmount, []byte) (cid.Cid, error)
-	GasEstimateMessageGas   func(context.Context, *types.UnsignedMessage, *types.MessageSendSpec, block.TipSetKey) (*types.UnsignedMessage, error)
-	GasEstimateFeeCap       func(context.Context, *types.UnsignedMessage, int64, block.TipSetKey) (big.Int, error)
-	GasEstimateGasPremium   func(context.Context, uint64, address.Address, int64, block.TipSetKey) (big.Int, error)
-	WalletSign              func(context.Context, address.Address, []byte) (*crypto.Signature, error)
+	DeleteByAdress             func(context.Context, address.Address) error
+	MpoolPublish               func(context.Context, address.Address) error
+	MpoolPush                  func(context.Context, *types.SignedMessage) (cid.Cid, error)
+	MpoolGetConfig             func(context.Context) (*messagepool.MpoolConfig, error)
+	MpoolSetConfig             func(context.Context, *messagepool.MpoolConfig) error
+	MpoolSelect                func(context.Context, block.TipSetKey, float64) ([]*types.SignedMessage, error)
+	MpoolPending               func(context.Context, block.TipSetKey) ([]*types.SignedMessage, error)
+	MpoolClear                 func(context.Context, bool) error
+	MpoolPushUntrusted         func(context.Context, *types.SignedMessage) (cid.Cid, error)
+	MpoolPushMessage           func(context.Context, *types.UnsignedMessage, *types.MessageSendSpec) (*types.SignedMessage, error)
+	MpoolBatchPush             func(context.Context, []*types.SignedMessage) ([]cid.Cid, error)
+	MpoolBatchPushUntrusted    func(context.Context, []*types.SignedMessage) ([]cid.Cid, error)
+	MpoolBatchPushMessage      func(context.Context, []*types.UnsignedMessage, *types.MessageSendSpec) ([]*types.SignedMessage, error)
+	MpoolGetNonce              func(context.Context, address.Address) (uint64, error)
+	MpoolSub                   func(context.Context) (<-chan messagepool.MpoolUpdate, error)
+	MpoolCheckMessages         func(context.Context, []*messagepool.MessagePrototype) ([][]messagepool.MessageCheckStatus, error)
+	MpoolCheckPendingMessages  func(context.Context, address.Address) ([][]messagepool.MessageCheckStatus, error)
+	MpoolCheckReplaceMessages  func(context.Context, []*types.UnsignedMessage) ([][]messagepool.MessageCheckStatus, error)
+	SendMsg                    func(context.Context, address.Address, address.Address, abi.MethodNum, abi.TokenAmount, abi.TokenAmount, []byte) (cid.Cid, error)
+	GasEstimateMessageGas      func(context.Context, *types.UnsignedMessage, *types.MessageSendSpec, block.TipSetKey) (*types.UnsignedMessage, error)
+	GasBatchEstimateMessageGas func(context.Context, []*messagepool.EstimateMessage, uint64, block.TipSetKey) ([]*messagepool.EstimateResult, error)
+	GasEstimateFeeCap          func(context.Context, *types.UnsignedMessage, int64, block.TipSetKey) (big.Int, error)
+	GasEstimateGasPremium      func(context.Context, uint64, address.Address, int64, block.TipSetKey) (big.Int, error)
+	ChainGetFeeHistory         func(context.Context, int, []float64) (*messagepool.FeeHistory, error)
+	WalletSign                 func(context.Context, address.Address, []byte) (*crypto.Signature, error)
 }
 
 type NetworkAPI struct {
-	NetworkGetBandwidthStats  func() metrics.Stats
-	NetworkGetPeerAddresses   func() []ma.Multiaddr
-	NetworkGetPeerID          func() peer.ID
-	NetworkFindProvidersAsync func(context.Context, cid.Cid, int) chan peer.AddrInfo
-	NetworkGetClosestPeers    func(context.Context, string) (chan peer.ID, error)
+	NetworkGetBandwidthStats  func(context.Context) metrics.Stats
+	NetworkGetPeerAddresses   func(context.Context) []ma.Multiaddr
+	NetworkGetPeerID          func(context.Context) peer.ID
+	NetworkFindProvidersAsync func(context.Context, cid.Cid, int) <-chan peer.AddrInfo
+	NetworkGetClosestPeers    func(context.Context, string) (<-chan peer.ID, error)
 	NetworkFindPeer           func(context.Context, peer.ID) (peer.AddrInfo, error)
-	NetworkConnect            func(context.Context, []string) (chan net.ConnectionResult, error)
-	NetworkPeers              func(context.Context, bool) (*net.SwarmConnInfos, error)
-	Version                   func(context.Context) (network.Version, error)
+	NetworkConnect            func(context.Context, []string) (<-chan net.ConnectionResult, error)
+	NetworkPeers              func(context.Context, bool, bool, bool) (*net.SwarmConnInfos, error)
+	Version                   func(context.Context) (networkApiTypes.Version, error)
 	NetAddrsListen            func(context.Context) (peer.AddrInfo, error)
 }
 
 type WalletAPI struct {
 	WalletBalance        func(context.Context, address.Address) (abi.TokenAmount, error)
 	WalletHas            func(context.Context, address.Address) (bool, error)
-	WalletDefaultAddress func() (address.Address, error)
-	WalletAddresses      func() []address.Address
+	WalletDefaultAddress func(context.Context) (address.Address, error)
+	WalletAddresses      func(context.Context) []address.Address
 	WalletSetDefault     func(context.Context, address.Address) error
-	WalletNewAddress     func(address.Protocol) (address.Address, error)
-	WalletImport         func(*crypto.KeyInfo) (address.Address, error)
-	WalletExport         func([]address.Address) ([]*crypto.KeyInfo, error)
+	WalletNewAddress     func(context.Context, address.Protocol) (address.Address, error)
+	WalletImport         func(context.Context, *crypto.KeyInfo) (address.Address, error)
+	WalletExport         func(context.Context, []address.Address) ([]*crypto.KeyInfo, error)
 	WalletSign           func(context.Context, address.Address, []byte, wallet.MsgMeta) (*crypto.Signature, error)
 	WalletSignMessage    func(context.Context, address.Address, *types.UnsignedMessage) (*types.SignedMessage, error)
+	WalletSetPassphrase  func(context.Context, []byte) error
+	WalletUnlock         func(context.Context, []byte, time.Duration) error
+	WalletLock           func(context.Context) error
+	WalletLocked         func(context.Context) (bool, error)
 }
 
 type BlockServiceAPI struct {
@@ -292,13 +382,14 @@ type BlockServiceAPI struct {
 }
 
 type ChainInfoAPI struct {
-	BlockTime                     func() time.Duration
+	BlockTime                     func(context.Context) time.Duration
 	ChainList                     func(context.Context, block.TipSetKey, int) ([]block.TipSetKey, error)
 	ProtocolParameters            func(context.Context) (*chainApiTypes.ProtocolParams, error)
 	ChainHead                     func(context.Context) (*block.TipSet, error)
 	ChainSetHead                  func(context.Context, block.TipSetKey) error
-	ChainGetTipSet                func(block.TipSetKey) (*block.TipSet, error)
+	ChainGetTipSet                func(context.Context, block.TipSetKey) (*block.TipSet, error)
 	ChainGetTipSetByHeight        func(context.Context, abi.ChainEpoch, block.TipSetKey) (*block.TipSet, error)
+	ChainGetPath                  func(context.Context, block.TipSetKey, block.TipSetKey) ([]*chain.HeadChange, error)
 	GetActor                      func(context.Context, address.Address) (*types.Actor, error)
 	ChainGetBlock                 func(context.Context, cid.Cid) (*block.Block, error)
 	ChainGetMessage               func(context.Context, cid.Cid) (*types.UnsignedMessage, error)
@@ -307,14 +398,47 @@ type ChainInfoAPI struct {
 	GetFullBlock                  func(context.Context, cid.Cid) (*block.FullBlock, error)
 	ResolveToKeyAddr              func(context.Context, address.Address, *block.TipSet) (address.Address, error)
 	ChainNotify                   func(context.Context) chan []*chain.HeadChange
+	ChainNotifyFrom               func(context.Context, block.TipSetKey, abi.ChainEpoch, *chain.CoalesceOptions) (chan []*chain.HeadChange, error)
 	GetEntry                      func(context.Context, abi.ChainEpoch, uint64) (*block.BeaconEntry, error)
-	VerifyEntry                   func(*block.BeaconEntry, abi.ChainEpoch) bool
+	VerifyEntry                   func(context.Context, *block.BeaconEntry, *block.BeaconEntry, abi.ChainEpoch) bool
 	StateNetworkName              func(context.Context) (chainApiTypes.NetworkName, error)
 	ChainGetRandomnessFromBeacon  func(context.Context, block.TipSetKey, acrypto.DomainSeparationTag, abi.ChainEpoch, []byte) (abi.Randomness, error)
 	ChainGetRandomnessFromTickets func(context.Context, block.TipSetKey, acrypto.DomainSeparationTag, abi.ChainEpoch, []byte) (abi.Randomness, error)
 	StateNetworkVersion           func(context.Context, block.TipSetKey) (network.Version, error)
-	MessageWait                   func(context.Context, cid.Cid, abi.ChainEpoch) (*cst.ChainMessage, error)
+	MessageWait                   func(context.Context, cid.Cid, abi.ChainEpoch, abi.ChainEpoch) (*cst.ChainMessage, error)
 	StateSearchMsg                func(context.Context, cid.Cid) (*cst.MsgLookup, error)
+	StateListMessages             func(context.Context, *chainApiTypes.MessageMatch, block.TipSetKey, abi.ChainEpoch) ([]cid.Cid, error)
 	StateWaitMsg                  func(context.Context, cid.Cid, abi.ChainEpoch) (*cst.MsgLookup, error)
 	StateGetReceipt               func(context.Context, cid.Cid, block.TipSetKey) (*types.MessageReceipt, error)
+	ChainExport                   func(context.Context, abi.ChainEpoch, bool, block.TipSetKey) (<-chan []byte, error)
+}
+
+type MultiSigAPI struct {
+	MsigCreate              func(context.Context, uint64, []address.Address, abi.ChainEpoch, abi.TokenAmount, address.Address) (cid.Cid, error)
+	MsigPropose             func(context.Context, address.Address, address.Address, abi.TokenAmount, address.Address, uint64, []byte) (cid.Cid, error)
+	MsigApprove             func(context.Context, address.Address, uint64, address.Address, address.Address, abi.TokenAmount, address.Address, uint64, []byte) (cid.Cid, error)
+	MsigCancel              func(context.Context, address.Address, uint64, address.Address, abi.TokenAmount, address.Address, uint64, []byte) (cid.Cid, error)
+	MsigAddSigner           func(context.Context, address.Address, address.Address, address.Address, bool) (cid.Cid, error)
+	MsigSwapSigner          func(context.Context, address.Address, address.Address, address.Address, address.Address) (cid.Cid, error)
+	MsigRemoveSigner        func(context.Context, address.Address, address.Address, address.Address, bool) (cid.Cid, error)
+	MsigGetPending          func(context.Context, address.Address, block.TipSetKey) ([]*multisigApiTypes.MsigTransaction, error)
+	MsigGetAvailableBalance func(context.Context, address.Address, block.TipSetKey) (abi.TokenAmount, error)
+	MsigGetVested           func(context.Context, address.Address, block.TipSetKey, block.TipSetKey) (abi.TokenAmount, error)
+}
+
+type PaychAPI struct {
+	PaychGet                    func(context.Context, address.Address, address.Address, big.Int) (*paychApiTypes.ChannelInfo, error)
+	PaychGetWaitReady           func(context.Context, cid.Cid) (address.Address, error)
+	PaychAvailableFunds         func(context.Context, address.Address) (*paychmgr.ChannelAvailableFunds, error)
+	PaychAvailableFundsByFromTo func(context.Context, address.Address, address.Address) (*paychmgr.ChannelAvailableFunds, error)
+	PaychList                   func(context.Context) ([]address.Address, error)
+	PaychStatus                 func(context.Context, address.Address) (*paychApiTypes.PaychStatus, error)
+	PaychAllocateLane           func(context.Context, address.Address) (uint64, error)
+	PaychVoucherCreate          func(context.Context, address.Address, big.Int, uint64) (*paychmgr.VoucherCreateResult, error)
+	PaychVoucherCheckValid      func(context.Context, address.Address, *paych.SignedVoucher) error
+	PaychVoucherAdd             func(context.Context, address.Address, *paych.SignedVoucher, []byte, big.Int) (big.Int, error)
+	PaychVoucherList            func(context.Context, address.Address) ([]*paych.SignedVoucher, error)
+	PaychVoucherSubmit          func(context.Context, address.Address, *paych.SignedVoucher, []byte) (cid.Cid, error)
+	PaychSettle                 func(context.Context, address.Address) (cid.Cid, error)
+	PaychCollect                func(context.Context, address.Address) (cid.Cid, error)
 }
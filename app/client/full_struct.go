@@ -0,0 +1,727 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	acrypto "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/filecoin-project/go-state-types/dline"
+	"github.com/filecoin-project/go-state-types/network"
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	"github.com/libp2p/go-libp2p-core/metrics"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+
+	chainApiTypes "github.com/filecoin-project/venus/app/submodule/chain"
+	"github.com/filecoin-project/venus/app/submodule/chain/cst"
+	mineApiTypes "github.com/filecoin-project/venus/app/submodule/mining"
+	multisigApiTypes "github.com/filecoin-project/venus/app/submodule/multisig"
+	networkApiTypes "github.com/filecoin-project/venus/app/submodule/network"
+	paychApiTypes "github.com/filecoin-project/venus/app/submodule/paych"
+	syncApiTypes "github.com/filecoin-project/venus/app/submodule/syncer"
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/chain"
+	syncTypes "github.com/filecoin-project/venus/pkg/chainsync/types"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/filecoin-project/venus/pkg/messagepool"
+	"github.com/filecoin-project/venus/pkg/net"
+	"github.com/filecoin-project/venus/pkg/paychmgr"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/miner"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/paych"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/power"
+	pstate "github.com/filecoin-project/venus/pkg/state"
+	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/vm"
+	vmstate "github.com/filecoin-project/venus/pkg/vm/state"
+)
+
+// FullNodeStruct serves the FullNode API with methods, as expected by jsonrpc.RPCServer.
+// The calls are forwarded to Internal, see jwtauth.PermissionedProxy to fill it with
+// implementations checking the permission of the caller.
+type FullNodeStruct struct {
+	Internal FullNode
+}
+
+func (s *FullNodeStruct) DAGGetNode(p0 context.Context, p1 string) (interface{}, error) {
+	return s.Internal.DAGGetNode(p0, p1)
+}
+
+func (s *FullNodeStruct) DAGGetFileSize(p0 context.Context, p1 cid.Cid) (uint64, error) {
+	return s.Internal.DAGGetFileSize(p0, p1)
+}
+
+func (s *FullNodeStruct) DAGCat(p0 context.Context, p1 cid.Cid) (io.Reader, error) {
+	return s.Internal.DAGCat(p0, p1)
+}
+
+func (s *FullNodeStruct) DAGImportData(p0 context.Context, p1 io.Reader) (ipld.Node, error) {
+	return s.Internal.DAGImportData(p0, p1)
+}
+
+func (s *FullNodeStruct) BlockTime(p0 context.Context) time.Duration {
+	return s.Internal.BlockTime(p0)
+}
+
+func (s *FullNodeStruct) ChainList(p0 context.Context, p1 block.TipSetKey, p2 int) ([]block.TipSetKey, error) {
+	return s.Internal.ChainList(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) ProtocolParameters(p0 context.Context) (*chainApiTypes.ProtocolParams, error) {
+	return s.Internal.ProtocolParameters(p0)
+}
+
+func (s *FullNodeStruct) ChainHead(p0 context.Context) (*block.TipSet, error) {
+	return s.Internal.ChainHead(p0)
+}
+
+func (s *FullNodeStruct) ChainSetHead(p0 context.Context, p1 block.TipSetKey) error {
+	return s.Internal.ChainSetHead(p0, p1)
+}
+
+func (s *FullNodeStruct) ChainGetTipSet(p0 context.Context, p1 block.TipSetKey) (*block.TipSet, error) {
+	return s.Internal.ChainGetTipSet(p0, p1)
+}
+
+func (s *FullNodeStruct) ChainGetTipSetByHeight(p0 context.Context, p1 abi.ChainEpoch, p2 block.TipSetKey) (*block.TipSet, error) {
+	return s.Internal.ChainGetTipSetByHeight(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) ChainGetPath(p0 context.Context, p1 block.TipSetKey, p2 block.TipSetKey) ([]*chain.HeadChange, error) {
+	return s.Internal.ChainGetPath(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) GetActor(p0 context.Context, p1 address.Address) (*types.Actor, error) {
+	return s.Internal.GetActor(p0, p1)
+}
+
+func (s *FullNodeStruct) ChainGetBlock(p0 context.Context, p1 cid.Cid) (*block.Block, error) {
+	return s.Internal.ChainGetBlock(p0, p1)
+}
+
+func (s *FullNodeStruct) ChainGetMessage(p0 context.Context, p1 cid.Cid) (*types.UnsignedMessage, error) {
+	return s.Internal.ChainGetMessage(p0, p1)
+}
+
+func (s *FullNodeStruct) ChainGetBlockMessages(p0 context.Context, p1 cid.Cid) (*chainApiTypes.BlockMessages, error) {
+	return s.Internal.ChainGetBlockMessages(p0, p1)
+}
+
+func (s *FullNodeStruct) ChainGetReceipts(p0 context.Context, p1 cid.Cid) ([]types.MessageReceipt, error) {
+	return s.Internal.ChainGetReceipts(p0, p1)
+}
+
+func (s *FullNodeStruct) ChainGetParentMessages(p0 context.Context, p1 cid.Cid) ([]chainApiTypes.Message, error) {
+	return s.Internal.ChainGetParentMessages(p0, p1)
+}
+
+func (s *FullNodeStruct) ChainGetParentReceipts(p0 context.Context, p1 cid.Cid) ([]*types.MessageReceipt, error) {
+	return s.Internal.ChainGetParentReceipts(p0, p1)
+}
+
+func (s *FullNodeStruct) GetFullBlock(p0 context.Context, p1 cid.Cid) (*block.FullBlock, error) {
+	return s.Internal.GetFullBlock(p0, p1)
+}
+
+func (s *FullNodeStruct) ResolveToKeyAddr(p0 context.Context, p1 address.Address, p2 *block.TipSet) (address.Address, error) {
+	return s.Internal.ResolveToKeyAddr(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) ChainNotify(p0 context.Context) chan []*chain.HeadChange {
+	return s.Internal.ChainNotify(p0)
+}
+
+func (s *FullNodeStruct) ChainNotifyFrom(p0 context.Context, p1 block.TipSetKey, p2 abi.ChainEpoch, p3 *chain.CoalesceOptions) (chan []*chain.HeadChange, error) {
+	return s.Internal.ChainNotifyFrom(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) GetEntry(p0 context.Context, p1 abi.ChainEpoch, p2 uint64) (*block.BeaconEntry, error) {
+	return s.Internal.GetEntry(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) VerifyEntry(p0 context.Context, p1 *block.BeaconEntry, p2 *block.BeaconEntry, p3 abi.ChainEpoch) bool {
+	return s.Internal.VerifyEntry(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) StateNetworkName(p0 context.Context) (chainApiTypes.NetworkName, error) {
+	return s.Internal.StateNetworkName(p0)
+}
+
+func (s *FullNodeStruct) ChainGetRandomnessFromBeacon(p0 context.Context, p1 block.TipSetKey, p2 acrypto.DomainSeparationTag, p3 abi.ChainEpoch, p4 []byte) (abi.Randomness, error) {
+	return s.Internal.ChainGetRandomnessFromBeacon(p0, p1, p2, p3, p4)
+}
+
+func (s *FullNodeStruct) ChainGetRandomnessFromTickets(p0 context.Context, p1 block.TipSetKey, p2 acrypto.DomainSeparationTag, p3 abi.ChainEpoch, p4 []byte) (abi.Randomness, error) {
+	return s.Internal.ChainGetRandomnessFromTickets(p0, p1, p2, p3, p4)
+}
+
+func (s *FullNodeStruct) StateNetworkVersion(p0 context.Context, p1 block.TipSetKey) (network.Version, error) {
+	return s.Internal.StateNetworkVersion(p0, p1)
+}
+
+func (s *FullNodeStruct) MessageWait(p0 context.Context, p1 cid.Cid, p2 abi.ChainEpoch, p3 abi.ChainEpoch) (*cst.ChainMessage, error) {
+	return s.Internal.MessageWait(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) StateSearchMsg(p0 context.Context, p1 cid.Cid) (*cst.MsgLookup, error) {
+	return s.Internal.StateSearchMsg(p0, p1)
+}
+
+func (s *FullNodeStruct) StateListMessages(p0 context.Context, p1 *chainApiTypes.MessageMatch, p2 block.TipSetKey, p3 abi.ChainEpoch) ([]cid.Cid, error) {
+	return s.Internal.StateListMessages(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) StateWaitMsg(p0 context.Context, p1 cid.Cid, p2 abi.ChainEpoch) (*cst.MsgLookup, error) {
+	return s.Internal.StateWaitMsg(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateGetReceipt(p0 context.Context, p1 cid.Cid, p2 block.TipSetKey) (*types.MessageReceipt, error) {
+	return s.Internal.StateGetReceipt(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) ChainExport(p0 context.Context, p1 abi.ChainEpoch, p2 bool, p3 block.TipSetKey) (<-chan []byte, error) {
+	return s.Internal.ChainExport(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) StateMinerSectorAllocated(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 block.TipSetKey) (bool, error) {
+	return s.Internal.StateMinerSectorAllocated(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) StateSectorPreCommitInfo(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 block.TipSetKey) (miner.SectorPreCommitOnChainInfo, error) {
+	return s.Internal.StateSectorPreCommitInfo(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) StateSectorGetInfo(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 block.TipSetKey) (*miner.SectorOnChainInfo, error) {
+	return s.Internal.StateSectorGetInfo(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) StateSectorPartition(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 block.TipSetKey) (*miner.SectorLocation, error) {
+	return s.Internal.StateSectorPartition(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) StateMinerSectorSize(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (abi.SectorSize, error) {
+	return s.Internal.StateMinerSectorSize(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateMinerInfo(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (miner.MinerInfo, error) {
+	return s.Internal.StateMinerInfo(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateMinerWorkerAddress(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (address.Address, error) {
+	return s.Internal.StateMinerWorkerAddress(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateMinerRecoveries(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (bitfield.BitField, error) {
+	return s.Internal.StateMinerRecoveries(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateMinerFaults(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (bitfield.BitField, error) {
+	return s.Internal.StateMinerFaults(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateMinerProvingDeadline(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (*dline.Info, error) {
+	return s.Internal.StateMinerProvingDeadline(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateMinerPartitions(p0 context.Context, p1 address.Address, p2 uint64, p3 block.TipSetKey) ([]chainApiTypes.Partition, error) {
+	return s.Internal.StateMinerPartitions(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) StateMinerDeadlines(p0 context.Context, p1 address.Address, p2 block.TipSetKey) ([]chainApiTypes.Deadline, error) {
+	return s.Internal.StateMinerDeadlines(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateMinerSectors(p0 context.Context, p1 address.Address, p2 *bitfield.BitField, p3 block.TipSetKey) ([]*miner.SectorOnChainInfo, error) {
+	return s.Internal.StateMinerSectors(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) StateMarketStorageDeal(p0 context.Context, p1 abi.DealID, p2 block.TipSetKey) (*chainApiTypes.MarketDeal, error) {
+	return s.Internal.StateMarketStorageDeal(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateMinerPreCommitDepositForPower(p0 context.Context, p1 address.Address, p2 miner.SectorPreCommitInfo, p3 block.TipSetKey) (big.Int, error) {
+	return s.Internal.StateMinerPreCommitDepositForPower(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) StateMinerInitialPledgeCollateral(p0 context.Context, p1 address.Address, p2 miner.SectorPreCommitInfo, p3 block.TipSetKey) (big.Int, error) {
+	return s.Internal.StateMinerInitialPledgeCollateral(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) StateVMCirculatingSupplyInternal(p0 context.Context, p1 block.TipSetKey) (chain.CirculatingSupply, error) {
+	return s.Internal.StateVMCirculatingSupplyInternal(p0, p1)
+}
+
+func (s *FullNodeStruct) StateCirculatingSupply(p0 context.Context, p1 block.TipSetKey) (abi.TokenAmount, error) {
+	return s.Internal.StateCirculatingSupply(p0, p1)
+}
+
+func (s *FullNodeStruct) StateMarketDeals(p0 context.Context, p1 block.TipSetKey) (map[string]pstate.MarketDeal, error) {
+	return s.Internal.StateMarketDeals(p0, p1)
+}
+
+func (s *FullNodeStruct) StateMinerActiveSectors(p0 context.Context, p1 address.Address, p2 block.TipSetKey) ([]*miner.SectorOnChainInfo, error) {
+	return s.Internal.StateMinerActiveSectors(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateLookupID(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (address.Address, error) {
+	return s.Internal.StateLookupID(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateListMiners(p0 context.Context, p1 block.TipSetKey) ([]address.Address, error) {
+	return s.Internal.StateListMiners(p0, p1)
+}
+
+func (s *FullNodeStruct) StateListActors(p0 context.Context, p1 block.TipSetKey) ([]address.Address, error) {
+	return s.Internal.StateListActors(p0, p1)
+}
+
+func (s *FullNodeStruct) StateMinerPower(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (*power.MinerPower, error) {
+	return s.Internal.StateMinerPower(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateMinerAvailableBalance(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (big.Int, error) {
+	return s.Internal.StateMinerAvailableBalance(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateSectorExpiration(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 block.TipSetKey) (*miner.SectorExpiration, error) {
+	return s.Internal.StateSectorExpiration(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) StateMinerSectorCount(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (chainApiTypes.MinerSectors, error) {
+	return s.Internal.StateMinerSectorCount(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateMarketBalance(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (chainApiTypes.MarketBalance, error) {
+	return s.Internal.StateMarketBalance(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) ConfigSet(p0 context.Context, p1 string, p2 string) error {
+	return s.Internal.ConfigSet(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) ConfigGet(p0 context.Context, p1 string) (interface{}, error) {
+	return s.Internal.ConfigGet(p0, p1)
+}
+
+func (s *FullNodeStruct) SyncerTracker(p0 context.Context) *syncTypes.TargetTracker {
+	return s.Internal.SyncerTracker(p0)
+}
+
+func (s *FullNodeStruct) SetConcurrent(p0 context.Context, p1 int64) error {
+	return s.Internal.SetConcurrent(p0, p1)
+}
+
+func (s *FullNodeStruct) ChainTipSetWeight(p0 context.Context, p1 block.TipSetKey) (big.Int, error) {
+	return s.Internal.ChainTipSetWeight(p0, p1)
+}
+
+func (s *FullNodeStruct) ChainSyncHandleNewTipSet(p0 context.Context, p1 *block.ChainInfo) error {
+	return s.Internal.ChainSyncHandleNewTipSet(p0, p1)
+}
+
+func (s *FullNodeStruct) SyncSubmitBlock(p0 context.Context, p1 *block.BlockMsg) error {
+	return s.Internal.SyncSubmitBlock(p0, p1)
+}
+
+func (s *FullNodeStruct) StateCall(p0 context.Context, p1 *types.UnsignedMessage, p2 block.TipSetKey) (*syncApiTypes.InvocResult, error) {
+	return s.Internal.StateCall(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateReplay(p0 context.Context, p1 block.TipSetKey, p2 cid.Cid) (*syncApiTypes.InvocResult, error) {
+	return s.Internal.StateReplay(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateCompute(p0 context.Context, p1 abi.ChainEpoch, p2 []*types.UnsignedMessage, p3 block.TipSetKey) (*syncApiTypes.ComputeStateOutput, error) {
+	return s.Internal.StateCompute(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) ChainValidate(p0 context.Context, p1 abi.ChainEpoch, p2 abi.ChainEpoch) (*syncApiTypes.ChainValidateResult, error) {
+	return s.Internal.ChainValidate(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) SyncState(p0 context.Context) (*syncApiTypes.SyncState, error) {
+	return s.Internal.SyncState(p0)
+}
+
+func (s *FullNodeStruct) SyncMarkBad(p0 context.Context, p1 block.TipSetKey, p2 string) error {
+	return s.Internal.SyncMarkBad(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) SyncUnmarkBad(p0 context.Context, p1 block.TipSetKey) error {
+	return s.Internal.SyncUnmarkBad(p0, p1)
+}
+
+func (s *FullNodeStruct) SyncCheckBad(p0 context.Context, p1 block.TipSetKey) (*syncTypes.BadTipSetInfo, error) {
+	return s.Internal.SyncCheckBad(p0, p1)
+}
+
+func (s *FullNodeStruct) DeleteByAdress(p0 context.Context, p1 address.Address) error {
+	return s.Internal.DeleteByAdress(p0, p1)
+}
+
+func (s *FullNodeStruct) MpoolPublish(p0 context.Context, p1 address.Address) error {
+	return s.Internal.MpoolPublish(p0, p1)
+}
+
+func (s *FullNodeStruct) MpoolPush(p0 context.Context, p1 *types.SignedMessage) (cid.Cid, error) {
+	return s.Internal.MpoolPush(p0, p1)
+}
+
+func (s *FullNodeStruct) MpoolGetConfig(p0 context.Context) (*messagepool.MpoolConfig, error) {
+	return s.Internal.MpoolGetConfig(p0)
+}
+
+func (s *FullNodeStruct) MpoolSetConfig(p0 context.Context, p1 *messagepool.MpoolConfig) error {
+	return s.Internal.MpoolSetConfig(p0, p1)
+}
+
+func (s *FullNodeStruct) MpoolSelect(p0 context.Context, p1 block.TipSetKey, p2 float64) ([]*types.SignedMessage, error) {
+	return s.Internal.MpoolSelect(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) MpoolPending(p0 context.Context, p1 block.TipSetKey) ([]*types.SignedMessage, error) {
+	return s.Internal.MpoolPending(p0, p1)
+}
+
+func (s *FullNodeStruct) MpoolClear(p0 context.Context, p1 bool) error {
+	return s.Internal.MpoolClear(p0, p1)
+}
+
+func (s *FullNodeStruct) MpoolPushUntrusted(p0 context.Context, p1 *types.SignedMessage) (cid.Cid, error) {
+	return s.Internal.MpoolPushUntrusted(p0, p1)
+}
+
+func (s *FullNodeStruct) MpoolPushMessage(p0 context.Context, p1 *types.UnsignedMessage, p2 *types.MessageSendSpec) (*types.SignedMessage, error) {
+	return s.Internal.MpoolPushMessage(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) MpoolBatchPush(p0 context.Context, p1 []*types.SignedMessage) ([]cid.Cid, error) {
+	return s.Internal.MpoolBatchPush(p0, p1)
+}
+
+func (s *FullNodeStruct) MpoolBatchPushUntrusted(p0 context.Context, p1 []*types.SignedMessage) ([]cid.Cid, error) {
+	return s.Internal.MpoolBatchPushUntrusted(p0, p1)
+}
+
+func (s *FullNodeStruct) MpoolBatchPushMessage(p0 context.Context, p1 []*types.UnsignedMessage, p2 *types.MessageSendSpec) ([]*types.SignedMessage, error) {
+	return s.Internal.MpoolBatchPushMessage(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) MpoolGetNonce(p0 context.Context, p1 address.Address) (uint64, error) {
+	return s.Internal.MpoolGetNonce(p0, p1)
+}
+
+func (s *FullNodeStruct) MpoolSub(p0 context.Context) (<-chan messagepool.MpoolUpdate, error) {
+	return s.Internal.MpoolSub(p0)
+}
+
+func (s *FullNodeStruct) MpoolCheckMessages(p0 context.Context, p1 []*messagepool.MessagePrototype) ([][]messagepool.MessageCheckStatus, error) {
+	return s.Internal.MpoolCheckMessages(p0, p1)
+}
+
+func (s *FullNodeStruct) MpoolCheckPendingMessages(p0 context.Context, p1 address.Address) ([][]messagepool.MessageCheckStatus, error) {
+	return s.Internal.MpoolCheckPendingMessages(p0, p1)
+}
+
+func (s *FullNodeStruct) MpoolCheckReplaceMessages(p0 context.Context, p1 []*types.UnsignedMessage) ([][]messagepool.MessageCheckStatus, error) {
+	return s.Internal.MpoolCheckReplaceMessages(p0, p1)
+}
+
+func (s *FullNodeStruct) SendMsg(p0 context.Context, p1 address.Address, p2 address.Address, p3 abi.MethodNum, p4 abi.TokenAmount, p5 abi.TokenAmount, p6 []byte) (cid.Cid, error) {
+	return s.Internal.SendMsg(p0, p1, p2, p3, p4, p5, p6)
+}
+
+func (s *FullNodeStruct) GasEstimateMessageGas(p0 context.Context, p1 *types.UnsignedMessage, p2 *types.MessageSendSpec, p3 block.TipSetKey) (*types.UnsignedMessage, error) {
+	return s.Internal.GasEstimateMessageGas(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) GasBatchEstimateMessageGas(p0 context.Context, p1 []*messagepool.EstimateMessage, p2 uint64, p3 block.TipSetKey) ([]*messagepool.EstimateResult, error) {
+	return s.Internal.GasBatchEstimateMessageGas(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) GasEstimateFeeCap(p0 context.Context, p1 *types.UnsignedMessage, p2 int64, p3 block.TipSetKey) (big.Int, error) {
+	return s.Internal.GasEstimateFeeCap(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) GasEstimateGasPremium(p0 context.Context, p1 uint64, p2 address.Address, p3 int64, p4 block.TipSetKey) (big.Int, error) {
+	return s.Internal.GasEstimateGasPremium(p0, p1, p2, p3, p4)
+}
+
+func (s *FullNodeStruct) ChainGetFeeHistory(p0 context.Context, p1 int, p2 []float64) (*messagepool.FeeHistory, error) {
+	return s.Internal.ChainGetFeeHistory(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) WalletSign(p0 context.Context, p1 address.Address, p2 []byte) (*crypto.Signature, error) {
+	return s.Internal.WalletSign(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) NetworkGetBandwidthStats(p0 context.Context) metrics.Stats {
+	return s.Internal.NetworkGetBandwidthStats(p0)
+}
+
+func (s *FullNodeStruct) NetworkGetPeerAddresses(p0 context.Context) []ma.Multiaddr {
+	return s.Internal.NetworkGetPeerAddresses(p0)
+}
+
+func (s *FullNodeStruct) NetworkGetPeerID(p0 context.Context) peer.ID {
+	return s.Internal.NetworkGetPeerID(p0)
+}
+
+func (s *FullNodeStruct) NetworkFindProvidersAsync(p0 context.Context, p1 cid.Cid, p2 int) <-chan peer.AddrInfo {
+	return s.Internal.NetworkFindProvidersAsync(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) NetworkGetClosestPeers(p0 context.Context, p1 string) (<-chan peer.ID, error) {
+	return s.Internal.NetworkGetClosestPeers(p0, p1)
+}
+
+func (s *FullNodeStruct) NetworkFindPeer(p0 context.Context, p1 peer.ID) (peer.AddrInfo, error) {
+	return s.Internal.NetworkFindPeer(p0, p1)
+}
+
+func (s *FullNodeStruct) NetworkConnect(p0 context.Context, p1 []string) (<-chan net.ConnectionResult, error) {
+	return s.Internal.NetworkConnect(p0, p1)
+}
+
+func (s *FullNodeStruct) NetworkPeers(p0 context.Context, p1 bool, p2 bool, p3 bool) (*net.SwarmConnInfos, error) {
+	return s.Internal.NetworkPeers(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) Version(p0 context.Context) (networkApiTypes.Version, error) {
+	return s.Internal.Version(p0)
+}
+
+func (s *FullNodeStruct) NetAddrsListen(p0 context.Context) (peer.AddrInfo, error) {
+	return s.Internal.NetAddrsListen(p0)
+}
+
+func (s *FullNodeStruct) WalletBalance(p0 context.Context, p1 address.Address) (abi.TokenAmount, error) {
+	return s.Internal.WalletBalance(p0, p1)
+}
+
+func (s *FullNodeStruct) WalletHas(p0 context.Context, p1 address.Address) (bool, error) {
+	return s.Internal.WalletHas(p0, p1)
+}
+
+func (s *FullNodeStruct) WalletDefaultAddress(p0 context.Context) (address.Address, error) {
+	return s.Internal.WalletDefaultAddress(p0)
+}
+
+func (s *FullNodeStruct) WalletAddresses(p0 context.Context) []address.Address {
+	return s.Internal.WalletAddresses(p0)
+}
+
+func (s *FullNodeStruct) WalletSetDefault(p0 context.Context, p1 address.Address) error {
+	return s.Internal.WalletSetDefault(p0, p1)
+}
+
+func (s *FullNodeStruct) WalletNewAddress(p0 context.Context, p1 address.Protocol) (address.Address, error) {
+	return s.Internal.WalletNewAddress(p0, p1)
+}
+
+func (s *FullNodeStruct) WalletImport(p0 context.Context, p1 *crypto.KeyInfo) (address.Address, error) {
+	return s.Internal.WalletImport(p0, p1)
+}
+
+func (s *FullNodeStruct) WalletExport(p0 context.Context, p1 []address.Address) ([]*crypto.KeyInfo, error) {
+	return s.Internal.WalletExport(p0, p1)
+}
+
+func (s *FullNodeStruct) WalletSignMessage(p0 context.Context, p1 address.Address, p2 *types.UnsignedMessage) (*types.SignedMessage, error) {
+	return s.Internal.WalletSignMessage(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) WalletSetPassphrase(p0 context.Context, p1 []byte) error {
+	return s.Internal.WalletSetPassphrase(p0, p1)
+}
+
+func (s *FullNodeStruct) WalletUnlock(p0 context.Context, p1 []byte, p2 time.Duration) error {
+	return s.Internal.WalletUnlock(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) WalletLock(p0 context.Context) error {
+	return s.Internal.WalletLock(p0)
+}
+
+func (s *FullNodeStruct) WalletLocked(p0 context.Context) (bool, error) {
+	return s.Internal.WalletLocked(p0)
+}
+
+func (s *FullNodeStruct) ChainReadObj(p0 context.Context, p1 cid.Cid) ([]byte, error) {
+	return s.Internal.ChainReadObj(p0, p1)
+}
+
+func (s *FullNodeStruct) ChainHasObj(p0 context.Context, p1 cid.Cid) (bool, error) {
+	return s.Internal.ChainHasObj(p0, p1)
+}
+
+func (s *FullNodeStruct) StateAccountKey(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (address.Address, error) {
+	return s.Internal.StateAccountKey(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateGetActor(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (*types.Actor, error) {
+	return s.Internal.StateGetActor(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) ActorGetSignature(p0 context.Context, p1 address.Address, p2 abi.MethodNum) (vm.ActorMethodSignature, error) {
+	return s.Internal.ActorGetSignature(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) ListActor(p0 context.Context) (map[address.Address]*types.Actor, error) {
+	return s.Internal.ListActor(p0)
+}
+
+func (s *FullNodeStruct) StateChangedActors(p0 context.Context, p1 cid.Cid, p2 cid.Cid) (*vmstate.ActorChanges, error) {
+	return s.Internal.StateChangedActors(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateDiffActorState(p0 context.Context, p1 address.Address, p2 cid.Cid, p3 cid.Cid) (*pstate.ActorStateChanges, error) {
+	return s.Internal.StateDiffActorState(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) StateReadState(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (*chainApiTypes.ActorState, error) {
+	return s.Internal.StateReadState(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateReadStateExpanded(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (*chainApiTypes.ActorState, error) {
+	return s.Internal.StateReadStateExpanded(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) StateDecodeParams(p0 context.Context, p1 address.Address, p2 abi.MethodNum, p3 []byte, p4 block.TipSetKey) (interface{}, error) {
+	return s.Internal.StateDecodeParams(p0, p1, p2, p3, p4)
+}
+
+func (s *FullNodeStruct) StateDecodeReturn(p0 context.Context, p1 address.Address, p2 abi.MethodNum, p3 []byte, p4 block.TipSetKey) (interface{}, error) {
+	return s.Internal.StateDecodeReturn(p0, p1, p2, p3, p4)
+}
+
+func (s *FullNodeStruct) StateEncodeParams(p0 context.Context, p1 address.Address, p2 abi.MethodNum, p3 json.RawMessage, p4 block.TipSetKey) ([]byte, error) {
+	return s.Internal.StateEncodeParams(p0, p1, p2, p3, p4)
+}
+
+func (s *FullNodeStruct) StateGetMethodNum(p0 context.Context, p1 address.Address, p2 string, p3 block.TipSetKey) (abi.MethodNum, error) {
+	return s.Internal.StateGetMethodNum(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) BeaconGetEntry(p0 context.Context, p1 abi.ChainEpoch) (*block.BeaconEntry, error) {
+	return s.Internal.BeaconGetEntry(p0, p1)
+}
+
+func (s *FullNodeStruct) MinerGetBaseInfo(p0 context.Context, p1 address.Address, p2 abi.ChainEpoch, p3 block.TipSetKey) (*block.MiningBaseInfo, error) {
+	return s.Internal.MinerGetBaseInfo(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) MinerCreateBlock(p0 context.Context, p1 *mineApiTypes.BlockTemplate) (*block.BlockMsg, error) {
+	return s.Internal.MinerCreateBlock(p0, p1)
+}
+
+func (s *FullNodeStruct) MsigCreate(p0 context.Context, p1 uint64, p2 []address.Address, p3 abi.ChainEpoch, p4 abi.TokenAmount, p5 address.Address) (cid.Cid, error) {
+	return s.Internal.MsigCreate(p0, p1, p2, p3, p4, p5)
+}
+
+func (s *FullNodeStruct) MsigPropose(p0 context.Context, p1 address.Address, p2 address.Address, p3 abi.TokenAmount, p4 address.Address, p5 uint64, p6 []byte) (cid.Cid, error) {
+	return s.Internal.MsigPropose(p0, p1, p2, p3, p4, p5, p6)
+}
+
+func (s *FullNodeStruct) MsigApprove(p0 context.Context, p1 address.Address, p2 uint64, p3 address.Address, p4 address.Address, p5 abi.TokenAmount, p6 address.Address, p7 uint64, p8 []byte) (cid.Cid, error) {
+	return s.Internal.MsigApprove(p0, p1, p2, p3, p4, p5, p6, p7, p8)
+}
+
+func (s *FullNodeStruct) MsigCancel(p0 context.Context, p1 address.Address, p2 uint64, p3 address.Address, p4 abi.TokenAmount, p5 address.Address, p6 uint64, p7 []byte) (cid.Cid, error) {
+	return s.Internal.MsigCancel(p0, p1, p2, p3, p4, p5, p6, p7)
+}
+
+func (s *FullNodeStruct) MsigAddSigner(p0 context.Context, p1 address.Address, p2 address.Address, p3 address.Address, p4 bool) (cid.Cid, error) {
+	return s.Internal.MsigAddSigner(p0, p1, p2, p3, p4)
+}
+
+func (s *FullNodeStruct) MsigSwapSigner(p0 context.Context, p1 address.Address, p2 address.Address, p3 address.Address, p4 address.Address) (cid.Cid, error) {
+	return s.Internal.MsigSwapSigner(p0, p1, p2, p3, p4)
+}
+
+func (s *FullNodeStruct) MsigRemoveSigner(p0 context.Context, p1 address.Address, p2 address.Address, p3 address.Address, p4 bool) (cid.Cid, error) {
+	return s.Internal.MsigRemoveSigner(p0, p1, p2, p3, p4)
+}
+
+func (s *FullNodeStruct) MsigGetPending(p0 context.Context, p1 address.Address, p2 block.TipSetKey) ([]*multisigApiTypes.MsigTransaction, error) {
+	return s.Internal.MsigGetPending(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) MsigGetAvailableBalance(p0 context.Context, p1 address.Address, p2 block.TipSetKey) (abi.TokenAmount, error) {
+	return s.Internal.MsigGetAvailableBalance(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) MsigGetVested(p0 context.Context, p1 address.Address, p2 block.TipSetKey, p3 block.TipSetKey) (abi.TokenAmount, error) {
+	return s.Internal.MsigGetVested(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) PaychGet(p0 context.Context, p1 address.Address, p2 address.Address, p3 big.Int) (*paychApiTypes.ChannelInfo, error) {
+	return s.Internal.PaychGet(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) PaychGetWaitReady(p0 context.Context, p1 cid.Cid) (address.Address, error) {
+	return s.Internal.PaychGetWaitReady(p0, p1)
+}
+
+func (s *FullNodeStruct) PaychAvailableFunds(p0 context.Context, p1 address.Address) (*paychmgr.ChannelAvailableFunds, error) {
+	return s.Internal.PaychAvailableFunds(p0, p1)
+}
+
+func (s *FullNodeStruct) PaychAvailableFundsByFromTo(p0 context.Context, p1 address.Address, p2 address.Address) (*paychmgr.ChannelAvailableFunds, error) {
+	return s.Internal.PaychAvailableFundsByFromTo(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) PaychList(p0 context.Context) ([]address.Address, error) {
+	return s.Internal.PaychList(p0)
+}
+
+func (s *FullNodeStruct) PaychStatus(p0 context.Context, p1 address.Address) (*paychApiTypes.PaychStatus, error) {
+	return s.Internal.PaychStatus(p0, p1)
+}
+
+func (s *FullNodeStruct) PaychAllocateLane(p0 context.Context, p1 address.Address) (uint64, error) {
+	return s.Internal.PaychAllocateLane(p0, p1)
+}
+
+func (s *FullNodeStruct) PaychVoucherCreate(p0 context.Context, p1 address.Address, p2 big.Int, p3 uint64) (*paychmgr.VoucherCreateResult, error) {
+	return s.Internal.PaychVoucherCreate(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) PaychVoucherCheckValid(p0 context.Context, p1 address.Address, p2 *paych.SignedVoucher) error {
+	return s.Internal.PaychVoucherCheckValid(p0, p1, p2)
+}
+
+func (s *FullNodeStruct) PaychVoucherAdd(p0 context.Context, p1 address.Address, p2 *paych.SignedVoucher, p3 []byte, p4 big.Int) (big.Int, error) {
+	return s.Internal.PaychVoucherAdd(p0, p1, p2, p3, p4)
+}
+
+func (s *FullNodeStruct) PaychVoucherList(p0 context.Context, p1 address.Address) ([]*paych.SignedVoucher, error) {
+	return s.Internal.PaychVoucherList(p0, p1)
+}
+
+func (s *FullNodeStruct) PaychVoucherSubmit(p0 context.Context, p1 address.Address, p2 *paych.SignedVoucher, p3 []byte) (cid.Cid, error) {
+	return s.Internal.PaychVoucherSubmit(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStruct) PaychSettle(p0 context.Context, p1 address.Address) (cid.Cid, error) {
+	return s.Internal.PaychSettle(p0, p1)
+}
+
+func (s *FullNodeStruct) PaychCollect(p0 context.Context, p1 address.Address) (cid.Cid, error) {
+	return s.Internal.PaychCollect(p0, p1)
+}
+
+func (s *FullNodeStruct) AuthVerify(p0 context.Context, p1 string) ([]auth.Permission, error) {
+	return s.Internal.AuthVerify(p0, p1)
+}
+
+func (s *FullNodeStruct) AuthNew(p0 context.Context, p1 []auth.Permission) ([]byte, error) {
+	return s.Internal.AuthNew(p0, p1)
+}
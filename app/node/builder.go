@@ -14,6 +14,7 @@ import (
 	"github.com/libp2p/go-libp2p"
 	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/venus/app/client"
 	"github.com/filecoin-project/venus/app/submodule/blockservice"
 	"github.com/filecoin-project/venus/app/submodule/blockstore"
 	"github.com/filecoin-project/venus/app/submodule/chain"
@@ -21,7 +22,9 @@ import (
 	"github.com/filecoin-project/venus/app/submodule/discovery"
 	"github.com/filecoin-project/venus/app/submodule/mining"
 	"github.com/filecoin-project/venus/app/submodule/mpool"
+	"github.com/filecoin-project/venus/app/submodule/multisig"
 	"github.com/filecoin-project/venus/app/submodule/network"
+	"github.com/filecoin-project/venus/app/submodule/paych"
 	"github.com/filecoin-project/venus/app/submodule/storagenetworking"
 	"github.com/filecoin-project/venus/app/submodule/syncer"
 	"github.com/filecoin-project/venus/app/submodule/wallet"
@@ -261,6 +264,10 @@ func (b *Builder) build(ctx context.Context) (*Node, error) {
 		return nil, errors.Wrap(err, "failed to build node.mpool")
 	}
 
+	nd.multiSig = multisig.NewMultiSigSubmodule(nd.chain, nd.mpool)
+
+	nd.paych = paych.NewPaychSubmodule(ctx, b.repo, nd.chain, nd.mpool, nd.wallet)
+
 	nd.storageNetworking, err = storagenetworking.NewStorgeNetworkingSubmodule(ctx, nd.network)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to build node.storageNetworking")
@@ -284,12 +291,18 @@ func (b *Builder) build(ctx context.Context) (*Node, error) {
 		nd.storageNetworking,
 		nd.mining,
 		nd.mpool,
+		nd.multiSig,
+		nd.paych,
 		nd.jwtAuth,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, "add service failed ")
 	}
-	nd.jsonRPCService = apiBuilder.Build()
+	var fullNode client.FullNodeStruct
+	if err := jwtauth.PermissionedProxy(&fullNode.Internal, apiBuilder.APIs()...); err != nil {
+		return nil, errors.Wrap(err, "build permissioned api failed")
+	}
+	nd.jsonRPCService = apiBuilder.BuildProxy(&fullNode)
 	return nd, nil
 }
 
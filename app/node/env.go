@@ -12,10 +12,13 @@ import (
 	"github.com/filecoin-project/venus/app/submodule/discovery"
 	"github.com/filecoin-project/venus/app/submodule/mining"
 	"github.com/filecoin-project/venus/app/submodule/mpool"
+	"github.com/filecoin-project/venus/app/submodule/multisig"
 	"github.com/filecoin-project/venus/app/submodule/network"
+	"github.com/filecoin-project/venus/app/submodule/paych"
 	"github.com/filecoin-project/venus/app/submodule/storagenetworking"
 	"github.com/filecoin-project/venus/app/submodule/syncer"
 	"github.com/filecoin-project/venus/app/submodule/wallet"
+	"github.com/filecoin-project/venus/pkg/jwtauth"
 )
 
 // Env is the environment for command API handlers.
@@ -33,6 +36,9 @@ type Env struct {
 	WalletAPI            *wallet.WalletAPI
 	MingingAPI           *mining.MiningAPI
 	MessagePoolAPI       *mpool.MessagePoolAPI
+	MultiSigAPI          *multisig.MultiSigAPI
+	PaychAPI             *paych.PaychAPI
+	JwtAuthAPI           *jwtauth.JwtAuthAPI
 }
 
 var _ cmds.Environment = (*Env)(nil)
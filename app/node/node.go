@@ -25,7 +25,9 @@ import (
 	"github.com/filecoin-project/venus/app/submodule/discovery"
 	"github.com/filecoin-project/venus/app/submodule/mining"
 	"github.com/filecoin-project/venus/app/submodule/mpool"
+	"github.com/filecoin-project/venus/app/submodule/multisig"
 	network2 "github.com/filecoin-project/venus/app/submodule/network"
+	"github.com/filecoin-project/venus/app/submodule/paych"
 	"github.com/filecoin-project/venus/app/submodule/storagenetworking"
 	syncer2 "github.com/filecoin-project/venus/app/submodule/syncer"
 	"github.com/filecoin-project/venus/app/submodule/wallet"
@@ -78,6 +80,8 @@ type Node struct {
 	//
 	wallet            *wallet.WalletSubmodule
 	mpool             *mpool.MessagePoolSubmodule
+	multiSig          *multisig.MultiSigSubmodule
+	paych             *paych.PaychSubmodule
 	storageNetworking *storagenetworking.StorageNetworkingSubmodule
 
 	//
@@ -97,6 +101,10 @@ func (node *Node) Chain() *chain2.ChainSubmodule {
 	return node.chain
 }
 
+func (node *Node) Syncer() *syncer2.SyncerSubmodule {
+	return node.syncer
+}
+
 func (node *Node) StorageNetworking() *storagenetworking.StorageNetworkingSubmodule {
 	return node.storageNetworking
 }
@@ -151,6 +159,11 @@ func (node *Node) Start(ctx context.Context) error {
 		return errors.Wrap(err, "failed to setup tracing")
 	}
 
+	// start the chain services following the head
+	if err := node.chain.Start(ctx); err != nil {
+		return err
+	}
+
 	var syncCtx context.Context
 	syncCtx, node.syncer.CancelChainSync = context.WithCancel(context.Background())
 
@@ -173,11 +186,19 @@ func (node *Node) Start(ctx context.Context) error {
 			return err
 		}
 	}
+
+	// resume tracking the payment channel messages
+	if err := node.paych.Start(); err != nil {
+		return err
+	}
 	return nil
 }
 
 // Stop initiates the shutdown of the node.
 func (node *Node) Stop(ctx context.Context) {
+	// stop paych submodule
+	node.paych.Stop()
+
 	// stop mpool submodule
 	node.mpool.Stop(ctx)
 
@@ -193,6 +214,9 @@ func (node *Node) Stop(ctx context.Context) {
 	//Stop chain submodule
 	node.chain.Stop(ctx)
 
+	//Stop wallet submodule
+	node.wallet.Stop(ctx)
+
 	if err := node.repo.Close(); err != nil {
 		fmt.Printf("error closing repo: %s\n", err)
 	}
@@ -304,6 +328,9 @@ func (node *Node) createServerEnv(ctx context.Context) *Env {
 		WalletAPI:            node.wallet.API(),
 		MingingAPI:           node.mining.API(),
 		MessagePoolAPI:       node.mpool.API(),
+		MultiSigAPI:          node.multiSig.API(),
+		PaychAPI:             node.paych.API(),
+		JwtAuthAPI:           node.jwtAuth.API(),
 	}
 
 	return &env
@@ -2,12 +2,18 @@ package chain
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/specactors/adt"
+	pstate "github.com/filecoin-project/venus/pkg/state"
 	"github.com/filecoin-project/venus/pkg/types"
 	"github.com/filecoin-project/venus/pkg/vm"
+	"github.com/filecoin-project/venus/pkg/vm/state"
+	"github.com/ipfs/go-cid"
 	xerrors "github.com/pkg/errors"
 )
 
@@ -32,6 +38,80 @@ func (actorAPI *ActorAPI) StateGetActor(ctx context.Context, actor address.Addre
 	return view.LoadActor(ctx, actor)
 }
 
+// StateReadState returns the state of the builtin actor `actor` at the tipset `tsk`,
+// decoded according to its actor version.
+func (actorAPI *ActorAPI) StateReadState(ctx context.Context, actor address.Address, tsk block.TipSetKey) (*ActorState, error) {
+	return actorAPI.readState(ctx, actor, tsk, func(store adt.Store, act *types.Actor) (interface{}, error) {
+		return pstate.ReadActorState(store, act)
+	})
+}
+
+// StateReadStateExpanded is StateReadState with the HAMTs, AMTs and other objects linked
+// from the state loaded in place of their cids. Expect large results for busy actors.
+func (actorAPI *ActorAPI) StateReadStateExpanded(ctx context.Context, actor address.Address, tsk block.TipSetKey) (*ActorState, error) {
+	return actorAPI.readState(ctx, actor, tsk, func(store adt.Store, act *types.Actor) (interface{}, error) {
+		return pstate.ReadActorStateExpanded(store, act)
+	})
+}
+
+func (actorAPI *ActorAPI) readState(ctx context.Context, actor address.Address, tsk block.TipSetKey, read func(adt.Store, *types.Actor) (interface{}, error)) (*ActorState, error) {
+	act, err := actorAPI.StateGetActor(ctx, actor, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("getting actor %s: %v", actor, err)
+	}
+
+	st, err := read(actorAPI.chain.State.Store(ctx), act)
+	if err != nil {
+		return nil, xerrors.Errorf("reading state of actor %s: %v", actor, err)
+	}
+
+	return &ActorState{
+		Balance: act.Balance,
+		Code:    act.Code,
+		State:   st,
+	}, nil
+}
+
+// StateDecodeParams decodes the raw params of a call to `method` of the builtin actor `toAddr`,
+// according to the code of the actor at the tipset `tsk`.
+func (actorAPI *ActorAPI) StateDecodeParams(ctx context.Context, toAddr address.Address, method abi.MethodNum, params []byte, tsk block.TipSetKey) (interface{}, error) {
+	act, err := actorAPI.StateGetActor(ctx, toAddr, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("getting actor %s: %v", toAddr, err)
+	}
+	return chain.DecodeParams(act.Code, method, params)
+}
+
+// StateDecodeReturn decodes the raw return value of a call to `method` of the builtin actor
+// `toAddr`, according to the code of the actor at the tipset `tsk`.
+func (actorAPI *ActorAPI) StateDecodeReturn(ctx context.Context, toAddr address.Address, method abi.MethodNum, ret []byte, tsk block.TipSetKey) (interface{}, error) {
+	act, err := actorAPI.StateGetActor(ctx, toAddr, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("getting actor %s: %v", toAddr, err)
+	}
+	return chain.DecodeReturn(act.Code, method, ret)
+}
+
+// StateEncodeParams encodes the JSON of the params of a call to `method` of the builtin actor
+// `toAddr` to raw message params, according to the code of the actor at the tipset `tsk`.
+func (actorAPI *ActorAPI) StateEncodeParams(ctx context.Context, toAddr address.Address, method abi.MethodNum, params json.RawMessage, tsk block.TipSetKey) ([]byte, error) {
+	act, err := actorAPI.StateGetActor(ctx, toAddr, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("getting actor %s: %v", toAddr, err)
+	}
+	return chain.EncodeParams(act.Code, method, params)
+}
+
+// StateGetMethodNum returns the number of the method named `name` of the builtin actor `toAddr`,
+// according to the code of the actor at the tipset `tsk`.
+func (actorAPI *ActorAPI) StateGetMethodNum(ctx context.Context, toAddr address.Address, name string, tsk block.TipSetKey) (abi.MethodNum, error) {
+	act, err := actorAPI.StateGetActor(ctx, toAddr, tsk)
+	if err != nil {
+		return 0, xerrors.Errorf("getting actor %s: %v", toAddr, err)
+	}
+	return chain.GetMethodNum(act.Code, name)
+}
+
 // ActorGetSignature returns the signature of the given actor's given method.
 // The function signature is typically used to enable a caller to decode the
 // output of an actor method call (message).
@@ -43,3 +123,49 @@ func (actorAPI *ActorAPI) ActorGetSignature(ctx context.Context, actorAddr addre
 func (actorAPI *ActorAPI) ListActor(ctx context.Context) (map[address.Address]*types.Actor, error) {
 	return actorAPI.chain.State.LsActors(ctx)
 }
+
+// StateChangedActors returns the actors added, modified and removed between the state roots `old` and `new`.
+func (actorAPI *ActorAPI) StateChangedActors(ctx context.Context, old cid.Cid, new cid.Cid) (*state.ActorChanges, error) {
+	store := actorAPI.chain.State.Store(ctx)
+	oldTree, err := state.LoadState(ctx, store, old)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load old state tree %s: %v", old, err)
+	}
+	newTree, err := state.LoadState(ctx, store, new)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load new state tree %s: %v", new, err)
+	}
+
+	return state.DiffActors(oldTree, newTree)
+}
+
+// StateDiffActorState returns the typed diff of the state of the builtin actor `addr`
+// between the state roots `old` and `new`.
+func (actorAPI *ActorAPI) StateDiffActorState(ctx context.Context, addr address.Address, old cid.Cid, new cid.Cid) (*pstate.ActorStateChanges, error) {
+	store := actorAPI.chain.State.Store(ctx)
+	loadActor := func(root cid.Cid) (*types.Actor, error) {
+		tree, err := state.LoadState(ctx, store, root)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load state tree %s: %v", root, err)
+		}
+		act, found, err := tree.GetActor(ctx, addr)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load actor %s: %v", addr, err)
+		}
+		if !found {
+			return nil, xerrors.Errorf("actor %s not found in state %s", addr, root)
+		}
+		return act, nil
+	}
+
+	oldAct, err := loadActor(old)
+	if err != nil {
+		return nil, err
+	}
+	newAct, err := loadActor(new)
+	if err != nil {
+		return nil, err
+	}
+
+	return pstate.DiffActorState(store, addr, oldAct, newAct)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/filecoin-project/go-state-types/big"
 
+	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-bitfield"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/venus/pkg/specactors/builtin/market"
@@ -70,3 +71,27 @@ type Message struct {
 	Cid     cid.Cid
 	Message *types.UnsignedMessage
 }
+
+// MessageMatch filters messages by sender and recipient, an undefined address matches any
+// address.
+type MessageMatch struct {
+	To   address.Address
+	From address.Address
+}
+
+func (mm *MessageMatch) matches(from, to address.Address) bool {
+	if mm.From != address.Undef && mm.From != from {
+		return false
+	}
+	if mm.To != address.Undef && mm.To != to {
+		return false
+	}
+	return true
+}
+
+// ActorState is the decoded state of an actor.
+type ActorState struct {
+	Balance big.Int
+	Code    cid.Cid
+	State   interface{}
+}
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
 
 	"github.com/filecoin-project/venus/app/submodule/blockstore"
 	"github.com/filecoin-project/venus/app/submodule/chain/cst"
@@ -16,8 +18,10 @@ import (
 	"github.com/filecoin-project/venus/pkg/config"
 	"github.com/filecoin-project/venus/pkg/consensus"
 	"github.com/filecoin-project/venus/pkg/fork"
+	"github.com/filecoin-project/venus/pkg/msgindex"
 	"github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/pkg/slashing"
+	"github.com/filecoin-project/venus/pkg/splitstore"
 	appstate "github.com/filecoin-project/venus/pkg/state"
 	"github.com/filecoin-project/venus/pkg/types"
 	"github.com/filecoin-project/venus/pkg/vm/register"
@@ -25,6 +29,8 @@ import (
 	"github.com/filecoin-project/venus/pkg/vmsupport"
 )
 
+var log = logging.Logger("chain_module") // nolint
+
 // ChainSubmodule enhances the `Node` with chain capabilities.
 type ChainSubmodule struct { //nolint
 	ChainReader  *chain.Store
@@ -46,11 +52,15 @@ type ChainSubmodule struct { //nolint
 
 	// Wait for confirm message
 	Waiter *cst.Waiter
+
+	// MsgIndex is the index of the messages on chain, nil if it is not enabled
+	MsgIndex *msgindex.Index
 }
 
 // xxx go back to using an interface here
 type chainRepo interface {
 	ChainDatastore() repo.Datastore
+	MetaDatastore() repo.Datastore
 	Config() *config.Config
 }
 
@@ -132,15 +142,33 @@ func NewChainSubmodule(config chainConfig,
 	if err != nil {
 		return nil, err
 	}
+
+	if idxCfg := repo.Config().Datastore.MessageIndex; idxCfg != nil && idxCfg.Enable {
+		store.MsgIndex = msgindex.NewIndex(repo.MetaDatastore(), chainStore, chainState, messageStore, abi.ChainEpoch(idxCfg.BackfillEpochs))
+	}
+
+	// the split store needs to follow the head to know when to compact
+	if ss, ok := blockstore.Blockstore.(*splitstore.SplitStore); ok {
+		if err := ss.Start(chainStore); err != nil {
+			return nil, err
+		}
+		chainStore.SubscribeHeadChanges(ss.HeadChange)
+	}
 	return store, nil
 }
 
 // Start loads the chain from disk.
 func (chain *ChainSubmodule) Start(ctx context.Context) error {
+	if chain.MsgIndex != nil {
+		chain.MsgIndex.Start(ctx)
+	}
 	return nil
 }
 
 func (chain *ChainSubmodule) Stop(ctx context.Context) {
+	if chain.MsgIndex != nil {
+		chain.MsgIndex.Stop()
+	}
 	chain.ChainReader.Stop()
 }
 
@@ -1,8 +1,10 @@
 package chain
 
 import (
+	"bufio"
 	"context"
 	miner0 "github.com/filecoin-project/specs-actors/actors/builtin/miner"
+	"io"
 	"time"
 
 	"github.com/filecoin-project/go-address"
@@ -13,6 +15,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/block"
 	"github.com/filecoin-project/venus/pkg/chain"
 	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/msgindex"
 	"github.com/filecoin-project/venus/pkg/types"
 	"github.com/ipfs/go-cid"
 	xerrors "github.com/pkg/errors"
@@ -28,7 +31,7 @@ func NewChainInfoAPI(chain *ChainSubmodule) ChainInfoAPI {
 
 //todo think which module should this api belong
 // BlockTime returns the block time used by the consensus protocol.
-func (chainInfoAPI *ChainInfoAPI) BlockTime() time.Duration {
+func (chainInfoAPI *ChainInfoAPI) BlockTime(ctx context.Context) time.Duration {
 	return chainInfoAPI.chain.config.BlockTime()
 }
 
@@ -95,7 +98,7 @@ func (chainInfoAPI *ChainInfoAPI) ChainSetHead(ctx context.Context, key block.Ti
 }
 
 // ChainTipSet returns the tipset at the given key
-func (chainInfoAPI *ChainInfoAPI) ChainGetTipSet(key block.TipSetKey) (*block.TipSet, error) {
+func (chainInfoAPI *ChainInfoAPI) ChainGetTipSet(ctx context.Context, key block.TipSetKey) (*block.TipSet, error) {
 	return chainInfoAPI.chain.ChainReader.GetTipSet(key)
 }
 
@@ -110,6 +113,21 @@ func (chainInfoAPI *ChainInfoAPI) ChainGetTipSetByHeight(ctx context.Context, he
 	return chainInfoAPI.chain.ChainReader.GetTipSetByHeight(ctx, ts, height, true)
 }
 
+// ChainGetPath returns the ordered list of head changes that lead from the tipset
+// `from` to the tipset `to`: reverts down to their common ancestor, then applies.
+func (chainInfoAPI *ChainInfoAPI) ChainGetPath(ctx context.Context, from block.TipSetKey, to block.TipSetKey) ([]*chain.HeadChange, error) {
+	fts, err := chainInfoAPI.chain.ChainReader.GetTipSet(from)
+	if err != nil {
+		return nil, xerrors.Errorf("loading from tipset %s: %v", from, err)
+	}
+	tts, err := chainInfoAPI.chain.ChainReader.GetTipSet(to)
+	if err != nil {
+		return nil, xerrors.Errorf("loading to tipset %s: %v", to, err)
+	}
+
+	return chain.ReorgPath(chainInfoAPI.chain.ChainReader.GetTipSet, fts, tts)
+}
+
 func (chainInfoAPI *ChainInfoAPI) GetActor(ctx context.Context, addr address.Address) (*types.Actor, error) {
 	head, err := chainInfoAPI.ChainHead(ctx)
 	if err != nil {
@@ -264,12 +282,82 @@ func (chainInfoAPI *ChainInfoAPI) ResolveToKeyAddr(ctx context.Context, addr add
 	return viewer.ResolveToKeyAddr(ctx, addr)
 }
 
+// ChainExport exports the chain rooted at `tsk` as a CAR snapshot. State roots are
+// included for the last `nroots` epochs, older messages are dropped when `skipOldMsgs`
+// is set. The stream ends with an empty slice once the export completed successfully.
+func (chainInfoAPI *ChainInfoAPI) ChainExport(ctx context.Context, nroots abi.ChainEpoch, skipOldMsgs bool, tsk block.TipSetKey) (<-chan []byte, error) {
+	ts, err := chainInfoAPI.chain.ChainReader.GetTipSet(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %v", tsk, err)
+	}
+	r, w := io.Pipe()
+	out := make(chan []byte)
+	go func() {
+		bw := bufio.NewWriterSize(w, 1<<20)
+
+		err := chainInfoAPI.chain.ChainReader.Export(ctx, ts, nroots, skipOldMsgs, bw)
+		bw.Flush()            //nolint:errcheck // it is a write to a pipe
+		w.CloseWithError(err) //nolint:errcheck // it is a pipe
+	}()
+
+	go func() {
+		defer close(out)
+		for {
+			buf := make([]byte, 1<<20)
+			n, err := r.Read(buf)
+			if err != nil && err != io.EOF {
+				log.Errorf("chain export pipe read failed: %s", err)
+				return
+			}
+			if n > 0 {
+				select {
+				case out <- buf[:n]:
+				case <-ctx.Done():
+					log.Warnf("export writer failed: %s", ctx.Err())
+					return
+				}
+			}
+			if err == io.EOF {
+				// send empty slice to indicate correct eof
+				select {
+				case out <- []byte{}:
+				case <-ctx.Done():
+					log.Warnf("export writer failed: %s", ctx.Err())
+					return
+				}
+
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
 //************Drand****************//
 // ChainNotify subscribe to chain head change event
 func (chainInfoAPI *ChainInfoAPI) ChainNotify(ctx context.Context) chan []*chain.HeadChange {
 	return chainInfoAPI.chain.State.ChainNotify(ctx)
 }
 
+// ChainNotifyFrom subscribes to the head changes starting from a tipset the client has already
+// seen, `from`, or the canonical tipset at `epoch` if `from` is empty. The path from that tipset to
+// the head is replayed before the live head changes, which are coalesced according to `opts` if set.
+func (chainInfoAPI *ChainInfoAPI) ChainNotifyFrom(ctx context.Context, from block.TipSetKey, epoch abi.ChainEpoch, opts *chain.CoalesceOptions) (chan []*chain.HeadChange, error) {
+	var fts *block.TipSet
+	var err error
+	if from.IsEmpty() {
+		fts, err = chainInfoAPI.chain.ChainReader.GetTipSetByHeight(ctx, chainInfoAPI.chain.ChainReader.GetHead(), epoch, true)
+	} else {
+		fts, err = chainInfoAPI.chain.ChainReader.GetTipSet(from)
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("loading starting tipset: %v", err)
+	}
+
+	return chainInfoAPI.chain.ChainReader.SubHeadChangesFrom(ctx, fts, opts), nil
+}
+
 //************Drand****************//
 
 // GetEntry retrieves an entry from the drand server
@@ -288,7 +376,7 @@ func (chainInfoAPI *ChainInfoAPI) GetEntry(ctx context.Context, height abi.Chain
 }
 
 // VerifyEntry verifies that child is a valid entry if its parent is.
-func (chainInfoAPI *ChainInfoAPI) VerifyEntry(parent, child *block.BeaconEntry, height abi.ChainEpoch) bool {
+func (chainInfoAPI *ChainInfoAPI) VerifyEntry(ctx context.Context, parent, child *block.BeaconEntry, height abi.ChainEpoch) bool {
 	return chainInfoAPI.chain.Drand.BeaconForEpoch(height).VerifyEntry(*parent, *child) != nil
 }
 
@@ -363,6 +451,22 @@ func (chainInfoAPI *ChainInfoAPI) MessageWait(ctx context.Context, msgCid cid.Ci
 }
 
 func (chainInfoAPI *ChainInfoAPI) StateSearchMsg(ctx context.Context, mCid cid.Cid) (*cst.MsgLookup, error) {
+	if idx := chainInfoAPI.chain.MsgIndex; idx != nil {
+		mi, err := idx.GetMsgInfo(ctx, mCid)
+		if err == nil {
+			return &cst.MsgLookup{
+				Message: mCid,
+				Receipt: mi.Receipt,
+				TipSet:  mi.TipSet,
+				Height:  mi.Height,
+			}, nil
+		}
+		// replaced messages are not indexed by the cid they were pushed with, search the chain
+		if err != msgindex.ErrNotIndexed {
+			return nil, err
+		}
+	}
+
 	chainMsg, err := chainInfoAPI.chain.MessageStore.LoadMessage(mCid)
 	if err != nil {
 		return nil, err
@@ -385,6 +489,120 @@ func (chainInfoAPI *ChainInfoAPI) StateSearchMsg(ctx context.Context, mCid cid.C
 	return nil, nil
 }
 
+// StateListMessages returns the messages matching `match` included in the tipsets from
+// `tsk` down to the height `toHeight`, the most recent first.
+func (chainInfoAPI *ChainInfoAPI) StateListMessages(ctx context.Context, match *MessageMatch, tsk block.TipSetKey, toHeight abi.ChainEpoch) ([]cid.Cid, error) {
+	if match.From == address.Undef && match.To == address.Undef {
+		return nil, xerrors.New("must specify at least To or From in message filter")
+	}
+
+	ts, err := chainInfoAPI.chain.ChainReader.GetTipSet(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %v", tsk, err)
+	}
+
+	// messages are matched by the ID of their sender and recipient, whichever address form
+	// they use
+	resolved := make(map[address.Address]address.Address)
+	lookupID := func(ts *block.TipSet, addr address.Address) (address.Address, error) {
+		if addr == address.Undef || addr.Protocol() == address.ID {
+			return addr, nil
+		}
+		if id, ok := resolved[addr]; ok {
+			return id, nil
+		}
+		id, err := chainInfoAPI.chain.State.ResolveAddressAt(ctx, ts, addr)
+		if err != nil {
+			if !xerrors.Is(err, types.ErrActorNotFound) {
+				return address.Undef, xerrors.Errorf("resolving %s: %v", addr, err)
+			}
+			// the actor may not exist yet
+			return addr, nil
+		}
+		resolved[addr] = id
+		return id, nil
+	}
+	from, err := lookupID(ts, match.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := lookupID(ts, match.To)
+	if err != nil {
+		return nil, err
+	}
+	match = &MessageMatch{From: from, To: to}
+
+	var out []cid.Cid
+	for ts.EnsureHeight() >= toHeight {
+		if idx := chainInfoAPI.chain.MsgIndex; idx != nil {
+			covers, err := idx.Covers(ctx, ts, toHeight)
+			if err != nil {
+				return nil, err
+			}
+			if covers {
+				indexed, err := idx.ListMessages(ctx, match.From, match.To, ts.EnsureHeight(), toHeight)
+				if err != nil {
+					return nil, err
+				}
+				return append(out, indexed...), nil
+			}
+		}
+
+		blkMsgs, err := chainInfoAPI.chain.MessageStore.LoadTipSetMessage(ctx, ts)
+		if err != nil {
+			return nil, xerrors.Errorf("loading messages of tipset %s: %v", ts.Key(), err)
+		}
+		matched, err := matchMessages(blkMsgs, match, func(addr address.Address) (address.Address, error) {
+			return lookupID(ts, addr)
+		})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, matched...)
+
+		if ts.EnsureHeight() == 0 {
+			break
+		}
+		ts, err = chainInfoAPI.chain.ChainReader.GetTipSet(ts.EnsureParents())
+		if err != nil {
+			return nil, xerrors.Errorf("loading parent tipset: %v", err)
+		}
+	}
+	return out, nil
+}
+
+// matchMessages returns the messages of a tipset matching `match` once their addresses are
+// resolved by `lookupID`, a message included in several blocks is returned once.
+func matchMessages(blkMsgs []block.BlockMessagesInfo, match *MessageMatch, lookupID func(address.Address) (address.Address, error)) ([]cid.Cid, error) {
+	var out []cid.Cid
+	seenMsgs := make(map[cid.Cid]struct{})
+	for _, bm := range blkMsgs {
+		for _, msg := range append(bm.BlsMessages, bm.SecpkMessages...) {
+			mcid, err := msg.Cid()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := seenMsgs[mcid]; ok {
+				continue
+			}
+			seenMsgs[mcid] = struct{}{}
+
+			from, err := lookupID(msg.VMMessage().From)
+			if err != nil {
+				return nil, err
+			}
+			to, err := lookupID(msg.VMMessage().To)
+			if err != nil {
+				return nil, err
+			}
+			if match.matches(from, to) {
+				out = append(out, mcid)
+			}
+		}
+	}
+	return out, nil
+}
+
 func (chainInfoAPI *ChainInfoAPI) StateWaitMsg(ctx context.Context, mCid cid.Cid, confidence abi.ChainEpoch) (*cst.MsgLookup, error) {
 	chainMsg, err := chainInfoAPI.chain.MessageStore.LoadMessage(mCid)
 	if err != nil {
@@ -395,8 +613,13 @@ func (chainInfoAPI *ChainInfoAPI) StateWaitMsg(ctx context.Context, mCid cid.Cid
 		return nil, err
 	}
 	if msgResult != nil {
+		// the message may have been replaced, report the one which landed on chain
+		landed, err := msgResult.Message.Cid()
+		if err != nil {
+			return nil, err
+		}
 		return &cst.MsgLookup{
-			Message: mCid,
+			Message: landed,
 			Receipt: *msgResult.Receipt,
 			TipSet:  msgResult.Ts.Key(),
 			Height:  msgResult.Ts.EnsureHeight(),
@@ -0,0 +1,53 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestMatchMessages(t *testing.T) {
+	tf.UnitTest(t)
+
+	msgs := types.NewMsgs(3)
+	sender := msgs[0].From
+	senderID := types.RequireIDAddress(t, 100)
+	ids := map[address.Address]address.Address{sender: senderID}
+	lookupID := func(addr address.Address) (address.Address, error) {
+		if id, ok := ids[addr]; ok {
+			return id, nil
+		}
+		return addr, nil
+	}
+
+	// the first two messages have the same sender in different forms, both blocks include
+	// the second message
+	msgs[1].From = senderID
+	mcid0, err := msgs[0].Cid()
+	require.NoError(t, err)
+	mcid1, err := msgs[1].Cid()
+	require.NoError(t, err)
+	blkMsgs := []block.BlockMessagesInfo{
+		{Block: &block.Block{}, BlsMessages: []types.ChainMsg{msgs[0], msgs[1]}},
+		{Block: &block.Block{}, BlsMessages: []types.ChainMsg{msgs[1], msgs[2]}},
+	}
+
+	for _, from := range []address.Address{sender, senderID} {
+		match, err := lookupID(from)
+		require.NoError(t, err)
+		found, err := matchMessages(blkMsgs, &MessageMatch{From: match}, lookupID)
+		require.NoError(t, err)
+		assert.Equal(t, []cid.Cid{mcid0, mcid1}, found)
+	}
+
+	found, err := matchMessages(blkMsgs, &MessageMatch{From: senderID, To: msgs[0].To}, lookupID)
+	require.NoError(t, err)
+	assert.Equal(t, []cid.Cid{mcid0}, found)
+}
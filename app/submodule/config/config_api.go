@@ -1,5 +1,7 @@
 package config
 
+import "context"
+
 type ConfigAPI struct { //nolint
 	config *ConfigModule
 }
@@ -10,12 +12,12 @@ type ConfigAPI struct { //nolint
 // For example:
 // ConfigSet("datastore.path", "dev/null") and ConfigSet("datastore", "{\"path\":\"dev/null\"}")
 // are the same operation.
-func (configAPI *ConfigAPI) ConfigSet(dottedPath string, paramJSON string) error {
+func (configAPI *ConfigAPI) ConfigSet(ctx context.Context, dottedPath string, paramJSON string) error {
 	return configAPI.config.Set(dottedPath, paramJSON)
 }
 
 // ConfigGet gets config parameters from the given path.
 // The path may be either a single field name, or a dotted path to a field.
-func (configAPI *ConfigAPI) ConfigGet(dottedPath string) (interface{}, error) {
+func (configAPI *ConfigAPI) ConfigGet(ctx context.Context, dottedPath string) (interface{}, error) {
 	return configAPI.config.Get(dottedPath)
 }
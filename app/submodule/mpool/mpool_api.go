@@ -48,7 +48,7 @@ func (a *MessagePoolAPI) MpoolSetConfig(ctx context.Context, cfg *messagepool.Mp
 }
 
 func (a *MessagePoolAPI) MpoolSelect(ctx context.Context, tsk block.TipSetKey, ticketQuality float64) ([]*types.SignedMessage, error) {
-	ts, err := a.mp.chain.API().ChainGetTipSet(tsk)
+	ts, err := a.mp.chain.API().ChainGetTipSet(ctx, tsk)
 	if err != nil {
 		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
 	}
@@ -65,7 +65,7 @@ func (a *MessagePoolAPI) MpoolPending(ctx context.Context, tsk block.TipSetKey)
 			return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
 		}
 	} else {
-		ts, err = a.mp.chain.API().ChainGetTipSet(tsk)
+		ts, err = a.mp.chain.API().ChainGetTipSet(ctx, tsk)
 		if err != nil {
 			return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
 		}
@@ -126,7 +126,7 @@ func (a *MessagePoolAPI) MpoolPending(ctx context.Context, tsk block.TipSetKey)
 			return pending, nil
 		}
 
-		ts, err = a.mp.chain.API().ChainGetTipSet(ts.EnsureParents())
+		ts, err = a.mp.chain.API().ChainGetTipSet(ctx, ts.EnsureParents())
 		if err != nil {
 			return nil, xerrors.Errorf("loading parent tipset: %w", err)
 		}
@@ -171,6 +171,12 @@ func (a *MessagePoolAPI) MpoolPushMessage(ctx context.Context, msg *types.Unsign
 		return nil, xerrors.Errorf("GasEstimateMessageGas error: %w", err)
 	}
 
+	return a.pushEstimated(ctx, &inMsg, msg, fromA)
+}
+
+// pushEstimated signs the estimated message `msg`, estimated from `inMsg`, with the next nonce of its
+// sender `fromA` and pushes it.
+func (a *MessagePoolAPI) pushEstimated(ctx context.Context, inMsg, msg *types.UnsignedMessage, fromA address.Address) (*types.SignedMessage, error) {
 	if msg.GasPremium.GreaterThan(msg.GasFeeCap) {
 		inJSON, err := json.Marshal(inMsg)
 		if err != nil {
@@ -216,7 +222,10 @@ func (a *MessagePoolAPI) MpoolPushMessage(ctx context.Context, msg *types.Unsign
 			return nil, xerrors.Errorf("serializing message: %w", err)
 		}
 
-		sig, err := a.mp.walletAPI.WalletSign(ctx, msg.From, mb.Cid().Bytes(), wallet.MsgMeta{})
+		sig, err := a.mp.walletAPI.WalletSign(ctx, msg.From, mb.Cid().Bytes(), wallet.MsgMeta{
+			Type:  wallet.MTChainMsg,
+			Extra: mb.RawData(),
+		})
 		if err != nil {
 			return nil, xerrors.Errorf("failed to sign message: %w", err)
 		}
@@ -267,10 +276,79 @@ func (a *MessagePoolAPI) MpoolBatchPushUntrusted(ctx context.Context, smsgs []*t
 	return messageCids, nil
 }
 
+// MpoolBatchPushMessage estimates, signs and pushes the messages. The messages of each sender are
+// estimated in sequence, each on top of the previous ones, so a message may depend on the previous ones.
+// The signed messages are returned in the order of `msgs`, on error the messages which weren't pushed are nil.
 func (a *MessagePoolAPI) MpoolBatchPushMessage(ctx context.Context, msgs []*types.UnsignedMessage, spec *types.MessageSendSpec) ([]*types.SignedMessage, error) {
+	ts, err := a.mp.chain.API().ChainHead(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("getting tipset error: %v", err)
+	}
+
+	// the indexes of the messages by sender, in order
+	var senders []address.Address
+	batches := make(map[address.Address][]int)
+	for i, msg := range msgs {
+		if msg.Nonce != 0 {
+			return nil, xerrors.Errorf("MpoolBatchPushMessage expects message nonce to be 0, was %d", msg.Nonce)
+		}
+		fromA, err := a.mp.chain.API().ResolveToKeyAddr(ctx, msg.From, ts)
+		if err != nil {
+			return nil, xerrors.Errorf("getting key address: %w", err)
+		}
+		if _, ok := batches[fromA]; !ok {
+			senders = append(senders, fromA)
+		}
+		batches[fromA] = append(batches[fromA], i)
+	}
+
+	smsgs := make([]*types.SignedMessage, len(msgs))
+	for _, fromA := range senders {
+		idxs := batches[fromA]
+		batch := make([]*types.UnsignedMessage, len(idxs))
+		for j, idx := range idxs {
+			batch[j] = msgs[idx]
+		}
+
+		pushed, err := a.batchPushMessage(ctx, fromA, batch, spec)
+		for j, smsg := range pushed {
+			smsgs[idxs[j]] = smsg
+		}
+		if err != nil {
+			return smsgs, err
+		}
+	}
+	return smsgs, nil
+}
+
+// batchPushMessage estimates the messages of the sender `fromA` in sequence, then signs and pushes them.
+func (a *MessagePoolAPI) batchPushMessage(ctx context.Context, fromA address.Address, msgs []*types.UnsignedMessage, spec *types.MessageSendSpec) ([]*types.SignedMessage, error) {
+	done, err := a.pushLocks.TakeLock(ctx, fromA)
+	if err != nil {
+		return nil, xerrors.Errorf("taking lock: %w", err)
+	}
+	defer done()
+
+	nonce, err := a.mp.MPool.GetNonce(fromA)
+	if err != nil {
+		return nil, xerrors.Errorf("getting nonce: %w", err)
+	}
+
+	estimateMessages := make([]*messagepool.EstimateMessage, len(msgs))
+	for i, msg := range msgs {
+		estimateMessages[i] = &messagepool.EstimateMessage{Msg: msg, Spec: spec}
+	}
+	results, err := a.GasBatchEstimateMessageGas(ctx, estimateMessages, nonce, block.TipSetKey{})
+	if err != nil {
+		return nil, xerrors.Errorf("GasBatchEstimateMessageGas error: %w", err)
+	}
+
 	var smsgs []*types.SignedMessage
-	for _, msg := range msgs {
-		smsg, err := a.MpoolPushMessage(ctx, msg, spec)
+	for i, res := range results {
+		if res.Err != "" {
+			return smsgs, xerrors.Errorf("estimating message %d from %s: %s", i, fromA, res.Err)
+		}
+		smsg, err := a.pushEstimated(ctx, msgs[i], res.Msg, fromA)
 		if err != nil {
 			return smsgs, err
 		}
@@ -287,6 +365,23 @@ func (a *MessagePoolAPI) MpoolSub(ctx context.Context) (<-chan messagepool.Mpool
 	return a.mp.MPool.Updates(ctx)
 }
 
+// MpoolCheckMessages runs every check of the message pool on the message prototypes and
+// returns the result of each check per message.
+func (a *MessagePoolAPI) MpoolCheckMessages(ctx context.Context, protos []*messagepool.MessagePrototype) ([][]messagepool.MessageCheckStatus, error) {
+	return a.mp.MPool.CheckMessages(ctx, protos)
+}
+
+// MpoolCheckPendingMessages runs every check of the message pool on the pending messages of `from`.
+func (a *MessagePoolAPI) MpoolCheckPendingMessages(ctx context.Context, from address.Address) ([][]messagepool.MessageCheckStatus, error) {
+	return a.mp.MPool.CheckPendingMessages(ctx, from)
+}
+
+// MpoolCheckReplaceMessages runs every check of the message pool on the pending messages of the
+// senders of `replace` once replaced by the messages of `replace`.
+func (a *MessagePoolAPI) MpoolCheckReplaceMessages(ctx context.Context, replace []*types.UnsignedMessage) ([][]messagepool.MessageCheckStatus, error) {
+	return a.mp.MPool.CheckReplaceMessages(ctx, replace)
+}
+
 func (a *MessagePoolAPI) SendMsg(ctx context.Context, from, to address.Address, method abi.MethodNum, value, maxFee abi.TokenAmount, params []byte) (cid.Cid, error) {
 	msg := types.UnsignedMessage{
 		To:     to,
@@ -308,6 +403,12 @@ func (a *MessagePoolAPI) GasEstimateMessageGas(ctx context.Context, msg *types.U
 	return a.mp.MPool.GasEstimateMessageGas(ctx, msg, spec, tsk)
 }
 
+// GasBatchEstimateMessageGas estimates the messages of a single sender to be sent in sequence with
+// consecutive nonces from `fromNonce`, each message on top of the previous ones.
+func (a *MessagePoolAPI) GasBatchEstimateMessageGas(ctx context.Context, estimateMessages []*messagepool.EstimateMessage, fromNonce uint64, tsk block.TipSetKey) ([]*messagepool.EstimateResult, error) {
+	return a.mp.MPool.GasBatchEstimateMessageGas(ctx, estimateMessages, fromNonce, tsk)
+}
+
 func (a *MessagePoolAPI) GasEstimateFeeCap(ctx context.Context, msg *types.UnsignedMessage, maxqueueblks int64, tsk block.TipSetKey) (big.Int, error) {
 	return a.mp.MPool.GasEstimateFeeCap(ctx, msg, maxqueueblks, tsk)
 }
@@ -316,6 +417,12 @@ func (a *MessagePoolAPI) GasEstimateGasPremium(ctx context.Context, nblocksincl
 	return a.mp.MPool.GasEstimateGasPremium(ctx, nblocksincl, sender, gaslimit, tsk)
 }
 
+// ChainGetFeeHistory returns the history of the base fee and of the gas premiums included in the last
+// `blocks` tipsets, at the ascending `percentiles` weighted by gas limit.
+func (a *MessagePoolAPI) ChainGetFeeHistory(ctx context.Context, blocks int, percentiles []float64) (*messagepool.FeeHistory, error) {
+	return a.mp.MPool.FeeHistory(ctx, blocks, percentiles, block.TipSetKey{})
+}
+
 func (a *MessagePoolAPI) WalletSign(ctx context.Context, k address.Address, msg []byte) (*crypto.Signature, error) {
 	head := a.mp.chain.ChainReader.GetHead()
 	view, err := a.mp.chain.State.StateView(head)
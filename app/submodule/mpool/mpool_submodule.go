@@ -11,6 +11,8 @@ import (
 
 	"github.com/filecoin-project/go-address"
 	logging "github.com/ipfs/go-log"
+	"github.com/libp2p/go-libp2p-core/peer"
+	libp2pps "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/pkg/errors"
 	"golang.org/x/xerrors"
 
@@ -88,20 +90,43 @@ func NewMpoolSubmodule(cfg messagepoolConfig,
 		return nil, xerrors.Errorf("constructing mpool: %s", err)
 	}
 
+	walletAPI := wallet.API()
+	if bumpCfg := cfg.Repo().Config().Mpool.FeeBump; bumpCfg != nil && bumpCfg.Enable {
+		sign := func(ctx context.Context, msg *types.UnsignedMessage) (*types.SignedMessage, error) {
+			return walletAPI.WalletSignMessage(ctx, msg.From, msg)
+		}
+		if err := mp.EnableFeeBump(bumpCfg, sign); err != nil {
+			return nil, xerrors.Errorf("enabling fee bump: %s", err)
+		}
+	}
+
 	// setup messaging topic.
 	// register block validation on pubsub
 	msgSyntaxValidator := consensus.NewMessageSyntaxValidator()
 	msgSignatureValidator := consensus.NewMessageSignatureValidator(chain.State)
 
 	mtv := msgsub.NewMessageTopicValidator(msgSyntaxValidator, msgSignatureValidator)
-	if err := network.Pubsub.RegisterTopicValidator(mtv.Topic(network.NetworkName), mtv.Validator(), mtv.Opts()...); err != nil {
+	validator := mtv.Validator()
+	// penalize the peers forwarding invalid messages in the admission limits of the pool, the
+	// messages of the node itself and the validations cut short by their timeout are not held
+	// against anyone
+	scoringValidator := func(ctx context.Context, p peer.ID, msg *libp2pps.Message) bool {
+		if validator(ctx, p, msg) {
+			return true
+		}
+		if p != network.Host.ID() && ctx.Err() == nil {
+			mp.RejectedFromPeer(p, msg.GetData())
+		}
+		return false
+	}
+	if err := network.Pubsub.RegisterTopicValidator(mtv.Topic(network.NetworkName), scoringValidator, mtv.Opts()...); err != nil {
 		return nil, xerrors.Errorf("failed to register message validator: %s", err)
 	}
 
 	return &MessagePoolSubmodule{
 		MPool:     mp,
 		chain:     chain,
-		walletAPI: wallet.API(),
+		walletAPI: walletAPI,
 		network:   network,
 	}, nil
 }
@@ -119,7 +144,7 @@ func (mp *MessagePoolSubmodule) handleIncomingMessage(ctx context.Context, pubSu
 		return err
 	}
 
-	if err := mp.MPool.Add(unmarshaled); err != nil {
+	if err := mp.MPool.AddFromPeer(unmarshaled, sender); err != nil {
 		log.Debugf("failed to add message from network to message pool (From: %s, To: %s, Nonce: %d, Value: %s): %s", unmarshaled.Message.From, unmarshaled.Message.To, unmarshaled.Message.Nonce, types.FIL(unmarshaled.Message.Value), err)
 		switch {
 		case xerrors.Is(err, messagepool.ErrSoftValidationFailure):
@@ -131,6 +156,8 @@ func (mp *MessagePoolSubmodule) handleIncomingMessage(ctx context.Context, pubSu
 		case xerrors.Is(err, messagepool.ErrNonceGap):
 			fallthrough
 		case xerrors.Is(err, messagepool.ErrNonceTooLow):
+			fallthrough
+		case xerrors.Is(err, messagepool.ErrAdmissionLimit):
 			return nil
 		default:
 			return err
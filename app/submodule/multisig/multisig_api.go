@@ -0,0 +1,284 @@
+package multisig
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/app/submodule/mpool"
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/specactors"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/multisig"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+type MultiSigAPI struct {
+	msig     *MultiSigSubmodule
+	mpoolAPI *mpool.MessagePoolAPI
+}
+
+// messageBuilder returns the multisig message builder of the actors version at the head.
+func (a *MultiSigAPI) messageBuilder(ctx context.Context, from address.Address) (multisig.MessageBuilder, error) {
+	nv, err := a.msig.chain.API().StateNetworkVersion(ctx, block.EmptyTSK)
+	if err != nil {
+		return nil, err
+	}
+	return multisig.Message(specactors.VersionForNetwork(nv), from), nil
+}
+
+// pushMessage signs and pushes `msg` to the message pool, and returns its cid.
+func (a *MultiSigAPI) pushMessage(ctx context.Context, msg *types.UnsignedMessage) (cid.Cid, error) {
+	smsg, err := a.mpoolAPI.MpoolPushMessage(ctx, msg, nil)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to push message: %w", err)
+	}
+	return smsg.Cid()
+}
+
+// loadState loads the state of the multisig at `addr` in the tipset `tsk`.
+func (a *MultiSigAPI) loadState(ctx context.Context, addr address.Address, tsk block.TipSetKey) (*block.TipSet, *types.Actor, multisig.State, error) {
+	ts, err := a.msig.chain.State.GetTipSet(tsk)
+	if err != nil {
+		return nil, nil, nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	act, err := a.msig.chain.State.GetActorAt(ctx, ts, addr)
+	if err != nil {
+		return nil, nil, nil, xerrors.Errorf("failed to load multisig actor %s: %w", addr, err)
+	}
+
+	msas, err := multisig.Load(a.msig.chain.State.Store(ctx), act)
+	if err != nil {
+		return nil, nil, nil, xerrors.Errorf("failed to load multisig actor state: %w", err)
+	}
+	return ts, act, msas, nil
+}
+
+// MsigCreate creates a multisig wallet, requiring `req` of the `addrs` signers to approve
+// a transaction. `val` is vested linearly over `duration` epochs.
+// It returns the cid of the message creating the wallet, whose return value holds the
+// address of the new wallet.
+func (a *MultiSigAPI) MsigCreate(ctx context.Context, req uint64, addrs []address.Address, duration abi.ChainEpoch, val abi.TokenAmount, src address.Address) (cid.Cid, error) {
+	mb, err := a.messageBuilder(ctx, src)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	msg, err := mb.Create(addrs, req, 0, duration, val)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	return a.pushMessage(ctx, msg)
+}
+
+// MsigPropose proposes a transaction sending `amt` to `to`, invoking `method` with `params`,
+// to the multisig at `msig`. `src` must be a signer of the multisig.
+func (a *MultiSigAPI) MsigPropose(ctx context.Context, msig address.Address, to address.Address, amt abi.TokenAmount, src address.Address, method uint64, params []byte) (cid.Cid, error) {
+	mb, err := a.messageBuilder(ctx, src)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	msg, err := mb.Propose(msig, to, amt, abi.MethodNum(method), params)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to create proposal: %w", err)
+	}
+
+	return a.pushMessage(ctx, msg)
+}
+
+// MsigApprove approves the pending transaction `txID` of the multisig at `msig`, proposed by
+// `proposer` to send `amt` to `to`, invoking `method` with `params`. The approval fails if the
+// transaction `txID` is not this one, e.g. after a reorg.
+func (a *MultiSigAPI) MsigApprove(ctx context.Context, msig address.Address, txID uint64, proposer address.Address, to address.Address, amt abi.TokenAmount, src address.Address, method uint64, params []byte) (cid.Cid, error) {
+	return a.msigApproveOrCancel(ctx, msigApprove, msig, txID, proposer, to, amt, src, method, params)
+}
+
+// MsigCancel cancels the pending transaction `txID` of the multisig at `msig`, sending `amt`
+// to `to`, invoking `method` with `params`. Only its proposer `src` can cancel it.
+func (a *MultiSigAPI) MsigCancel(ctx context.Context, msig address.Address, txID uint64, to address.Address, amt abi.TokenAmount, src address.Address, method uint64, params []byte) (cid.Cid, error) {
+	return a.msigApproveOrCancel(ctx, msigCancel, msig, txID, src, to, amt, src, method, params)
+}
+
+type msigOperation int
+
+const (
+	msigApprove msigOperation = iota
+	msigCancel
+)
+
+func (a *MultiSigAPI) msigApproveOrCancel(ctx context.Context, operation msigOperation, msig address.Address, txID uint64, proposer address.Address, to address.Address, amt abi.TokenAmount, src address.Address, method uint64, params []byte) (cid.Cid, error) {
+	if msig == address.Undef {
+		return cid.Undef, xerrors.Errorf("must provide multisig address")
+	}
+	if src == address.Undef {
+		return cid.Undef, xerrors.Errorf("must provide source address")
+	}
+
+	// the proposal hash is computed from the id address of the proposer
+	if proposer.Protocol() != address.ID {
+		proposerID, err := a.msig.chain.API().StateLookupID(ctx, proposer, block.EmptyTSK)
+		if err != nil {
+			return cid.Undef, xerrors.Errorf("failed to lookup id of proposer %s: %w", proposer, err)
+		}
+		proposer = proposerID
+	}
+
+	mb, err := a.messageBuilder(ctx, src)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	msg, err := approveOrCancelMessage(mb, operation, msig, txID, &multisig.ProposalHashData{
+		Requester: proposer,
+		To:        to,
+		Value:     amt,
+		Method:    abi.MethodNum(method),
+		Params:    params,
+	})
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	return a.pushMessage(ctx, msg)
+}
+
+// approveOrCancelMessage builds the message approving or cancelling the transaction `txID`,
+// checked against the hash of `proposal` by the multisig.
+func approveOrCancelMessage(mb multisig.MessageBuilder, operation msigOperation, msig address.Address, txID uint64, proposal *multisig.ProposalHashData) (*types.UnsignedMessage, error) {
+	switch operation {
+	case msigApprove:
+		msg, err := mb.Approve(msig, txID, proposal)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to create approval: %w", err)
+		}
+		return msg, nil
+	case msigCancel:
+		msg, err := mb.Cancel(msig, txID, proposal)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to create cancellation: %w", err)
+		}
+		return msg, nil
+	default:
+		return nil, xerrors.Errorf("unknown multisig operation %d", operation)
+	}
+}
+
+// MsigAddSigner proposes to add `newSigner` to the signers of the multisig at `msig`,
+// increasing the approval threshold by one if `increase` is true.
+func (a *MultiSigAPI) MsigAddSigner(ctx context.Context, msig address.Address, src address.Address, newSigner address.Address, increase bool) (cid.Cid, error) {
+	enc, err := specactors.SerializeParams(&multisig.AddSignerParams{
+		Signer:   newSigner,
+		Increase: increase,
+	})
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	return a.MsigPropose(ctx, msig, msig, big.Zero(), src, uint64(multisig.Methods.AddSigner), enc)
+}
+
+// MsigSwapSigner proposes to replace the signer `oldSigner` of the multisig at `msig`
+// with `newSigner`.
+func (a *MultiSigAPI) MsigSwapSigner(ctx context.Context, msig address.Address, src address.Address, oldSigner address.Address, newSigner address.Address) (cid.Cid, error) {
+	enc, err := specactors.SerializeParams(&multisig.SwapSignerParams{
+		From: oldSigner,
+		To:   newSigner,
+	})
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	return a.MsigPropose(ctx, msig, msig, big.Zero(), src, uint64(multisig.Methods.SwapSigner), enc)
+}
+
+// MsigRemoveSigner proposes to remove `signer` from the signers of the multisig at `msig`,
+// decreasing the approval threshold by one if `decrease` is true.
+func (a *MultiSigAPI) MsigRemoveSigner(ctx context.Context, msig address.Address, src address.Address, signer address.Address, decrease bool) (cid.Cid, error) {
+	enc, err := specactors.SerializeParams(&multisig.RemoveSignerParams{
+		Signer:   signer,
+		Decrease: decrease,
+	})
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	return a.MsigPropose(ctx, msig, msig, big.Zero(), src, uint64(multisig.Methods.RemoveSigner), enc)
+}
+
+// MsigGetPending returns the transactions of the multisig at `addr` waiting for approvals.
+func (a *MultiSigAPI) MsigGetPending(ctx context.Context, addr address.Address, tsk block.TipSetKey) ([]*MsigTransaction, error) {
+	_, _, msas, err := a.loadState(ctx, addr, tsk)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*MsigTransaction
+	if err := msas.ForEachPendingTxn(func(id int64, txn multisig.Transaction) error {
+		out = append(out, &MsigTransaction{
+			ID:     id,
+			To:     txn.To,
+			Value:  txn.Value,
+			Method: txn.Method,
+			Params: txn.Params,
+
+			Approved: txn.Approved,
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MsigGetAvailableBalance returns the part of the balance of the multisig at `addr` which
+// has vested, and can be spent, at the tipset `tsk`.
+func (a *MultiSigAPI) MsigGetAvailableBalance(ctx context.Context, addr address.Address, tsk block.TipSetKey) (abi.TokenAmount, error) {
+	ts, act, msas, err := a.loadState(ctx, addr, tsk)
+	if err != nil {
+		return big.Zero(), err
+	}
+
+	locked, err := msas.LockedBalance(ts.EnsureHeight())
+	if err != nil {
+		return big.Zero(), xerrors.Errorf("failed to compute locked multisig balance: %w", err)
+	}
+	return big.Sub(act.Balance, locked), nil
+}
+
+// MsigGetVested returns the amount vested by the multisig at `addr` between the tipsets
+// `start` and `end`.
+func (a *MultiSigAPI) MsigGetVested(ctx context.Context, addr address.Address, start block.TipSetKey, end block.TipSetKey) (abi.TokenAmount, error) {
+	startTS, err := a.msig.chain.State.GetTipSet(start)
+	if err != nil {
+		return big.Zero(), xerrors.Errorf("loading start tipset %s: %w", start, err)
+	}
+
+	endTS, _, msas, err := a.loadState(ctx, addr, end)
+	if err != nil {
+		return big.Zero(), err
+	}
+
+	if startTS.EnsureHeight() > endTS.EnsureHeight() {
+		return big.Zero(), xerrors.Errorf("start tipset %d is after end tipset %d", startTS.EnsureHeight(), endTS.EnsureHeight())
+	} else if startTS.EnsureHeight() == endTS.EnsureHeight() {
+		return big.Zero(), nil
+	}
+
+	startLk, err := msas.LockedBalance(startTS.EnsureHeight())
+	if err != nil {
+		return big.Zero(), xerrors.Errorf("failed to compute locked balance at start height: %w", err)
+	}
+
+	endLk, err := msas.LockedBalance(endTS.EnsureHeight())
+	if err != nil {
+		return big.Zero(), xerrors.Errorf("failed to compute locked balance at end height: %w", err)
+	}
+
+	return big.Sub(startLk, endLk), nil
+}
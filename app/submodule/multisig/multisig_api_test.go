@@ -0,0 +1,76 @@
+package multisig
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	multisig3 "github.com/filecoin-project/specs-actors/v3/actors/builtin/multisig"
+	"github.com/minio/blake2b-simd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/specactors"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/multisig"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestApproveOrCancelMessage(t *testing.T) {
+	tf.UnitTest(t)
+
+	msig, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	proposer, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+	to, err := address.NewIDAddress(1002)
+	require.NoError(t, err)
+	from, err := address.NewIDAddress(1003)
+	require.NoError(t, err)
+
+	proposal := &multisig.ProposalHashData{
+		Requester: proposer,
+		To:        to,
+		Value:     abi.NewTokenAmount(100),
+		Method:    abi.MethodNum(2),
+		Params:    []byte{1, 2, 3},
+	}
+	// the hash the multisig computes from the pending transaction, whose first approver is its proposer
+	expected, err := multisig3.ComputeProposalHash(&multisig3.Transaction{
+		To:       to,
+		Value:    abi.NewTokenAmount(100),
+		Method:   abi.MethodNum(2),
+		Params:   []byte{1, 2, 3},
+		Approved: []address.Address{proposer},
+	}, blake2b.Sum256)
+	require.NoError(t, err)
+
+	for _, version := range []specactors.Version{specactors.Version0, specactors.Version2, specactors.Version3} {
+		mb := multisig.Message(version, from)
+		for operation, method := range map[msigOperation]abi.MethodNum{
+			msigApprove: multisig.Methods.Approve,
+			msigCancel:  multisig.Methods.Cancel,
+		} {
+			msg, err := approveOrCancelMessage(mb, operation, msig, 7, proposal)
+			require.NoError(t, err)
+			assert.Equal(t, msig, msg.To)
+			assert.Equal(t, from, msg.From)
+			assert.Equal(t, method, msg.Method)
+
+			var params multisig3.TxnIDParams
+			require.NoError(t, params.UnmarshalCBOR(bytes.NewReader(msg.Params)))
+			assert.Equal(t, multisig3.TxnID(7), params.ID)
+			assert.Equal(t, expected, params.ProposalHash)
+		}
+	}
+
+	// the proposer must be resolved to its id address
+	key, err := address.NewSecp256k1Address([]byte("proposer"))
+	require.NoError(t, err)
+	_, err = approveOrCancelMessage(multisig.Message(specactors.Version3, from), msigApprove, msig, 7, &multisig.ProposalHashData{
+		Requester: key,
+		To:        to,
+		Value:     abi.NewTokenAmount(100),
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,27 @@
+package multisig
+
+import (
+	"github.com/filecoin-project/venus/app/submodule/chain"
+	"github.com/filecoin-project/venus/app/submodule/mpool"
+)
+
+// MultiSigSubmodule enhances the `Node` with multisig wallet capabilities.
+type MultiSigSubmodule struct { //nolint
+	chain *chain.ChainSubmodule
+	mpool *mpool.MessagePoolSubmodule
+}
+
+// NewMultiSigSubmodule creates a new multisig submodule.
+func NewMultiSigSubmodule(chain *chain.ChainSubmodule, mpool *mpool.MessagePoolSubmodule) *MultiSigSubmodule {
+	return &MultiSigSubmodule{
+		chain: chain,
+		mpool: mpool,
+	}
+}
+
+func (sb *MultiSigSubmodule) API() *MultiSigAPI {
+	return &MultiSigAPI{
+		msig:     sb,
+		mpoolAPI: sb.mpool.API(),
+	}
+}
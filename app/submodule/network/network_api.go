@@ -16,17 +16,17 @@ type NetworkAPI struct { //nolint
 }
 
 // NetworkGetBandwidthStats gets stats on the current bandwidth usage of the network
-func (networkAPI *NetworkAPI) NetworkGetBandwidthStats() metrics.Stats {
+func (networkAPI *NetworkAPI) NetworkGetBandwidthStats(ctx context.Context) metrics.Stats {
 	return networkAPI.network.Network.GetBandwidthStats()
 }
 
 // NetworkGetPeerAddresses gets the current addresses of the node
-func (networkAPI *NetworkAPI) NetworkGetPeerAddresses() []ma.Multiaddr {
+func (networkAPI *NetworkAPI) NetworkGetPeerAddresses(ctx context.Context) []ma.Multiaddr {
 	return networkAPI.network.Network.GetPeerAddresses()
 }
 
 // NetworkGetPeerID gets the current peer id of the node
-func (networkAPI *NetworkAPI) NetworkGetPeerID() peer.ID {
+func (networkAPI *NetworkAPI) NetworkGetPeerID(ctx context.Context) peer.ID {
 	return networkAPI.network.Network.GetPeerID()
 }
 
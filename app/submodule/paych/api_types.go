@@ -0,0 +1,29 @@
+package paych
+
+import (
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+)
+
+// ChannelInfo is the result of PaychGet.
+type ChannelInfo struct {
+	// Channel is the address of the channel, undefined while it is being created
+	Channel address.Address
+	// WaitSentinel is the message to wait for with PaychGetWaitReady
+	WaitSentinel cid.Cid
+}
+
+// PaychStatus is the status of a tracked channel.
+type PaychStatus struct { //nolint
+	ControlAddr address.Address
+	Direction   PCHDir
+}
+
+// PCHDir is the direction of the payments of a channel, as seen by the node.
+type PCHDir int
+
+const (
+	PCHUndef PCHDir = iota
+	PCHInbound
+	PCHOutbound
+)
@@ -0,0 +1,75 @@
+package paych
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/network"
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/venus/app/submodule/chain"
+	"github.com/filecoin-project/venus/app/submodule/mpool"
+	walletapi "github.com/filecoin-project/venus/app/submodule/wallet"
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/filecoin-project/venus/pkg/paychmgr"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/paych"
+	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/wallet"
+)
+
+// managerAPI provides the payment channel manager with the functionality of the node.
+type managerAPI struct {
+	chain     *chain.ChainSubmodule
+	chainAPI  *chain.ChainAPI
+	mpoolAPI  *mpool.MessagePoolAPI
+	walletAPI *walletapi.WalletAPI
+}
+
+var _ paychmgr.ManagerAPI = (*managerAPI)(nil)
+
+func (m *managerAPI) StateNetworkVersion(ctx context.Context, tsk block.TipSetKey) (network.Version, error) {
+	return m.chainAPI.StateNetworkVersion(ctx, tsk)
+}
+
+func (m *managerAPI) StateAccountKey(ctx context.Context, addr address.Address, tsk block.TipSetKey) (address.Address, error) {
+	return m.chainAPI.StateAccountKey(ctx, addr, tsk)
+}
+
+func (m *managerAPI) GetPaychState(ctx context.Context, ch address.Address) (*types.Actor, paych.State, error) {
+	act, err := m.chainAPI.StateGetActor(ctx, ch, block.EmptyTSK)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	st, err := paych.Load(m.chain.State.Store(ctx), act)
+	if err != nil {
+		return nil, nil, err
+	}
+	return act, st, nil
+}
+
+func (m *managerAPI) PushMessage(ctx context.Context, msg *types.UnsignedMessage) (cid.Cid, error) {
+	smsg, err := m.mpoolAPI.MpoolPushMessage(ctx, msg, nil)
+	if err != nil {
+		return cid.Undef, err
+	}
+	return smsg.Cid()
+}
+
+func (m *managerAPI) WaitMsg(ctx context.Context, mcid cid.Cid) (*types.MessageReceipt, error) {
+	lookup, err := m.chainAPI.StateWaitMsg(ctx, mcid, constants.MessageConfidence)
+	if err != nil {
+		return nil, err
+	}
+	return &lookup.Receipt, nil
+}
+
+func (m *managerAPI) WalletHas(ctx context.Context, addr address.Address) (bool, error) {
+	return m.walletAPI.WalletHas(ctx, addr)
+}
+
+func (m *managerAPI) SignVoucher(ctx context.Context, addr address.Address, data []byte) (*crypto.Signature, error) {
+	return m.walletAPI.WalletSign(ctx, addr, data, wallet.MsgMeta{Type: wallet.MTSignedVoucher})
+}
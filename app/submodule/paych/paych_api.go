@@ -0,0 +1,128 @@
+package paych
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/paychmgr"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/paych"
+)
+
+type PaychAPI struct { //nolint
+	paych *PaychSubmodule
+}
+
+// PaychGet returns the outbound channel from `from` to `to`, creating it if needed, and
+// adds `amt` to its funds. WaitSentinel must land on chain before the funds can be used.
+func (a *PaychAPI) PaychGet(ctx context.Context, from, to address.Address, amt big.Int) (*ChannelInfo, error) {
+	ch, mcid, err := a.paych.Manager.GetPaych(ctx, from, to, amt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChannelInfo{
+		Channel:      ch,
+		WaitSentinel: mcid,
+	}, nil
+}
+
+// PaychGetWaitReady waits for the message `sentinel` returned by PaychGet and returns the
+// address of the channel.
+func (a *PaychAPI) PaychGetWaitReady(ctx context.Context, sentinel cid.Cid) (address.Address, error) {
+	return a.paych.Manager.GetPaychWaitReady(ctx, sentinel)
+}
+
+// PaychAvailableFunds returns the funds of the channel `ch`.
+func (a *PaychAPI) PaychAvailableFunds(ctx context.Context, ch address.Address) (*paychmgr.ChannelAvailableFunds, error) {
+	return a.paych.Manager.AvailableFunds(ctx, ch)
+}
+
+// PaychAvailableFundsByFromTo returns the funds of the outbound channel from `from` to `to`.
+func (a *PaychAPI) PaychAvailableFundsByFromTo(ctx context.Context, from, to address.Address) (*paychmgr.ChannelAvailableFunds, error) {
+	return a.paych.Manager.AvailableFundsByFromTo(ctx, from, to)
+}
+
+// PaychList returns the addresses of the tracked channels.
+func (a *PaychAPI) PaychList(ctx context.Context) ([]address.Address, error) {
+	return a.paych.Manager.ListChannels()
+}
+
+// PaychStatus returns the local party and the direction of the channel `ch`.
+func (a *PaychAPI) PaychStatus(ctx context.Context, ch address.Address) (*PaychStatus, error) {
+	ci, err := a.paych.Manager.GetChannelInfo(ch)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := PCHInbound
+	if ci.Direction == paychmgr.DirOutbound {
+		dir = PCHOutbound
+	}
+	return &PaychStatus{
+		ControlAddr: ci.Control,
+		Direction:   dir,
+	}, nil
+}
+
+// PaychAllocateLane allocates a new lane of the channel `ch`.
+func (a *PaychAPI) PaychAllocateLane(ctx context.Context, ch address.Address) (uint64, error) {
+	return a.paych.Manager.AllocateLane(ch)
+}
+
+// PaychVoucherCreate creates and signs a voucher of the outbound channel `ch`, paying
+// `amt` in total on the lane `lane`. If the channel doesn't have enough funds, the
+// result has no voucher and the missing amount as Shortfall.
+func (a *PaychAPI) PaychVoucherCreate(ctx context.Context, ch address.Address, amt big.Int, lane uint64) (*paychmgr.VoucherCreateResult, error) {
+	return a.paych.Manager.CreateVoucher(ctx, ch, paych.SignedVoucher{
+		Amount: amt,
+		Lane:   lane,
+	})
+}
+
+// PaychVoucherCheckValid checks that the voucher `sv` can be redeemed from the channel `ch`.
+func (a *PaychAPI) PaychVoucherCheckValid(ctx context.Context, ch address.Address, sv *paych.SignedVoucher) error {
+	return a.paych.Manager.CheckVoucherValid(ctx, ch, sv)
+}
+
+// PaychVoucherAdd adds the voucher `sv` received on the inbound channel `ch`, and returns
+// the amount it adds to its lane, which must be at least `minDelta`.
+func (a *PaychAPI) PaychVoucherAdd(ctx context.Context, ch address.Address, sv *paych.SignedVoucher, proof []byte, minDelta big.Int) (big.Int, error) {
+	return a.paych.Manager.AddVoucherInbound(ctx, ch, sv, proof, minDelta)
+}
+
+// PaychVoucherList returns the vouchers of the channel `ch`.
+func (a *PaychAPI) PaychVoucherList(ctx context.Context, ch address.Address) ([]*paych.SignedVoucher, error) {
+	vis, err := a.paych.Manager.ListVouchers(ch)
+	if err != nil {
+		return nil, err
+	}
+
+	vouchers := make([]*paych.SignedVoucher, 0, len(vis))
+	for _, vi := range vis {
+		vouchers = append(vouchers, vi.Voucher)
+	}
+	return vouchers, nil
+}
+
+// PaychVoucherSubmit submits the voucher `sv` of the channel `ch` to the chain, `secret`
+// is the preimage of its secret hash, if any.
+func (a *PaychAPI) PaychVoucherSubmit(ctx context.Context, ch address.Address, sv *paych.SignedVoucher, secret []byte) (cid.Cid, error) {
+	if sv.Signature == nil {
+		return cid.Undef, xerrors.New("voucher is not signed")
+	}
+	return a.paych.Manager.SubmitVoucher(ctx, ch, sv, secret)
+}
+
+// PaychSettle settles the channel `ch`.
+func (a *PaychAPI) PaychSettle(ctx context.Context, ch address.Address) (cid.Cid, error) {
+	return a.paych.Manager.Settle(ctx, ch)
+}
+
+// PaychCollect collects the funds of the settled channel `ch`.
+func (a *PaychAPI) PaychCollect(ctx context.Context, ch address.Address) (cid.Cid, error) {
+	return a.paych.Manager.Collect(ctx, ch)
+}
@@ -0,0 +1,49 @@
+package paych
+
+import (
+	"context"
+
+	"github.com/filecoin-project/venus/app/submodule/chain"
+	"github.com/filecoin-project/venus/app/submodule/mpool"
+	"github.com/filecoin-project/venus/app/submodule/wallet"
+	"github.com/filecoin-project/venus/pkg/paychmgr"
+	"github.com/filecoin-project/venus/pkg/repo"
+)
+
+// PaychSubmodule enhances the `Node` with payment channel capabilities.
+type PaychSubmodule struct { //nolint
+	Manager *paychmgr.Manager
+}
+
+// NewPaychSubmodule creates a new payment channel submodule, which stores the channels
+// in the metadata datastore of `repo`.
+func NewPaychSubmodule(ctx context.Context,
+	repo repo.Repo,
+	chain *chain.ChainSubmodule,
+	mpool *mpool.MessagePoolSubmodule,
+	wallet *wallet.WalletSubmodule,
+) *PaychSubmodule {
+	api := &managerAPI{
+		chain:     chain,
+		chainAPI:  chain.API(),
+		mpoolAPI:  mpool.API(),
+		walletAPI: wallet.API(),
+	}
+	store := paychmgr.NewStore(repo.MetaDatastore())
+	return &PaychSubmodule{
+		Manager: paychmgr.NewManager(ctx, store, api),
+	}
+}
+
+// Start resumes tracking the messages creating or funding channels.
+func (ps *PaychSubmodule) Start() error {
+	return ps.Manager.Start()
+}
+
+func (ps *PaychSubmodule) Stop() {
+	ps.Manager.Stop()
+}
+
+func (ps *PaychSubmodule) API() *PaychAPI {
+	return &PaychAPI{paych: ps}
+}
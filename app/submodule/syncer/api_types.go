@@ -3,8 +3,11 @@ package syncer
 import (
 	"fmt"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/venus/pkg/block"
 	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/vm"
+	vmstate "github.com/filecoin-project/venus/pkg/vm/state"
 	"github.com/ipfs/go-cid"
 	"time"
 )
@@ -30,10 +33,68 @@ type InvocResult struct {
 	Duration       time.Duration
 }
 
+// ComputeStateOutput is the state root computed by StateCompute along with the result of
+// every message applied.
+type ComputeStateOutput struct {
+	Root  cid.Cid
+	Trace []*InvocResult
+}
+
+// ChainValidateResult is the outcome of re-executing the tipsets of a range of epochs.
+type ChainValidateResult struct {
+	// Validated is the number of tipsets whose re-execution matched the stored results.
+	Validated  int
+	Divergence *StateDivergence `json:",omitempty"`
+}
+
+// StateDivergence describes a tipset whose re-execution does not match the state and
+// receipt roots stored in its metadata or in the headers of its child.
+type StateDivergence struct {
+	TipSet block.TipSetKey
+	Height abi.ChainEpoch
+	// Source is where the expected roots come from, "metadata" or "child".
+	Source               string
+	ExpectedStateRoot    cid.Cid
+	ComputedStateRoot    cid.Cid
+	ExpectedReceiptsRoot cid.Cid
+	ComputedReceiptsRoot cid.Cid
+	// Diff lists the actors that differ between the expected and the computed state,
+	// it is nil if the states match or if the expected state is not available.
+	Diff *vmstate.ActorChanges `json:",omitempty"`
+}
+
+// toInvocResult builds the result of the message `mcid` from its execution by the vm.
+func toInvocResult(mcid cid.Cid, msg *types.UnsignedMessage, ret *vm.Ret) *InvocResult {
+	trace := ret.GasTracker.ExecutionTrace
+	return &InvocResult{
+		MsgCid: mcid,
+		Msg:    msg,
+		MsgRct: &ret.Receipt,
+		GasCost: MsgGasCost{
+			Message:            mcid,
+			GasUsed:            big.NewInt(ret.Receipt.GasUsed),
+			BaseFeeBurn:        ret.OutPuts.BaseFeeBurn,
+			OverEstimationBurn: ret.OutPuts.OverEstimationBurn,
+			MinerPenalty:       ret.OutPuts.MinerPenalty,
+			MinerTip:           ret.OutPuts.MinerTip,
+			Refund:             ret.OutPuts.Refund,
+			TotalCost:          big.Add(big.Add(ret.OutPuts.BaseFeeBurn, ret.OutPuts.OverEstimationBurn), ret.OutPuts.MinerTip),
+		},
+		ExecutionTrace: trace,
+		Error:          trace.Error,
+		Duration:       trace.Duration,
+	}
+}
+
 type SyncState struct {
 	ActiveSyncs []ActiveSync
 
 	VMApplied uint64
+
+	// HeadHeight is the height of the head of the node
+	HeadHeight abi.ChainEpoch
+	// ClockEpoch is the epoch of the chain at the wall clock time
+	ClockEpoch abi.ChainEpoch
 }
 
 //just compatible code lotus
@@ -79,4 +140,12 @@ type ActiveSync struct {
 	Start   time.Time
 	End     time.Time
 	Message string
+
+	// TargetHeight is the height of Target and FetchedHeight the height up to which the messages were fetched
+	TargetHeight  abi.ChainEpoch
+	FetchedHeight abi.ChainEpoch
+	// EpochsPerSecond is the rate of the state transitions and EstimatedCompletion when the sync
+	// is expected to complete at this rate, zero if unknown
+	EpochsPerSecond     float64
+	EstimatedCompletion time.Time
 }
@@ -5,9 +5,15 @@ import (
 	syncTypes "github.com/filecoin-project/venus/pkg/chainsync/types"
 	"time"
 
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/constants"
 	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/vm"
+	vmstate "github.com/filecoin-project/venus/pkg/vm/state"
+	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log/v2"
 	xerrors "github.com/pkg/errors"
 )
@@ -19,13 +25,14 @@ type SyncerAPI struct { //nolint
 }
 
 // SyncerStatus returns the current status of the active or last active chain sync operation.
-func (syncerAPI *SyncerAPI) SyncerTracker() *syncTypes.TargetTracker {
+func (syncerAPI *SyncerAPI) SyncerTracker(ctx context.Context) *syncTypes.TargetTracker {
 	return syncerAPI.syncer.ChainSyncManager.BlockProposer().SyncTracker()
 }
 
 // SyncerStatus returns the current status of the active or last active chain sync operation.
-func (syncerAPI *SyncerAPI) SetConcurrent(concurrent int64) {
+func (syncerAPI *SyncerAPI) SetConcurrent(ctx context.Context, concurrent int64) error {
 	syncerAPI.syncer.ChainSyncManager.BlockProposer().SetConcurrent(concurrent)
+	return nil
 }
 
 func (syncerAPI *SyncerAPI) ChainTipSetWeight(ctx context.Context, tsk block.TipSetKey) (big.Int, error) {
@@ -37,7 +44,7 @@ func (syncerAPI *SyncerAPI) ChainTipSetWeight(ctx context.Context, tsk block.Tip
 }
 
 // ChainSyncHandleNewTipSet submits a chain head to the syncer for processing.
-func (syncerAPI *SyncerAPI) ChainSyncHandleNewTipSet(ci *block.ChainInfo) error {
+func (syncerAPI *SyncerAPI) ChainSyncHandleNewTipSet(ctx context.Context, ci *block.ChainInfo) error {
 	return syncerAPI.syncer.SyncProvider.HandleNewTipSet(ci)
 }
 
@@ -118,26 +125,234 @@ func (syncerAPI *SyncerAPI) StateCall(ctx context.Context, msg *types.UnsignedMe
 		MsgCid:         mcid,
 		Msg:            msg,
 		MsgRct:         &ret.Receipt,
-		ExecutionTrace: types.ExecutionTrace{},
+		ExecutionTrace: ret.GasTracker.ExecutionTrace,
+		Error:          ret.GasTracker.ExecutionTrace.Error,
 		Duration:       duration,
 	}, nil
 }
 
+// errHaltExecution stops the re-execution of a tipset once the replayed message was applied.
+var errHaltExecution = xerrors.New("halt execution")
+
+// StateReplay replays the message `mc` in the context of the tipset it was included in,
+// returning its receipt, gas cost and execution trace. If `tsk` is not empty, the message
+// is assumed to have been executed in the tipset `tsk`, that is included in its parent.
+// Otherwise the chain is searched for the message.
+func (syncerAPI *SyncerAPI) StateReplay(ctx context.Context, tsk block.TipSetKey, mc cid.Cid) (*InvocResult, error) {
+	chainModule := syncerAPI.syncer.ChainModule
+
+	chainMsg, err := chainModule.MessageStore.LoadMessage(mc)
+	if err != nil {
+		return nil, xerrors.Errorf("loading message %s: %v", mc, err)
+	}
+
+	var executionTs *block.TipSet
+	if tsk.IsEmpty() {
+		res, found, err := chainModule.Waiter.Find(ctx, chainMsg, constants.LookbackNoLimit, nil)
+		if err != nil {
+			return nil, xerrors.Errorf("searching for message %s: %v", mc, err)
+		}
+		if !found {
+			return nil, xerrors.Errorf("message %s not found on chain", mc)
+		}
+		executionTs = res.Ts
+		// the message may have been replaced, replay the one which landed on chain
+		chainMsg = res.Message
+	} else {
+		executionTs, err = chainModule.ChainReader.GetTipSet(tsk)
+		if err != nil {
+			return nil, xerrors.Errorf("loading tipset %s: %v", tsk, err)
+		}
+	}
+
+	ts, err := chainModule.ChainReader.GetTipSet(executionTs.EnsureParents())
+	if err != nil {
+		return nil, xerrors.Errorf("loading parent tipset of %s: %v", executionTs.Key(), err)
+	}
+
+	// the messages are executed by the cid of their unsigned message, signed or not
+	executed, err := chainMsg.VMMessage().Cid()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		msg *types.UnsignedMessage
+		ret *vm.Ret
+	)
+	_, _, err = syncerAPI.syncer.Consensus.ExecuteTipSet(ctx, ts, func(mcid cid.Cid, _ vm.VmMessage, r *vm.Ret) error {
+		if mcid.Equals(executed) {
+			msg = r.GasTracker.ExecutionTrace.Msg
+			ret = r
+			return errHaltExecution
+		}
+		return nil
+	})
+	if err != nil && xerrors.Cause(err) != errHaltExecution {
+		return nil, xerrors.Errorf("replaying tipset %s: %v", ts.Key(), err)
+	}
+	if ret == nil {
+		return nil, xerrors.Errorf("message %s was not executed in tipset %s", mc, ts.Key())
+	}
+
+	return toInvocResult(mc, msg, ret), nil
+}
+
+// StateCompute applies the messages `msgs` at `height` on top of the parent state of the
+// tipset `tsk`, returning the resulting state root and the execution trace of every message.
+func (syncerAPI *SyncerAPI) StateCompute(ctx context.Context, height abi.ChainEpoch, msgs []*types.UnsignedMessage, tsk block.TipSetKey) (*ComputeStateOutput, error) {
+	ts, err := syncerAPI.syncer.ChainModule.ChainReader.GetTipSet(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %v", tsk, err)
+	}
+
+	var trace []*InvocResult
+	root, err := syncerAPI.syncer.Consensus.ComputeTipSetState(ctx, height, msgs, ts, func(mcid cid.Cid, _ vm.VmMessage, r *vm.Ret) error {
+		trace = append(trace, toInvocResult(mcid, r.GasTracker.ExecutionTrace.Msg, r))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ComputeStateOutput{
+		Root:  root,
+		Trace: trace,
+	}, nil
+}
+
+// ChainValidate re-executes the tipsets of the canonical chain between the epochs `from` and
+// `to` and compares the resulting state and receipt roots with the stored tipset metadata
+// and with the headers of the child tipsets. It stops at the first divergence. A zero `to`
+// validates up to the head.
+func (syncerAPI *SyncerAPI) ChainValidate(ctx context.Context, from, to abi.ChainEpoch) (*ChainValidateResult, error) {
+	chainReader := syncerAPI.syncer.ChainModule.ChainReader
+	head := chainReader.GetHead()
+	if to == 0 || to > head.EnsureHeight() {
+		to = head.EnsureHeight()
+	}
+	if from > to {
+		return nil, xerrors.Errorf("invalid range from %d to %d", from, to)
+	}
+
+	// load the tipsets of the range from the top down, along with the child of the highest one
+	top := head
+	if to < head.EnsureHeight() {
+		var err error
+		if top, err = chainReader.GetTipSetByHeight(ctx, head, to+1, false); err != nil {
+			return nil, xerrors.Errorf("loading tipset at %d: %v", to+1, err)
+		}
+	}
+	tss := []*block.TipSet{top}
+	for ts := top; ts.EnsureHeight() > from && ts.EnsureHeight() > 0; {
+		parent, err := chainReader.GetTipSet(ts.EnsureParents())
+		if err != nil {
+			return nil, xerrors.Errorf("loading parent of %s: %v", ts.Key(), err)
+		}
+		tss = append(tss, parent)
+		ts = parent
+	}
+
+	res := &ChainValidateResult{}
+	for i := len(tss) - 1; i >= 0; i-- {
+		ts := tss[i]
+		if ts.EnsureHeight() < from || ts.EnsureHeight() > to || ts.EnsureHeight() == 0 {
+			continue
+		}
+		var child *block.TipSet
+		if i > 0 {
+			child = tss[i-1]
+		}
+
+		div, err := syncerAPI.validateTipSet(ctx, ts, child)
+		if err != nil {
+			return nil, err
+		}
+		if div != nil {
+			res.Divergence = div
+			return res, nil
+		}
+		res.Validated++
+	}
+	return res, nil
+}
+
+// validateTipSet executes `ts` on its parent state and returns the first mismatch with its
+// metadata or with the headers of `child`, if any.
+func (syncerAPI *SyncerAPI) validateTipSet(ctx context.Context, ts, child *block.TipSet) (*StateDivergence, error) {
+	root, receipts, err := syncerAPI.syncer.Consensus.RunStateTransition(ctx, ts, ts.At(0).ParentStateRoot)
+	if err != nil {
+		return nil, xerrors.Errorf("executing tipset %s: %v", ts.Key(), err)
+	}
+	if !root.Defined() {
+		return nil, xerrors.Errorf("executing tipset %s: failed to load its messages", ts.Key())
+	}
+	receiptsRoot, err := chain.GetReceiptRoot(receipts)
+	if err != nil {
+		return nil, xerrors.Errorf("computing receipts root of %s: %v", ts.Key(), err)
+	}
+
+	expects := []*StateDivergence{}
+	if md, err := syncerAPI.syncer.ChainModule.ChainReader.LoadTipsetMetadata(ts); err == nil {
+		expects = append(expects, &StateDivergence{
+			Source:               "metadata",
+			ExpectedStateRoot:    md.TipSetStateRoot,
+			ExpectedReceiptsRoot: md.TipSetReceipts,
+		})
+	}
+	if child != nil {
+		expects = append(expects, &StateDivergence{
+			Source:               "child",
+			ExpectedStateRoot:    child.At(0).ParentStateRoot,
+			ExpectedReceiptsRoot: child.At(0).ParentMessageReceipts,
+		})
+	}
+
+	for _, div := range expects {
+		if div.ExpectedStateRoot.Equals(root) && div.ExpectedReceiptsRoot.Equals(receiptsRoot) {
+			continue
+		}
+		div.TipSet = ts.Key()
+		div.Height = ts.EnsureHeight()
+		div.ComputedStateRoot = root
+		div.ComputedReceiptsRoot = receiptsRoot
+		if div.ExpectedStateRoot.Equals(root) {
+			return div, nil
+		}
+
+		store := syncerAPI.syncer.ChainModule.State.Store(ctx)
+		expected, err := vmstate.LoadState(ctx, store, div.ExpectedStateRoot)
+		if err != nil {
+			syncAPILog.Warnf("failed to load expected state %s: %s", div.ExpectedStateRoot, err)
+			return div, nil
+		}
+		computed, err := vmstate.LoadState(ctx, store, root)
+		if err != nil {
+			return nil, xerrors.Errorf("loading computed state %s: %v", root, err)
+		}
+		if div.Diff, err = vmstate.DiffActors(expected, computed); err != nil {
+			return nil, xerrors.Errorf("diffing states: %v", err)
+		}
+		return div, nil
+	}
+	return nil, nil
+}
+
 //SyncState just compatible code lotus
 func (syncerAPI *SyncerAPI) SyncState(ctx context.Context) (*SyncState, error) {
 	tracker := syncerAPI.syncer.ChainSyncManager.BlockProposer().SyncTracker()
 	tracker.History()
 
+	now := time.Now()
 	syncState := &SyncState{
-		VMApplied: 0,
+		VMApplied:  0,
+		HeadHeight: syncerAPI.syncer.ChainModule.ChainReader.GetHead().EnsureHeight(),
+		ClockEpoch: syncerAPI.syncer.ChainClock.EpochAtTime(now),
 	}
 
 	count := 0
 	toActiveSync := func(t *syncTypes.Target) ActiveSync {
-		currentHeight := t.Base.EnsureHeight()
-		if t.Current != nil {
-			currentHeight = t.Current.EnsureHeight()
-		}
+		progress := t.Progress(now)
 
 		msg := ""
 		if t.Err != nil {
@@ -146,13 +361,17 @@ func (syncerAPI *SyncerAPI) SyncState(ctx context.Context) (*SyncState, error) {
 		count++
 
 		activeSync := ActiveSync{
-			WorkerID: uint64(count),
-			Base:     t.Base,
-			Target:   t.Head,
-			Height:   currentHeight,
-			Start:    t.Start,
-			End:      t.End,
-			Message:  msg,
+			WorkerID:            uint64(count),
+			Base:                t.Base,
+			Target:              t.Head,
+			Height:              progress.Current,
+			Start:               t.Start,
+			End:                 t.End,
+			Message:             msg,
+			TargetHeight:        progress.Target,
+			FetchedHeight:       progress.Fetched,
+			EpochsPerSecond:     progress.EpochsPerSecond,
+			EstimatedCompletion: progress.EstimatedCompletion,
 		}
 
 		switch t.State {
@@ -163,7 +382,14 @@ func (syncerAPI *SyncerAPI) SyncState(ctx context.Context) (*SyncState, error) {
 		case syncTypes.StageSyncComplete:
 			activeSync.Stage = StageSyncComplete
 		case syncTypes.StateInSyncing:
-			activeSync.Stage = StageMessages
+			switch progress.Stage {
+			case syncTypes.SyncStageHeaders:
+				activeSync.Stage = StageHeaders
+			case syncTypes.SyncStageMessages:
+				activeSync.Stage = StageFetchingMessages
+			default:
+				activeSync.Stage = StageMessages
+			}
 		}
 
 		return activeSync
@@ -185,3 +411,27 @@ func (syncerAPI *SyncerAPI) SyncState(ctx context.Context) (*SyncState, error) {
 
 	return syncState, nil
 }
+
+// SyncMarkBad marks a tipset as bad so the syncer refuses to sync it or any chain that
+// includes it. The mark is persisted and survives restarts.
+func (syncerAPI *SyncerAPI) SyncMarkBad(ctx context.Context, tsk block.TipSetKey, reason string) error {
+	if tsk.IsEmpty() {
+		return xerrors.New("tipset key must not be empty")
+	}
+	if reason == "" {
+		reason = "marked bad manually"
+	}
+	head := syncerAPI.syncer.ChainModule.ChainReader.GetHead()
+	return syncerAPI.syncer.BadTipSets.Add(tsk, reason, head.EnsureHeight())
+}
+
+// SyncUnmarkBad removes a tipset from the bad tipsets, the syncer will try it again the next
+// time it is proposed.
+func (syncerAPI *SyncerAPI) SyncUnmarkBad(ctx context.Context, tsk block.TipSetKey) error {
+	return syncerAPI.syncer.BadTipSets.Remove(tsk)
+}
+
+// SyncCheckBad returns why a tipset was marked bad, or nil if it is not marked.
+func (syncerAPI *SyncerAPI) SyncCheckBad(ctx context.Context, tsk block.TipSetKey) (*syncTypes.BadTipSetInfo, error) {
+	return syncerAPI.syncer.BadTipSets.Get(tsk), nil
+}
@@ -0,0 +1,324 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chain2 "github.com/filecoin-project/venus/app/submodule/chain"
+	"github.com/filecoin-project/venus/app/submodule/chain/cst"
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/consensus"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/vm"
+	"github.com/filecoin-project/venus/pkg/vm/gas"
+	"github.com/filecoin-project/venus/pkg/vm/vmcontext"
+)
+
+// fakeExecutor applies every message with a gas cost of 100 per nonce and a subcall to
+// the receiver of the message.
+type fakeExecutor struct {
+	consensus.Protocol
+	t        *testing.T
+	mstore   chain.MessageProvider
+	executed []*types.UnsignedMessage
+
+	// states and receipts are the results of the state transitions by tipset
+	states   map[block.TipSetKey]cid.Cid
+	receipts map[block.TipSetKey][]types.MessageReceipt
+}
+
+func (e *fakeExecutor) apply(msg *types.UnsignedMessage, cb vm.ExecCallBack) error {
+	e.executed = append(e.executed, msg)
+
+	mcid, err := msg.Cid()
+	require.NoError(e.t, err)
+
+	outputs := gas.ZeroGasOutputs()
+	outputs.MinerTip = big.NewInt(7)
+	receipt := types.MessageReceipt{ExitCode: exitcode.Ok, GasUsed: 100 * int64(msg.Nonce+1)}
+	ret := &vm.Ret{
+		GasTracker: &gas.GasTracker{
+			ExecutionTrace: types.ExecutionTrace{
+				Msg:    msg,
+				MsgRct: &receipt,
+				Subcalls: []types.ExecutionTrace{{
+					Msg:    &types.UnsignedMessage{From: msg.To, To: msg.From},
+					MsgRct: &types.MessageReceipt{ExitCode: exitcode.Ok},
+				}},
+			},
+		},
+		OutPuts: outputs,
+		Receipt: receipt,
+	}
+	return cb(mcid, vmcontext.VmMessageFromUnsignedMessage(msg), ret)
+}
+
+func (e *fakeExecutor) ExecuteTipSet(ctx context.Context, ts *block.TipSet, cb vm.ExecCallBack) (cid.Cid, []types.MessageReceipt, error) {
+	blockMsgs, err := e.mstore.LoadTipSetMessage(ctx, ts)
+	require.NoError(e.t, err)
+	for _, blockMsg := range blockMsgs {
+		for _, msg := range append(blockMsg.BlsMessages, blockMsg.SecpkMessages...) {
+			if err := e.apply(msg.VMMessage(), cb); err != nil {
+				return cid.Undef, nil, err
+			}
+		}
+	}
+	return ts.At(0).ParentStateRoot, nil, nil
+}
+
+func (e *fakeExecutor) RunStateTransition(_ context.Context, ts *block.TipSet, _ cid.Cid) (cid.Cid, []types.MessageReceipt, error) {
+	return e.states[ts.Key()], e.receipts[ts.Key()], nil
+}
+
+func (e *fakeExecutor) ComputeTipSetState(ctx context.Context, height abi.ChainEpoch, msgs []*types.UnsignedMessage, ts *block.TipSet, cb vm.ExecCallBack) (cid.Cid, error) {
+	for _, msg := range msgs {
+		if err := e.apply(msg, cb); err != nil {
+			return cid.Undef, err
+		}
+	}
+	return types.CidFromString(e.t, "computed"), nil
+}
+
+// msgCids returns the cids of `msgs`, the decoded messages aren't deeply equal to the built ones.
+func msgCids(t *testing.T, msgs []*types.UnsignedMessage) []cid.Cid {
+	cids := make([]cid.Cid, len(msgs))
+	for i, msg := range msgs {
+		var err error
+		cids[i], err = msg.Cid()
+		require.NoError(t, err)
+	}
+	return cids
+}
+
+func newTestSyncerAPI(t *testing.T) (*SyncerAPI, *chain.Builder, *fakeExecutor) {
+	builder := chain.NewBuilder(t, address.Undef)
+	executor := &fakeExecutor{
+		t:        t,
+		mstore:   builder.Mstore(),
+		states:   make(map[block.TipSetKey]cid.Cid),
+		receipts: make(map[block.TipSetKey][]types.MessageReceipt),
+	}
+	return &SyncerAPI{syncer: &SyncerSubmodule{
+		ChainModule: &chain2.ChainSubmodule{
+			ChainReader:  builder.Store(),
+			MessageStore: builder.Mstore(),
+			State:        cst.NewChainStateReadWriter(builder.Store(), builder.Mstore(), builder.BlockStore(), nil, nil),
+		},
+		Consensus: executor,
+	}}, builder, executor
+}
+
+func TestStateReplay(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	api, builder, executor := newTestSyncerAPI(t)
+
+	msgs := types.NewMsgs(3)
+	withMsgs := builder.BuildOneOn(builder.Genesis(), func(bb *chain.BlockBuilder) {
+		bb.AddMessages([]*types.SignedMessage{}, msgs)
+	})
+	child := builder.AppendOn(withMsgs, 1)
+
+	replayed, err := msgs[1].Cid()
+	require.NoError(t, err)
+
+	// the messages of the parent of the tipset are executed until the replayed one
+	res, err := api.StateReplay(ctx, child.Key(), replayed)
+	require.NoError(t, err)
+	assert.Equal(t, msgCids(t, msgs[:2]), msgCids(t, executor.executed))
+
+	assert.Equal(t, replayed, res.MsgCid)
+	assert.Equal(t, msgCids(t, msgs[1:2]), msgCids(t, []*types.UnsignedMessage{res.Msg}))
+	assert.Equal(t, int64(200), res.MsgRct.GasUsed)
+	assert.Equal(t, replayed, res.GasCost.Message)
+	assert.True(t, big.NewInt(200).Equals(res.GasCost.GasUsed))
+	assert.True(t, big.NewInt(7).Equals(res.GasCost.TotalCost))
+	require.Len(t, res.ExecutionTrace.Subcalls, 1)
+	assert.Equal(t, msgs[1].To, res.ExecutionTrace.Subcalls[0].Msg.From)
+
+	// a message which isn't executed in the tipset can't be replayed
+	_, err = api.StateReplay(ctx, withMsgs.Key(), replayed)
+	assert.Error(t, err)
+
+	_, err = api.StateReplay(ctx, child.Key(), types.CidFromString(t, "unknown"))
+	assert.Error(t, err)
+}
+
+func TestStateReplaySignedMessage(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	api, builder, executor := newTestSyncerAPI(t)
+
+	signer, _ := types.NewMockSignersAndKeyInfo(1)
+	newSignedMsg := types.NewSignedMessageForTestGetter(signer)
+	smsgs := []*types.SignedMessage{newSignedMsg(0), newSignedMsg(1)}
+	withMsgs := builder.BuildOneOn(builder.Genesis(), func(bb *chain.BlockBuilder) {
+		bb.AddMessages(smsgs, []*types.UnsignedMessage{})
+	})
+	child := builder.AppendOn(withMsgs, 1)
+
+	// the message is replayed by the cid of the signed message, it is executed by the cid of the unsigned one
+	replayed, err := smsgs[1].Cid()
+	require.NoError(t, err)
+	res, err := api.StateReplay(ctx, child.Key(), replayed)
+	require.NoError(t, err)
+	assert.Equal(t, msgCids(t, []*types.UnsignedMessage{&smsgs[0].Message, &smsgs[1].Message}), msgCids(t, executor.executed))
+
+	// the result is of the message asked for
+	assert.Equal(t, replayed, res.MsgCid)
+	assert.Equal(t, msgCids(t, []*types.UnsignedMessage{&smsgs[1].Message}), msgCids(t, []*types.UnsignedMessage{res.Msg}))
+	assert.Equal(t, int64(200), res.MsgRct.GasUsed)
+}
+
+func TestStateReplayTraceOfSignedMessage(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	api, builder, _ := newTestSyncerAPI(t)
+
+	// a block with a bls message followed by a secp message, as most user sends are
+	signer, _ := types.NewMockSignersAndKeyInfo(1)
+	smsg := types.NewSignedMessageForTestGetter(signer)(1)
+	msgs := types.NewMsgs(1)
+	withMsgs := builder.BuildOneOn(builder.Genesis(), func(bb *chain.BlockBuilder) {
+		bb.AddMessages([]*types.SignedMessage{smsg}, msgs)
+	})
+	executedIn := builder.AppendOn(withMsgs, 1)
+
+	// `state wait --show-trace` replays the landed message, by its signed cid, in the tipset
+	// it was executed in
+	landed, err := smsg.Cid()
+	require.NoError(t, err)
+	res, err := api.StateReplay(ctx, executedIn.Key(), landed)
+	require.NoError(t, err)
+
+	assert.Equal(t, landed, res.MsgCid)
+	assert.Equal(t, msgCids(t, []*types.UnsignedMessage{&smsg.Message}), msgCids(t, []*types.UnsignedMessage{res.ExecutionTrace.Msg}))
+	require.Len(t, res.ExecutionTrace.Subcalls, 1)
+	assert.Equal(t, smsg.Message.To, res.ExecutionTrace.Subcalls[0].Msg.From)
+	assert.Equal(t, smsg.Message.From, res.ExecutionTrace.Subcalls[0].Msg.To)
+}
+
+func TestStateCompute(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	api, builder, executor := newTestSyncerAPI(t)
+	head := builder.AppendOn(builder.Genesis(), 1)
+
+	msgs := types.NewMsgs(2)
+	out, err := api.StateCompute(ctx, head.EnsureHeight()+1, msgs, head.Key())
+	require.NoError(t, err)
+	assert.Equal(t, types.CidFromString(t, "computed"), out.Root)
+	assert.Equal(t, msgCids(t, msgs), msgCids(t, executor.executed))
+
+	// every message applied is traced, in order
+	require.Len(t, out.Trace, 2)
+	for i, res := range out.Trace {
+		mcid, err := msgs[i].Cid()
+		require.NoError(t, err)
+		assert.Equal(t, mcid, res.MsgCid)
+		assert.Equal(t, msgs[i], res.Msg)
+		assert.Equal(t, int64(100*(i+1)), res.MsgRct.GasUsed)
+		assert.Len(t, res.ExecutionTrace.Subcalls, 1)
+	}
+
+	_, err = api.StateCompute(ctx, head.EnsureHeight()+1, msgs, block.NewTipSetKey(types.CidFromString(t, "unknown")))
+	assert.Error(t, err)
+}
+
+// receipts returns `n` receipts, the gas used by each one is its index.
+func receipts(n int) []types.MessageReceipt {
+	out := make([]types.MessageReceipt, n)
+	for i := range out {
+		out[i].GasUsed = int64(i)
+	}
+	return out
+}
+
+func TestChainValidate(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	api, builder, executor := newTestSyncerAPI(t)
+
+	// the tipset at height k executes k messages, recorded by the headers of its child
+	tss := []*block.TipSet{builder.Genesis()}
+	for k := 1; k <= 4; k++ {
+		parentReceipts := receipts(k - 1)
+		tss = append(tss, builder.BuildOneOn(tss[k-1], func(bb *chain.BlockBuilder) {
+			bb.SetParentReceipts(parentReceipts)
+		}))
+	}
+	for k := 1; k < 4; k++ {
+		executor.states[tss[k].Key()] = tss[k+1].At(0).ParentStateRoot
+		executor.receipts[tss[k].Key()] = receipts(k)
+	}
+	// the head has no child to check against
+	executor.states[tss[4].Key()] = types.CidFromString(t, "head")
+	executor.receipts[tss[4].Key()] = receipts(4)
+	require.NoError(t, builder.Store().SetHead(ctx, tss[4]))
+
+	receiptsRoot := func(k int) cid.Cid {
+		root, err := chain.GetReceiptRoot(receipts(k))
+		require.NoError(t, err)
+		return root
+	}
+	require.NoError(t, builder.Store().PutTipSetMetadata(ctx, &chain.TipSetMetadata{
+		TipSet:          tss[2],
+		TipSetStateRoot: executor.states[tss[2].Key()],
+		TipSetReceipts:  receiptsRoot(2),
+	}))
+
+	res, err := api.ChainValidate(ctx, 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Validated)
+	assert.Nil(t, res.Divergence)
+
+	// the receipts stored for a tipset don't match its execution
+	require.NoError(t, builder.Store().PutTipSetMetadata(ctx, &chain.TipSetMetadata{
+		TipSet:          tss[2],
+		TipSetStateRoot: executor.states[tss[2].Key()],
+		TipSetReceipts:  receiptsRoot(5),
+	}))
+	res, err = api.ChainValidate(ctx, 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Validated)
+	require.NotNil(t, res.Divergence)
+	assert.Equal(t, tss[2].Key(), res.Divergence.TipSet)
+	assert.Equal(t, "metadata", res.Divergence.Source)
+	assert.Equal(t, receiptsRoot(5), res.Divergence.ExpectedReceiptsRoot)
+	assert.Equal(t, receiptsRoot(2), res.Divergence.ComputedReceiptsRoot)
+
+	// the state computed for a tipset doesn't match the one of its child
+	res, err = api.ChainValidate(ctx, 3, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Validated)
+	assert.Nil(t, res.Divergence)
+
+	executor.states[tss[3].Key()] = types.CidFromString(t, "tampered")
+	res, err = api.ChainValidate(ctx, 3, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Validated)
+	require.NotNil(t, res.Divergence)
+	assert.Equal(t, tss[3].Key(), res.Divergence.TipSet)
+	assert.Equal(t, abi.ChainEpoch(3), res.Divergence.Height)
+	assert.Equal(t, "child", res.Divergence.Source)
+	assert.Equal(t, tss[4].At(0).ParentStateRoot, res.Divergence.ExpectedStateRoot)
+	assert.Equal(t, types.CidFromString(t, "tampered"), res.Divergence.ComputedStateRoot)
+
+	_, err = api.ChainValidate(ctx, 3, 2)
+	assert.Error(t, err)
+}
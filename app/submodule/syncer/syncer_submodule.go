@@ -29,6 +29,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/chain"
 	"github.com/filecoin-project/venus/pkg/chainsync"
 	"github.com/filecoin-project/venus/pkg/chainsync/fetcher"
+	syncTypes "github.com/filecoin-project/venus/pkg/chainsync/types"
 	"github.com/filecoin-project/venus/pkg/clock"
 	"github.com/filecoin-project/venus/pkg/consensus"
 	"github.com/filecoin-project/venus/pkg/net/blocksub"
@@ -56,6 +57,8 @@ type SyncerSubmodule struct { //nolint
 	Drand            beacon.Schedule
 	SyncProvider     ChainSyncProvider
 	SlashFilter      *slashfilter.SlashFilter
+	BadTipSets       *syncTypes.BadTipSetCache
+	ChainClock       clock.ChainEpochClock
 	// cancelChainSync cancels the context for chain sync subscriptions and handlers.
 	CancelChainSync context.CancelFunc
 	// faultCh receives detected consensus faults
@@ -140,7 +143,12 @@ func NewSyncerSubmodule(ctx context.Context,
 	faultCh := make(chan slashing.ConsensusFault)
 	faultDetector := slashing.NewConsensusFaultDetector(faultCh)
 
-	chainSyncManager, err := chainsync.NewManager(nodeConsensus, blkValid, nodeChainSelector, chn.ChainReader, chn.MessageStore, blockstore.Blockstore, fetcher, discovery.ExchangeClient, config.ChainClock(), faultDetector, chn.Fork)
+	badTipSets, err := syncTypes.NewBadTipSetCache(config.Repo().ChainDatastore())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load bad tipsets")
+	}
+
+	chainSyncManager, err := chainsync.NewManager(nodeConsensus, blkValid, nodeChainSelector, chn.ChainReader, chn.MessageStore, blockstore.Blockstore, fetcher, discovery.ExchangeClient, config.ChainClock(), faultDetector, chn.Fork, badTipSets)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +167,8 @@ func NewSyncerSubmodule(ctx context.Context,
 		NetworkModule:      network,
 		DiscoverySubmodule: discovery,
 		SlashFilter:        slashfilter.New(config.Repo().ChainDatastore()),
+		BadTipSets:         badTipSets,
+		ChainClock:         config.ChainClock(),
 		Consensus:          nodeConsensus,
 		ChainSelector:      nodeChainSelector,
 		ChainSyncManager:   &chainSyncManager,
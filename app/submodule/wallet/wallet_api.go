@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
@@ -39,7 +40,7 @@ func (walletAPI *WalletAPI) WalletHas(ctx context.Context, addr address.Address)
 }
 
 // SetWalletDefaultAddress set the specified address as the default in the config.
-func (walletAPI *WalletAPI) WalletDefaultAddress() (address.Address, error) {
+func (walletAPI *WalletAPI) WalletDefaultAddress(ctx context.Context) (address.Address, error) {
 	ret, err := walletAPI.walletModule.Config.Get("walletModule.defaultAddress")
 	addr := ret.(address.Address)
 	if err != nil || !addr.Empty() {
@@ -47,8 +48,8 @@ func (walletAPI *WalletAPI) WalletDefaultAddress() (address.Address, error) {
 	}
 
 	// No default is set; pick the 0th and make it the default.
-	if len(walletAPI.WalletAddresses()) > 0 {
-		addr := walletAPI.WalletAddresses()[0]
+	if addrs := walletAPI.WalletAddresses(ctx); len(addrs) > 0 {
+		addr := addrs[0]
 		err := walletAPI.walletModule.Config.Set("walletModule.defaultAddress", addr.String())
 		if err != nil {
 			return address.Undef, err
@@ -61,13 +62,13 @@ func (walletAPI *WalletAPI) WalletDefaultAddress() (address.Address, error) {
 }
 
 // WalletAddresses gets addresses from the walletModule
-func (walletAPI *WalletAPI) WalletAddresses() []address.Address {
+func (walletAPI *WalletAPI) WalletAddresses(ctx context.Context) []address.Address {
 	return walletAPI.walletModule.Wallet.Addresses()
 }
 
 // SetWalletDefaultAddress set the specified address as the default in the config.
-func (walletAPI *WalletAPI) WalletSetDefault(_ context.Context, addr address.Address) error {
-	localAddrs := walletAPI.WalletAddresses()
+func (walletAPI *WalletAPI) WalletSetDefault(ctx context.Context, addr address.Address) error {
+	localAddrs := walletAPI.WalletAddresses(ctx)
 	for _, localAddr := range localAddrs {
 		if localAddr == addr {
 			err := walletAPI.walletModule.Config.Set("walletModule.defaultAddress", addr.String())
@@ -81,12 +82,12 @@ func (walletAPI *WalletAPI) WalletSetDefault(_ context.Context, addr address.Add
 }
 
 // WalletNewAddress generates a new walletModule address
-func (walletAPI *WalletAPI) WalletNewAddress(protocol address.Protocol) (address.Address, error) {
+func (walletAPI *WalletAPI) WalletNewAddress(ctx context.Context, protocol address.Protocol) (address.Address, error) {
 	return wallet.NewAddress(walletAPI.walletModule.Wallet, protocol)
 }
 
 // WalletImport adds a given set of KeyInfos to the walletModule
-func (walletAPI *WalletAPI) WalletImport(key *crypto.KeyInfo) (address.Address, error) {
+func (walletAPI *WalletAPI) WalletImport(ctx context.Context, key *crypto.KeyInfo) (address.Address, error) {
 	addrs, err := walletAPI.walletModule.Wallet.Import(key)
 	if err != nil {
 		return address.Undef, err
@@ -95,11 +96,70 @@ func (walletAPI *WalletAPI) WalletImport(key *crypto.KeyInfo) (address.Address,
 }
 
 // WalletExport returns the KeyInfos for the given walletModule addresses
-func (walletAPI *WalletAPI) WalletExport(addrs []address.Address) ([]*crypto.KeyInfo, error) {
+func (walletAPI *WalletAPI) WalletExport(ctx context.Context, addrs []address.Address) ([]*crypto.KeyInfo, error) {
 	return walletAPI.walletModule.Wallet.Export(addrs)
 }
 
-func (walletAPI *WalletAPI) WalletSign(ctx context.Context, k address.Address, msg []byte, _ wallet.MsgMeta) (*crypto.Signature, error) {
+// WalletSetPassphrase sets the passphrase of an encrypted walletModule and encrypts the
+// keys stored so far with it. It fails if a passphrase is already set.
+func (walletAPI *WalletAPI) WalletSetPassphrase(ctx context.Context, passphrase []byte) error {
+	backend, err := walletAPI.encryptedBackend()
+	if err != nil {
+		return err
+	}
+	return backend.SetPassphrase(passphrase)
+}
+
+// WalletUnlock unlocks an encrypted walletModule for `timeout`, or for the configured
+// unlock timeout if zero.
+func (walletAPI *WalletAPI) WalletUnlock(ctx context.Context, passphrase []byte, timeout time.Duration) error {
+	backend, err := walletAPI.encryptedBackend()
+	if err != nil {
+		return err
+	}
+
+	if timeout == 0 {
+		ret, err := walletAPI.walletModule.Config.Get("walletModule.unlockTimeout")
+		if err != nil {
+			return err
+		}
+		timeout, err = time.ParseDuration(ret.(string))
+		if err != nil {
+			return xerrors.Errorf("invalid unlock timeout in config: %w", err)
+		}
+	}
+
+	return backend.Unlock(passphrase, timeout)
+}
+
+// WalletLock locks an encrypted walletModule, signing is refused until it is unlocked.
+func (walletAPI *WalletAPI) WalletLock(ctx context.Context) error {
+	backend, err := walletAPI.encryptedBackend()
+	if err != nil {
+		return err
+	}
+	backend.Lock()
+	return nil
+}
+
+// WalletLocked returns true while an encrypted walletModule is locked.
+func (walletAPI *WalletAPI) WalletLocked(ctx context.Context) (bool, error) {
+	backend, err := walletAPI.encryptedBackend()
+	if err != nil {
+		return false, err
+	}
+	return backend.Locked(), nil
+}
+
+func (walletAPI *WalletAPI) encryptedBackend() (*wallet.EncryptedBackend, error) {
+	backends := walletAPI.walletModule.Wallet.Backends(wallet.EncryptedBackendType)
+	if len(backends) == 0 {
+		return nil, errors.New("walletModule is not encrypted, set walletModule.encrypted in the config")
+	}
+	return backends[0].(*wallet.EncryptedBackend), nil
+}
+
+func (walletAPI *WalletAPI) WalletSign(ctx context.Context, k address.Address, msg []byte, meta wallet.MsgMeta) (*crypto.Signature, error) {
 	head := walletAPI.walletModule.Chain.ChainReader.GetHead()
 	view, err := walletAPI.walletModule.Chain.State.StateView(head)
 	if err != nil {
@@ -110,9 +170,10 @@ func (walletAPI *WalletAPI) WalletSign(ctx context.Context, k address.Address, m
 	if err != nil {
 		return nil, xerrors.Errorf("failed to resolve ID address: %v", keyAddr)
 	}
-	return walletAPI.walletModule.Wallet.WalletSign(ctx, keyAddr, msg, wallet.MsgMeta{
-		Type: wallet.MTUnknown,
-	})
+	if meta.Type == "" {
+		meta.Type = wallet.MTUnknown
+	}
+	return walletAPI.walletModule.Wallet.WalletSign(ctx, keyAddr, msg, meta)
 }
 
 func (walletAPI *WalletAPI) WalletSignMessage(ctx context.Context, k address.Address, msg *types.UnsignedMessage) (*types.SignedMessage, error) {
@@ -121,7 +182,10 @@ func (walletAPI *WalletAPI) WalletSignMessage(ctx context.Context, k address.Add
 		return nil, xerrors.Errorf("serializing message: %w", err)
 	}
 
-	sig, err := walletAPI.WalletSign(ctx, k, mb.Cid().Bytes(), wallet.MsgMeta{})
+	sig, err := walletAPI.WalletSign(ctx, k, mb.Cid().Bytes(), wallet.MsgMeta{
+		Type:  wallet.MTChainMsg,
+		Extra: mb.RawData(),
+	})
 	if err != nil {
 		return nil, xerrors.Errorf("failed to sign message: %w", err)
 	}
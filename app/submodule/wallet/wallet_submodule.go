@@ -5,6 +5,7 @@ import (
 	"github.com/filecoin-project/venus/app/submodule/chain"
 	"github.com/filecoin-project/venus/app/submodule/config"
 
+	pconfig "github.com/filecoin-project/venus/pkg/config"
 	"github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/pkg/state"
 	"github.com/filecoin-project/venus/pkg/types"
@@ -18,15 +19,27 @@ type WalletSubmodule struct { //nolint
 	Wallet *wallet.Wallet
 	Signer types.Signer
 	Config *config.ConfigModule
+
+	backend wallet.Backend
 }
 
 type walletRepo interface {
+	Config() *pconfig.Config
 	WalletDatastore() repo.Datastore
 }
 
 // NewWalletSubmodule creates a new storage protocol submodule.
 func NewWalletSubmodule(ctx context.Context, cfg *config.ConfigModule, repo walletRepo, chain *chain.ChainSubmodule) (*WalletSubmodule, error) {
-	backend, err := wallet.NewDSBackend(repo.WalletDatastore())
+	var backend wallet.Backend
+	var err error
+	switch walletCfg := repo.Config().Wallet; {
+	case walletCfg.RemoteBackend != "":
+		backend, err = wallet.NewRemoteBackendFromInfo(ctx, walletCfg.RemoteBackend)
+	case walletCfg.Encrypted:
+		backend, err = wallet.NewEncryptedBackend(repo.WalletDatastore())
+	default:
+		backend, err = wallet.NewDSBackend(repo.WalletDatastore())
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to set up walletModule backend")
 	}
@@ -37,9 +50,18 @@ func NewWalletSubmodule(ctx context.Context, cfg *config.ConfigModule, repo wall
 		Chain:  chain,
 		Wallet: fcWallet,
 		Signer: state.NewSigner(chain.ActorState, chain.ChainReader, fcWallet),
+
+		backend: backend,
 	}, nil
 }
 
+// Stop closes the connection to the remote signing service, if the wallet uses one.
+func (wallet *WalletSubmodule) Stop(ctx context.Context) {
+	if closer, ok := wallet.backend.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
 func (wallet *WalletSubmodule) API() *WalletAPI {
 	return &WalletAPI{walletModule: wallet}
 }
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/filecoin-project/go-address"
 	cmds "github.com/ipfs/go-ipfs-cmds"
@@ -25,13 +26,16 @@ var walletCmd = &cmds.Command{
 		Tagline: "Manage your filecoin wallets",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"balance":     balanceCmd,
-		"import":      walletImportCmd,
-		"export":      walletExportCmd,
-		"ls":          addrsLsCmd,
-		"new":         addrsNewCmd,
-		"default":     defaultAddressCmd,
-		"set-default": setDefaultAddressCmd,
+		"balance":        balanceCmd,
+		"import":         walletImportCmd,
+		"export":         walletExportCmd,
+		"ls":             addrsLsCmd,
+		"new":            addrsNewCmd,
+		"default":        defaultAddressCmd,
+		"set-default":    setDefaultAddressCmd,
+		"set-passphrase": walletSetPassphraseCmd,
+		"lock":           walletLockCmd,
+		"unlock":         walletUnlockCmd,
 	},
 }
 
@@ -57,7 +61,7 @@ var addrsNewCmd = &cmds.Command{
 			return fmt.Errorf("unrecognized address protocol %s", protocolName)
 		}
 
-		addr, err := env.(*node.Env).WalletAPI.WalletNewAddress(protocol)
+		addr, err := env.(*node.Env).WalletAPI.WalletNewAddress(req.Context, protocol)
 		if err != nil {
 			return err
 		}
@@ -79,10 +83,10 @@ var addrsLsCmd = &cmds.Command{
 		api := env.(*node.Env)
 		ctx := req.Context
 
-		addrs := api.WalletAPI.WalletAddresses()
+		addrs := api.WalletAPI.WalletAddresses(ctx)
 
 		// Assume an error means no default key is set
-		def, _ := api.WalletAPI.WalletDefaultAddress()
+		def, _ := api.WalletAPI.WalletDefaultAddress(ctx)
 
 		buf := new(bytes.Buffer)
 		tw := tablewriter.New(
@@ -159,7 +163,7 @@ var addrsLsCmd = &cmds.Command{
 
 var defaultAddressCmd = &cmds.Command{
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
-		addr, err := env.(*node.Env).WalletAPI.WalletDefaultAddress()
+		addr, err := env.(*node.Env).WalletAPI.WalletDefaultAddress(req.Context)
 		if err != nil {
 			return err
 		}
@@ -231,7 +235,7 @@ var walletImportCmd = &cmds.Command{
 			return err
 		}
 
-		addr, err := env.(*node.Env).WalletAPI.WalletImport(&key)
+		addr, err := env.(*node.Env).WalletAPI.WalletImport(req.Context, &key)
 		if err != nil {
 			return err
 		}
@@ -254,7 +258,7 @@ var walletExportCmd = &cmds.Command{
 			addrs[i] = addr
 		}
 
-		kis, err := env.(*node.Env).WalletAPI.WalletExport(addrs)
+		kis, err := env.(*node.Env).WalletAPI.WalletExport(req.Context, addrs)
 		if err != nil {
 			return err
 		}
@@ -265,3 +269,69 @@ var walletExportCmd = &cmds.Command{
 		return printOneString(re, hex.EncodeToString(data))
 	},
 }
+
+var walletSetPassphraseCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Set the passphrase of an encrypted wallet",
+		ShortDescription: `
+Encrypts the keys of the wallet with the given passphrase. Requires walletModule.encrypted
+to be set in the config, and can only be done once. The wallet stays locked.
+`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("passphrase", true, false, "passphrase to encrypt the wallet with").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		err := env.(*node.Env).WalletAPI.WalletSetPassphrase(req.Context, []byte(req.Arguments[0]))
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, "wallet passphrase set")
+	},
+}
+
+var walletUnlockCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Unlock an encrypted wallet",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("passphrase", true, false, "passphrase of the wallet").EnableStdin(),
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("timeout", "how long the wallet stays unlocked, e.g. 30m. Defaults to walletModule.unlockTimeout"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		var timeout time.Duration
+		if v, ok := req.Options["timeout"].(string); ok {
+			var err error
+			timeout, err = time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid timeout: %s", err)
+			}
+			if timeout <= 0 {
+				return fmt.Errorf("timeout must be positive")
+			}
+		}
+
+		err := env.(*node.Env).WalletAPI.WalletUnlock(req.Context, []byte(req.Arguments[0]), timeout)
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, "wallet unlocked")
+	},
+}
+
+var walletLockCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Lock an encrypted wallet",
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		if err := env.(*node.Env).WalletAPI.WalletLock(req.Context); err != nil {
+			return err
+		}
+
+		return printOneString(re, "wallet locked")
+	},
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/pkg/jwtauth"
+)
+
+var authCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Manage JSON-RPC API authorization",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"create-token": authCreateTokenCmd,
+		"api-info":     authAPIInfoCmd,
+	},
+}
+
+var permOption = cmds.StringOption("perm", "permission to assign to the token, one of: read, write, sign, admin")
+
+var authCreateTokenCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Create a JSON-RPC API token",
+		ShortDescription: `
+A token with a permission can call the methods requiring that permission or a
+lower one, in the order read, write, sign, admin.
+`,
+	},
+	Options: []cmds.Option{
+		permOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		token, err := createToken(req, env)
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, token)
+	},
+}
+
+var authAPIInfoCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Get a token and the API address, to use the JSON-RPC API from another process",
+	},
+	Options: []cmds.Option{
+		permOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		token, err := createToken(req, env)
+		if err != nil {
+			return err
+		}
+
+		apiAddr, err := env.(*node.Env).ConfigAPI.ConfigGet(req.Context, "api.apiAddress")
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, fmt.Sprintf("FULLNODE_API_INFO=%s:%s", token, apiAddr))
+	},
+}
+
+func createToken(req *cmds.Request, env cmds.Environment) (string, error) {
+	perm, ok := req.Options["perm"].(string)
+	if !ok {
+		return "", fmt.Errorf("--perm flag not set, one of: read, write, sign, admin")
+	}
+
+	perms, err := jwtauth.PermissionsUpTo(auth.Permission(perm))
+	if err != nil {
+		return "", err
+	}
+
+	token, err := env.(*node.Env).JwtAuthAPI.AuthNew(req.Context, perms)
+	if err != nil {
+		return "", err
+	}
+	return string(token), nil
+}
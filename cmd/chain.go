@@ -4,6 +4,10 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/filecoin-project/go-address"
@@ -16,6 +20,7 @@ import (
 	"github.com/filecoin-project/venus/app/node"
 	"github.com/filecoin-project/venus/app/submodule/chain"
 	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/specactors/policy"
 	"github.com/filecoin-project/venus/pkg/types"
 )
 
@@ -24,10 +29,14 @@ var chainCmd = &cmds.Command{
 		Tagline: "Inspect the filecoin blockchain",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"head":     chainHeadCmd,
-		"ls":       chainLsCmd,
-		"set-head": chainSetHeadCmd,
-		"getblock": chainGetBlockCmd,
+		"head":        chainHeadCmd,
+		"ls":          chainLsCmd,
+		"set-head":    chainSetHeadCmd,
+		"getblock":    chainGetBlockCmd,
+		"export":      chainExportCmd,
+		"path":        chainPathCmd,
+		"validate":    chainValidateCmd,
+		"fee-history": chainFeeHistoryCmd,
 	},
 }
 
@@ -111,7 +120,7 @@ var chainLsCmd = &cmds.Command{
 
 		res := make([]ChainLsResult, 0)
 		for _, key := range tipSetKeys {
-			tp, err := env.(*node.Env).ChainAPI.ChainGetTipSet(key)
+			tp, err := env.(*node.Env).ChainAPI.ChainGetTipSet(req.Context, key)
 			if err != nil {
 				return err
 			}
@@ -157,6 +166,149 @@ var chainSetHeadCmd = &cmds.Command{
 	},
 }
 
+var chainPathCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the reverts and applies that lead from one tipset to another.",
+		ShortDescription: `Tipsets are given as comma separated lists of block CIDs.
+The tipsets from <from> down to the common ancestor are listed first as reverts,
+then the tipsets up to <to> as applies.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("from", true, false, "Tipset to start from."),
+		cmds.StringArg("to", true, false, "Tipset to go to."),
+	},
+	Options: []cmds.Option{
+		cmds.BoolOption("json", "print the head changes as json"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		from, err := tipSetKeyFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		to, err := tipSetKeyFromString(req.Arguments[1])
+		if err != nil {
+			return err
+		}
+
+		path, err := env.(*node.Env).ChainAPI.ChainGetPath(req.Context, from, to)
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+
+		if asJSON, _ := req.Options["json"].(bool); asJSON {
+			out, err := json.MarshalIndent(path, "", "  ")
+			if err != nil {
+				return err
+			}
+			_ = writer.Write(out)
+			return re.Emit(buf)
+		}
+
+		for _, hc := range path {
+			writer.Printf("%s\t%d\t%s\n", hc.Type, hc.Val.EnsureHeight(), hc.Val.Key())
+		}
+		return re.Emit(buf)
+	},
+}
+
+var chainFeeHistoryCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the base fee and the gas premiums included in the last tipsets.",
+		ShortDescription: `Prints, from the oldest tipset, its height, the base fee paid by its messages,
+the ratio of their gas limit to the gas target and their effective gas premiums at
+the given percentiles, weighted by gas limit. The last line is the base fee of the
+messages to be included on top of the head.`,
+	},
+	Options: []cmds.Option{
+		cmds.IntOption("blocks", "number of tipsets").WithDefault(20),
+		cmds.StringOption("percentiles", "comma separated ascending percentiles of the gas premiums").WithDefault("10,50,90"),
+		cmds.BoolOption("json", "print the fee history as json"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		blocks, _ := req.Options["blocks"].(int)
+		var percentiles []float64
+		if str, _ := req.Options["percentiles"].(string); str != "" {
+			for _, s := range strings.Split(str, ",") {
+				p, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+				if err != nil {
+					return xerrors.Errorf("parsing percentile %s: %w", s, err)
+				}
+				percentiles = append(percentiles, p)
+			}
+		}
+
+		history, err := env.(*node.Env).MessagePoolAPI.ChainGetFeeHistory(req.Context, blocks, percentiles)
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+
+		if asJSON, _ := req.Options["json"].(bool); asJSON {
+			out, err := json.MarshalIndent(history, "", "  ")
+			if err != nil {
+				return err
+			}
+			_ = writer.Write(out)
+			return re.Emit(buf)
+		}
+
+		for i, epoch := range history.Epochs {
+			premiums := make([]string, len(history.Premiums[i]))
+			for j, premium := range history.Premiums[i] {
+				premiums[j] = premium.String()
+			}
+			writer.Printf("%d\tbase fee %s\tgas target ratio %.2f\tpremiums %s\n", epoch, history.BaseFees[i], history.GasTargetRatios[i], strings.Join(premiums, " "))
+		}
+		writer.Printf("next\tbase fee %s\n", history.BaseFees[len(history.BaseFees)-1])
+		return re.Emit(buf)
+	},
+}
+
+var chainValidateCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Re-execute the tipsets of a range of epochs and check the stored results.",
+		ShortDescription: `Every tipset of the canonical chain between --from and --to is executed again
+on its parent state. The resulting state and receipt roots are compared with the
+stored tipset metadata and with the headers of the child tipset. The first divergence
+is reported along with the actors that differ.`,
+	},
+	Options: []cmds.Option{
+		cmds.Int64Option("from", "first epoch to validate").WithDefault(int64(1)),
+		cmds.Int64Option("to", "last epoch to validate, defaults to the head"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		from, _ := req.Options["from"].(int64)
+		to, _ := req.Options["to"].(int64)
+
+		res, err := env.(*node.Env).SyncerAPI.ChainValidate(req.Context, abi.ChainEpoch(from), abi.ChainEpoch(to))
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		writer.Printf("validated %d tipsets\n", res.Validated)
+		if div := res.Divergence; div != nil {
+			writer.Printf("divergence at height %d, tipset %s\n", div.Height, div.TipSet)
+			writer.Printf("expected (%s): state %s, receipts %s\n", div.Source, div.ExpectedStateRoot, div.ExpectedReceiptsRoot)
+			writer.Printf("computed: state %s, receipts %s\n", div.ComputedStateRoot, div.ComputedReceiptsRoot)
+			if div.Diff != nil {
+				out, err := json.MarshalIndent(div.Diff, "", "  ")
+				if err != nil {
+					return err
+				}
+				writer.Println(string(out))
+			}
+		}
+		return re.Emit(buf)
+	},
+}
+
 var chainGetBlockCmd = &cmds.Command{
 	Helptext: cmds.HelpText{
 		Tagline: "Get a block and print its details.",
@@ -233,6 +385,102 @@ var chainGetBlockCmd = &cmds.Command{
 	},
 }
 
+var chainExportCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Export chain from repo to a car file.",
+		ShortDescription: `Writes a CAR snapshot of the chain, rooted at the current head or at the tipset
+given by --tipset, to <outputPath>. The headers and messages of every tipset down to
+genesis are included, but by default only the genesis state is; --recent-stateroots adds
+the state trees and receipts of the most recent epochs, at least a finality of them, or
+pass the height of the exported tipset to include the full state history.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("outputPath", true, false, "Path of the car file to write"),
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("tipset", "Comma separated block cids of the tipset to export from, defaults to head"),
+		cmds.Int64Option("recent-stateroots", "Number of recent state roots to include in the export, only the genesis state is included if unset"),
+		cmds.BoolOption("skip-old-msgs", "Only include messages within the recent state root range"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		api := env.(*node.Env).ChainAPI
+
+		rsrs, _ := req.Options["recent-stateroots"].(int64)
+		skipOldMsgs, _ := req.Options["skip-old-msgs"].(bool)
+		if rsrs == 0 && skipOldMsgs {
+			return xerrors.New("must pass recent stateroots along with skip-old-msgs")
+		}
+
+		ts, err := api.ChainHead(req.Context)
+		if err != nil {
+			return err
+		}
+		if tss, ok := req.Options["tipset"].(string); ok && tss != "" {
+			tsCids, err := cidsFromSlice(strings.Split(tss, ","))
+			if err != nil {
+				return err
+			}
+			ts, err = api.ChainGetTipSet(req.Context, block.NewTipSetKey(tsCids...))
+			if err != nil {
+				return err
+			}
+		}
+
+		// fewer state roots than the finality are only allowed to cover the whole chain
+		if rsrs > 0 && rsrs < int64(policy.ChainFinality) && rsrs < int64(ts.EnsureHeight()) {
+			return xerrors.Errorf("\"recent-stateroots\" has to be at least %d, or the height of the exported tipset", policy.ChainFinality)
+		}
+
+		stream, err := api.ChainExport(req.Context, abi.ChainEpoch(rsrs), skipOldMsgs, ts.Key())
+		if err != nil {
+			return err
+		}
+
+		r, w := io.Pipe()
+		go func() {
+			var last bool
+			for b := range stream {
+				last = len(b) == 0
+				if _, err := w.Write(b); err != nil {
+					return
+				}
+			}
+			if !last {
+				_ = w.CloseWithError(xerrors.New("incomplete export (remote connection lost?)"))
+				return
+			}
+			_ = w.Close()
+		}()
+
+		return re.Emit(r)
+	},
+	PostRun: cmds.PostRunMap{
+		cmds.CLI: func(res cmds.Response, re cmds.ResponseEmitter) error {
+			v, err := res.Next()
+			if err != nil {
+				return err
+			}
+
+			r, ok := v.(io.Reader)
+			if !ok {
+				return xerrors.Errorf("unexpected export response type %T", v)
+			}
+
+			fi, err := os.Create(res.Request().Arguments[0])
+			if err != nil {
+				return err
+			}
+			defer fi.Close() //nolint:errcheck
+
+			if _, err := io.Copy(fi, r); err != nil {
+				return xerrors.Errorf("writing export: %w", err)
+			}
+
+			return nil
+		},
+	},
+}
+
 func apiMsgCids(in []chain.Message) []cid.Cid {
 	out := make([]cid.Cid, len(in))
 	for k, v := range in {
@@ -116,6 +116,8 @@ NETWORK COMMANDS
 MESSAGE COMMANDS
   venus send                   - Send message
   venus mpool                  - Manage the message pool
+  venus msig                   - Interact with a multisig wallet
+  venus paych                  - Manage payment channels
 
 State COMMANDS
   venus wait-msg               - Wait for a message to appear on chain
@@ -133,6 +135,7 @@ State COMMANDS
   venus list-actor             - list all actors
 
 TOOL COMMANDS
+  venus auth                   - Manage JSON-RPC API authorization
   venus inspect                - Show info about the venus node
   venus leb128                 - Leb128 cli encode/decode
   venus log                    - Interact with the daemon event log output
@@ -165,6 +168,7 @@ var rootSubcmdsLocal = map[string]*cmds.Command{
 
 // all top level commands, available on daemon. set during init() to avoid configuration loops.
 var rootSubcmdsDaemon = map[string]*cmds.Command{
+	"auth":     authCmd,
 	"chain":    chainCmd,
 	"sync":     syncCmd,
 	"config":   configCmd,
@@ -175,6 +179,8 @@ var rootSubcmdsDaemon = map[string]*cmds.Command{
 	"log":      logCmd,
 	"send":     msgSendCmd,
 	"mpool":    mpoolCmd,
+	"msig":     multisigCmd,
+	"paych":    paychCmd,
 	"protocol": protocolCmd,
 	"show":     showCmd,
 	"swarm":    swarmCmd,
@@ -1,12 +1,10 @@
 package cmd
 
 import (
-	"bytes"
 	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"reflect"
 	"strconv"
 
 	"github.com/filecoin-project/go-address"
@@ -14,10 +12,9 @@ import (
 	"github.com/ipfs/go-cid"
 	cmds "github.com/ipfs/go-ipfs-cmds"
 	"github.com/pkg/errors"
-	cbg "github.com/whyrusleeping/cbor-gen"
 
 	"github.com/filecoin-project/venus/app/node"
-	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/block"
 	"github.com/filecoin-project/venus/pkg/specactors/builtin"
 	"github.com/filecoin-project/venus/pkg/types"
 	"github.com/filecoin-project/venus/pkg/vm"
@@ -36,7 +33,7 @@ var msgSendCmd = &cmds.Command{
 	},
 	Arguments: []cmds.Argument{
 		cmds.StringArg("target", true, false, "address of the actor to send the message to"),
-		cmds.StringArg("method", false, false, "The method to invoke on the target actor"),
+		cmds.StringArg("method", false, false, "The method to invoke on the target actor, by number or name"),
 	},
 	Options: []cmds.Option{
 		cmds.StringOption("value", "Value to send with message in FIL"),
@@ -56,11 +53,10 @@ var msgSendCmd = &cmds.Command{
 
 		methodID := builtin.MethodSend
 		if len(req.Arguments) > 1 {
-			tm, err := strconv.ParseUint(req.Arguments[0], 10, 64)
+			methodID, err = parseMethod(req.Context, env.(*node.Env), toAddr, req.Arguments[1])
 			if err != nil {
 				return err
 			}
-			methodID = abi.MethodNum(tm)
 		}
 
 		rawVal := req.Options["value"]
@@ -155,27 +151,15 @@ var msgSendCmd = &cmds.Command{
 }
 
 func decodeTypedParams(ctx context.Context, fapi *node.Env, to address.Address, method abi.MethodNum, paramstr string) ([]byte, error) {
-	act, err := fapi.ChainAPI.GetActor(ctx, to)
-	if err != nil {
-		return nil, err
-	}
-
-	methodMeta, found := chain.MethodsMap[act.Code][method]
-	if !found {
-		return nil, fmt.Errorf("method %d not found on actor %s", method, act.Code)
-	}
-
-	p := reflect.New(methodMeta.Params.Elem()).Interface().(cbg.CBORMarshaler)
-
-	if err := json.Unmarshal([]byte(paramstr), p); err != nil {
-		return nil, fmt.Errorf("unmarshaling input into params type: %s", err)
-	}
+	return fapi.ChainAPI.StateEncodeParams(ctx, to, method, json.RawMessage(paramstr), block.EmptyTSK)
+}
 
-	buf := new(bytes.Buffer)
-	if err := p.MarshalCBOR(buf); err != nil {
-		return nil, err
+// parseMethod parses a method number, or resolves a method name against the code of the actor `to`.
+func parseMethod(ctx context.Context, fapi *node.Env, to address.Address, method string) (abi.MethodNum, error) {
+	if num, err := strconv.ParseUint(method, 10, 64); err == nil {
+		return abi.MethodNum(num), nil
 	}
-	return buf.Bytes(), nil
+	return fapi.ChainAPI.StateGetMethodNum(ctx, to, method, block.EmptyTSK)
 }
 
 // WaitResult is the result of a message wait call.
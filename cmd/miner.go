@@ -83,7 +83,7 @@ var newMinerCmd = &cmds.Command{
 		if workerAddr != "" {
 			worker, err = address.NewFromString(workerAddr)
 		} else if createWorkerKey { // TODO: Do we need to force this if owner is Secpk?
-			worker, err = env.(*node.Env).WalletAPI.WalletNewAddress(address.BLS)
+			worker, err = env.(*node.Env).WalletAPI.WalletNewAddress(ctx, address.BLS)
 		}
 		if err != nil {
 			return err
@@ -133,13 +133,13 @@ var newMinerCmd = &cmds.Command{
 			Owner:         owner,
 			Worker:        worker,
 			SealProofType: spt,
-			Peer:          abi.PeerID(env.(*node.Env).NetworkAPI.NetworkGetPeerID()),
+			Peer:          abi.PeerID(env.(*node.Env).NetworkAPI.NetworkGetPeerID(ctx)),
 		})
 		if err != nil {
 			return err
 		}
 
-		minerCmdLog.Info("peer id: ", env.(*node.Env).NetworkAPI.NetworkGetPeerID())
+		minerCmdLog.Info("peer id: ", env.(*node.Env).NetworkAPI.NetworkGetPeerID(ctx))
 
 		sender := owner
 		fromstr, _ := req.Options["from"].(string)
@@ -218,7 +218,7 @@ var minerInfoCmd = &cmds.Command{
 		ctx := req.Context
 		api := env.(*node.Env).ChainAPI
 
-		blockDelay, err := blockDelay(env.(*node.Env).ConfigAPI)
+		blockDelay, err := blockDelay(req.Context, env.(*node.Env).ConfigAPI)
 		if err != nil {
 			return err
 		}
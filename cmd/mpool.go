@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -37,6 +38,81 @@ var mpoolCmd = &cmds.Command{
 		"gas-perf": mpoolGasPerfCmd,
 		"publish":  mpoolPublish,
 		"delete":   mpoolDeleteAddress,
+		"check":    mpoolCheckCmd,
+	},
+}
+
+var mpoolCheckCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Run every check of the message pool on the pending messages of an address",
+		ShortDescription: `Checks the syntax, signature, nonce, gas limit, fee cap and balance of every pending
+message of the address, in nonce order, and prints the failed checks along with hints.
+Checks passing with a warning are printed too.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("address", true, false, "address whose pending messages are checked"),
+	},
+	Options: []cmds.Option{
+		cmds.BoolOption("json", "print the result of every check in JSON"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		checks, err := env.(*node.Env).MessagePoolAPI.MpoolCheckPendingMessages(req.Context, addr)
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+
+		if asJSON, _ := req.Options["json"].(bool); asJSON {
+			out, err := json.MarshalIndent(checks, "", "  ")
+			if err != nil {
+				return err
+			}
+			_ = writer.Write(out)
+			return re.Emit(buf)
+		}
+
+		if len(checks) == 0 {
+			writer.Printf("no pending messages for %s\n", addr)
+			return re.Emit(buf)
+		}
+
+		for _, statuses := range checks {
+			if len(statuses) == 0 {
+				continue
+			}
+			failed := 0
+			writer.Printf("%s:\n", statuses[0].Cid)
+			for _, status := range statuses {
+				if status.OK && status.Err == "" {
+					continue
+				}
+				level := "warning"
+				if !status.OK {
+					level = "failed"
+					failed++
+				}
+				writer.Printf("\t%s %s: %s", level, status.Code, status.Err)
+				if len(status.Hint) > 0 {
+					hint, err := json.Marshal(status.Hint)
+					if err != nil {
+						return err
+					}
+					writer.Printf(" %s", hint)
+				}
+				writer.Println()
+			}
+			if failed == 0 {
+				writer.Println("\tall checks passed")
+			}
+		}
+		return re.Emit(buf)
 	},
 }
 
@@ -85,7 +161,7 @@ var mpoolPublish = &cmds.Command{
 
 		var fromAddr address.Address
 		if from == "" {
-			defaddr, err := env.(*node.Env).WalletAPI.WalletDefaultAddress()
+			defaddr, err := env.(*node.Env).WalletAPI.WalletDefaultAddress(ctx)
 			if err != nil {
 				return err
 			}
@@ -370,7 +446,7 @@ Get pending messages.
 				if err != nil {
 					return xerrors.Errorf("get TipSetKey error: %w", err)
 				}
-				currTs, err = env.(*node.Env).ChainAPI.ChainGetTipSet(key)
+				currTs, err = env.(*node.Env).ChainAPI.ChainGetTipSet(ctx, key)
 				if err != nil {
 					return xerrors.Errorf("walking chain: %w", err)
 				}
@@ -384,7 +460,7 @@ Get pending messages.
 		if local {
 			filter = map[address.Address]struct{}{}
 
-			addrss := env.(*node.Env).WalletAPI.WalletAddresses()
+			addrss := env.(*node.Env).WalletAPI.WalletAddresses(ctx)
 
 			for _, a := range addrss {
 				filter[a] = struct{}{}
@@ -534,7 +610,7 @@ Get pending messages.
 		if local {
 			filter = map[address.Address]struct{}{}
 
-			addrss := env.(*node.Env).WalletAPI.WalletAddresses()
+			addrss := env.(*node.Env).WalletAPI.WalletAddresses(req.Context)
 			for _, a := range addrss {
 				filter[a] = struct{}{}
 			}
@@ -678,7 +754,7 @@ Check gas performance of messages in mempool
 		if !all {
 			filter = map[address.Address]struct{}{}
 
-			addrss := env.(*node.Env).WalletAPI.WalletAddresses()
+			addrss := env.(*node.Env).WalletAPI.WalletAddresses(ctx)
 
 			for _, a := range addrss {
 				filter[a] = struct{}{}
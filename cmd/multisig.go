@@ -0,0 +1,552 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	init2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/init"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/cmd/tablewriter"
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+var multisigCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Interact with a multisig wallet",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"create":        msigCreateCmd,
+		"propose":       msigProposeCmd,
+		"approve":       msigApproveCmd,
+		"cancel":        msigCancelCmd,
+		"add-signer":    msigAddSignerCmd,
+		"swap-signer":   msigSwapSignerCmd,
+		"remove-signer": msigRemoveSignerCmd,
+		"pending":       msigPendingCmd,
+		"balance":       msigBalanceCmd,
+		"vested":        msigVestedCmd,
+	},
+}
+
+var msigFromOption = cmds.StringOption("from", "account to send the message from, defaults to the wallet default address")
+
+var msigCreateCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Create a new multisig wallet",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("signers", true, true, "addresses of the signers of the wallet"),
+	},
+	Options: []cmds.Option{
+		cmds.Uint64Option("required", "number of signers required to approve a transaction, defaults to all of them"),
+		cmds.StringOption("value", "initial balance of the wallet in FIL").WithDefault("0"),
+		cmds.Int64Option("duration", "number of epochs over which the initial balance vests").WithDefault(int64(0)),
+		msigFromOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		api := env.(*node.Env)
+
+		signers, err := addressesFromArgs(req.Arguments)
+		if err != nil {
+			return err
+		}
+
+		required, _ := req.Options["required"].(uint64)
+		duration, _ := req.Options["duration"].(int64)
+
+		val, ok := types.NewAttoFILFromFILString(req.Options["value"].(string))
+		if !ok {
+			return xerrors.New("mal-formed value")
+		}
+
+		from, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		msgCid, err := api.MultiSigAPI.MsigCreate(req.Context, required, signers, abi.ChainEpoch(duration), val, from)
+		if err != nil {
+			return err
+		}
+
+		_ = re.Emit("sent create in message: " + msgCid.String())
+
+		wait, err := api.ChainAPI.StateWaitMsg(req.Context, msgCid, constants.MessageConfidence)
+		if err != nil {
+			return err
+		}
+		if wait.Receipt.ExitCode != 0 {
+			return xerrors.Errorf("create multisig failed: exit code %d", wait.Receipt.ExitCode)
+		}
+
+		var execreturn init2.ExecReturn
+		if err := execreturn.UnmarshalCBOR(bytes.NewReader(wait.Receipt.ReturnValue)); err != nil {
+			return err
+		}
+
+		return re.Emit(fmt.Sprintf("Created new multisig: %s %s", execreturn.IDAddress, execreturn.RobustAddress))
+	},
+	Type: "",
+}
+
+var msigProposeCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Propose a multisig transaction",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("multisig", true, false, "address of the multisig wallet"),
+		cmds.StringArg("destination", true, false, "recipient of the transaction"),
+		cmds.StringArg("value", true, false, "value to send in FIL"),
+		cmds.StringArg("method", false, false, "method to invoke on the recipient"),
+		cmds.StringArg("params", false, false, "hex encoded params of the method"),
+	},
+	Options: []cmds.Option{
+		msigFromOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		msig, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		dest, err := address.NewFromString(req.Arguments[1])
+		if err != nil {
+			return err
+		}
+
+		val, ok := types.NewAttoFILFromFILString(req.Arguments[2])
+		if !ok {
+			return xerrors.New("mal-formed value")
+		}
+
+		var method uint64
+		var params []byte
+		if len(req.Arguments) > 3 {
+			method, err = strconv.ParseUint(req.Arguments[3], 10, 64)
+			if err != nil {
+				return xerrors.Errorf("invalid method: %v", err)
+			}
+		}
+		if len(req.Arguments) > 4 {
+			params, err = hex.DecodeString(req.Arguments[4])
+			if err != nil {
+				return xerrors.Errorf("invalid params: %v", err)
+			}
+		}
+
+		from, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		msgCid, err := env.(*node.Env).MultiSigAPI.MsigPropose(req.Context, msig, dest, val, from, method, params)
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, msgCid.String())
+	},
+}
+
+var msigApproveCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Approve a pending multisig transaction",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("multisig", true, false, "address of the multisig wallet"),
+		cmds.StringArg("txID", true, false, "id of the pending transaction"),
+		cmds.StringArg("proposer", false, false, "proposer of the transaction, read with the next arguments from the pending transaction if omitted"),
+		cmds.StringArg("destination", false, false, "recipient of the transaction"),
+		cmds.StringArg("value", false, false, "value of the transaction in FIL"),
+		cmds.StringArg("method", false, false, "method invoked on the recipient"),
+		cmds.StringArg("params", false, false, "hex encoded params of the method"),
+	},
+	Options: []cmds.Option{
+		msigFromOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		msig, txID, err := parseMsigTxn(req)
+		if err != nil {
+			return err
+		}
+
+		p, err := parseMsigProposal(req, env, msig, txID, req.Arguments[2:], true)
+		if err != nil {
+			return err
+		}
+
+		from, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		msgCid, err := env.(*node.Env).MultiSigAPI.MsigApprove(req.Context, msig, txID, p.proposer, p.to, p.value, from, p.method, p.params)
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, msgCid.String())
+	},
+}
+
+var msigCancelCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Cancel a pending multisig transaction, only its proposer can cancel it",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("multisig", true, false, "address of the multisig wallet"),
+		cmds.StringArg("txID", true, false, "id of the pending transaction"),
+		cmds.StringArg("destination", false, false, "recipient of the transaction, read with the next arguments from the pending transaction if omitted"),
+		cmds.StringArg("value", false, false, "value of the transaction in FIL"),
+		cmds.StringArg("method", false, false, "method invoked on the recipient"),
+		cmds.StringArg("params", false, false, "hex encoded params of the method"),
+	},
+	Options: []cmds.Option{
+		msigFromOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		msig, txID, err := parseMsigTxn(req)
+		if err != nil {
+			return err
+		}
+
+		p, err := parseMsigProposal(req, env, msig, txID, req.Arguments[2:], false)
+		if err != nil {
+			return err
+		}
+
+		from, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		msgCid, err := env.(*node.Env).MultiSigAPI.MsigCancel(req.Context, msig, txID, p.to, p.value, from, p.method, p.params)
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, msgCid.String())
+	},
+}
+
+var msigAddSignerCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Propose to add a signer to a multisig wallet",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("multisig", true, false, "address of the multisig wallet"),
+		cmds.StringArg("signer", true, false, "address of the new signer"),
+	},
+	Options: []cmds.Option{
+		cmds.BoolOption("increase-threshold", "increase the number of required approvals by one"),
+		msigFromOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addrs, err := addressesFromArgs(req.Arguments)
+		if err != nil {
+			return err
+		}
+
+		from, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		increase, _ := req.Options["increase-threshold"].(bool)
+		msgCid, err := env.(*node.Env).MultiSigAPI.MsigAddSigner(req.Context, addrs[0], from, addrs[1], increase)
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, msgCid.String())
+	},
+}
+
+var msigSwapSignerCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Propose to replace a signer of a multisig wallet",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("multisig", true, false, "address of the multisig wallet"),
+		cmds.StringArg("old-signer", true, false, "address of the signer to replace"),
+		cmds.StringArg("new-signer", true, false, "address of the new signer"),
+	},
+	Options: []cmds.Option{
+		msigFromOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addrs, err := addressesFromArgs(req.Arguments)
+		if err != nil {
+			return err
+		}
+
+		from, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		msgCid, err := env.(*node.Env).MultiSigAPI.MsigSwapSigner(req.Context, addrs[0], from, addrs[1], addrs[2])
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, msgCid.String())
+	},
+}
+
+var msigRemoveSignerCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Propose to remove a signer from a multisig wallet",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("multisig", true, false, "address of the multisig wallet"),
+		cmds.StringArg("signer", true, false, "address of the signer to remove"),
+	},
+	Options: []cmds.Option{
+		cmds.BoolOption("decrease-threshold", "decrease the number of required approvals by one"),
+		msigFromOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addrs, err := addressesFromArgs(req.Arguments)
+		if err != nil {
+			return err
+		}
+
+		from, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		decrease, _ := req.Options["decrease-threshold"].(bool)
+		msgCid, err := env.(*node.Env).MultiSigAPI.MsigRemoveSigner(req.Context, addrs[0], from, addrs[1], decrease)
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, msgCid.String())
+	},
+}
+
+var msigPendingCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the transactions of a multisig wallet waiting for approvals",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("multisig", true, false, "address of the multisig wallet"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		msig, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		pending, err := env.(*node.Env).MultiSigAPI.MsigGetPending(req.Context, msig, block.EmptyTSK)
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		tw := tablewriter.New(
+			tablewriter.Col("ID"),
+			tablewriter.Col("Approvals"),
+			tablewriter.Col("To"),
+			tablewriter.Col("Value"),
+			tablewriter.Col("Method"),
+			tablewriter.Col("Params"))
+		for _, txn := range pending {
+			tw.Write(map[string]interface{}{
+				"ID":        txn.ID,
+				"Approvals": len(txn.Approved),
+				"To":        txn.To,
+				"Value":     types.FIL(txn.Value),
+				"Method":    txn.Method,
+				"Params":    hex.EncodeToString(txn.Params),
+			})
+		}
+		if err := tw.Flush(buf); err != nil {
+			return err
+		}
+
+		return re.Emit(buf)
+	},
+}
+
+var msigBalanceCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Get the balance of a multisig wallet which has vested and can be spent",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("multisig", true, false, "address of the multisig wallet"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		msig, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		bal, err := env.(*node.Env).MultiSigAPI.MsigGetAvailableBalance(req.Context, msig, block.EmptyTSK)
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, types.FIL(bal).String())
+	},
+}
+
+var msigVestedCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Get the amount vested by a multisig wallet between two epochs",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("multisig", true, false, "address of the multisig wallet"),
+	},
+	Options: []cmds.Option{
+		cmds.Int64Option("start-epoch", "start epoch of the vesting period").WithDefault(int64(0)),
+		cmds.Int64Option("end-epoch", "end epoch of the vesting period, defaults to the head").WithDefault(int64(-1)),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		api := env.(*node.Env)
+
+		msig, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		head, err := api.ChainAPI.ChainHead(req.Context)
+		if err != nil {
+			return err
+		}
+
+		start, _ := req.Options["start-epoch"].(int64)
+		startTS, err := api.ChainAPI.ChainGetTipSetByHeight(req.Context, abi.ChainEpoch(start), head.Key())
+		if err != nil {
+			return err
+		}
+
+		endTS := head
+		if end, _ := req.Options["end-epoch"].(int64); end >= 0 {
+			endTS, err = api.ChainAPI.ChainGetTipSetByHeight(req.Context, abi.ChainEpoch(end), head.Key())
+			if err != nil {
+				return err
+			}
+		}
+
+		vested, err := api.MultiSigAPI.MsigGetVested(req.Context, msig, startTS.Key(), endTS.Key())
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, types.FIL(vested).String())
+	},
+}
+
+func parseMsigTxn(req *cmds.Request) (address.Address, uint64, error) {
+	msig, err := address.NewFromString(req.Arguments[0])
+	if err != nil {
+		return address.Undef, 0, err
+	}
+
+	txID, err := strconv.ParseUint(req.Arguments[1], 10, 64)
+	if err != nil {
+		return address.Undef, 0, xerrors.Errorf("invalid transaction id: %v", err)
+	}
+	return msig, txID, nil
+}
+
+// msigProposal is the proposal of a pending multisig transaction, checked by the multisig
+// when the transaction is approved or cancelled.
+type msigProposal struct {
+	proposer address.Address
+	to       address.Address
+	value    abi.TokenAmount
+	method   uint64
+	params   []byte
+}
+
+// parseMsigProposal reads the proposal of the transaction `txID` from `args`, starting with
+// the proposer if `withProposer` is true, or from the pending transactions of the multisig
+// if `args` is empty.
+func parseMsigProposal(req *cmds.Request, env cmds.Environment, msig address.Address, txID uint64, args []string, withProposer bool) (*msigProposal, error) {
+	if len(args) == 0 {
+		pending, err := env.(*node.Env).MultiSigAPI.MsigGetPending(req.Context, msig, block.EmptyTSK)
+		if err != nil {
+			return nil, err
+		}
+		for _, txn := range pending {
+			if txn.ID != int64(txID) {
+				continue
+			}
+			if len(txn.Approved) == 0 {
+				return nil, xerrors.Errorf("pending transaction %d has no proposer", txID)
+			}
+			// the proposer is the first approver
+			return &msigProposal{
+				proposer: txn.Approved[0],
+				to:       txn.To,
+				value:    txn.Value,
+				method:   uint64(txn.Method),
+				params:   txn.Params,
+			}, nil
+		}
+		return nil, xerrors.Errorf("multisig %s has no pending transaction %d", msig, txID)
+	}
+
+	var p msigProposal
+	var err error
+	if withProposer {
+		p.proposer, err = address.NewFromString(args[0])
+		if err != nil {
+			return nil, xerrors.Errorf("invalid proposer: %v", err)
+		}
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		return nil, xerrors.New("the destination and the value of the transaction are required")
+	}
+
+	p.to, err = address.NewFromString(args[0])
+	if err != nil {
+		return nil, xerrors.Errorf("invalid destination: %v", err)
+	}
+
+	var ok bool
+	p.value, ok = types.NewAttoFILFromFILString(args[1])
+	if !ok {
+		return nil, xerrors.New("mal-formed value")
+	}
+
+	if len(args) > 2 {
+		p.method, err = strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid method: %v", err)
+		}
+	}
+	if len(args) > 3 {
+		p.params, err = hex.DecodeString(args[3])
+		if err != nil {
+			return nil, xerrors.Errorf("invalid params: %v", err)
+		}
+	}
+	return &p, nil
+}
+
+func addressesFromArgs(args []string) ([]address.Address, error) {
+	addrs := make([]address.Address, len(args))
+	for i, arg := range args {
+		addr, err := address.NewFromString(arg)
+		if err != nil {
+			return nil, err
+		}
+		addrs[i] = addr
+	}
+	return addrs, nil
+}
@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/app/node"
+	paychApiTypes "github.com/filecoin-project/venus/app/submodule/paych"
+	"github.com/filecoin-project/venus/pkg/paychmgr"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/paych"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+var paychCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Manage payment channels",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"add-funds":         paychAddFundsCmd,
+		"list":              paychListCmd,
+		"status":            paychStatusCmd,
+		"status-by-from-to": paychStatusByFromToCmd,
+		"voucher":           paychVoucherCmd,
+		"settle":            paychSettleCmd,
+		"collect":           paychCollectCmd,
+	},
+}
+
+var paychAddFundsCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Add funds to the payment channel between from and to, creating it if needed",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("from", true, false, "payer of the channel"),
+		cmds.StringArg("to", true, false, "recipient of the channel"),
+		cmds.StringArg("amount", true, false, "amount to add in FIL"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		api := env.(*node.Env).PaychAPI
+
+		addrs, err := addressesFromArgs(req.Arguments[:2])
+		if err != nil {
+			return err
+		}
+
+		amt, ok := types.NewAttoFILFromFILString(req.Arguments[2])
+		if !ok {
+			return xerrors.New("mal-formed amount")
+		}
+
+		info, err := api.PaychGet(req.Context, addrs[0], addrs[1], amt)
+		if err != nil {
+			return err
+		}
+
+		ch := info.Channel
+		if info.WaitSentinel.Defined() {
+			ch, err = api.PaychGetWaitReady(req.Context, info.WaitSentinel)
+			if err != nil {
+				return err
+			}
+		}
+
+		return printOneString(re, ch.String())
+	},
+}
+
+var paychListCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the tracked payment channels",
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		chs, err := env.(*node.Env).PaychAPI.PaychList(req.Context)
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		for _, ch := range chs {
+			writer.Println(ch.String())
+		}
+		return re.Emit(buf)
+	},
+}
+
+var paychStatusCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the status of a payment channel",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("channel", true, false, "address of the channel"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		api := env.(*node.Env).PaychAPI
+
+		ch, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		status, err := api.PaychStatus(req.Context, ch)
+		if err != nil {
+			return err
+		}
+
+		funds, err := api.PaychAvailableFunds(req.Context, ch)
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		dir := "Inbound"
+		if status.Direction == paychApiTypes.PCHOutbound {
+			dir = "Outbound"
+		}
+		writer.Printf("Channel:      %s\n", ch)
+		writer.Printf("Direction:    %s\n", dir)
+		writePaychFunds(writer, funds)
+		return re.Emit(buf)
+	},
+}
+
+var paychStatusByFromToCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the status of the outbound payment channel between from and to",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("from", true, false, "payer of the channel"),
+		cmds.StringArg("to", true, false, "recipient of the channel"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addrs, err := addressesFromArgs(req.Arguments)
+		if err != nil {
+			return err
+		}
+
+		funds, err := env.(*node.Env).PaychAPI.PaychAvailableFundsByFromTo(req.Context, addrs[0], addrs[1])
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		if funds.Channel == nil {
+			writer.Printf("Channel:      none\n")
+		} else {
+			writer.Printf("Channel:      %s\n", funds.Channel)
+		}
+		writePaychFunds(writer, funds)
+		return re.Emit(buf)
+	},
+}
+
+func writePaychFunds(writer *SilentWriter, funds *paychmgr.ChannelAvailableFunds) {
+	writer.Printf("From:         %s\n", funds.From)
+	writer.Printf("To:           %s\n", funds.To)
+	writer.Printf("Confirmed:    %s\n", types.FIL(funds.ConfirmedAmt))
+	writer.Printf("Pending:      %s\n", types.FIL(funds.PendingAmt))
+	if funds.PendingWaitSentinel != nil {
+		writer.Printf("Pending in:   %s\n", funds.PendingWaitSentinel)
+	}
+	writer.Printf("Redeemed:     %s\n", types.FIL(funds.VoucherRedeemedAmt))
+}
+
+var paychSettleCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Settle a payment channel, it can be collected once the settling period is over",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("channel", true, false, "address of the channel"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ch, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		mcid, err := env.(*node.Env).PaychAPI.PaychSettle(req.Context, ch)
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, mcid.String())
+	},
+}
+
+var paychCollectCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Collect the funds of a settled payment channel",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("channel", true, false, "address of the channel"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ch, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		mcid, err := env.(*node.Env).PaychAPI.PaychCollect(req.Context, ch)
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, mcid.String())
+	},
+}
+
+var paychVoucherCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Manage the vouchers of payment channels",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"create": paychVoucherCreateCmd,
+		"check":  paychVoucherCheckCmd,
+		"add":    paychVoucherAddCmd,
+		"list":   paychVoucherListCmd,
+		"submit": paychVoucherSubmitCmd,
+	},
+}
+
+var paychVoucherCreateCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Create a signed voucher of an outbound payment channel",
+		ShortDescription: `
+The amount is the total amount paid on the lane, not the increment over the
+previous voucher of the lane.
+`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("channel", true, false, "address of the channel"),
+		cmds.StringArg("amount", true, false, "amount of the voucher in FIL"),
+	},
+	Options: []cmds.Option{
+		cmds.Uint64Option("lane", "lane of the voucher").WithDefault(uint64(0)),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ch, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		amt, ok := types.NewAttoFILFromFILString(req.Arguments[1])
+		if !ok {
+			return xerrors.New("mal-formed amount")
+		}
+
+		lane, _ := req.Options["lane"].(uint64)
+		res, err := env.(*node.Env).PaychAPI.PaychVoucherCreate(req.Context, ch, amt, lane)
+		if err != nil {
+			return err
+		}
+		if res.Voucher == nil {
+			return xerrors.Errorf("could not create voucher: shortfall of %s", types.FIL(res.Shortfall))
+		}
+
+		enc, err := encodeVoucher(res.Voucher)
+		if err != nil {
+			return err
+		}
+		return printOneString(re, enc)
+	},
+}
+
+var paychVoucherCheckCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Check that a voucher can be redeemed from a payment channel",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("channel", true, false, "address of the channel"),
+		cmds.StringArg("voucher", true, false, "encoded voucher"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ch, sv, err := parseChannelVoucher(req)
+		if err != nil {
+			return err
+		}
+
+		if err := env.(*node.Env).PaychAPI.PaychVoucherCheckValid(req.Context, ch, sv); err != nil {
+			return err
+		}
+		return printOneString(re, "voucher is valid")
+	},
+}
+
+var paychVoucherAddCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Add a voucher received on an inbound payment channel",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("channel", true, false, "address of the channel"),
+		cmds.StringArg("voucher", true, false, "encoded voucher"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ch, sv, err := parseChannelVoucher(req)
+		if err != nil {
+			return err
+		}
+
+		delta, err := env.(*node.Env).PaychAPI.PaychVoucherAdd(req.Context, ch, sv, nil, big.Zero())
+		if err != nil {
+			return err
+		}
+		return printOneString(re, fmt.Sprintf("voucher added, it pays %s more on lane %d", types.FIL(delta), sv.Lane))
+	},
+}
+
+var paychVoucherListCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the vouchers of a payment channel",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("channel", true, false, "address of the channel"),
+	},
+	Options: []cmds.Option{
+		cmds.BoolOption("export", "print the encoded vouchers"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ch, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		vouchers, err := env.(*node.Env).PaychAPI.PaychVoucherList(req.Context, ch)
+		if err != nil {
+			return err
+		}
+
+		export, _ := req.Options["export"].(bool)
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		for _, sv := range vouchers {
+			line := "Lane " + strconv.FormatUint(sv.Lane, 10) + ", Nonce " + strconv.FormatUint(sv.Nonce, 10) + ": " + types.FIL(sv.Amount).String()
+			if export {
+				enc, err := encodeVoucher(sv)
+				if err != nil {
+					return err
+				}
+				line += "; " + enc
+			}
+			writer.Println(line)
+		}
+		return re.Emit(buf)
+	},
+}
+
+var paychVoucherSubmitCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Submit a voucher to the chain to redeem it",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("channel", true, false, "address of the channel"),
+		cmds.StringArg("voucher", true, false, "encoded voucher"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ch, sv, err := parseChannelVoucher(req)
+		if err != nil {
+			return err
+		}
+
+		mcid, err := env.(*node.Env).PaychAPI.PaychVoucherSubmit(req.Context, ch, sv, nil)
+		if err != nil {
+			return err
+		}
+		return printOneString(re, mcid.String())
+	},
+}
+
+func parseChannelVoucher(req *cmds.Request) (address.Address, *paych.SignedVoucher, error) {
+	ch, err := address.NewFromString(req.Arguments[0])
+	if err != nil {
+		return address.Undef, nil, err
+	}
+
+	sv, err := paych.DecodeSignedVoucher(req.Arguments[1])
+	if err != nil {
+		return address.Undef, nil, xerrors.Errorf("failed to decode voucher: %v", err)
+	}
+	return ch, sv, nil
+}
+
+// encodeVoucher encodes a voucher the way paych.DecodeSignedVoucher decodes it.
+func encodeVoucher(sv *paych.SignedVoucher) (string, error) {
+	buf := new(bytes.Buffer)
+	if err := sv.MarshalCBOR(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -78,6 +78,13 @@ all other block properties will be included as well.`,
 	Type: block.Block{},
 }
 
+// MessageInfo is a message along with its params decoded according to the method it calls,
+// when the receiver is a builtin actor.
+type MessageInfo struct {
+	*types.UnsignedMessage
+	DecodedParams interface{} `json:",omitempty"`
+}
+
 var showMessageCmd = &cmds.Command{
 	Helptext: cmds.HelpText{
 		Tagline: "Show a filecoin message by its CID",
@@ -96,9 +103,14 @@ var showMessageCmd = &cmds.Command{
 			return err
 		}
 
-		return re.Emit(msg)
+		info := &MessageInfo{UnsignedMessage: msg}
+		if len(msg.Params) > 0 {
+			// best effort, the receiver may not be a builtin actor
+			info.DecodedParams, _ = env.(*node.Env).ChainAPI.StateDecodeParams(req.Context, msg.To, msg.Method, msg.Params, block.EmptyTSK)
+		}
+		return re.Emit(info)
 	},
-	Type: types.UnsignedMessage{},
+	Type: MessageInfo{},
 }
 
 var showMessagesCmd = &cmds.Command{
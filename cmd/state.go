@@ -7,6 +7,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/constants"
 	"io"
 	"strconv"
+	"strings"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
@@ -19,6 +20,7 @@ import (
 	"github.com/filecoin-project/venus/app/node"
 	"github.com/filecoin-project/venus/app/submodule/chain"
 	"github.com/filecoin-project/venus/app/submodule/config"
+	"github.com/filecoin-project/venus/pkg/block"
 	"github.com/filecoin-project/venus/pkg/crypto"
 	"github.com/filecoin-project/venus/pkg/specactors/builtin"
 	"github.com/filecoin-project/venus/pkg/types"
@@ -51,6 +53,11 @@ var stateCmd = &cmds.Command{
 		"miner-info":      stateMinerInfo,
 		"network-version": stateNtwkVersionCmd,
 		"list-actor":      stateListActorCmd,
+		"replay":          stateReplayCmd,
+		"compute-state":   stateComputeStateCmd,
+		"list-messages":   stateListMessagesCmd,
+		"diff":            stateDiffCmd,
+		"read-state":      stateReadStateCmd,
 	},
 }
 
@@ -61,6 +68,9 @@ var stateWaitMsgCmd = &cmds.Command{
 	Arguments: []cmds.Argument{
 		cmds.StringArg("cid", true, false, "CID of message to show"),
 	},
+	Options: []cmds.Option{
+		cmds.BoolOption("show-trace", "replay the message and print its execution trace"),
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		cid, err := cid.Decode(req.Arguments[0])
 		if err != nil {
@@ -79,6 +89,19 @@ var stateWaitMsgCmd = &cmds.Command{
 		writer.Printf("Exit Code: %d\n", mw.Receipt.ExitCode)
 		writer.Printf("Gas Used: %d\n", mw.Receipt.GasUsed)
 		writer.Printf("Return: %x\n", mw.Receipt.ReturnValue)
+		if showTrace, _ := req.Options["show-trace"].(bool); showTrace {
+			// the receipts on chain don't record the trace, replay the message to get it
+			res, err := env.(*node.Env).SyncerAPI.StateReplay(req.Context, mw.TipSet, mw.Message)
+			if err != nil {
+				return err
+			}
+			trace, err := json.MarshalIndent(res.ExecutionTrace, "", "  ")
+			if err != nil {
+				return err
+			}
+			writer.Println("Execution trace:")
+			writer.Println(string(trace))
+		}
 
 		return re.Emit(buf)
 	},
@@ -254,7 +277,7 @@ var stateSectorCmd = &cmds.Command{
 			return err
 		}
 
-		blockDelay, err := blockDelay(env.(*node.Env).ConfigAPI)
+		blockDelay, err := blockDelay(req.Context, env.(*node.Env).ConfigAPI)
 		if err != nil {
 			return err
 		}
@@ -298,8 +321,8 @@ var stateSectorCmd = &cmds.Command{
 	},
 }
 
-func blockDelay(a *config.ConfigAPI) (uint64, error) {
-	data, err := a.ConfigGet("parameters.blockDelay")
+func blockDelay(ctx context.Context, a *config.ConfigAPI) (uint64, error) {
+	data, err := a.ConfigGet(ctx, "parameters.blockDelay")
 	if err != nil {
 		return 0, err
 	}
@@ -458,7 +481,7 @@ var stateMinerInfo = &cmds.Command{
 			return err
 		}
 
-		blockDelay, err := blockDelay(env.(*node.Env).ConfigAPI)
+		blockDelay, err := blockDelay(req.Context, env.(*node.Env).ConfigAPI)
 		if err != nil {
 			return err
 		}
@@ -597,3 +620,326 @@ func makeActorView(act *types.Actor, addr address.Address) *ActorView {
 		Head:    act.Head,
 	}
 }
+
+var stateReplayCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Replay a message in the context of the tipset it was included in",
+		ShortDescription: `
+Re-executes the message and prints its receipt, gas cost and execution trace. If the
+--tipset option is set, the message is assumed to have been executed in that tipset,
+otherwise the chain is searched for the message.
+`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("cid", true, false, "CID of the message to replay"),
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("tipset", "Comma separated block cids of the tipset the message was executed in"),
+		cmds.BoolOption("show-trace", "print the execution trace of the message"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		mcid, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return xerrors.Errorf("invalid message cid: %w", err)
+		}
+		tsk, err := tipSetKeyFromOption(req)
+		if err != nil {
+			return err
+		}
+
+		res, err := env.(*node.Env).SyncerAPI.StateReplay(req.Context, tsk, mcid)
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		chainAPI := env.(*node.Env).ChainAPI
+		if len(res.Msg.Params) > 0 {
+			if params, err := chainAPI.StateDecodeParams(req.Context, res.Msg.To, res.Msg.Method, res.Msg.Params, tsk); err == nil {
+				out, err := json.MarshalIndent(params, "", "  ")
+				if err != nil {
+					return err
+				}
+				writer.Printf("Params: %s\n", out)
+			}
+		}
+		writer.Println("Replay receipt:")
+		writer.Printf("Exit code: %d\n", res.MsgRct.ExitCode)
+		writer.Printf("Return: %x\n", res.MsgRct.ReturnValue)
+		if res.MsgRct.ExitCode.IsSuccess() && len(res.MsgRct.ReturnValue) > 0 {
+			if ret, err := chainAPI.StateDecodeReturn(req.Context, res.Msg.To, res.Msg.Method, res.MsgRct.ReturnValue, tsk); err == nil {
+				out, err := json.MarshalIndent(ret, "", "  ")
+				if err != nil {
+					return err
+				}
+				writer.Printf("Decoded return: %s\n", out)
+			}
+		}
+		writer.Printf("Gas Used: %d\n", res.MsgRct.GasUsed)
+		writer.Printf("Total Cost: %s\n", types.FIL(res.GasCost.TotalCost))
+		if res.Error != "" {
+			writer.Printf("Error message: %s\n", res.Error)
+		}
+		if showTrace, _ := req.Options["show-trace"].(bool); showTrace {
+			trace, err := json.MarshalIndent(res.ExecutionTrace, "", "  ")
+			if err != nil {
+				return err
+			}
+			writer.Println("Execution trace:")
+			writer.Println(string(trace))
+		}
+
+		return re.Emit(buf)
+	},
+}
+
+var stateComputeStateCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Compute the state of a tipset, optionally applying the messages of the message pool",
+	},
+	Options: []cmds.Option{
+		cmds.Int64Option("height", "height to run the vm at, defaults to the height of the tipset"),
+		cmds.StringOption("tipset", "Comma separated block cids of the tipset to compute the state on, defaults to head"),
+		cmds.BoolOption("apply-mpool-messages", "apply the messages selected from the message pool"),
+		cmds.BoolOption("show-trace", "print the execution trace of the applied messages"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		tsk, err := tipSetKeyFromOption(req)
+		if err != nil {
+			return err
+		}
+
+		var ts *block.TipSet
+		if tsk.IsEmpty() {
+			ts, err = env.(*node.Env).ChainAPI.ChainHead(req.Context)
+		} else {
+			ts, err = env.(*node.Env).ChainAPI.ChainGetTipSet(req.Context, tsk)
+		}
+		if err != nil {
+			return err
+		}
+
+		height := ts.EnsureHeight()
+		if h, ok := req.Options["height"].(int64); ok && h != 0 {
+			height = abi.ChainEpoch(h)
+		}
+
+		var msgs []*types.UnsignedMessage
+		if apply, _ := req.Options["apply-mpool-messages"].(bool); apply {
+			pmsgs, err := env.(*node.Env).MessagePoolAPI.MpoolSelect(req.Context, ts.Key(), 1)
+			if err != nil {
+				return err
+			}
+			for _, sm := range pmsgs {
+				msgs = append(msgs, &sm.Message)
+			}
+		}
+
+		out, err := env.(*node.Env).SyncerAPI.StateCompute(req.Context, height, msgs, ts.Key())
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		writer.Printf("computed state cid: %s\n", out.Root)
+		if showTrace, _ := req.Options["show-trace"].(bool); showTrace {
+			for _, ir := range out.Trace {
+				writer.Printf("%s\t%s\t%s\t%d\t%x\t%d\t%s\n", ir.MsgCid, ir.Msg.From, ir.Msg.To, ir.Msg.Method,
+					ir.Msg.Params, ir.MsgRct.ExitCode, ir.Error)
+				printInternalExecutions(writer, "\t", ir.ExecutionTrace.Subcalls)
+			}
+		}
+
+		return re.Emit(buf)
+	},
+}
+
+func printInternalExecutions(writer *SilentWriter, prefix string, traces []types.ExecutionTrace) {
+	for _, trace := range traces {
+		writer.Printf("%s%s\t%s\t%d\t%x\t%d\t%s\n", prefix, trace.Msg.From, trace.Msg.To, trace.Msg.Method,
+			trace.Msg.Params, trace.MsgRct.ExitCode, trace.Error)
+		printInternalExecutions(writer, prefix+"\t", trace.Subcalls)
+	}
+}
+
+var stateListMessagesCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the messages sent from or to an address",
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("from", "only list messages sent from this address"),
+		cmds.StringOption("to", "only list messages sent to this address"),
+		cmds.Int64Option("toheight", "do not list messages included below this height").WithDefault(int64(0)),
+		cmds.StringOption("tipset", "Comma separated block cids of the tipset to list messages from, defaults to head"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		match := &chain.MessageMatch{}
+		var err error
+		if from, ok := req.Options["from"].(string); ok && from != "" {
+			if match.From, err = address.NewFromString(from); err != nil {
+				return err
+			}
+		}
+		if to, ok := req.Options["to"].(string); ok && to != "" {
+			if match.To, err = address.NewFromString(to); err != nil {
+				return err
+			}
+		}
+		if match.From == address.Undef && match.To == address.Undef {
+			return xerrors.New("at least one of --from or --to must be set")
+		}
+
+		tsk, err := tipSetKeyFromOption(req)
+		if err != nil {
+			return err
+		}
+		if tsk.IsEmpty() {
+			head, err := env.(*node.Env).ChainAPI.ChainHead(req.Context)
+			if err != nil {
+				return err
+			}
+			tsk = head.Key()
+		}
+
+		toHeight, _ := req.Options["toheight"].(int64)
+		msgs, err := env.(*node.Env).ChainAPI.StateListMessages(req.Context, match, tsk, abi.ChainEpoch(toHeight))
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		for _, c := range msgs {
+			writer.Println(c.String())
+		}
+		return re.Emit(buf)
+	},
+}
+
+var stateDiffCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the actors changed between the states of two tipsets",
+		ShortDescription: `Tipsets are given as comma separated lists of block CIDs, the state of
+a tipset is its parent state. With --actor, print the typed diff of the state of that
+builtin actor (miner sectors, market deal states, power claims, init address map or
+multisig pending transactions) instead.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("tsA", true, false, "Tipset to diff from"),
+		cmds.StringArg("tsB", true, false, "Tipset to diff to"),
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("actor", "only diff the state of this actor"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		var roots []cid.Cid
+		for _, arg := range req.Arguments[:2] {
+			tsk, err := tipSetKeyFromString(arg)
+			if err != nil {
+				return err
+			}
+			ts, err := env.(*node.Env).ChainAPI.ChainGetTipSet(req.Context, tsk)
+			if err != nil {
+				return err
+			}
+			roots = append(roots, ts.At(0).ParentStateRoot)
+		}
+
+		var diff interface{}
+		if actor, ok := req.Options["actor"].(string); ok && actor != "" {
+			addr, err := address.NewFromString(actor)
+			if err != nil {
+				return err
+			}
+			diff, err = env.(*node.Env).ChainAPI.StateDiffActorState(req.Context, addr, roots[0], roots[1])
+			if err != nil {
+				return err
+			}
+		} else {
+			var err error
+			diff, err = env.(*node.Env).ChainAPI.StateChangedActors(req.Context, roots[0], roots[1])
+			if err != nil {
+				return err
+			}
+		}
+
+		out, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		writer.Println(string(out))
+		return re.Emit(buf)
+	},
+}
+
+var stateReadStateCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "View a json representation of an actor's state",
+		ShortDescription: `With --expand, the HAMTs, AMTs and other objects linked from the state are
+printed in place of their CIDs, with their values decoded as generic CBOR.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("address", true, false, "Address of the actor"),
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("tipset", "Comma separated block cids of the tipset to read the state at, defaults to head"),
+		cmds.BoolOption("expand", "load the collections and objects linked from the state"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		tsk, err := tipSetKeyFromOption(req)
+		if err != nil {
+			return err
+		}
+
+		var as *chain.ActorState
+		if expand, _ := req.Options["expand"].(bool); expand {
+			as, err = env.(*node.Env).ChainAPI.StateReadStateExpanded(req.Context, addr, tsk)
+		} else {
+			as, err = env.(*node.Env).ChainAPI.StateReadState(req.Context, addr, tsk)
+		}
+		if err != nil {
+			return err
+		}
+
+		out, err := json.MarshalIndent(as.State, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		writer.Println("Balance:", types.FIL(as.Balance))
+		writer.Println("Code:", as.Code, "("+builtin.ActorNameByCode(as.Code)+")")
+		writer.Println("State:", string(out))
+		return re.Emit(buf)
+	},
+}
+
+// tipSetKeyFromOption returns the key of the tipset of the "tipset" option, or an empty key
+// if it is not set.
+func tipSetKeyFromOption(req *cmds.Request) (block.TipSetKey, error) {
+	tss, ok := req.Options["tipset"].(string)
+	if !ok || tss == "" {
+		return block.EmptyTSK, nil
+	}
+
+	return tipSetKeyFromString(tss)
+}
+
+// tipSetKeyFromString parses a comma separated list of block cids.
+func tipSetKeyFromString(s string) (block.TipSetKey, error) {
+	tsCids, err := cidsFromSlice(strings.Split(s, ","))
+	if err != nil {
+		return block.EmptyTSK, err
+	}
+	return block.NewTipSetKey(tsCids...), nil
+}
@@ -3,11 +3,17 @@ package cmd
 
 import (
 	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
 	syncTypes "github.com/filecoin-project/venus/pkg/chainsync/types"
 	cmds "github.com/ipfs/go-ipfs-cmds"
 	"strconv"
 
 	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/app/submodule/syncer"
 )
 
 var syncCmd = &cmds.Command{
@@ -18,6 +24,10 @@ var syncCmd = &cmds.Command{
 		"status":         storeStatusCmd,
 		"history":        historyCmd,
 		"set-concurrent": setConcurrent,
+		"mark-bad":       syncMarkBadCmd,
+		"unmark-bad":     syncUnmarkBadCmd,
+		"check-bad":      syncCheckBadCmd,
+		"wait":           syncWaitCmd,
 	},
 }
 var setConcurrent = &cmds.Command{
@@ -35,17 +45,83 @@ var setConcurrent = &cmds.Command{
 		if err != nil {
 			return cmds.ClientError("invalid number")
 		}
-		env.(*node.Env).SyncerAPI.SetConcurrent(int64(concurrent))
+		if err := env.(*node.Env).SyncerAPI.SetConcurrent(req.Context, int64(concurrent)); err != nil {
+			return err
+		}
 		return nil
 	},
 }
 
+// syncWaitInterval is the interval at which `sync wait` checks the sync state.
+const syncWaitInterval = 3 * time.Second
+
+var syncWaitCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Wait for the chain to be synced.",
+		ShortDescription: `Blocks until the head of the node is at most --epochs epochs behind the epoch
+of the chain at the wall clock time. With --watch the progress of the syncing targets
+is printed while waiting.`,
+	},
+	Options: []cmds.Option{
+		cmds.Int64Option("epochs", "number of epochs the head may be behind the wall clock epoch").WithDefault(int64(5)),
+		cmds.BoolOption("watch", "print the progress of the sync while waiting"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		epochs, _ := req.Options["epochs"].(int64)
+		watch, _ := req.Options["watch"].(bool)
+
+		ticker := time.NewTicker(syncWaitInterval)
+		defer ticker.Stop()
+		for {
+			state, err := env.(*node.Env).SyncerAPI.SyncState(req.Context)
+			if err != nil {
+				return err
+			}
+
+			behind := state.ClockEpoch - state.HeadHeight
+			if behind <= abi.ChainEpoch(epochs) {
+				return re.Emit(fmt.Sprintf("synced: head %d, %d epochs behind the wall clock epoch %d\n", state.HeadHeight, behind, state.ClockEpoch))
+			}
+			if watch {
+				_ = re.Emit(syncProgressLine(state, behind) + "\n")
+			}
+
+			select {
+			case <-ticker.C:
+			case <-req.Context.Done():
+				return req.Context.Err()
+			}
+		}
+	},
+}
+
+// syncProgressLine describes the head of the node and the progress of the syncing targets of `state`.
+func syncProgressLine(state *syncer.SyncState, behind abi.ChainEpoch) string {
+	line := fmt.Sprintf("head %d, %d epochs behind the wall clock epoch %d", state.HeadHeight, behind, state.ClockEpoch)
+	var syncs []string
+	for _, as := range state.ActiveSyncs {
+		switch as.Stage {
+		case syncer.StageIdle, syncer.StageSyncComplete, syncer.StageSyncErrored:
+			continue
+		}
+		desc := fmt.Sprintf("%s %d/%d, fetched %d, %.2f epochs/s", as.Stage, as.Height, as.TargetHeight, as.FetchedHeight, as.EpochsPerSecond)
+		if !as.EstimatedCompletion.IsZero() {
+			desc += fmt.Sprintf(", eta %s", time.Until(as.EstimatedCompletion).Truncate(time.Second))
+		}
+		syncs = append(syncs, desc)
+	}
+	if len(syncs) > 0 {
+		line += "; " + strings.Join(syncs, "; ")
+	}
+	return line
+}
+
 var storeStatusCmd = &cmds.Command{
 	Helptext: cmds.HelpText{
 		Tagline: "Show status of chain sync operation.",
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
-		tracker := env.(*node.Env).SyncerAPI.SyncerTracker()
+		tracker := env.(*node.Env).SyncerAPI.SyncerTracker(req.Context)
 		targets := tracker.Buckets()
 		w := bytes.NewBufferString("")
 		writer := NewSilentWriter(w)
@@ -60,6 +136,7 @@ var storeStatusCmd = &cmds.Command{
 			}
 		}
 		count := 1
+		now := time.Now()
 		writer.Println("Syncing:")
 		for _, t := range inSyncing {
 			writer.Println("SyncTarget:", strconv.Itoa(count))
@@ -73,6 +150,13 @@ var storeStatusCmd = &cmds.Command{
 			}
 
 			writer.Println("\tStatus:", t.State.String())
+			progress := t.Progress(now)
+			writer.Println("\tStage:", progress.Stage.String())
+			writer.Println("\tFetched:", progress.Fetched)
+			writer.Printf("\tRate: %.2f epochs/s\n", progress.EpochsPerSecond)
+			if !progress.EstimatedCompletion.IsZero() {
+				writer.Println("\tETA:", progress.EstimatedCompletion.Format("2006-01-02 15:04:05"))
+			}
 			writer.Println("\tErr:", t.Err)
 			writer.Println()
 			count++
@@ -128,7 +212,7 @@ var historyCmd = &cmds.Command{
 		Tagline: "Show history of chain sync.",
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
-		tracker := env.(*node.Env).SyncerAPI.SyncerTracker()
+		tracker := env.(*node.Env).SyncerAPI.SyncerTracker(req.Context)
 		w := bytes.NewBufferString("")
 		writer := NewSilentWriter(w)
 
@@ -159,3 +243,68 @@ var historyCmd = &cmds.Command{
 		return nil
 	},
 }
+
+var syncMarkBadCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Mark the given tipset as bad, the node will refuse to sync it or any chain that includes it",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("tipset", true, false, "tipset key, comma separated block cids"),
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("reason", "why the tipset is marked bad"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		tsk, err := tipSetKeyFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		reason, _ := req.Options["reason"].(string)
+		return env.(*node.Env).SyncerAPI.SyncMarkBad(req.Context, tsk, reason)
+	},
+}
+
+var syncUnmarkBadCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Remove the given tipset from the bad tipsets",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("tipset", true, false, "tipset key, comma separated block cids"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		tsk, err := tipSetKeyFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		return env.(*node.Env).SyncerAPI.SyncUnmarkBad(req.Context, tsk)
+	},
+}
+
+var syncCheckBadCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Check if the given tipset is marked bad, and why",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("tipset", true, false, "tipset key, comma separated block cids"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		tsk, err := tipSetKeyFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		info, err := env.(*node.Env).SyncerAPI.SyncCheckBad(req.Context, tsk)
+		if err != nil {
+			return err
+		}
+
+		w := bytes.NewBufferString("")
+		writer := NewSilentWriter(w)
+		if info == nil {
+			writer.Println("tipset is not marked bad")
+		} else {
+			writer.Println("Reason:", info.Reason)
+			writer.Println("Epoch:", info.Epoch)
+		}
+		return re.Emit(w)
+	},
+}
@@ -0,0 +1,127 @@
+package chain_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/repo"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// storedStateBuilder stores the fake states as objects linking to the previous state and to
+// the messages applied on it, so that they are walked by snapshots.
+type storedStateBuilder struct {
+	chain.FakeStateBuilder
+	bs  blockstore.Blockstore
+	cst cbor.IpldStore
+}
+
+func (sb *storedStateBuilder) ComputeState(prev cid.Cid, blockmsg []block.BlockMessagesInfo) (cid.Cid, []types.MessageReceipt, error) {
+	_, receipts, err := sb.FakeStateBuilder.ComputeState(prev, blockmsg)
+	if err != nil {
+		return cid.Undef, nil, err
+	}
+
+	inputs := []cid.Cid{}
+	if has, _ := sb.bs.Has(prev); has {
+		inputs = append(inputs, prev)
+	}
+	for _, blockMessages := range blockmsg {
+		for _, msg := range append(blockMessages.BlsMessages, blockMessages.SecpkMessages...) {
+			mCid, err := msg.Cid()
+			if err != nil {
+				return cid.Undef, nil, err
+			}
+			inputs = append(inputs, mCid)
+		}
+	}
+	if len(inputs) == 1 && inputs[0] == prev {
+		// no messages, the state doesn't change
+		return prev, receipts, nil
+	}
+
+	root, err := sb.cst.Put(context.Background(), inputs)
+	if err != nil {
+		return cid.Undef, nil, err
+	}
+	return root, receipts, nil
+}
+
+func TestExportImport(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	r := repo.NewInMemoryRepo()
+	sb := &storedStateBuilder{bs: r.Datastore(), cst: cbor.NewCborStore(r.Datastore())}
+	builder := chain.NewBuilderWithDeps(t, address.Undef, sb, &chain.ZeroTimestamper{})
+	genesis := builder.Genesis()
+
+	signer, _ := types.NewMockSignersAndKeyInfo(1)
+	msg := types.NewSignedMessageForTestGetter(signer)(0)
+	msgCid, err := msg.Cid()
+	require.NoError(t, err)
+
+	link1 := builder.AppendOn(genesis, 1)
+	link2 := builder.BuildOneOn(link1, func(bb *chain.BlockBuilder) {
+		bb.AddMessages([]*types.SignedMessage{msg}, []*types.UnsignedMessage{})
+	})
+	head := builder.AppendManyOn(3, link2)
+	msgState := head.At(0).ParentStateRoot
+	require.NotEqual(t, genesis.At(0).ParentStateRoot, msgState)
+
+	// export from a store holding both the blocks and the states
+	keys, err := builder.BlockStore().AllKeysChan(ctx)
+	require.NoError(t, err)
+	for c := range keys {
+		blk, err := builder.BlockStore().Get(c)
+		require.NoError(t, err)
+		require.NoError(t, r.Datastore().Put(blk))
+	}
+	src := chain.NewStore(r.ChainDatastore(), sb.cst, r.Datastore(), chain.NewStatusReporter(), config.DefaultForkUpgradeParam, genesis.At(0).Cid())
+
+	importExport := func(inclRecentRoots abi.ChainEpoch) blockstore.Blockstore {
+		buf := new(bytes.Buffer)
+		require.NoError(t, src.Export(ctx, head, inclRecentRoots, false, buf))
+
+		dst := repo.NewInMemoryRepo()
+		store := chain.NewStore(dst.ChainDatastore(), cbor.NewCborStore(dst.Datastore()), dst.Datastore(), chain.NewStatusReporter(), config.DefaultForkUpgradeParam, genesis.At(0).Cid())
+		parent, err := store.Import(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		assert.Equal(t, head.EnsureParents(), parent.Key())
+		return dst.Datastore()
+	}
+
+	assertHas := func(bs blockstore.Blockstore, c cid.Cid, expected bool) {
+		has, err := bs.Has(c)
+		require.NoError(t, err)
+		assert.Equal(t, expected, has, c.String())
+	}
+
+	// by default only the genesis state is exported
+	bs := importExport(0)
+	assertHas(bs, head.At(0).Cid(), true)
+	assertHas(bs, link1.At(0).Cid(), true)
+	assertHas(bs, msgCid, true)
+	assertHas(bs, genesis.At(0).ParentStateRoot, true)
+	assertHas(bs, msgState, false)
+
+	// the state of every tipset is exported when the recent state roots cover the chain
+	bs = importExport(head.EnsureHeight())
+	assertHas(bs, head.At(0).Cid(), true)
+	assertHas(bs, msgCid, true)
+	assertHas(bs, genesis.At(0).ParentStateRoot, true)
+	assertHas(bs, msgState, true)
+}
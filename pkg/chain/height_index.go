@@ -0,0 +1,208 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/repo"
+)
+
+// heightIndexPrefix is the datastore prefix of the height index. The index maps
+// every epoch of the canonical chain to the key of the lowest tipset at or above
+// it, so null rounds point to the tipset that follows them.
+var heightIndexPrefix = datastore.NewKey("/chain/heightIndex")
+
+// heightIndexHeadKey holds the key of the head the height index was last updated to.
+var heightIndexHeadKey = datastore.NewKey("/chain/heightIndexHead")
+
+// heightIndexBatchSize bounds the number of writes of a batch when rebuilding.
+const heightIndexBatchSize = 4096
+
+// heightIndex is a persisted height -> tipset key index of the canonical chain.
+// It is updated by the store under its head lock on every head change.
+type heightIndex struct {
+	ds         repo.Datastore
+	loadTipSet loadTipSetFunc
+}
+
+func newHeightIndex(ds repo.Datastore, lts loadTipSetFunc) *heightIndex {
+	return &heightIndex{
+		ds:         ds,
+		loadTipSet: lts,
+	}
+}
+
+func heightKey(h abi.ChainEpoch) datastore.Key {
+	return heightIndexPrefix.ChildString(strconv.FormatInt(int64(h), 10))
+}
+
+func (hi *heightIndex) get(key datastore.Key) (block.TipSetKey, bool, error) {
+	val, err := hi.ds.Get(key)
+	if err == datastore.ErrNotFound {
+		return block.EmptyTSK, false, nil
+	}
+	if err != nil {
+		return block.EmptyTSK, false, err
+	}
+
+	var tsk block.TipSetKey
+	if err := tsk.UnmarshalCBOR(bytes.NewReader(val)); err != nil {
+		return block.EmptyTSK, false, errors.Wrapf(err, "decoding height index entry %s", key)
+	}
+	return tsk, true, nil
+}
+
+// lookup returns the lowest tipset at or above `h` on the chain of `from`, or nil if
+// `from` is not on the indexed chain or `h` is not indexed.
+func (hi *heightIndex) lookup(from *block.TipSet, h abi.ChainEpoch) (*block.TipSet, error) {
+	tsk, found, err := hi.get(heightKey(from.EnsureHeight()))
+	if err != nil || !found || !tsk.Equals(from.Key()) {
+		return nil, err
+	}
+
+	tsk, found, err = hi.get(heightKey(h))
+	if err != nil || !found {
+		return nil, err
+	}
+	return hi.loadTipSet(tsk)
+}
+
+// isHead returns whether the index was last updated to the head `tsk`.
+func (hi *heightIndex) isHead(tsk block.TipSetKey) (bool, error) {
+	head, found, err := hi.get(heightIndexHeadKey)
+	if err != nil {
+		return false, err
+	}
+	return found && head.Equals(tsk), nil
+}
+
+// update moves the index from the chain of `oldHead` to the chain of `newHead`.
+// `added` holds the tipsets of the new chain above the common ancestor.
+func (hi *heightIndex) update(oldHead, newHead *block.TipSet, added []*block.TipSet) error {
+	batch, err := hi.ds.Batch()
+	if err != nil {
+		return err
+	}
+
+	if oldHead != nil {
+		for h := oldHead.EnsureHeight(); h > newHead.EnsureHeight(); h-- {
+			if err := batch.Delete(heightKey(h)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, ts := range added {
+		if _, err := hi.put(batch, ts); err != nil {
+			return err
+		}
+	}
+	if err := hi.putHead(batch, newHead.Key()); err != nil {
+		return err
+	}
+	return batch.Commit()
+}
+
+// rebuild clears the index and indexes the chain of `head` down to genesis, or down to
+// the first tipset whose parent can't be loaded.
+func (hi *heightIndex) rebuild(ctx context.Context, head *block.TipSet) error {
+	res, err := hi.ds.Query(query.Query{Prefix: heightIndexPrefix.String(), KeysOnly: true})
+	if err != nil {
+		return err
+	}
+	entries, err := res.Rest()
+	if err != nil {
+		return err
+	}
+
+	batch, err := hi.ds.Batch()
+	if err != nil {
+		return err
+	}
+	if err := batch.Delete(heightIndexHeadKey); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := batch.Delete(datastore.NewKey(e.Key)); err != nil {
+			return err
+		}
+	}
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+
+	if batch, err = hi.ds.Batch(); err != nil {
+		return err
+	}
+	writes := 0
+	for ts := head; ; {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		parent, err := hi.put(batch, ts)
+		if err != nil {
+			log.Warnf("stop rebuilding height index at %d: %s", ts.EnsureHeight(), err)
+			break
+		}
+		if parent == nil {
+			break
+		}
+
+		writes += int(ts.EnsureHeight() - parent.EnsureHeight())
+		if writes >= heightIndexBatchSize {
+			if err := batch.Commit(); err != nil {
+				return err
+			}
+			if batch, err = hi.ds.Batch(); err != nil {
+				return err
+			}
+			writes = 0
+		}
+		ts = parent
+	}
+
+	if err := hi.putHead(batch, head.Key()); err != nil {
+		return err
+	}
+	return batch.Commit()
+}
+
+// put indexes `ts` at its height and at the null rounds below it, and returns its
+// parent, nil for the genesis.
+func (hi *heightIndex) put(batch datastore.Batch, ts *block.TipSet) (*block.TipSet, error) {
+	var parent *block.TipSet
+	from := abi.ChainEpoch(0)
+	if ts.EnsureHeight() > 0 {
+		var err error
+		if parent, err = hi.loadTipSet(ts.EnsureParents()); err != nil {
+			return nil, errors.Wrapf(err, "loading parent of %s", ts.Key())
+		}
+		from = parent.EnsureHeight() + 1
+	}
+
+	buf := new(bytes.Buffer)
+	if err := ts.Key().MarshalCBOR(buf); err != nil {
+		return nil, err
+	}
+	for h := from; h <= ts.EnsureHeight(); h++ {
+		if err := batch.Put(heightKey(h), buf.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	return parent, nil
+}
+
+func (hi *heightIndex) putHead(batch datastore.Batch, tsk block.TipSetKey) error {
+	buf := new(bytes.Buffer)
+	if err := tsk.MarshalCBOR(buf); err != nil {
+		return err
+	}
+	return batch.Put(heightIndexHeadKey, buf.Bytes())
+}
@@ -0,0 +1,102 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/venus/pkg/specactors/builtin"
+)
+
+// GetMethodMeta returns the meta of the method `method` of the builtin actor with code `code`.
+func GetMethodMeta(code cid.Cid, method abi.MethodNum) (MethodMeta, error) {
+	methods, found := MethodsMap[code]
+	if !found {
+		return MethodMeta{}, errors.Errorf("unknown actor code %s", code)
+	}
+	meta, found := methods[method]
+	if !found {
+		return MethodMeta{}, errors.Errorf("method %d not found on actor %s", method, builtin.ActorNameByCode(code))
+	}
+	return meta, nil
+}
+
+// GetMethodNum returns the number of the method named `name` of the builtin actor with code `code`.
+func GetMethodNum(code cid.Cid, name string) (abi.MethodNum, error) {
+	methods, found := MethodsMap[code]
+	if !found {
+		return 0, errors.Errorf("unknown actor code %s", code)
+	}
+	for num, meta := range methods {
+		if meta.Name == name {
+			return num, nil
+		}
+	}
+	return 0, errors.Errorf("method %s not found on actor %s", name, builtin.ActorNameByCode(code))
+}
+
+// DecodeParams decodes the raw params of a call to `method` of the builtin actor with code `code`
+// into the params type of the method.
+func DecodeParams(code cid.Cid, method abi.MethodNum, params []byte) (interface{}, error) {
+	meta, err := GetMethodMeta(code, method)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTyped(meta.Params, params)
+}
+
+// DecodeReturn decodes the raw return value of a call to `method` of the builtin actor with code
+// `code` into the return type of the method.
+func DecodeReturn(code cid.Cid, method abi.MethodNum, ret []byte) (interface{}, error) {
+	meta, err := GetMethodMeta(code, method)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTyped(meta.Ret, ret)
+}
+
+// EncodeParams encodes the JSON of the params of a call to `method` of the builtin actor with code
+// `code` to the raw params of a message.
+func EncodeParams(code cid.Cid, method abi.MethodNum, params json.RawMessage) ([]byte, error) {
+	meta, err := GetMethodMeta(code, method)
+	if err != nil {
+		return nil, err
+	}
+	if meta.Params.Kind() != reflect.Ptr {
+		return nil, errors.Errorf("unsupported params type %s", meta.Params)
+	}
+
+	p, ok := reflect.New(meta.Params.Elem()).Interface().(cbg.CBORMarshaler)
+	if !ok {
+		return nil, errors.Errorf("params type %s can't be marshaled to cbor", meta.Params)
+	}
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, errors.Wrapf(err, "unmarshaling input into params type %s", meta.Params)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := p.MarshalCBOR(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeTyped(typ reflect.Type, raw []byte) (interface{}, error) {
+	if typ.Kind() != reflect.Ptr {
+		return nil, errors.Errorf("unsupported type %s", typ)
+	}
+
+	v, ok := reflect.New(typ.Elem()).Interface().(cbg.CBORUnmarshaler)
+	if !ok {
+		return nil, errors.Errorf("type %s can't be unmarshaled from cbor", typ)
+	}
+	if err := v.UnmarshalCBOR(bytes.NewReader(raw)); err != nil {
+		return nil, errors.Wrapf(err, "decoding %s", typ)
+	}
+	return v, nil
+}
@@ -0,0 +1,37 @@
+package chain_test
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	builtin3 "github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestMethodParams(t *testing.T) {
+	tf.UnitTest(t)
+	code := builtin3.StorageMarketActorCodeID
+
+	method, err := chain.GetMethodNum(code, "AddBalance")
+	require.NoError(t, err)
+	assert.Equal(t, builtin3.MethodsMarket.AddBalance, method)
+	_, err = chain.GetMethodNum(code, "NoSuchMethod")
+	assert.Error(t, err)
+
+	params, err := chain.EncodeParams(code, method, []byte(`"t01000"`))
+	require.NoError(t, err)
+
+	decoded, err := chain.DecodeParams(code, method, params)
+	require.NoError(t, err)
+	addr, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	assert.Equal(t, &addr, decoded)
+
+	_, err = chain.DecodeParams(code, abi.MethodNum(1000), params)
+	assert.Error(t, err)
+}
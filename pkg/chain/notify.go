@@ -0,0 +1,177 @@
+package chain
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/venus/pkg/block"
+)
+
+// replayBatchSize is the maximum number of head changes of a message when replaying
+// the path from the starting tipset of a subscription to the head.
+const replayBatchSize = 128
+
+// maxPendingHeadChanges is the maximum number of head change messages buffered for a
+// subscriber, a subscriber falling further behind is dropped.
+const maxPendingHeadChanges = 1024
+
+// reorgSubscription is called by the reorg worker with the head of the last reorg it
+// dispatched, and returns the notifee of the reorgs that follow.
+type reorgSubscription func(head *block.TipSet) ReorgNotifee
+
+// CoalesceOptions configures the coalescing of the live head changes of a subscription,
+// see NewHeadChangeCoalescer for the meaning of the delays. A zero MinDelay disables coalescing.
+type CoalesceOptions struct {
+	MinDelay      time.Duration
+	MaxDelay      time.Duration
+	MergeInterval time.Duration
+}
+
+// SubHeadChangesFrom subscribes to the head changes starting from `from`. The path from `from`
+// to the current head is replayed first as revert/apply changes, then every following head change
+// is streamed, coalesced according to `opts` if not nil. The channel is closed when ctx is done, or
+// when the subscriber doesn't keep up with the head changes.
+func (store *Store) SubHeadChangesFrom(ctx context.Context, from *block.TipSet, opts *CoalesceOptions) chan []*HeadChange {
+	out := make(chan []*HeadChange, 16)
+	queue := newHeadChangeQueue()
+
+	var notifee ReorgNotifee = queue.push
+	var coalescer *HeadChangeCoalescer
+	if opts != nil && opts.MinDelay > 0 {
+		coalescer = NewHeadChangeCoalescer(queue.push, opts.MinDelay, opts.MaxDelay, opts.MergeInterval)
+		notifee = coalescer.HeadChange
+	}
+
+	headCh := make(chan *block.TipSet, 1)
+	store.reorgSubCh <- func(head *block.TipSet) ReorgNotifee {
+		headCh <- head
+		return func(rev, app []*block.TipSet) error {
+			if ctx.Err() != nil || queue.isDropped() {
+				if coalescer != nil {
+					_ = coalescer.Close()
+				}
+				return ErrNotifeeDone
+			}
+			return notifee(rev, app)
+		}
+	}
+
+	go func() {
+		defer close(out)
+
+		head := <-headCh
+		if head == nil {
+			// no reorg was dispatched yet, the following ones start from the current head
+			head = store.GetHead()
+		}
+		if head != nil {
+			path, err := ReorgPath(store.GetTipSet, from, head)
+			if err != nil {
+				log.Errorf("failed to replay head changes from %s to %s: %s", from.Key(), head.Key(), err)
+				return
+			}
+			for len(path) > 0 {
+				n := replayBatchSize
+				if len(path) < n {
+					n = len(path)
+				}
+				select {
+				case out <- path[:n]:
+				case <-ctx.Done():
+					return
+				}
+				path = path[n:]
+			}
+		}
+
+		for {
+			select {
+			case <-queue.ready:
+				pending, dropped := queue.pop()
+				if dropped {
+					log.Errorf("dropping head change sub, more than %d head changes are pending", maxPendingHeadChanges)
+					return
+				}
+				for _, changes := range pending {
+					if len(out) > 5 {
+						log.Warnf("head change sub is slow, has %d buffered entries", len(out))
+					}
+					select {
+					case out <- changes:
+					case <-ctx.Done():
+						return
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// headChangeQueue buffers the head changes of a subscription so that the reorg worker
+// never waits on a slow subscriber. The queue is dropped once maxPendingHeadChanges are
+// pending.
+type headChangeQueue struct {
+	lk      sync.Mutex
+	pending [][]*HeadChange
+	dropped bool
+	ready   chan struct{}
+}
+
+func newHeadChangeQueue() *headChangeQueue {
+	return &headChangeQueue{ready: make(chan struct{}, 1)}
+}
+
+func (q *headChangeQueue) push(rev, app []*block.TipSet) error {
+	changes := make([]*HeadChange, 0, len(rev)+len(app))
+	for _, ts := range rev {
+		changes = append(changes, &HeadChange{Type: HCRevert, Val: ts})
+	}
+	for _, ts := range app {
+		changes = append(changes, &HeadChange{Type: HCApply, Val: ts})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	q.lk.Lock()
+	if q.dropped {
+		q.lk.Unlock()
+		return ErrNotifeeDone
+	}
+	if len(q.pending) >= maxPendingHeadChanges {
+		q.dropped = true
+		q.pending = nil
+	} else {
+		q.pending = append(q.pending, changes)
+	}
+	dropped := q.dropped
+	q.lk.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	if dropped {
+		return ErrNotifeeDone
+	}
+	return nil
+}
+
+// pop returns the pending head changes, and whether the queue was dropped.
+func (q *headChangeQueue) pop() ([][]*HeadChange, bool) {
+	q.lk.Lock()
+	defer q.lk.Unlock()
+	pending := q.pending
+	q.pending = nil
+	return pending, q.dropped
+}
+
+func (q *headChangeQueue) isDropped() bool {
+	q.lk.Lock()
+	defer q.lk.Unlock()
+	return q.dropped
+}
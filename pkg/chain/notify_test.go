@@ -0,0 +1,39 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestHeadChangeQueueDropsSlowSubscriber(t *testing.T) {
+	tf.UnitTest(t)
+
+	ts := mkTipSet(mkBlock(nil, 1, 1))
+	q := newHeadChangeQueue()
+
+	for i := 0; i < maxPendingHeadChanges; i++ {
+		require.NoError(t, q.push(nil, []*block.TipSet{ts}))
+	}
+	pending, dropped := q.pop()
+	assert.Len(t, pending, maxPendingHeadChanges)
+	assert.False(t, dropped)
+
+	// the popped head changes are no longer pending
+	for i := 0; i < maxPendingHeadChanges; i++ {
+		require.NoError(t, q.push([]*block.TipSet{ts}, nil))
+	}
+
+	// the subscriber falls too far behind, it is dropped with its pending head changes
+	assert.Equal(t, ErrNotifeeDone, q.push(nil, []*block.TipSet{ts}))
+	assert.True(t, q.isDropped())
+	assert.Equal(t, ErrNotifeeDone, q.push(nil, []*block.TipSet{ts}))
+
+	pending, dropped = q.pop()
+	assert.Empty(t, pending)
+	assert.True(t, dropped)
+}
@@ -41,3 +41,22 @@ func ReorgDiff(old, new, commonAncestor *block.TipSet) (abi.ChainEpoch, abi.Chai
 
 	return hOld - hCommon, hNew - hCommon, nil
 }
+
+// ReorgPath returns the head changes that move the chain from `from` to `to`:
+// the reverts from `from` down to the common ancestor, followed by the applies
+// from the common ancestor up to `to`.
+func ReorgPath(lts func(block.TipSetKey) (*block.TipSet, error), from, to *block.TipSet) ([]*HeadChange, error) {
+	revert, apply, err := ReorgOps(lts, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	path := make([]*HeadChange, 0, len(revert)+len(apply))
+	for _, ts := range revert {
+		path = append(path, &HeadChange{Type: HCRevert, Val: ts})
+	}
+	for i := len(apply) - 1; i >= 0; i-- {
+		path = append(path, &HeadChange{Type: HCApply, Val: apply[i]})
+	}
+	return path, nil
+}
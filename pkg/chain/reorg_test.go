@@ -7,6 +7,7 @@ import (
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/venus/pkg/block"
 	"github.com/filecoin-project/venus/pkg/chain"
@@ -64,6 +65,31 @@ func TestReorgDiffSubset(t *testing.T) {
 	assert.Equal(t, abi.ChainEpoch(1), added)
 }
 
+func TestReorgPath(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	// main chain has 3 blocks past CA, fork has 2
+	old, new, common := getForkOldNewCommon(ctx, t, builder, 2, 3, 2)
+
+	path, err := chain.ReorgPath(builder.GetTipSet, old, new)
+	require.NoError(t, err)
+	require.Len(t, path, 5)
+	for i, hc := range path[:2] {
+		assert.Equal(t, chain.HCRevert, hc.Type)
+		assert.Equal(t, old.EnsureHeight()-abi.ChainEpoch(i), hc.Val.EnsureHeight())
+	}
+	for i, hc := range path[2:] {
+		assert.Equal(t, chain.HCApply, hc.Type)
+		assert.Equal(t, common.EnsureHeight()+abi.ChainEpoch(i+1), hc.Val.EnsureHeight())
+	}
+	assert.True(t, new.Equals(path[4].Val))
+
+	path, err = chain.ReorgPath(builder.GetTipSet, new, new)
+	require.NoError(t, err)
+	assert.Empty(t, path)
+}
+
 // getForkOldNewCommon is a testing helper function that creates chain with the builder.
 // The blockchain forks and the common ancestor block is 'a' (> 0) blocks after the genesis block.
 // The  main chain has an additional 'b' blocks, the fork has an additional 'c' blocks.
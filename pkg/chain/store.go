@@ -7,6 +7,7 @@ import (
 	"os"
 	"runtime/debug"
 	"sync"
+	"time"
 
 	"github.com/cskr/pubsub"
 	"github.com/filecoin-project/go-address"
@@ -20,7 +21,9 @@ import (
 	cbor "github.com/ipfs/go-ipld-cbor"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/ipld/go-car"
+	carutil "github.com/ipld/go-car/util"
 	"github.com/pkg/errors"
+	cbg "github.com/whyrusleeping/cbor-gen"
 	"go.opencensus.io/trace"
 	"golang.org/x/xerrors"
 
@@ -68,8 +71,9 @@ type loadTipSetFunc func(block.TipSetKey) (*block.TipSet, error)
 type ReorgNotifee func(rev, app []*block.TipSet) error
 
 type reorg struct {
-	old []*block.TipSet
-	new []*block.TipSet
+	old  []*block.TipSet
+	new  []*block.TipSet
+	head *block.TipSet
 }
 
 type HeadChange struct {
@@ -128,8 +132,12 @@ type Store struct {
 
 	chainIndex *ChainIndex
 
+	// heightIndex persists the canonical tipset key of every height.
+	heightIndex *heightIndex
+
 	reorgCh        chan reorg
 	reorgNotifeeCh chan ReorgNotifee
+	reorgSubCh     chan reorgSubscription
 
 	tsCache *lru.ARCCache
 }
@@ -154,11 +162,13 @@ func NewStore(ds repo.Datastore,
 		genesis:        genesisCid,
 		reporter:       sr,
 		reorgNotifeeCh: make(chan ReorgNotifee),
+		reorgSubCh:     make(chan reorgSubscription),
 		tsCache:        tsCache,
 	}
 	//todo cycle reference , may think a better idea
 	store.tipIndex = NewTipStateCache(store)
 	store.chainIndex = NewChainIndex(store.GetTipSet)
+	store.heightIndex = newHeightIndex(ds, store.GetTipSet)
 	store.circulatingSupplyCalculator = NewCirculatingSupplyCalculator(bsstore, store, forkConfig)
 
 	val, err := store.ds.Get(CheckPoint)
@@ -232,6 +242,17 @@ func (store *Store) Load(ctx context.Context) (err error) {
 
 	log.Infof("finished loading %d tipsets from %s", latestHeight, headTs.String())
 
+	indexed, err := store.heightIndex.isHead(headTs.Key())
+	if err != nil {
+		return errors.Wrap(err, "error reading height index")
+	}
+	if !indexed {
+		log.Infof("rebuilding height index from %s", headTs.String())
+		if err := store.heightIndex.rebuild(ctx, headTs); err != nil {
+			return errors.Wrap(err, "error rebuilding height index")
+		}
+	}
+
 	//todo just for test should remove if ok, 新创建节点会出问题?
 	/*	if checkPointTs == nil || headTs.EnsureHeight() > checkPointTs.EnsureHeight() {
 		p, err := headTs.Parents()
@@ -357,17 +378,26 @@ func (store *Store) GetTipSetByHeight(ctx context.Context, ts *block.TipSet, h a
 		return ts, nil
 	}
 
-	lbts, err := store.chainIndex.GetTipSetByHeight(ctx, ts, h)
+	store.mu.RLock()
+	lbts, err := store.heightIndex.lookup(ts, h)
+	store.mu.RUnlock()
 	if err != nil {
-		return nil, err
+		log.Warnf("failed to read height index at %d: %s", h, err)
 	}
 
-	if lbts.EnsureHeight() < h {
-		log.Warnf("chain index returned the wrong tipset at height %d, using slow retrieval", h)
-		lbts, err = store.chainIndex.GetTipsetByHeightWithoutCache(ts, h)
+	if lbts == nil {
+		lbts, err = store.chainIndex.GetTipSetByHeight(ctx, ts, h)
 		if err != nil {
 			return nil, err
 		}
+
+		if lbts.EnsureHeight() < h {
+			log.Warnf("chain index returned the wrong tipset at height %d, using slow retrieval", h)
+			lbts, err = store.chainIndex.GetTipsetByHeightWithoutCache(ts, h)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if lbts.EnsureHeight() == h || !prev {
@@ -508,6 +538,10 @@ func (store *Store) SetHead(ctx context.Context, newTs *block.TipSet) error {
 			added = []*block.TipSet{newTs}
 		}
 
+		if errInner := store.heightIndex.update(store.head, newTs, added); errInner != nil {
+			return nil, nil, false, errors.Wrap(errInner, "failed to update height index")
+		}
+
 		// Ensure consistency by storing this new head on disk.
 		if errInner := store.writeHead(ctx, newTs.Key()); errInner != nil {
 			return nil, nil, false, errors.Wrap(errInner, "failed to write new Head to datastore")
@@ -536,8 +570,9 @@ func (store *Store) SetHead(ctx context.Context, newTs *block.TipSet) error {
 
 	//do reorg
 	store.reorgCh <- reorg{
-		old: dropped,
-		new: added,
+		old:  dropped,
+		new:  added,
+		head: newTs,
 	}
 	return nil
 }
@@ -565,6 +600,8 @@ func (store *Store) reorgWorker(ctx context.Context) chan reorg {
 
 	out := make(chan reorg, 32)
 	notifees := []ReorgNotifee{headChangeNotifee}
+	// head is the head of the last reorg dispatched to the notifees
+	var head *block.TipSet
 
 	go func() {
 		defer log.Warn("reorgWorker quit")
@@ -573,6 +610,9 @@ func (store *Store) reorgWorker(ctx context.Context) chan reorg {
 			case n := <-store.reorgNotifeeCh:
 				notifees = append(notifees, n)
 
+			case sub := <-store.reorgSubCh:
+				notifees = append(notifees, sub(head))
+
 			case r := <-out:
 				var toremove map[int]struct{}
 				for i, hcf := range notifees {
@@ -603,6 +643,7 @@ func (store *Store) reorgWorker(ctx context.Context) chan reorg {
 					}
 					notifees = newNotifees
 				}
+				head = r.head
 
 			case <-ctx.Done():
 				return
@@ -786,6 +827,180 @@ func (store *Store) Import(r io.Reader) (*block.TipSet, error) {
 	return parentTipset, nil
 }
 
+// Export writes a CAR snapshot rooted at `ts` to `w`. State trees and receipts are
+// only included for the last `inclRecentRoots` epochs (plus genesis), and when
+// `skipOldMsgs` is set messages are dropped outside of that window as well.
+func (store *Store) Export(ctx context.Context, ts *block.TipSet, inclRecentRoots abi.ChainEpoch, skipOldMsgs bool, w io.Writer) error {
+	h := &car.CarHeader{
+		Roots:   ts.Key().Cids(),
+		Version: 1,
+	}
+
+	if err := car.WriteHeader(h, w); err != nil {
+		return xerrors.Errorf("failed to write car header: %s", err)
+	}
+
+	return store.WalkSnapshot(ctx, ts, inclRecentRoots, skipOldMsgs, func(c cid.Cid) error {
+		blk, err := store.bsstore.Get(c)
+		if err != nil {
+			return xerrors.Errorf("writing object to car, bs.Get: %w", err)
+		}
+
+		if err := carutil.LdWrite(w, c.Bytes(), blk.RawData()); err != nil {
+			return xerrors.Errorf("failed to write block to car output: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// WalkSnapshot walks the chain back from `ts` to genesis and calls `cb` once for
+// every object that belongs in a snapshot of `ts`.
+func (store *Store) WalkSnapshot(ctx context.Context, ts *block.TipSet, inclRecentRoots abi.ChainEpoch, skipOldMsgs bool, cb func(cid.Cid) error) error {
+	if ts == nil {
+		ts = store.GetHead()
+	}
+
+	seen := cid.NewSet()
+	walked := cid.NewSet()
+
+	blocksToWalk := ts.Key().Cids()
+	currentMinHeight := ts.EnsureHeight()
+
+	walkChain := func(blk cid.Cid) error {
+		if !seen.Visit(blk) {
+			return nil
+		}
+
+		if err := cb(blk); err != nil {
+			return err
+		}
+
+		data, err := store.bsstore.Get(blk)
+		if err != nil {
+			return xerrors.Errorf("getting block: %w", err)
+		}
+
+		var b block.Block
+		if err := b.UnmarshalCBOR(bytes.NewReader(data.RawData())); err != nil {
+			return xerrors.Errorf("unmarshaling block header (cid=%s): %w", blk, err)
+		}
+
+		if currentMinHeight > b.Height {
+			currentMinHeight = b.Height
+			if currentMinHeight%builtin.EpochsInDay == 0 {
+				log.Infow("export", "height", currentMinHeight)
+			}
+		}
+
+		var cids []cid.Cid
+		if !skipOldMsgs || b.Height > ts.EnsureHeight()-inclRecentRoots {
+			if walked.Visit(b.Messages) {
+				mcids, err := recurseLinks(store.bsstore, walked, b.Messages, []cid.Cid{b.Messages})
+				if err != nil {
+					return xerrors.Errorf("recursing messages failed: %w", err)
+				}
+				cids = mcids
+			}
+		}
+
+		if b.Height > 0 {
+			blocksToWalk = append(blocksToWalk, b.Parents.Cids()...)
+		} else {
+			// include the genesis block
+			cids = append(cids, b.Parents.Cids()...)
+		}
+
+		out := cids
+
+		if b.Height == 0 || b.Height > ts.EnsureHeight()-inclRecentRoots {
+			if walked.Visit(b.ParentStateRoot) {
+				cids, err := recurseLinks(store.bsstore, walked, b.ParentStateRoot, []cid.Cid{b.ParentStateRoot})
+				if err != nil {
+					return xerrors.Errorf("recursing genesis state failed: %w", err)
+				}
+
+				out = append(out, cids...)
+			}
+
+			if walked.Visit(b.ParentMessageReceipts) {
+				out = append(out, b.ParentMessageReceipts)
+			}
+		}
+
+		for _, c := range out {
+			if seen.Visit(c) {
+				if c.Prefix().Codec != cid.DagCBOR {
+					continue
+				}
+
+				if err := cb(c); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	log.Infow("export started")
+	exportStart := time.Now()
+
+	for len(blocksToWalk) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		next := blocksToWalk[0]
+		blocksToWalk = blocksToWalk[1:]
+		if err := walkChain(next); err != nil {
+			return xerrors.Errorf("walk chain failed: %w", err)
+		}
+	}
+
+	log.Infow("export finished", "duration", time.Since(exportStart).Seconds())
+
+	return nil
+}
+
+func recurseLinks(bs blockstore.Blockstore, walked *cid.Set, root cid.Cid, in []cid.Cid) ([]cid.Cid, error) {
+	if root.Prefix().Codec != cid.DagCBOR {
+		return in, nil
+	}
+
+	data, err := bs.Get(root)
+	if err != nil {
+		return nil, xerrors.Errorf("recurse links get (%s) failed: %w", root, err)
+	}
+
+	var rerr error
+	err = cbg.ScanForLinks(bytes.NewReader(data.RawData()), func(c cid.Cid) {
+		if rerr != nil {
+			// No error return on ScanForLinks :(
+			return
+		}
+
+		// traversed this already...
+		if !walked.Visit(c) {
+			return
+		}
+
+		in = append(in, c)
+		var err error
+		in, err = recurseLinks(bs, walked, c, in)
+		if err != nil {
+			rerr = err
+		}
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("scanning for links failed: %w", err)
+	}
+
+	return in, rerr
+}
+
 func (store *Store) SetCheckPoint(checkPoint block.TipSetKey) {
 	store.checkPoint = checkPoint
 }
@@ -194,6 +194,87 @@ func TestRevertChange(t *testing.T) {
 	test.Equal(t, headChanges[5].Val, link6)
 }
 
+func TestSubHeadChangesFrom(t *testing.T) {
+	tf.UnitTest(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	builder := chain.NewBuilder(t, address.Undef)
+	genesis := builder.Genesis()
+	cs := newChainStore(builder.Repo(), genesis)
+
+	link1 := builder.AppendOn(genesis, 1)
+	link2 := builder.AppendOn(link1, 1)
+	// wait for the head change to be dispatched
+	heads := cs.SubHeadChanges(ctx)
+	<-heads
+	require.NoError(t, cs.SetHead(ctx, link2))
+	<-heads
+
+	// resume from a tipset of a fork the subscriber saw before
+	fork1 := builder.AppendOn(genesis, 2)
+	ch := cs.SubHeadChangesFrom(ctx, fork1, nil)
+	replay := <-ch
+	require.Len(t, replay, 3)
+	test.Equal(t, chain.HCRevert, replay[0].Type)
+	test.Equal(t, fork1, replay[0].Val)
+	test.Equal(t, chain.HCApply, replay[1].Type)
+	test.Equal(t, link1, replay[1].Val)
+	test.Equal(t, chain.HCApply, replay[2].Type)
+	test.Equal(t, link2, replay[2].Val)
+
+	// then the live changes are streamed
+	link3 := builder.AppendOn(link2, 1)
+	require.NoError(t, cs.SetHead(ctx, link3))
+	live := <-ch
+	require.Len(t, live, 1)
+	test.Equal(t, chain.HCApply, live[0].Type)
+	test.Equal(t, link3, live[0].Val)
+
+	cancel()
+	for range ch {
+	}
+}
+
+func TestGetTipSetByHeightIndexed(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.TODO()
+	builder := chain.NewBuilder(t, address.Undef)
+	genesis := builder.Genesis()
+	cs := newChainStore(builder.Repo(), genesis)
+
+	link1 := builder.AppendOn(genesis, 1)
+	link2 := builder.AppendOn(link1, 1)
+	link3 := builder.AppendOn(link2, 1)
+	require.NoError(t, cs.SetHead(ctx, link3))
+
+	ts, err := cs.GetTipSetByHeight(ctx, nil, 2, true)
+	require.NoError(t, err)
+	test.Equal(t, link2, ts)
+
+	// reorg to a fork with a null round at height 2
+	link4 := builder.AppendOn(genesis, 2)
+	link5 := builder.BuildOn(link4, 2, func(bb *chain.BlockBuilder, i int) { bb.IncHeight(1) })
+	require.NoError(t, cs.SetHead(ctx, link5))
+
+	ts, err = cs.GetTipSetByHeight(ctx, link5, 2, true)
+	require.NoError(t, err)
+	test.Equal(t, link4, ts)
+	ts, err = cs.GetTipSetByHeight(ctx, link5, 2, false)
+	require.NoError(t, err)
+	test.Equal(t, link5, ts)
+
+	// tipsets of the old chain are still resolved by walking back
+	ts, err = cs.GetTipSetByHeight(ctx, link3, 2, true)
+	require.NoError(t, err)
+	test.Equal(t, link2, ts)
+
+	// moving the head back keeps the heights below it
+	require.NoError(t, cs.SetHead(ctx, link4))
+	ts, err = cs.GetTipSetByHeight(ctx, nil, 0, true)
+	require.NoError(t, err)
+	test.Equal(t, genesis, ts)
+}
+
 // Tipsets can be retrieved by parent key (all block cids of parents).
 func TestGetByParent(t *testing.T) {
 	tf.UnitTest(t)
@@ -477,6 +477,14 @@ func (bb *BlockBuilder) AddMessages(secpmsgs []*types.SignedMessage, blsMsgs []*
 	bb.block.Messages = meta
 }
 
+// SetParentReceipts stores the receipts of the messages executed in the block.
+func (bb *BlockBuilder) SetParentReceipts(receipts []types.MessageReceipt) {
+	root, err := bb.messages.StoreReceipts(context.Background(), receipts)
+	require.NoError(bb.t, err)
+
+	bb.block.ParentMessageReceipts = root
+}
+
 // SetStateRoot sets the block's state root.
 func (bb *BlockBuilder) SetStateRoot(root cid.Cid) {
 	bb.block.ParentStateRoot = root
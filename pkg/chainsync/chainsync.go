@@ -42,8 +42,9 @@ func NewManager(fv syncer.FullBlockValidator,
 	exchangeClient exchange.Client,
 	c clock.Clock,
 	detector *slashing.ConsensusFaultDetector,
-	fork fork.IFork) (Manager, error) {
-	syncer, err := syncer.NewSyncer(fv, hv, cs, s, m, bsstore, f, exchangeClient, c, detector, fork)
+	fork fork.IFork,
+	badTipSets *types.BadTipSetCache) (Manager, error) {
+	syncer, err := syncer.NewSyncer(fv, hv, cs, s, m, bsstore, f, exchangeClient, c, detector, fork, badTipSets)
 	if err != nil {
 		return Manager{}, err
 	}
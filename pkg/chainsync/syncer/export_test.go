@@ -0,0 +1,8 @@
+package syncer
+
+// MaxFetchAhead and MaxProcessLen expose the sizes of the sync pipeline to the tests of the
+// syncer_test package.
+var (
+	MaxFetchAhead = maxFetchAhead
+	MaxProcessLen = maxProcessLen
+)
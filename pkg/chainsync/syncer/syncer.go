@@ -24,6 +24,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/chain"
 	"github.com/filecoin-project/venus/pkg/chainsync/exchange"
 	"github.com/filecoin-project/venus/pkg/clock"
+	"github.com/filecoin-project/venus/pkg/consensus"
 	"github.com/filecoin-project/venus/pkg/constants"
 	"github.com/filecoin-project/venus/pkg/fork"
 	"github.com/filecoin-project/venus/pkg/metrics"
@@ -168,6 +169,24 @@ func init() {
 	syncOneTimer = metrics.NewTimerMs("syncer/sync_one", "Duration of single tipset validation in milliseconds")
 }
 
+var (
+	fetchSegmentTimer    *metrics.Float64Timer
+	processSegmentTimer  *metrics.Float64Timer
+	processWaitTimer     *metrics.Float64Timer
+	fetchedSegmentsGauge *metrics.Int64Gauge
+)
+
+func init() {
+	fetchSegmentTimer = metrics.NewTimerMs("syncer/fetch_segment", "Duration of the message fetching of a segment in milliseconds")
+	processSegmentTimer = metrics.NewTimerMs("syncer/process_segment", "Duration of the state transitions of a segment in milliseconds")
+	processWaitTimer = metrics.NewTimerMs("syncer/process_wait", "Duration the state transitions waited for a fetched segment in milliseconds")
+	fetchedSegmentsGauge = metrics.NewInt64Gauge("syncer/fetched_segments", "Number of fetched segments waiting for their state transitions")
+}
+
+// maxFetchAhead is the number of fetched segments waiting for their state transitions
+// the syncer keeps at most, bounding how far the message fetching runs ahead.
+var maxFetchAhead = 2
+
 var logSyncer = logging.Logger("chainsync.syncer")
 
 // NewSyncer constructs a Syncer ready for use.  The chain reader must have a
@@ -182,11 +201,12 @@ func NewSyncer(fv FullBlockValidator,
 	exchangeClient exchange.Client,
 	c clock.Clock,
 	fd faultDetector,
-	fork fork.IFork) (*Syncer, error) {
+	fork fork.IFork,
+	badTipSets *syncTypes.BadTipSetCache) (*Syncer, error) {
 	return &Syncer{
 		fetcher:         f,
 		exchangeClient:  exchangeClient,
-		badTipSets:      syncTypes.NewBadTipSetCache(),
+		badTipSets:      badTipSets,
 		fullValidator:   fv,
 		blockValidator:  hv,
 		chainSelector:   cs,
@@ -354,6 +374,11 @@ func (syncer *Syncer) HandleNewTipSet(ctx context.Context, target *syncTypes.Tar
 		return xerrors.New("do not sync to a target has synced before")
 	}
 
+	if info := syncer.badTipSets.Get(target.Head.Key()); info != nil {
+		return errors.Wrapf(ErrChainHasBadTipSet, "target %s is bad: %s", target.Head.Key(), info.Reason)
+	}
+
+	target.SetStage(syncTypes.SyncStageHeaders)
 	tipsets, err := syncer.fetchChainBlocks(ctx, head, target.Head.Key())
 	if err != nil {
 		return errors.Wrapf(err, "failure fetching or validating headers")
@@ -363,64 +388,92 @@ func (syncer *Syncer) HandleNewTipSet(ctx context.Context, target *syncTypes.Tar
 	return syncer.syncSegement(ctx, target, tipsets)
 }
 
+// syncSegement syncs the tipsets in segments of maxProcessLen tipsets through a pipeline of two stages:
+// the messages of the segments are fetched ahead while the state transitions of the previous segments
+// are computed. At most maxFetchAhead fetched segments wait for their processing.
 func (syncer *Syncer) syncSegement(ctx context.Context, target *syncTypes.Target, tipsets []*block.TipSet) error {
 	parent, err := syncer.chainStore.GetTipSet(tipsets[0].EnsureParents())
 	if err != nil {
 		return err
 	}
 
-	errProcessChan := make(chan error, 1)
-	errProcessChan <- nil //init
-	var wg sync.WaitGroup
-	//todo  write a pipline segment processor function
-	if err = RangeProcess(tipsets, func(segTipset []*block.TipSet) error {
-		// fetch messages
-		startTip := segTipset[0].EnsureHeight()
-		emdTipset := segTipset[len(segTipset)-1].EnsureHeight()
-		logSyncer.Infof("start to fetch message segement %d-%d", startTip, emdTipset)
-		_, err := syncer.fetchSegMessage(ctx, segTipset)
-		if err != nil {
-			return err
-		}
-		logSyncer.Infof("finish to fetch message segement %d-%d", startTip, emdTipset)
-		err = <-errProcessChan
-		if err != nil {
-			return xerrors.Errorf("process message failed %v", err)
-		}
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			logSyncer.Infof("start to process message segement %d-%d", startTip, emdTipset)
-			defer logSyncer.Infof("finish to process message segement %d-%d", startTip, emdTipset)
-			var processErr error
-			parent, processErr = syncer.processTipSetSegment(ctx, target, parent, segTipset)
-			if processErr != nil {
-				errProcessChan <- processErr
-				return
+	target.SetStage(syncTypes.SyncStageMessages)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// a segment holds a slot from the start of its fetching until its processing starts, the
+	// buffer of fetched alone would let the fetching run one more segment ahead
+	slots := make(chan struct{}, maxFetchAhead)
+	fetched := make(chan []*block.TipSet, maxFetchAhead)
+	fetchErr := make(chan error, 1)
+	go func() {
+		defer close(fetched)
+		fetchErr <- RangeProcess(tipsets, func(segTipset []*block.TipSet) error {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			startTip := segTipset[0].EnsureHeight()
+			endTipset := segTipset[len(segTipset)-1].EnsureHeight()
+			logSyncer.Infof("start to fetch message segement %d-%d", startTip, endTipset)
+			stopwatch := fetchSegmentTimer.Start(ctx)
+			_, err := syncer.fetchSegMessage(ctx, segTipset)
+			stopwatch.Stop(ctx)
+			if err != nil {
+				return err
+			}
+			logSyncer.Infof("finish to fetch message segement %d-%d", startTip, endTipset)
+			target.SetFetched(segTipset[len(segTipset)-1])
+
+			select {
+			case fetched <- segTipset:
+				fetchedSegmentsGauge.Set(ctx, int64(len(fetched)))
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	processErr := func() error {
+		for {
+			stopwatch := processWaitTimer.Start(ctx)
+			segTipset, ok := <-fetched
+			stopwatch.Stop(ctx)
+			if !ok {
+				return nil
+			}
+			<-slots
+			fetchedSegmentsGauge.Set(ctx, int64(len(fetched)))
+
+			startTip := segTipset[0].EnsureHeight()
+			endTipset := segTipset[len(segTipset)-1].EnsureHeight()
+			logSyncer.Infof("start to process message segement %d-%d", startTip, endTipset)
+			target.StartExecution(time.Now(), parent.EnsureHeight())
+			stopwatch = processSegmentTimer.Start(ctx)
+			parent, err = syncer.processTipSetSegment(ctx, target, parent, segTipset)
+			stopwatch.Stop(ctx)
+			if err != nil {
+				return xerrors.Errorf("process message failed %w", err)
 			}
+			logSyncer.Infof("finish to process message segement %d-%d", startTip, endTipset)
 
 			if !parent.Key().Equals(syncer.checkPoint) {
-				err := syncer.SetHead(ctx, parent)
-				if err != nil {
-					errProcessChan <- err
-					return
+				if err := syncer.SetHead(ctx, parent); err != nil {
+					return err
 				}
 			}
-			errProcessChan <- nil
-		}()
-
-		return nil
-	}); err != nil {
-		return err
-	}
+		}
+	}()
 
-	wg.Wait()
-	select {
-	case err = <-errProcessChan:
+	// stop fetching ahead once the processing failed
+	cancel()
+	if err := <-fetchErr; err != nil && processErr == nil {
 		return err
-	default:
-		return nil
 	}
+	return processErr
 }
 
 func (syncer *Syncer) fetchChainBlocks(ctx context.Context, knownTip *block.TipSet, targetTip block.TipSetKey) ([]*block.TipSet, error) {
@@ -445,6 +498,10 @@ func (syncer *Syncer) fetchChainBlocks(ctx context.Context, knownTip *block.TipS
 	count := 0
 loop:
 	for len(chainTipsets) == 0 || chainTipsets[len(chainTipsets)-1].EnsureHeight() > untilHeight {
+		if info := syncer.badTipSets.Get(targetTip); info != nil {
+			return nil, errors.Wrapf(ErrChainHasBadTipSet, "tipset %s is bad: %s", targetTip, info.Reason)
+		}
+
 		tipset, err := syncer.chainStore.GetTipSet(targetTip)
 		if err == nil {
 			chainTipsets = append(chainTipsets, tipset)
@@ -473,6 +530,9 @@ loop:
 			if b.EnsureHeight() < untilHeight {
 				break loop
 			}
+			if info := syncer.badTipSets.Get(b.Key()); info != nil {
+				return nil, errors.Wrapf(ErrChainHasBadTipSet, "tipset %s is bad: %s", b.Key(), info.Reason)
+			}
 			chainTipsets = append(chainTipsets, b)
 			targetTip = b.EnsureParents()
 		}
@@ -652,20 +712,28 @@ func (syncer *Syncer) processTipSetSegment(ctx context.Context, target *syncType
 	for i, ts := range segTipset {
 		err := syncer.syncOne(ctx, parent, ts)
 		if err != nil {
-			// While `syncOne` can indeed fail for reasons other than consensus,
-			// adding to the badTipSets at this point is the simplest, since we
-			// have access to the chain. If syncOne fails for non-consensus reasons,
-			// there is no assumption that the running node's data is valid at all,
-			// so we don't really lose anything with this simplification.
-			syncer.badTipSets.AddChain(segTipset[i:])
+			// Only the tipsets failing the consensus rules are bad, the failures of the
+			// node to process them, like datastore errors or a cancelled sync, say
+			// nothing about the chain.
+			var invalidErr *consensus.InvalidBlockError
+			if ctx.Err() == nil && xerrors.As(err, &invalidErr) {
+				if markErr := syncer.badTipSets.AddChain(segTipset[i:], err.Error(), syncer.chainStore.GetHead().EnsureHeight()); markErr != nil {
+					logSyncer.Errorf("failed to mark chain bad: %s", markErr)
+				}
+			}
 			return nil, errors.Wrapf(err, "failed to sync tipset %s, number %d of %d in chain", ts.Key(), i, len(segTipset))
 		}
 		parent = ts
-		target.Current = ts
+		target.SetCurrent(ts)
 	}
 	return parent, nil
 }
 
+// BadTipSetCache returns the cache of the tipsets the syncer refuses to sync.
+func (syncer *Syncer) BadTipSetCache() *syncTypes.BadTipSetCache {
+	return syncer.badTipSets
+}
+
 func (syncer *Syncer) Head() *block.TipSet {
 	return syncer.chainStore.GetHead()
 }
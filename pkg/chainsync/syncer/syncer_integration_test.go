@@ -36,7 +36,7 @@ func TestLoadFork(t *testing.T) {
 	// *not* as the bsstore, to which the syncer must ensure to put blocks.
 	eval := &chain.FakeStateEvaluator{MessageStore: builder.Mstore()}
 	sel := &chain.FakeChainSelector{}
-	s, err := syncer.NewSyncer(eval, eval, sel, builder.Store(), builder.Mstore(), builder.BlockStore(), builder, builder, clock.NewFake(time.Unix(1234567890, 0)), &noopFaultDetector{}, nil)
+	s, err := syncer.NewSyncer(eval, eval, sel, builder.Store(), builder.Mstore(), builder.BlockStore(), builder, builder, clock.NewFake(time.Unix(1234567890, 0)), &noopFaultDetector{}, nil, newBadTipSetCache(t))
 	require.NoError(t, err)
 
 	base := builder.AppendManyOn(3, genesis)
@@ -92,7 +92,8 @@ func TestLoadFork(t *testing.T) {
 		builder,
 		clock.NewFake(time.Unix(1234567890, 0)),
 		&noopFaultDetector{},
-		fork.NewMockFork())
+		fork.NewMockFork(),
+		newBadTipSetCache(t))
 	require.NoError(t, err)
 
 	assert.True(t, newStore.HasTipSetAndState(ctx, left))
@@ -4,21 +4,26 @@ import (
 	"context"
 	syncTypes "github.com/filecoin-project/venus/pkg/chainsync/types"
 	emptycid "github.com/filecoin-project/venus/pkg/testhelpers/empty_cid"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/venus/pkg/block"
 	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/chainsync/exchange"
 	"github.com/filecoin-project/venus/pkg/chainsync/syncer"
 	"github.com/filecoin-project/venus/pkg/clock"
+	"github.com/filecoin-project/venus/pkg/consensus"
 	"github.com/filecoin-project/venus/pkg/fork"
 	"github.com/filecoin-project/venus/pkg/specactors/policy"
 	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
 	"github.com/filecoin-project/venus/pkg/types"
 	"github.com/filecoin-project/venus/pkg/util/test"
 	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -43,6 +48,110 @@ func TestOneBlock(t *testing.T) {
 	verifyHead(t, builder.Store(), t1)
 }
 
+func TestRejectsBadTipSet(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	builder, s := setup(ctx, t)
+	genesis := builder.Store().GetHead()
+	t1 := builder.AppendOn(genesis, 1)
+	t2 := builder.AppendOn(t1, 1)
+
+	require.NoError(t, s.BadTipSetCache().Add(t1.Key(), "bad state", 0))
+	target := &syncTypes.Target{ChainInfo: *block.NewChainInfo("", "", t2)}
+	err := s.HandleNewTipSet(ctx, target)
+	assert.Equal(t, syncer.ErrChainHasBadTipSet, errors.Cause(err))
+	verifyHead(t, builder.Store(), genesis)
+
+	// once unmarked the chain is synced again
+	require.NoError(t, s.BadTipSetCache().Remove(t1.Key()))
+	target = &syncTypes.Target{ChainInfo: *block.NewChainInfo("", "", t2)}
+	require.NoError(t, s.HandleNewTipSet(ctx, target))
+	verifyHead(t, builder.Store(), t2)
+}
+
+func TestMarksInvalidTipSetBad(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	eval := newPoisonValidator(t, 98, 99)
+	builder := chain.NewBuilder(t, address.Undef)
+	builder, s := setupWithValidator(ctx, t, builder, eval, eval)
+	genesis := builder.Store().GetHead()
+
+	t1 := builder.BuildOneOn(genesis, func(bb *chain.BlockBuilder) {
+		bb.SetTimestamp(98) // poison mining validation
+	})
+	t2 := builder.AppendOn(t1, 1)
+
+	target := &syncTypes.Target{ChainInfo: *block.NewChainInfo("", "", t2)}
+	require.Error(t, s.HandleNewTipSet(ctx, target))
+	assert.NotNil(t, s.BadTipSetCache().Get(t1.Key()))
+	assert.NotNil(t, s.BadTipSetCache().Get(t2.Key()))
+}
+
+func TestStateTransitionFailureDoesNotMarkBad(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	eval := newPoisonValidator(t, 98, 99)
+	builder := chain.NewBuilder(t, address.Undef)
+	builder, s := setupWithValidator(ctx, t, builder, eval, eval)
+	genesis := builder.Store().GetHead()
+
+	t1 := builder.BuildOneOn(genesis, func(bb *chain.BlockBuilder) {
+		bb.SetTimestamp(99) // poison state transition
+	})
+	t2 := builder.AppendOn(t1, 1)
+
+	// the state of a valid tipset can't be computed while the blockstore fails
+	target := &syncTypes.Target{ChainInfo: *block.NewChainInfo("", "", t2)}
+	err := s.HandleNewTipSet(ctx, target)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "blockstore failure")
+	assert.Nil(t, s.BadTipSetCache().Get(t1.Key()))
+	assert.Nil(t, s.BadTipSetCache().Get(t2.Key()))
+	verifyHead(t, builder.Store(), genesis)
+}
+
+// failingReceiptStore fails to store receipts while `fail` is set.
+type failingReceiptStore struct {
+	*chain.MessageStore
+	fail bool
+}
+
+func (s *failingReceiptStore) StoreReceipts(ctx context.Context, receipts []types.MessageReceipt) (cid.Cid, error) {
+	if s.fail {
+		return cid.Undef, errors.New("datastore failure")
+	}
+	return s.MessageStore.StoreReceipts(ctx, receipts)
+}
+
+func TestStoreFailureDoesNotMarkBad(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	eval := &chain.FakeStateEvaluator{MessageStore: builder.Mstore()}
+	mstore := &failingReceiptStore{MessageStore: builder.Mstore(), fail: true}
+	s, err := syncer.NewSyncer(eval, eval, &chain.FakeChainSelector{}, builder.Store(), mstore, builder.BlockStore(), builder, builder,
+		clock.NewFake(time.Unix(1234567890, 0)), &noopFaultDetector{}, fork.NewMockFork(), newBadTipSetCache(t))
+	require.NoError(t, err)
+	genesis := builder.Store().GetHead()
+	t1 := builder.AppendOn(genesis, 1)
+	t2 := builder.AppendOn(t1, 1)
+
+	target := &syncTypes.Target{ChainInfo: *block.NewChainInfo("", "", t2)}
+	err = s.HandleNewTipSet(ctx, target)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "datastore failure")
+	assert.Nil(t, s.BadTipSetCache().Get(t1.Key()))
+	assert.Nil(t, s.BadTipSetCache().Get(t2.Key()))
+	verifyHead(t, builder.Store(), genesis)
+
+	// the chain is synced once the datastore recovers
+	mstore.fail = false
+	target = &syncTypes.Target{ChainInfo: *block.NewChainInfo("", "", t2)}
+	require.NoError(t, s.HandleNewTipSet(ctx, target))
+	verifyHead(t, builder.Store(), t2)
+}
+
 func TestMultiBlockTip(t *testing.T) {
 	tf.UnitTest(t)
 	ctx := context.Background()
@@ -156,6 +265,202 @@ func TestChainJump(t *testing.T) {
 	verifyHead(t, builder.Store(), t4)
 }
 
+// Syncing more tipsets than a segment fetches the messages of the next segments while
+// the previous ones are processed.
+// pipelineRecorder records the progress of the two stages of a sync: the segments whose
+// messages are fetched and the tipsets whose state transitions are computed.
+type pipelineRecorder struct {
+	t *testing.T
+	// segments are the indexes of the segments syncing the messages of the blocks
+	segments map[cid.Cid]int
+	// lastHeights are the heights of the last tipsets of the segments
+	lastHeights []abi.ChainEpoch
+	// failSegment is the index of the segment whose fetching fails, -1 for none
+	failSegment int
+	// overlapped is closed once the fetching of the second segment starts
+	overlapped chan struct{}
+
+	lk       sync.Mutex
+	fetching int
+	executed abi.ChainEpoch
+}
+
+func newPipelineRecorder(t *testing.T, tipsets []*block.TipSet, failSegment int) *pipelineRecorder {
+	rec := &pipelineRecorder{
+		t:           t,
+		segments:    make(map[cid.Cid]int),
+		failSegment: failSegment,
+		overlapped:  make(chan struct{}),
+	}
+	for i, ts := range tipsets {
+		seg := i / syncer.MaxProcessLen
+		for _, blk := range ts.Blocks() {
+			rec.segments[blk.Messages] = seg
+		}
+		if seg == len(rec.lastHeights) {
+			rec.lastHeights = append(rec.lastHeights, 0)
+		}
+		rec.lastHeights[seg] = ts.EnsureHeight()
+	}
+	return rec
+}
+
+// fetch records the loading of the messages `msgs` by the fetching stage.
+func (rec *pipelineRecorder) fetch(msgs cid.Cid) error {
+	seg, ok := rec.segments[msgs]
+	if !ok {
+		return nil
+	}
+
+	rec.lk.Lock()
+	defer rec.lk.Unlock()
+	if seg == rec.fetching {
+		rec.fetching++
+		// at most maxFetchAhead fetched segments wait for the one being processed
+		if prev := seg - syncer.MaxFetchAhead - 1; prev >= 0 {
+			assert.GreaterOrEqual(rec.t, int64(rec.executed), int64(rec.lastHeights[prev]), "segment %d fetched too far ahead", seg)
+		}
+		if seg == 1 {
+			close(rec.overlapped)
+		}
+	}
+	if seg == rec.failSegment {
+		return errors.New("fetch failure")
+	}
+	return nil
+}
+
+// beforeExecution holds the state transition of the first tipset until the fetching of the
+// next segment starts, which doesn't happen if the stages don't overlap.
+func (rec *pipelineRecorder) beforeExecution(ts *block.TipSet) error {
+	if ts.EnsureHeight() != 1 || len(rec.lastHeights) < 2 {
+		return nil
+	}
+	select {
+	case <-rec.overlapped:
+		return nil
+	case <-time.After(10 * time.Second):
+		return errors.New("the next segment isn't fetched during the state transitions")
+	}
+}
+
+func (rec *pipelineRecorder) afterExecution(ts *block.TipSet) {
+	rec.lk.Lock()
+	defer rec.lk.Unlock()
+	rec.executed = ts.EnsureHeight()
+}
+
+type fetchRecorder struct {
+	*chain.MessageStore
+	rec *pipelineRecorder
+}
+
+func (fr *fetchRecorder) LoadMetaMessages(ctx context.Context, c cid.Cid) ([]*types.SignedMessage, []*types.UnsignedMessage, error) {
+	if err := fr.rec.fetch(c); err != nil {
+		return nil, nil, err
+	}
+	return fr.MessageStore.LoadMetaMessages(ctx, c)
+}
+
+type executionRecorder struct {
+	syncer.FullBlockValidator
+	rec *pipelineRecorder
+}
+
+func (er *executionRecorder) RunStateTransition(ctx context.Context, ts *block.TipSet, parentStateRoot cid.Cid) (cid.Cid, []types.MessageReceipt, error) {
+	if err := er.rec.beforeExecution(ts); err != nil {
+		return cid.Undef, nil, err
+	}
+	root, receipts, err := er.FullBlockValidator.RunStateTransition(ctx, ts, parentStateRoot)
+	if err != nil {
+		return cid.Undef, nil, err
+	}
+	er.rec.afterExecution(ts)
+	return root, receipts, nil
+}
+
+// messagesUnavailable fails to fetch messages from the network.
+type messagesUnavailable struct {
+	*chain.Builder
+}
+
+func (mu *messagesUnavailable) GetChainMessages(context.Context, []*block.TipSet) ([]*exchange.CompactedMessages, error) {
+	return nil, errors.New("no peer has the messages")
+}
+
+// buildSegments builds on genesis 3 full segments of tipsets and a partial one, each tipset
+// including a distinct message.
+func buildSegments(t *testing.T, builder *chain.Builder) []*block.TipSet {
+	keys := types.MustGenerateKeyInfo(1, 42)
+	mm := types.NewMessageMaker(t, keys)
+	alice := mm.Addresses()[0]
+	var tipsets []*block.TipSet
+	head := builder.Genesis()
+	for i := 0; i < 3*syncer.MaxProcessLen+5; i++ {
+		head = builder.BuildOneOn(head, func(b *chain.BlockBuilder) {
+			b.AddMessages([]*types.SignedMessage{}, []*types.UnsignedMessage{mm.NewUnsignedMessage(alice, uint64(i))})
+		})
+		tipsets = append(tipsets, head)
+	}
+	return tipsets
+}
+
+func setupPipelineRecorder(t *testing.T, builder *chain.Builder, rec *pipelineRecorder, exchangeClient exchange.Client) *syncer.Syncer {
+	eval := &chain.FakeStateEvaluator{MessageStore: builder.Mstore()}
+	s, err := syncer.NewSyncer(&executionRecorder{eval, rec}, eval, &chain.FakeChainSelector{}, builder.Store(),
+		&fetchRecorder{builder.Mstore(), rec}, builder.BlockStore(), builder, exchangeClient,
+		clock.NewFake(time.Unix(1234567890, 0)), &noopFaultDetector{}, fork.NewMockFork(), newBadTipSetCache(t))
+	require.NoError(t, err)
+	return s
+}
+
+func TestChainMultipleSegments(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	tipsets := buildSegments(t, builder)
+	head := tipsets[len(tipsets)-1]
+	s := setupPipelineRecorder(t, builder, newPipelineRecorder(t, tipsets, -1), builder)
+
+	target := &syncTypes.Target{ChainInfo: *block.NewChainInfo("", "", head)}
+	require.NoError(t, s.HandleNewTipSet(ctx, target))
+	for _, ts := range tipsets {
+		verifyTip(t, builder.Store(), ts, builder.StateForKey(ts.Key()))
+	}
+	verifyHead(t, builder.Store(), head)
+	assert.True(t, target.Current.Equals(head))
+
+	progress := target.Progress(time.Now())
+	assert.Equal(t, syncTypes.SyncStageExecution, progress.Stage)
+	assert.Equal(t, head.EnsureHeight(), progress.Current)
+	assert.Equal(t, head.EnsureHeight(), progress.Fetched)
+	assert.Equal(t, head.EnsureHeight(), progress.Target)
+	assert.True(t, progress.EstimatedCompletion.IsZero())
+}
+
+func TestChainSegmentFetchFailure(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	tipsets := buildSegments(t, builder)
+	head := tipsets[len(tipsets)-1]
+	// the messages of the third segment are neither stored nor available from the network
+	s := setupPipelineRecorder(t, builder, newPipelineRecorder(t, tipsets, 2), &messagesUnavailable{builder})
+
+	target := &syncTypes.Target{ChainInfo: *block.NewChainInfo("", "", head)}
+	err := s.HandleNewTipSet(ctx, target)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no peer has the messages")
+
+	// the segments fetched before the failure are processed
+	synced := tipsets[2*syncer.MaxProcessLen-1]
+	for _, ts := range tipsets[:2*syncer.MaxProcessLen] {
+		verifyTip(t, builder.Store(), ts, builder.StateForKey(ts.Key()))
+	}
+	verifyHead(t, builder.Store(), synced)
+	assert.True(t, target.Current.Equals(synced))
+}
+
 func TestIgnoreLightFork(t *testing.T) {
 	tf.UnitTest(t)
 	ctx := context.Background()
@@ -312,7 +617,8 @@ func TestNoUncessesaryFetch(t *testing.T) {
 		emptyFetcher,
 		clock.NewFake(time.Unix(1234567890, 0)),
 		&noopFaultDetector{},
-		fork.NewMockFork())
+		fork.NewMockFork(),
+		newBadTipSetCache(t))
 	require.NoError(t, err)
 
 	target2 := &syncTypes.Target{
@@ -449,14 +755,14 @@ type poisonValidator struct {
 func (pv *poisonValidator) RunStateTransition(ctx context.Context, ts *block.TipSet, parentStateRoot cid.Cid) (root cid.Cid, receipts []types.MessageReceipt, err error) {
 	stamp := ts.At(0).Timestamp
 	if pv.fullFailureTS == stamp {
-		return emptycid.EmptyTxMetaCID, nil, errors.New("run state transition fails on poison timestamp")
+		return emptycid.EmptyTxMetaCID, nil, errors.New("run state transition fails on poison timestamp: blockstore failure")
 	}
 	return emptycid.EmptyTxMetaCID, nil, nil
 }
 
 func (pv *poisonValidator) ValidateMining(ctx context.Context, parent, ts *block.TipSet, parentWeight big.Int, parentReceiptRoot cid.Cid) error {
 	if pv.headerFailureTS == ts.At(0).Timestamp {
-		return errors.New("val semantic fails on poison timestamp")
+		return &consensus.InvalidBlockError{Err: errors.New("val semantic fails on poison timestamp")}
 	}
 	return nil
 }
@@ -574,12 +880,18 @@ func setupWithValidator(ctx context.Context, t *testing.T, builder *chain.Builde
 		builder,
 		builder,
 		clock.NewFake(time.Unix(1234567890, 0)),
-		&noopFaultDetector{}, fork.NewMockFork())
+		&noopFaultDetector{}, fork.NewMockFork(), newBadTipSetCache(t))
 	require.NoError(t, err)
 
 	return builder, syncer
 }
 
+func newBadTipSetCache(t *testing.T) *syncTypes.BadTipSetCache {
+	cache, err := syncTypes.NewBadTipSetCache(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	return cache
+}
+
 ///// Verification helpers /////
 
 // Sub-interface of the bsstore used for verification.
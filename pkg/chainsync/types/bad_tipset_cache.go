@@ -1,48 +1,126 @@
 package types
 
 import (
+	"encoding/json"
+	"strings"
 	"sync"
 
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	"github.com/ipfs/go-datastore/query"
+	"github.com/pkg/errors"
+
 	"github.com/filecoin-project/venus/pkg/block"
 )
 
+// BadTipSetInfo records why a tipset was marked bad.
+type BadTipSetInfo struct {
+	Key block.TipSetKey
+	// Reason is the validation error of the tipset, or the reason given when it was marked by hand.
+	Reason string
+	// Epoch is the height of the head of the node when the tipset was marked.
+	Epoch abi.ChainEpoch
+}
+
 // BadTipSetCache keeps track of bad tipsets that the syncer should not try to
 // download. Readers and writers grab a lock. The purpose of this cache is to
 // prevent a node from having to repeatedly invalidate a block (and its children)
-// in the event that the tipset does not conform to the rules of consensus. Note
-// that the cache is only in-memory, so it is reset whenever the node is restarted.
+// in the event that the tipset does not conform to the rules of consensus.
+// Entries are persisted so they survive restarts, and can be added or removed by
+// operators to steer the node away from a fork.
 // TODO: this needs to be limited.
 type BadTipSetCache struct {
 	mu  sync.Mutex
-	bad map[string]struct{}
+	ds  datastore.Datastore
+	bad map[string]*BadTipSetInfo
+}
+
+// NewBadTipSetCache creates a cache persisted in `ds` and loads the entries already stored.
+func NewBadTipSetCache(ds datastore.Datastore) (*BadTipSetCache, error) {
+	cache := &BadTipSetCache{
+		ds:  namespace.Wrap(ds, datastore.NewKey("/badtipsets")),
+		bad: make(map[string]*BadTipSetInfo),
+	}
+
+	res, err := cache.ds.Query(query.Query{})
+	if err != nil {
+		return nil, err
+	}
+	entries, err := res.Rest()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		info := new(BadTipSetInfo)
+		if err := json.Unmarshal(e.Value, info); err != nil {
+			return nil, errors.Wrapf(err, "decoding bad tipset %s", e.Key)
+		}
+		cache.bad[info.Key.String()] = info
+	}
+	return cache, nil
 }
 
-func NewBadTipSetCache() *BadTipSetCache {
-	return &BadTipSetCache{
-		bad: make(map[string]struct{}),
+func badTipSetKey(tsk block.TipSetKey) datastore.Key {
+	var cids []string
+	for _, c := range tsk.Cids() {
+		cids = append(cids, c.String())
 	}
+	return datastore.NewKey(strings.Join(cids, ","))
 }
 
 // AddChain adds the chain of tipsets to the BadTipSetCache.  For now it just
 // does the simplest thing and adds all blocks of the chain to the cache.
 // TODO: might want to cache a random subset once cache size is limited.
-func (cache *BadTipSetCache) AddChain(chain []*block.TipSet) {
+func (cache *BadTipSetCache) AddChain(chain []*block.TipSet, reason string, epoch abi.ChainEpoch) error {
 	for _, ts := range chain {
-		cache.Add(ts.String())
+		if err := cache.Add(ts.Key(), reason, epoch); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 // Add adds a single tipset key to the BadTipSetCache.
-func (cache *BadTipSetCache) Add(tsKey string) {
+func (cache *BadTipSetCache) Add(tsk block.TipSetKey, reason string, epoch abi.ChainEpoch) error {
+	info := &BadTipSetInfo{
+		Key:    tsk,
+		Reason: reason,
+		Epoch:  epoch,
+	}
+	val, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
 	cache.mu.Lock()
 	defer cache.mu.Unlock()
-	cache.bad[tsKey] = struct{}{}
+	if err := cache.ds.Put(badTipSetKey(tsk), val); err != nil {
+		return errors.Wrapf(err, "persisting bad tipset %s", tsk)
+	}
+	cache.bad[tsk.String()] = info
+	return nil
 }
 
-// Has checks for membership in the BadTipSetCache.
-func (cache *BadTipSetCache) Has(tsKey string) bool {
+// Remove removes a tipset key from the BadTipSetCache.
+func (cache *BadTipSetCache) Remove(tsk block.TipSetKey) error {
 	cache.mu.Lock()
 	defer cache.mu.Unlock()
-	_, ok := cache.bad[tsKey]
-	return ok
+	if err := cache.ds.Delete(badTipSetKey(tsk)); err != nil && err != datastore.ErrNotFound {
+		return errors.Wrapf(err, "removing bad tipset %s", tsk)
+	}
+	delete(cache.bad, tsk.String())
+	return nil
+}
+
+// Get returns why the tipset was marked bad, or nil if it is not in the BadTipSetCache.
+func (cache *BadTipSetCache) Get(tsk block.TipSetKey) *BadTipSetInfo {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return cache.bad[tsk.String()]
+}
+
+// Has checks for membership in the BadTipSetCache.
+func (cache *BadTipSetCache) Has(tsk block.TipSetKey) bool {
+	return cache.Get(tsk) != nil
 }
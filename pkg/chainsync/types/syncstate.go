@@ -26,3 +26,28 @@ func (v SyncStateStage) String() string {
 		return fmt.Sprintf("<unknown: %d>", v)
 	}
 }
+
+// SyncStage is the stage of the sync of a target while it is syncing.
+type SyncStage int
+
+const (
+	// SyncStageHeaders fetches and validates the headers of the chain of the target
+	SyncStageHeaders = SyncStage(iota)
+	// SyncStageMessages fetches the messages of the first segment of the chain
+	SyncStageMessages
+	// SyncStageExecution computes the state transitions of the chain while fetching the next messages
+	SyncStageExecution
+)
+
+func (v SyncStage) String() string {
+	switch v {
+	case SyncStageHeaders:
+		return "headers"
+	case SyncStageMessages:
+		return "messages"
+	case SyncStageExecution:
+		return "execution"
+	default:
+		return fmt.Sprintf("<unknown: %d>", v)
+	}
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"container/list"
+	"github.com/filecoin-project/go-state-types/abi"
 	fbig "github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/venus/pkg/block"
 	"github.com/ipfs/go-cid"
@@ -21,6 +22,99 @@ type Target struct {
 	End     time.Time
 	Err     error
 	block.ChainInfo
+
+	// progressLk guards the progress fields below and Current, which are set by the syncer while
+	// the progress is read, see Progress
+	progressLk sync.Mutex
+	// Stage is the stage of the sync while State is StateInSyncing
+	Stage SyncStage
+	// Fetched is the last tipset whose messages were fetched
+	Fetched *block.TipSet
+	// ExecStart is when the state transitions started from the height ExecStartHeight
+	ExecStart       time.Time
+	ExecStartHeight abi.ChainEpoch
+}
+
+// SyncProgress is the progress of the sync of a target.
+type SyncProgress struct {
+	Stage SyncStage
+	// Current is the height of the last tipset whose state was computed
+	Current abi.ChainEpoch
+	// Fetched is the height of the last tipset whose messages were fetched
+	Fetched abi.ChainEpoch
+	// Target is the height of the head of the target
+	Target abi.ChainEpoch
+	// EpochsPerSecond is the rate of the state transitions
+	EpochsPerSecond float64
+	// EstimatedCompletion is when the sync is expected to complete at the current rate, zero if unknown
+	EstimatedCompletion time.Time
+}
+
+// SetStage sets the stage of the sync of the target.
+func (target *Target) SetStage(stage SyncStage) {
+	target.progressLk.Lock()
+	defer target.progressLk.Unlock()
+	target.Stage = stage
+}
+
+// SetFetched sets the last tipset whose messages were fetched.
+func (target *Target) SetFetched(ts *block.TipSet) {
+	target.progressLk.Lock()
+	defer target.progressLk.Unlock()
+	target.Fetched = ts
+}
+
+// SetCurrent sets the last tipset whose state was computed.
+func (target *Target) SetCurrent(ts *block.TipSet) {
+	target.progressLk.Lock()
+	defer target.progressLk.Unlock()
+	target.Current = ts
+}
+
+// StartExecution moves the sync to the execution stage at `now`, from the state of the tipset at
+// `height`, unless the execution already started.
+func (target *Target) StartExecution(now time.Time, height abi.ChainEpoch) {
+	target.progressLk.Lock()
+	defer target.progressLk.Unlock()
+	if !target.ExecStart.IsZero() {
+		return
+	}
+	target.ExecStart = now
+	target.ExecStartHeight = height
+	target.Stage = SyncStageExecution
+}
+
+// Progress returns the progress of the sync of the target at `now`.
+func (target *Target) Progress(now time.Time) SyncProgress {
+	target.progressLk.Lock()
+	defer target.progressLk.Unlock()
+
+	progress := SyncProgress{
+		Stage:  target.Stage,
+		Target: target.Head.EnsureHeight(),
+	}
+	if target.Base != nil {
+		progress.Current = target.Base.EnsureHeight()
+		progress.Fetched = progress.Current
+	}
+	if target.Current != nil {
+		progress.Current = target.Current.EnsureHeight()
+	}
+	if target.Fetched != nil {
+		progress.Fetched = target.Fetched.EnsureHeight()
+	}
+
+	if target.ExecStart.IsZero() {
+		return progress
+	}
+	if elapsed := now.Sub(target.ExecStart).Seconds(); elapsed > 0 {
+		progress.EpochsPerSecond = float64(progress.Current-target.ExecStartHeight) / elapsed
+	}
+	if progress.EpochsPerSecond > 0 && progress.Target > progress.Current {
+		remaining := float64(progress.Target-progress.Current) / progress.EpochsPerSecond
+		progress.EstimatedCompletion = now.Add(time.Duration(remaining * float64(time.Second)))
+	}
+	return progress
 }
 
 func (target *Target) IsNeibor(t *Target) bool {
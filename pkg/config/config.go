@@ -51,8 +51,36 @@ func newDefaultAPIConfig() *APIConfig {
 // DatastoreConfig holds all the configuration options for the datastore.
 // TODO: use the advanced datastore configuration from ipfs
 type DatastoreConfig struct {
-	Type string `json:"type"`
-	Path string `json:"path"`
+	Type         string              `json:"type"`
+	Path         string              `json:"path"`
+	SplitStore   *SplitStoreConfig   `json:"splitstore"`
+	MessageIndex *MessageIndexConfig `json:"messageIndex"`
+}
+
+// SplitStoreConfig holds the options for splitting the chain blockstore into a hot
+// store for recent chain data and a cold store for everything else.
+type SplitStoreConfig struct {
+	// Enable turns on the split store, existing objects are moved out of the hot store
+	// by the first compaction.
+	Enable bool `json:"enable"`
+	// ColdStoreType is either "badgerds", to move old objects to a cold store, or
+	// "discard" to delete them.
+	ColdStoreType string `json:"coldStoreType"`
+	// ColdStorePath is the path of the cold store, relative to the repo.
+	ColdStorePath string `json:"coldStorePath"`
+	// HotStoreFinalities is the number of finalities behind the head whose state is
+	// kept in the hot store.
+	HotStoreFinalities uint64 `json:"hotStoreFinalities"`
+}
+
+// MessageIndexConfig holds the options of the index of the messages on chain, used to
+// search messages and list the messages of an address.
+type MessageIndexConfig struct {
+	// Enable turns on the index, it is built from the messages already on chain on start.
+	Enable bool `json:"enable"`
+	// BackfillEpochs is the number of epochs below the head indexed when the index is
+	// built, 0 to index the whole chain.
+	BackfillEpochs uint64 `json:"backfillEpochs"`
 }
 
 // Validators hold the list of validation functions for each configuration
@@ -68,6 +96,16 @@ func newDefaultDatastoreConfig() *DatastoreConfig {
 	return &DatastoreConfig{
 		Type: "badgerds",
 		Path: "badger",
+		SplitStore: &SplitStoreConfig{
+			Enable:             false,
+			ColdStoreType:      "badgerds",
+			ColdStorePath:      "badger-cold",
+			HotStoreFinalities: 2,
+		},
+		MessageIndex: &MessageIndexConfig{
+			Enable:         false,
+			BackfillEpochs: 0,
+		},
 	}
 }
 
@@ -102,11 +140,21 @@ func newDefaultBootstrapConfig() *BootstrapConfig {
 // WalletConfig holds all configuration options related to the wallet.
 type WalletConfig struct {
 	DefaultAddress address.Address `json:"defaultAddress,omitempty"`
+	// Encrypted stores the private keys encrypted with a passphrase. The wallet starts
+	// locked and must be unlocked to sign.
+	Encrypted bool `json:"encrypted"`
+	// UnlockTimeout is how long the wallet stays unlocked when no timeout is given on unlock.
+	UnlockTimeout string `json:"unlockTimeout"`
+	// RemoteBackend is the API info of a remote signing service, as <token>:<multiaddr>.
+	// When set, the node uses it instead of storing private keys itself.
+	RemoteBackend string `json:"remoteBackend,omitempty"`
 }
 
 func newDefaultWalletConfig() *WalletConfig {
 	return &WalletConfig{
 		DefaultAddress: address.Undef,
+		Encrypted:      false,
+		UnlockTimeout:  "10m",
 	}
 }
 
@@ -186,12 +234,38 @@ type MessagePoolConfig struct {
 	MaxPoolSize uint `json:"maxPoolSize"`
 	// MaxNonceGap is the maximum nonce of a message past the last received on chain
 	MaxNonceGap uint64 `json:"maxNonceGap"`
+	// FeeBump configures the automatic replacement of stuck local messages
+	FeeBump *FeeBumpConfig `json:"feeBump"`
 }
 
 func newDefaultMessagePoolConfig() *MessagePoolConfig {
 	return &MessagePoolConfig{
 		MaxPoolSize: 1000000,
 		MaxNonceGap: 100,
+		FeeBump:     newDefaultFeeBumpConfig(),
+	}
+}
+
+// FeeBumpConfig holds the options of the service replacing local messages that stay pending
+// while the base fee rises with messages paying a higher gas premium and fee cap.
+type FeeBumpConfig struct {
+	// Enable turns the fee bump service on
+	Enable bool `json:"enable"`
+	// StuckEpochs is the number of epochs a local message has to stay pending before it is replaced
+	StuckEpochs abi.ChainEpoch `json:"stuckEpochs"`
+	// DefaultMaxFee is the maximum fee, in FIL, the pending messages of a sender without a budget in MaxFees may pay
+	// altogether once replaced
+	DefaultMaxFee string `json:"defaultMaxFee"`
+	// MaxFees maps sender addresses to the maximum fee, in FIL, their pending messages may pay altogether once replaced
+	MaxFees map[string]string `json:"maxFees"`
+}
+
+func newDefaultFeeBumpConfig() *FeeBumpConfig {
+	return &FeeBumpConfig{
+		Enable:        false,
+		StuckEpochs:   10,
+		DefaultMaxFee: "0.007",
+		MaxFees:       map[string]string{},
 	}
 }
 
@@ -67,11 +67,6 @@ func (c *Expected) CallWithGas(ctx context.Context, msg *types.UnsignedMessage,
 		return nil, fork.ErrExpensiveFork
 	}
 
-	priorState, err := state.LoadState(ctx, cbor.NewCborStore(c.bstore), stateRoot)
-	if err != nil {
-		return nil, err
-	}
-
 	rnd := HeadRandomness{
 		Chain: c.rnd,
 		Head:  ts.Key(),
@@ -96,14 +91,28 @@ func (c *Expected) CallWithGas(ctx context.Context, msg *types.UnsignedMessage,
 		Fork:              c.fork,
 	}
 
+	// the prior messages and the message are applied on the same vm for the message to see their effects
+	v, err := vm.NewVM(vmOption)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer := state2.NewView(c.cstore, stateRoot)
 	for i, m := range priorMsgs {
-		_, err := c.processor.ProcessMessage(ctx, m, vmOption)
+		if um, ok := m.(*types.UnsignedMessage); ok {
+			fromKey, err := viewer.ResolveToKeyAddr(ctx, um.From)
+			if err != nil {
+				return nil, xerrors.Errorf("resolving sender of prior message (%d): %v", i, err)
+			}
+			m = estimationMsg(um, fromKey)
+		}
+		_, err := v.ApplyMessage(m)
 		if err != nil {
 			return nil, xerrors.Errorf("applying prior message (%d): %v", i, err)
 		}
 	}
 
-	fromActor, found, err := priorState.GetActor(ctx, msg.VMMessage().From)
+	fromActor, found, err := v.StateTree().GetActor(ctx, msg.VMMessage().From)
 	if err != nil {
 		return nil, xerrors.Errorf("get actor failed: %s", err)
 	}
@@ -112,26 +121,26 @@ func (c *Expected) CallWithGas(ctx context.Context, msg *types.UnsignedMessage,
 	}
 	msg.Nonce = fromActor.Nonce
 
-	viewer := state2.NewView(c.cstore, stateRoot)
 	fromKey, err := viewer.ResolveToKeyAddr(ctx, msg.VMMessage().From)
 	if err != nil {
 		return nil, err
 	}
+	return v.ApplyMessage(estimationMsg(msg, fromKey))
+}
 
-	var msgApply types.ChainMsg
-	switch fromKey.Protocol() {
-	case address.BLS:
-		msgApply = msg
-	case address.SECP256K1:
-		msgApply = &types.SignedMessage{
-			Message: *msg,
-			Signature: acrypto.Signature{
-				Type: crypto.SigTypeSecp256k1,
-				Data: make([]byte, 65),
-			},
-		}
+// estimationMsg returns the message to apply for the gas estimation of the unsigned message `msg`
+// of the key `fromKey`, secp256k1 messages are signed with an empty signature for their size to match.
+func estimationMsg(msg *types.UnsignedMessage, fromKey address.Address) types.ChainMsg {
+	if fromKey.Protocol() != address.SECP256K1 {
+		return msg
+	}
+	return &types.SignedMessage{
+		Message: *msg,
+		Signature: acrypto.Signature{
+			Type: crypto.SigTypeSecp256k1,
+			Data: make([]byte, 65),
+		},
 	}
-	return c.processor.ProcessMessage(ctx, msgApply, vmOption)
 }
 
 func (c *Expected) Call(ctx context.Context, msg *types.UnsignedMessage, ts *block.TipSet) (*vm.Ret, error) {
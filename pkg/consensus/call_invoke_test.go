@@ -0,0 +1,74 @@
+package consensus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/app/node/test"
+	"github.com/filecoin-project/venus/pkg/constants"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestCallWithGasPriorMessages(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	ctx := context.Background()
+	cs := test.FixtureChainSeed(t)
+	n := test.NewNodeBuilder(t).WithGenesisInit(cs.GenesisInitFunc).Build(ctx)
+	protocol := n.Syncer().Consensus
+
+	from := cs.GiveKey(t, n, 0)
+	to := cs.GiveKey(t, n, 1)
+	head := n.Chain().ChainReader.GetHead()
+	actor, err := n.Chain().API().StateGetActor(ctx, from, head.Key())
+	require.NoError(t, err)
+
+	send := func(nonce uint64, value abi.TokenAmount) *types.UnsignedMessage {
+		return &types.UnsignedMessage{
+			From:       from,
+			To:         to,
+			Nonce:      nonce,
+			Value:      value,
+			GasLimit:   constants.BlockGasLimit,
+			GasFeeCap:  abi.NewTokenAmount(int64(constants.MinimumBaseFee) + 1),
+			GasPremium: abi.NewTokenAmount(1),
+		}
+	}
+
+	// alone, the message can be paid by its sender
+	msg := send(0, types.NewAttoFILFromFIL(10))
+	ret, err := protocol.CallWithGas(ctx, msg, nil, head)
+	require.NoError(t, err)
+	assert.Equal(t, exitcode.Ok, ret.Receipt.ExitCode)
+	assert.Equal(t, uint64(0), msg.Nonce)
+
+	// the prior messages of the sender spend all but 5 FIL of its balance, one after the other
+	priorMsgs := []types.ChainMsg{
+		send(0, big.Sub(actor.Balance, types.NewAttoFILFromFIL(6))),
+		send(1, types.NewAttoFILFromFIL(1)),
+	}
+	msg = send(0, types.NewAttoFILFromFIL(10))
+	ret, err = protocol.CallWithGas(ctx, msg, priorMsgs, head)
+	require.NoError(t, err)
+	assert.Equal(t, exitcode.SysErrInsufficientFunds, ret.Receipt.ExitCode)
+	// the message is applied after both prior messages
+	assert.Equal(t, uint64(2), msg.Nonce)
+
+	msg = send(0, types.NewAttoFILFromFIL(1))
+	ret, err = protocol.CallWithGas(ctx, msg, priorMsgs, head)
+	require.NoError(t, err)
+	assert.Equal(t, exitcode.Ok, ret.Receipt.ExitCode)
+	assert.Equal(t, uint64(2), msg.Nonce)
+
+	// a prior message which can't be applied fails the estimation
+	_, err = protocol.CallWithGas(ctx, send(0, types.NewAttoFILFromFIL(1)), []types.ChainMsg{send(0, types.NewAttoFILFromFIL(1)), &types.UnsignedMessage{From: address.Undef}}, head)
+	assert.Error(t, err)
+}
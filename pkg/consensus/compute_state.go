@@ -0,0 +1,95 @@
+package consensus
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	xerrors "github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/vm"
+	"github.com/filecoin-project/venus/pkg/vm/state"
+	"github.com/filecoin-project/venus/pkg/vm/vmcontext"
+)
+
+// ExecuteTipSet re-executes the messages of `ts` on top of its parent state, calling `cb`
+// with the result of every applied message, implicit messages included.
+func (c *Expected) ExecuteTipSet(ctx context.Context, ts *block.TipSet, cb vm.ExecCallBack) (cid.Cid, []types.MessageReceipt, error) {
+	ctx, span := trace.StartSpan(ctx, "Expected.ExecuteTipSet")
+	span.AddAttributes(trace.StringAttribute("tipset", ts.String()))
+	defer span.End()
+
+	return c.runStateTransition(ctx, ts, ts.At(0).ParentStateRoot, cb)
+}
+
+// ComputeTipSetState applies `msgs` at `height` on top of the parent state of `ts`, running
+// the state migrations of the epochs in between, and returns the resulting state root.
+// `cb` is called with the result of every applied message.
+func (c *Expected) ComputeTipSetState(ctx context.Context, height abi.ChainEpoch, msgs []*types.UnsignedMessage, ts *block.TipSet, cb vm.ExecCallBack) (cid.Cid, error) {
+	ctx, span := trace.StartSpan(ctx, "Expected.ComputeTipSetState")
+	span.AddAttributes(trace.StringAttribute("tipset", ts.String()))
+	defer span.End()
+
+	base := ts.At(0).ParentStateRoot
+	for i := ts.EnsureHeight(); i < height; i++ {
+		var err error
+		base, err = c.fork.HandleStateForks(ctx, base, i, ts)
+		if err != nil {
+			return cid.Undef, xerrors.Errorf("error handling state forks at %d: %v", i, err)
+		}
+	}
+
+	rnd := HeadRandomness{
+		Chain: c.rnd,
+		Head:  ts.Key(),
+	}
+
+	vmOption := vm.VmOption{
+		CircSupplyCalculator: func(ctx context.Context, epoch abi.ChainEpoch, tree state.Tree) (abi.TokenAmount, error) {
+			dertail, err := c.chainState.GetCirculatingSupplyDetailed(ctx, epoch, tree)
+			if err != nil {
+				return abi.TokenAmount{}, err
+			}
+			return dertail.FilCirculating, nil
+		},
+		NtwkVersionGetter: c.fork.GetNtwkVersion,
+		Rnd:               &rnd,
+		BaseFee:           ts.At(0).ParentBaseFee,
+		Fork:              c.fork,
+		Epoch:             height,
+		GasPriceSchedule:  c.gasPirceSchedule,
+		Bsstore:           c.bstore,
+		PRoot:             base,
+		SysCallsImpl:      c.syscallsImpl,
+	}
+
+	v, err := vm.NewVM(vmOption)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	for i, msg := range msgs {
+		ret, err := v.ApplyMessage(msg)
+		if err != nil {
+			return cid.Undef, xerrors.Errorf("applying message %d: %v", i, err)
+		}
+		if cb != nil {
+			mcid, err := msg.Cid()
+			if err != nil {
+				return cid.Undef, err
+			}
+			if err := cb(mcid, vmcontext.VmMessageFromUnsignedMessage(msg), ret); err != nil {
+				return cid.Undef, err
+			}
+		}
+	}
+
+	root, err := v.Flush()
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("flushing vm: %v", err)
+	}
+	return root, nil
+}
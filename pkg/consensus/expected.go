@@ -68,12 +68,36 @@ var (
 
 var logExpect = logging.Logger("consensus")
 
+// InvalidBlockError is the error of a block failing the consensus rules, as opposed to the
+// failures of the node to validate it, like a missing state or a datastore error. The checks
+// whose failures can't be told apart from those of the node don't return it.
+type InvalidBlockError struct {
+	Err error
+}
+
+func (e *InvalidBlockError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *InvalidBlockError) Unwrap() error {
+	return e.Err
+}
+
+func invalidBlock(err error) error {
+	return &InvalidBlockError{Err: err}
+}
+
+func isInvalidBlock(err error) bool {
+	var invalidErr *InvalidBlockError
+	return xerrors.As(err, &invalidErr)
+}
+
 const AllowableClockDriftSecs = uint64(1)
 
 // A Processor processes all the messages in a block or tip set.
 type Processor interface {
 	// ProcessTipSet processes all messages in a tip set.
-	ProcessTipSet(context.Context, *block.TipSet, *block.TipSet, []block.BlockMessagesInfo, vm.VmOption) (cid.Cid, []types.MessageReceipt, error)
+	ProcessTipSet(context.Context, *block.TipSet, *block.TipSet, []block.BlockMessagesInfo, vm.VmOption, vm.ExecCallBack) (cid.Cid, []types.MessageReceipt, error)
 	ProcessMessage(context.Context, types.ChainMsg, vm.VmOption) (*vm.Ret, error)
 	ProcessImplicitMessage(context.Context, *types.UnsignedMessage, vm.VmOption) (*vm.Ret, error)
 }
@@ -209,6 +233,16 @@ func (c *Expected) RunStateTransition(ctx context.Context,
 	ctx, span := trace.StartSpan(ctx, "Expected.RunStateTransition")
 	span.AddAttributes(trace.StringAttribute("tipset", ts.String()))
 
+	return c.runStateTransition(ctx, ts, parentStateRoot, nil)
+}
+
+// runStateTransition applies the messages in a tipset to a state, calling `cb` with the
+// result of every applied message if it is not nil.
+func (c *Expected) runStateTransition(ctx context.Context,
+	ts *block.TipSet,
+	parentStateRoot cid.Cid,
+	cb vm.ExecCallBack,
+) (cid.Cid, []types.MessageReceipt, error) {
 	blockMessageInfo, err := c.messageStore.LoadTipSetMessage(ctx, ts)
 	if err != nil {
 		return cid.Undef, nil, nil
@@ -251,7 +285,7 @@ func (c *Expected) RunStateTransition(ctx context.Context,
 		PRoot:             parentStateRoot,
 		SysCallsImpl:      c.syscallsImpl,
 	}
-	root, receipts, err := c.processor.ProcessTipSet(ctx, pts, ts, blockMessageInfo, vmOption)
+	root, receipts, err := c.processor.ProcessTipSet(ctx, pts, ts, blockMessageInfo, vmOption, cb)
 	if err != nil {
 		return cid.Undef, nil, errors.Wrap(err, "error validating tipset")
 	}
@@ -308,18 +342,18 @@ func (c *Expected) validateBlock(ctx context.Context,
 		return xerrors.Errorf("get parent tipset state failed %s", err)
 	}
 	if !rootAfterCalc.Equals(blk.ParentStateRoot) {
-		return ErrStateRootMismatch
+		return invalidBlock(ErrStateRootMismatch)
 	}
 
 	// fast checks first
 	if err := blockSanityChecks(blk); err != nil {
-		return xerrors.Errorf("incoming header failed basic sanity checks: %v", err)
+		return invalidBlock(xerrors.Errorf("incoming header failed basic sanity checks: %v", err))
 	}
 
 	baseHeight, _ := parent.Height()
 	nulls := blk.Height - (baseHeight + 1)
 	if tgtTs := parent.MinTimestamp() + c.config.BlockDelay*uint64(nulls+1); blk.Timestamp != tgtTs {
-		return xerrors.Errorf("block has wrong timestamp: %d != %d", blk.Timestamp, tgtTs)
+		return invalidBlock(xerrors.Errorf("block has wrong timestamp: %d != %d", blk.Timestamp, tgtTs))
 	}
 
 	now := uint64(time.Now().Unix())
@@ -338,11 +372,11 @@ func (c *Expected) validateBlock(ctx context.Context,
 
 	// confirm block receipts match parent receipts
 	if !parentReceiptRoot.Equals(blk.ParentMessageReceipts) {
-		return ErrReceiptRootMismatch
+		return invalidBlock(ErrReceiptRootMismatch)
 	}
 
 	if !parentWeight.Equals(blk.ParentWeight) {
-		return errors.Errorf("block %s has invalid parent weight %d expected %d", blk.Cid().String(), blk.ParentWeight, parentWeight)
+		return invalidBlock(errors.Errorf("block %s has invalid parent weight %d expected %d", blk.Cid().String(), blk.ParentWeight, parentWeight))
 	}
 
 	// get worker address
@@ -357,7 +391,7 @@ func (c *Expected) validateBlock(ctx context.Context,
 
 	msgsCheck := async.Err(func() error {
 		if err := c.checkBlockMessages(ctx, sigValidator, blk, parent); err != nil {
-			return xerrors.Errorf("block had invalid messages: %v", err)
+			return xerrors.Errorf("block had invalid messages: %w", err)
 		}
 		return nil
 	})
@@ -375,8 +409,8 @@ func (c *Expected) validateBlock(ctx context.Context,
 			return xerrors.Errorf("computing base fee: %v", err)
 		}
 		if big.Cmp(baseFee, blk.ParentBaseFee) != 0 {
-			return xerrors.Errorf("base fee doesn't match: %s (header) != %s (computed)",
-				blk.ParentBaseFee, baseFee)
+			return invalidBlock(xerrors.Errorf("base fee doesn't match: %s (header) != %s (computed)",
+				blk.ParentBaseFee, baseFee))
 		}
 		return nil
 	})
@@ -384,7 +418,7 @@ func (c *Expected) validateBlock(ctx context.Context,
 	blockSigCheck := async.Err(func() error {
 		// Validate block signature
 		if err := crypto.ValidateSignature(blk.SignatureData(), workerAddr, *blk.BlockSig); err != nil {
-			return errors.Wrap(err, "block signature invalid")
+			return invalidBlock(errors.Wrap(err, "block signature invalid"))
 		}
 
 		return nil
@@ -439,9 +473,11 @@ func (c *Expected) validateBlock(ctx context.Context,
 	}
 
 	var merr error
+	invalid := false
 	for _, fut := range await {
 		if err := fut.AwaitContext(ctx); err != nil {
 			merr = multierror.Append(merr, err)
+			invalid = invalid || isInvalidBlock(err)
 		}
 	}
 
@@ -461,6 +497,9 @@ func (c *Expected) validateBlock(ctx context.Context,
 				"%d errors occurred:\n\t%s\n\n",
 				len(es), strings.Join(points, "\n\t"))
 		}
+		if invalid {
+			return invalidBlock(mulErr)
+		}
 		return mulErr
 	}
 	c.validateBlkCache.Add(blk.Cid().String(), struct{}{})
@@ -494,13 +533,13 @@ func (c *Expected) checkBlockMessages(ctx context.Context, sigValidator *appstat
 	{
 		// Verify that the BLS signature aggregate is correct
 		if err := sigValidator.ValidateBLSMessageAggregate(ctx, blkblsMsgs, blk.BLSAggregate); err != nil {
-			return errors.Wrapf(err, "bls message verification failed for block %s", blk.Cid())
+			return invalidBlock(errors.Wrapf(err, "bls message verification failed for block %s", blk.Cid()))
 		}
 
 		// Verify that all secp message signatures are correct
 		for i, msg := range blksecpMsgs {
 			if err := sigValidator.ValidateMessageSignature(ctx, msg); err != nil {
-				return errors.Wrapf(err, "invalid signature for secp message %d in block %s", i, blk.Cid())
+				return invalidBlock(errors.Wrapf(err, "invalid signature for secp message %d in block %s", i, blk.Cid()))
 			}
 		}
 	}
@@ -521,14 +560,14 @@ func (c *Expected) checkBlockMessages(ctx context.Context, sigValidator *appstat
 		// Phase 1: syntactic validation, as defined in the spec
 		minGas := pl.OnChainMessage(msg.ChainLength())
 		if err := m.ValidForBlockInclusion(minGas.Total(), c.fork.GetNtwkVersion(ctx, blk.Height)); err != nil {
-			return err
+			return invalidBlock(err)
 		}
 
 		// ValidForBlockInclusion checks if any single message does not exceed BlockGasLimit
 		// So below is overflow safe
 		sumGasLimit += m.GasLimit
 		if sumGasLimit > constants.BlockGasLimit {
-			return xerrors.Errorf("block gas limit exceeded")
+			return invalidBlock(xerrors.Errorf("block gas limit exceeded"))
 		}
 
 		// Phase 2: (Partial) semantic validation:
@@ -541,17 +580,17 @@ func (c *Expected) checkBlockMessages(ctx context.Context, sigValidator *appstat
 			}
 
 			if !find {
-				return xerrors.Errorf("actor %s not found", m.From)
+				return invalidBlock(xerrors.Errorf("actor %s not found", m.From))
 			}
 
 			if !builtin.IsAccountActor(act.Code) {
-				return xerrors.New("Sender must be an account actor")
+				return invalidBlock(xerrors.New("Sender must be an account actor"))
 			}
 			nonces[m.From] = act.Nonce
 		}
 
 		if nonces[m.From] != m.Nonce {
-			return xerrors.Errorf("wrong nonce (exp: %d, got: %d)", nonces[m.From], m.Nonce)
+			return invalidBlock(xerrors.Errorf("wrong nonce (exp: %d, got: %d)", nonces[m.From], m.Nonce))
 		}
 		nonces[m.From]++
 
@@ -562,7 +601,7 @@ func (c *Expected) checkBlockMessages(ctx context.Context, sigValidator *appstat
 	blsMsgs := make([]types.ChainMsg, len(blkblsMsgs))
 	for i, m := range blkblsMsgs {
 		if err := checkMsg(m); err != nil {
-			return xerrors.Errorf("block had invalid bls message at index %d: %v", i, err)
+			return xerrors.Errorf("block had invalid bls message at index %d: %w", i, err)
 		}
 
 		blsMsgs[i] = m
@@ -571,7 +610,7 @@ func (c *Expected) checkBlockMessages(ctx context.Context, sigValidator *appstat
 	secpMsgs := make([]types.ChainMsg, len(blksecpMsgs))
 	for i, m := range blksecpMsgs {
 		if err := checkMsg(m); err != nil {
-			return xerrors.Errorf("block had invalid secpk message at index %d: %v", i, err)
+			return xerrors.Errorf("block had invalid secpk message at index %d: %w", i, err)
 		}
 
 		secpMsgs[i] = m
@@ -596,7 +635,7 @@ func (c *Expected) checkBlockMessages(ctx context.Context, sigValidator *appstat
 		return xerrors.Errorf("serialize tx meta failed: %v", err)
 	}
 	if blk.Messages != b.Cid() {
-		return fmt.Errorf("messages didnt match message root in header")
+		return invalidBlock(fmt.Errorf("messages didnt match message root in header"))
 	}
 
 	return nil
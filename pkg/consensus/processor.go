@@ -62,6 +62,7 @@ func (p *DefaultProcessor) ProcessTipSet(ctx context.Context,
 	parent, ts *block.TipSet,
 	msgs []block.BlockMessagesInfo,
 	vmOption vm.VmOption,
+	cb vm.ExecCallBack,
 ) (cid.Cid, []types.MessageReceipt, error) {
 	_, span := trace.StartSpan(ctx, "DefaultProcessor.ProcessTipSet")
 	span.AddAttributes(trace.StringAttribute("tipset", ts.String()))
@@ -84,7 +85,7 @@ func (p *DefaultProcessor) ProcessTipSet(ctx context.Context,
 		return cid.Undef, nil, err
 	}
 
-	return v.ApplyTipSetMessages(msgs, ts, parentEpoch, epoch, nil)
+	return v.ApplyTipSetMessages(msgs, ts, parentEpoch, epoch, cb)
 }
 
 // ProcessTipSet computes the state transition specified by the messages.
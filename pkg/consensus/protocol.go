@@ -40,6 +40,12 @@ type Protocol interface {
 
 	CallWithGas(ctx context.Context, msg *types.UnsignedMessage, priorMsgs []types.ChainMsg, ts *block.TipSet) (*vm.Ret, error)
 
+	// ExecuteTipSet re-executes the messages of ts on its parent state, calling cb for every applied message.
+	ExecuteTipSet(ctx context.Context, ts *block.TipSet, cb vm.ExecCallBack) (cid.Cid, []types.MessageReceipt, error)
+
+	// ComputeTipSetState applies msgs at height on the parent state of ts and returns the resulting state root.
+	ComputeTipSetState(ctx context.Context, height abi.ChainEpoch, msgs []*types.UnsignedMessage, ts *block.TipSet, cb vm.ExecCallBack) (cid.Cid, error)
+
 	//Validation
 	ValidateMining(ctx context.Context, parent, ts *block.TipSet, parentWeight big.Int, parentReceiptRoot cid.Cid) error
 
@@ -43,7 +43,7 @@ func NewJwtAuth(lr repo.Repo) (*JwtAuth, error) {
 		jwtSecetName:  "auth-jwt-private",
 		jwtHmacSecret: "jwt-hmac-secret",
 		lr:            lr,
-		payload:       JwtPayload{Allow: []auth.Permission{"all"}},
+		payload:       JwtPayload{Allow: AllPermissions},
 	}
 	var err error
 	jwtAuth.apiSecret, err = jwtAuth.loadAPISecret()
@@ -92,12 +92,23 @@ func (a *JwtAuthAPI) AuthVerify(ctx context.Context, token string) ([]auth.Permi
 		return nil, xerrors.Errorf("JWT Verification failed: %v", err)
 	}
 
+	for _, perm := range payload.Allow {
+		if perm == legacyPermAll {
+			return AllPermissions, nil
+		}
+	}
 	return payload.Allow, nil
 }
 
 func (a *JwtAuthAPI) AuthNew(ctx context.Context, perms []auth.Permission) ([]byte, error) {
+	for _, perm := range perms {
+		if _, err := PermissionsUpTo(perm); err != nil {
+			return nil, err
+		}
+	}
+
 	p := JwtPayload{
-		Allow: perms, // TODO: consider checking validity
+		Allow: perms,
 	}
 
 	return jwt3.Sign(&p, (*jwt3.HMACSHA)(a.JwtAuth.apiSecret))
@@ -0,0 +1,138 @@
+package jwtauth
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	xerrors "github.com/pkg/errors"
+)
+
+// Permission tiers of the JSON-RPC API, each one includes the ones before it.
+const (
+	PermRead  auth.Permission = "read"
+	PermWrite auth.Permission = "write"
+	PermSign  auth.Permission = "sign"
+	PermAdmin auth.Permission = "admin"
+)
+
+// AllPermissions are the permissions of an admin token.
+var AllPermissions = []auth.Permission{PermRead, PermWrite, PermSign, PermAdmin}
+
+// DefaultPerms are the permissions of requests without a token.
+var DefaultPerms = []auth.Permission{PermRead}
+
+// legacyPermAll is the permission of tokens issued before permission tiers existed.
+const legacyPermAll auth.Permission = "all"
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// PermissionsUpTo returns the permissions of a token allowed to call methods requiring
+// at most `perm`.
+func PermissionsUpTo(perm auth.Permission) ([]auth.Permission, error) {
+	for i, p := range AllPermissions {
+		if p == perm {
+			return AllPermissions[:i+1], nil
+		}
+	}
+	return nil, xerrors.Errorf("unknown permission %s, expected one of %v", perm, AllPermissions)
+}
+
+// MethodPermissions reads the permission of every method of an API struct of func
+// fields, from the `perm` tag of the fields.
+func MethodPermissions(api interface{}) (map[string]auth.Permission, error) {
+	rt := reflect.TypeOf(api)
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	perms := make(map[string]auth.Permission, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.Type.Kind() != reflect.Func {
+			continue
+		}
+
+		perm := auth.Permission(field.Tag.Get("perm"))
+		if _, err := PermissionsUpTo(perm); err != nil {
+			return nil, xerrors.Wrapf(err, "method %s", field.Name)
+		}
+		perms[field.Name] = perm
+	}
+	return perms, nil
+}
+
+// PermissionedProxy fills `out`, a pointer to an API struct of func fields tagged with
+// their permission, with the methods of `impls` of the same name and type. The calls
+// of callers missing the permission of a method are rejected with an error, see
+// auth.PermissionedProxy. When several implementations have a method, the last one wins.
+//
+// Every method takes a context first. The methods requiring more than PermRead must
+// return an error last, the read methods which can't return one are left unchecked as
+// every caller can read.
+func PermissionedProxy(out interface{}, impls ...interface{}) error {
+	outV := reflect.ValueOf(out)
+	if outV.Kind() != reflect.Ptr || outV.Elem().Kind() != reflect.Struct {
+		return xerrors.Errorf("expected a pointer to an API struct, got %T", out)
+	}
+	outV = outV.Elem()
+	outT := outV.Type()
+
+	perms, err := MethodPermissions(out)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < outT.NumField(); i++ {
+		field := outT.Field(i)
+		if field.Type.Kind() != reflect.Func {
+			return xerrors.Errorf("field %s is not a method", field.Name)
+		}
+		if field.Type.NumIn() == 0 || field.Type.In(0) != contextType {
+			return xerrors.Errorf("method %s doesn't take a context first", field.Name)
+		}
+		if perms[field.Name] != PermRead && !returnsError(field.Type) {
+			return xerrors.Errorf("method %s requires %s but can't return a permission error", field.Name, perms[field.Name])
+		}
+	}
+
+	for _, impl := range impls {
+		proxy := reflect.New(outT)
+		auth.PermissionedProxy(AllPermissions, DefaultPerms, impl, proxy.Interface())
+
+		implV := reflect.ValueOf(impl)
+		for i := 0; i < outT.NumField(); i++ {
+			field := outT.Field(i)
+			method := implV.MethodByName(field.Name)
+			if !method.IsValid() || method.Type() != field.Type {
+				continue
+			}
+
+			if returnsError(field.Type) {
+				outV.Field(i).Set(proxy.Elem().Field(i))
+			} else {
+				outV.Field(i).Set(method)
+			}
+		}
+	}
+
+	var missing []string
+	for i := 0; i < outT.NumField(); i++ {
+		if outV.Field(i).IsNil() {
+			missing = append(missing, outT.Field(i).Name)
+		}
+	}
+	if len(missing) > 0 {
+		return xerrors.Errorf("no implementation of %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// returnsError returns whether a rejected call of a method of type `t` can return the
+// permission error, auth.PermissionedProxy returns it alone or after the zero value.
+func returnsError(t reflect.Type) bool {
+	return t.NumOut() > 0 && t.NumOut() <= 2 && t.Out(t.NumOut()-1) == errorType
+}
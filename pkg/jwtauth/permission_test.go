@@ -0,0 +1,194 @@
+package jwtauth_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/jwtauth"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+type testAPI struct{}
+
+func (testAPI) Read(context.Context) (string, error)  { return "read", nil }
+func (testAPI) Admin(context.Context) (string, error) { return "admin", nil }
+func (testAPI) Version(context.Context) string        { return "v1" }
+
+type testAdminAPI struct{}
+
+func (testAdminAPI) Admin(context.Context) (string, error) { return "other admin", nil }
+
+// testAPIStruct serves the methods of its Internal fields.
+type testAPIStruct struct {
+	Internal struct {
+		Read    func(context.Context) (string, error) `perm:"read"`
+		Admin   func(context.Context) (string, error) `perm:"admin"`
+		Version func(context.Context) string          `perm:"read"`
+	}
+}
+
+func (s *testAPIStruct) Read(ctx context.Context) (string, error)  { return s.Internal.Read(ctx) }
+func (s *testAPIStruct) Admin(ctx context.Context) (string, error) { return s.Internal.Admin(ctx) }
+func (s *testAPIStruct) Version(ctx context.Context) string        { return s.Internal.Version(ctx) }
+
+type testClient struct {
+	Read    func(context.Context) (string, error)
+	Admin   func(context.Context) (string, error)
+	Version func(context.Context) (string, error)
+}
+
+// newTestServer serves testAPI, callers get the permissions listed in the Authorization header.
+func newTestServer(t *testing.T) *httptest.Server {
+	var api testAPIStruct
+	require.NoError(t, jwtauth.PermissionedProxy(&api.Internal, testAPI{}))
+
+	rpcServer := jsonrpc.NewServer()
+	rpcServer.Register("Test", &api)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var perms []auth.Permission
+		for _, p := range strings.Split(r.Header.Get("Authorization"), ",") {
+			if p != "" {
+				perms = append(perms, auth.Permission(p))
+			}
+		}
+		rpcServer.ServeHTTP(w, r.WithContext(auth.WithPerm(r.Context(), perms)))
+	}))
+}
+
+func TestPermissionsUpTo(t *testing.T) {
+	tf.UnitTest(t)
+
+	perms, err := jwtauth.PermissionsUpTo(jwtauth.PermWrite)
+	require.NoError(t, err)
+	assert.Equal(t, []auth.Permission{jwtauth.PermRead, jwtauth.PermWrite}, perms)
+
+	_, err = jwtauth.PermissionsUpTo("all")
+	assert.Error(t, err)
+}
+
+func TestMethodPermissions(t *testing.T) {
+	tf.UnitTest(t)
+
+	_, err := jwtauth.MethodPermissions(testClient{})
+	assert.Error(t, err, "untagged methods are refused")
+
+	perms, err := jwtauth.MethodPermissions(struct {
+		Read func(context.Context) (string, error) `perm:"read"`
+	}{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]auth.Permission{"Read": jwtauth.PermRead}, perms)
+}
+
+func TestPermissionedProxy(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+
+	var api testAPIStruct
+	require.NoError(t, jwtauth.PermissionedProxy(&api.Internal, testAPI{}, testAdminAPI{}))
+	res, err := api.Internal.Admin(auth.WithPerm(ctx, jwtauth.AllPermissions))
+	require.NoError(t, err)
+	assert.Equal(t, "other admin", res, "the last implementation wins")
+
+	_, err = api.Internal.Admin(auth.WithPerm(ctx, []auth.Permission{jwtauth.PermRead, jwtauth.PermWrite}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing permission")
+
+	var partial testAPIStruct
+	err = jwtauth.PermissionedProxy(&partial.Internal, testAdminAPI{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no implementation of Read, Version")
+
+	var unchecked struct {
+		Admin func(context.Context) string `perm:"admin"`
+	}
+	assert.Error(t, jwtauth.PermissionedProxy(&unchecked, testAPI{}), "admin methods must return an error")
+
+	var noContext struct {
+		Read func() (string, error) `perm:"read"`
+	}
+	assert.Error(t, jwtauth.PermissionedProxy(&noContext, testAPI{}), "methods must take a context")
+}
+
+func TestPermissionedProxyWebsocket(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	call := func(perms string) testClient {
+		var c testClient
+		header := http.Header{}
+		header.Set("Authorization", perms)
+		closer, err := jsonrpc.NewClient(ctx, "ws://"+srv.Listener.Addr().String()+"/rpc/v0", "Test", &c, header)
+		require.NoError(t, err)
+		t.Cleanup(closer)
+		return c
+	}
+
+	reader := call("read")
+	res, err := reader.Read(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "read", res)
+
+	_, err = reader.Admin(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing permission")
+
+	res, err = reader.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", res)
+
+	admin := call("read,write,sign,admin")
+	res, err = admin.Admin(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", res)
+}
+
+func TestPermissionedProxyHTTP(t *testing.T) {
+	tf.UnitTest(t)
+
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	post := func(method string) map[string]interface{} {
+		body, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  method,
+			"params":  []interface{}{},
+		})
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/rpc/v0", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "read")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close() // nolint: errcheck
+
+		out, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		var res map[string]interface{}
+		require.NoError(t, json.Unmarshal(out, &res))
+		return res
+	}
+
+	assert.Equal(t, "read", post("Test.Read")["result"])
+	assert.NotNil(t, post("Test.Admin")["error"])
+}
@@ -0,0 +1,291 @@
+package messagepool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/metrics"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// ErrAdmissionLimit is returned when an untrusted message is rejected by the admission limits of the pool.
+var ErrAdmissionLimit = errors.New("admission limit reached")
+
+var mAdmissionRejected = metrics.NewInt64Counter("mpool/admission_rejected", "Number of untrusted messages rejected by the admission limits of the message pool")
+var mPeerInvalidMsg = metrics.NewInt64Counter("mpool/peer_invalid_message", "Number of invalid messages forwarded by peers")
+
+type msgKey struct {
+	from  address.Address
+	nonce uint64
+}
+
+// admissionWindow counts the events of an admission window.
+type admissionWindow struct {
+	start time.Time
+	count int
+}
+
+// admission tracks the untrusted messages admitted in the pool per sender and origin peer,
+// and the invalid messages forwarded by peers.
+type admission struct {
+	lk sync.Mutex
+
+	// origins of the pending messages forwarded by peers
+	origins     map[msgKey]peer.ID
+	peerPending map[peer.ID]int
+
+	peerAdmitted   map[peer.ID]*admissionWindow
+	senderAdmitted map[address.Address]*admissionWindow
+	peerInvalid    map[peer.ID]*admissionWindow
+	lastSweep      time.Time
+}
+
+func newAdmission() *admission {
+	return &admission{
+		origins:        make(map[msgKey]peer.ID),
+		peerPending:    make(map[peer.ID]int),
+		peerAdmitted:   make(map[peer.ID]*admissionWindow),
+		senderAdmitted: make(map[address.Address]*admissionWindow),
+		peerInvalid:    make(map[peer.ID]*admissionWindow),
+	}
+}
+
+// current returns the current window of `w`, starting a new window if it expired.
+func (w *admissionWindow) current(now time.Time, length time.Duration) *admissionWindow {
+	if now.Sub(w.start) >= length {
+		w.start = now
+		w.count = 0
+	}
+	return w
+}
+
+func windowOf(now time.Time, length time.Duration, win *admissionWindow) int {
+	if win == nil || length <= 0 {
+		return 0
+	}
+	return win.current(now, length).count
+}
+
+// check returns an ErrAdmissionLimit error if a message of `sender` forwarded by `p`,
+// empty for messages pushed over the api, exceeds the limits of `cfg`. `senderPending` is the
+// number of pending messages of the sender and `replace` tells if the message replaces one of them.
+func (a *admission) check(cfg *MpoolConfig, sender address.Address, p peer.ID, senderPending int, replace bool) error {
+	a.lk.Lock()
+	defer a.lk.Unlock()
+
+	now := constants.Clock.Now()
+	a.sweep(now, cfg.AdmissionWindow)
+
+	if p != "" {
+		if cfg.MaxInvalidPerWindow > 0 && windowOf(now, cfg.AdmissionWindow, a.peerInvalid[p]) >= cfg.MaxInvalidPerWindow {
+			return xerrors.Errorf("peer %s forwarded too many invalid messages: %w", p, ErrAdmissionLimit)
+		}
+		if cfg.MaxPeerPending > 0 && !replace && a.peerPending[p] >= cfg.MaxPeerPending {
+			return xerrors.Errorf("too many pending messages forwarded by peer %s: %w", p, ErrAdmissionLimit)
+		}
+		if cfg.MaxAdmittedPerWindow > 0 && windowOf(now, cfg.AdmissionWindow, a.peerAdmitted[p]) >= cfg.MaxAdmittedPerWindow {
+			return xerrors.Errorf("too many messages forwarded by peer %s in the admission window: %w", p, ErrAdmissionLimit)
+		}
+	}
+
+	if cfg.MaxSenderPending > 0 && !replace && senderPending >= cfg.MaxSenderPending {
+		return xerrors.Errorf("too many pending messages from sender %s: %w", sender, ErrAdmissionLimit)
+	}
+	if cfg.MaxAdmittedPerWindow > 0 && windowOf(now, cfg.AdmissionWindow, a.senderAdmitted[sender]) >= cfg.MaxAdmittedPerWindow {
+		return xerrors.Errorf("too many messages from sender %s in the admission window: %w", sender, ErrAdmissionLimit)
+	}
+	return nil
+}
+
+// admitted records the admission of `m` forwarded by `p`.
+func (a *admission) admitted(cfg *MpoolConfig, m *types.SignedMessage, p peer.ID) {
+	a.lk.Lock()
+	defer a.lk.Unlock()
+
+	now := constants.Clock.Now()
+	key := msgKey{from: m.Message.From, nonce: m.Message.Nonce}
+	// the message may replace one forwarded by another peer
+	a.releaseLocked(key)
+
+	if p != "" {
+		a.origins[key] = p
+		a.peerPending[p]++
+
+		w, ok := a.peerAdmitted[p]
+		if !ok {
+			w = &admissionWindow{start: now}
+			a.peerAdmitted[p] = w
+		}
+		w.current(now, cfg.AdmissionWindow).count++
+	}
+
+	w, ok := a.senderAdmitted[m.Message.From]
+	if !ok {
+		w = &admissionWindow{start: now}
+		a.senderAdmitted[m.Message.From] = w
+	}
+	w.current(now, cfg.AdmissionWindow).count++
+}
+
+// invalid records an invalid message forwarded by `p`.
+func (a *admission) invalid(cfg *MpoolConfig, p peer.ID) {
+	if p == "" {
+		return
+	}
+	mPeerInvalidMsg.Inc(context.TODO(), 1)
+
+	a.lk.Lock()
+	defer a.lk.Unlock()
+
+	now := constants.Clock.Now()
+	w, ok := a.peerInvalid[p]
+	if !ok {
+		w = &admissionWindow{start: now}
+		a.peerInvalid[p] = w
+	}
+	w.current(now, cfg.AdmissionWindow).count++
+}
+
+// release forgets the origin of the pending message of `from` with nonce `nonce`.
+func (a *admission) release(from address.Address, nonce uint64) {
+	a.lk.Lock()
+	defer a.lk.Unlock()
+	a.releaseLocked(msgKey{from: from, nonce: nonce})
+}
+
+func (a *admission) releaseLocked(key msgKey) {
+	p, ok := a.origins[key]
+	if !ok {
+		return
+	}
+	delete(a.origins, key)
+	if a.peerPending[p]--; a.peerPending[p] <= 0 {
+		delete(a.peerPending, p)
+	}
+}
+
+// clear forgets the origins of all pending messages.
+func (a *admission) clear() {
+	a.lk.Lock()
+	defer a.lk.Unlock()
+	a.origins = make(map[msgKey]peer.ID)
+	a.peerPending = make(map[peer.ID]int)
+}
+
+// sweep drops the expired windows once per window length.
+func (a *admission) sweep(now time.Time, length time.Duration) {
+	if length <= 0 || now.Sub(a.lastSweep) < length {
+		return
+	}
+	a.lastSweep = now
+
+	for p, w := range a.peerAdmitted {
+		if now.Sub(w.start) >= length {
+			delete(a.peerAdmitted, p)
+		}
+	}
+	for p, w := range a.peerInvalid {
+		if now.Sub(w.start) >= length {
+			delete(a.peerInvalid, p)
+		}
+	}
+	for addr, w := range a.senderAdmitted {
+		if now.Sub(w.start) >= length {
+			delete(a.senderAdmitted, addr)
+		}
+	}
+}
+
+// AddFromPeer adds a message received from the network through peer `p`, subject to the
+// admission limits of the pool. Peers forwarding invalid messages are penalized.
+func (mp *MessagePool) AddFromPeer(m *types.SignedMessage, p peer.ID) error {
+	err := mp.checkMessage(m)
+	if err != nil {
+		mp.PeerForwardedInvalid(p)
+		return err
+	}
+
+	// serialize push access to reduce lock contention
+	mp.addSema <- struct{}{}
+	defer func() {
+		<-mp.addSema
+	}()
+
+	mp.curTsLk.Lock()
+	defer mp.curTsLk.Unlock()
+
+	cfg := mp.admissionConfig()
+	if err := mp.admit(cfg, m, p); err != nil {
+		return err
+	}
+
+	if _, err := mp.addTs(m, mp.curTs, false, false); err != nil {
+		return err
+	}
+	mp.admission.admitted(cfg, m, p)
+	return nil
+}
+
+// PeerForwardedInvalid penalizes peer `p` for forwarding an invalid message, once a peer forwarded
+// MaxInvalidPerWindow invalid messages its messages are rejected until the end of the admission window.
+func (mp *MessagePool) PeerForwardedInvalid(p peer.ID) {
+	mp.admission.invalid(mp.admissionConfig(), p)
+}
+
+// RejectedFromPeer is called when the network validation rejected `data`, a message forwarded by
+// peer `p`. The peer is penalized only when the message is invalid by itself, and not when it was
+// rejected for a local reason, e.g. a sender missing from the state of the node.
+func (mp *MessagePool) RejectedFromPeer(p peer.ID, data []byte) {
+	m := &types.SignedMessage{}
+	if err := m.UnmarshalCBOR(bytes.NewReader(data)); err != nil {
+		mp.PeerForwardedInvalid(p)
+		return
+	}
+	if err := mp.checkMessage(m); err != nil {
+		mp.PeerForwardedInvalid(p)
+	}
+}
+
+func (mp *MessagePool) admissionConfig() *MpoolConfig {
+	mp.cfgLk.Lock()
+	defer mp.cfgLk.Unlock()
+	return mp.cfg
+}
+
+// admit checks the admission limits of the untrusted message `m` forwarded by `p`, publishing
+// the rejection to the pool updates.
+func (mp *MessagePool) admit(cfg *MpoolConfig, m *types.SignedMessage, p peer.ID) error {
+	mp.lk.Lock()
+	_, local := mp.localAddrs[m.Message.From]
+	var senderPending int
+	var replace bool
+	if mset, ok := mp.pending[m.Message.From]; ok {
+		senderPending = len(mset.msgs)
+		_, replace = mset.msgs[m.Message.Nonce]
+	}
+	mp.lk.Unlock()
+
+	if local {
+		return nil
+	}
+
+	err := mp.admission.check(cfg, m.Message.From, p, senderPending, replace)
+	if err != nil {
+		log.Debugf("rejecting message from %s with nonce %d: %s", m.Message.From, m.Message.Nonce, err)
+		mAdmissionRejected.Inc(context.TODO(), 1)
+		mp.changes.Pub(MpoolUpdate{
+			Type:    MpoolReject,
+			Message: m,
+			Error:   err.Error(),
+		}, localUpdates)
+	}
+	return err
+}
@@ -0,0 +1,215 @@
+package messagepool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/messagepool/gasguess"
+	"github.com/filecoin-project/venus/pkg/repo"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/wallet"
+)
+
+func TestAdmissionLimits(t *testing.T) {
+	tf.UnitTest(t)
+
+	mp, tma := makeTestMpool()
+
+	cfg := mp.GetConfig()
+	cfg.MaxSenderPending = 5
+	cfg.MaxPeerPending = 8
+	cfg.MaxAdmittedPerWindow = 11
+	cfg.MaxInvalidPerWindow = 3
+	cfg.AdmissionWindow = time.Hour
+	if err := mp.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates, err := mp.Updates(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := repo.NewInMemoryRepo()
+	backend, err := wallet.NewDSBackend(r.WalletDatastore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := wallet.New(backend)
+
+	var senders []address.Address
+	for i := 0; i < 4; i++ {
+		a, err := wallet.NewAddress(w, address.SECP256K1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tma.setBalance(a, 1) // in FIL
+		senders = append(senders, a)
+	}
+	target := mkAddress(1001)
+	gasLimit := gasguess.Costs[gasguess.CostKey{Code: builtin2.StorageMarketActorCodeID, M: 2}]
+
+	spammer := peer.ID("spammer")
+	honest := peer.ID("honest")
+
+	expectRejected := func(err error) {
+		t.Helper()
+		if !xerrors.Is(err, ErrAdmissionLimit) {
+			t.Fatalf("expected admission limit error, got %v", err)
+		}
+		for {
+			select {
+			case u := <-updates:
+				if u.Type != MpoolReject {
+					continue
+				}
+				if u.Error == "" {
+					t.Fatal("expected rejection reason")
+				}
+				return
+			case <-time.After(time.Second):
+				t.Fatal("expected reject update")
+			}
+		}
+	}
+
+	// per sender limit
+	for i := 0; i < 5; i++ {
+		if err := mp.AddFromPeer(makeTestMessage(w, senders[0], target, uint64(i), gasLimit, 1), spammer); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectRejected(mp.AddFromPeer(makeTestMessage(w, senders[0], target, 5, gasLimit, 1), spammer))
+	// replacing a pending message isn't limited
+	if err := mp.AddFromPeer(makeTestMessage(w, senders[0], target, 4, gasLimit, 2), spammer); err != nil {
+		t.Fatal(err)
+	}
+
+	// per peer limit, the replacement doesn't count twice
+	for i := 0; i < 3; i++ {
+		if err := mp.AddFromPeer(makeTestMessage(w, senders[1], target, uint64(i), gasLimit, 1), spammer); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectRejected(mp.AddFromPeer(makeTestMessage(w, senders[1], target, 3, gasLimit, 1), spammer))
+	if err := mp.AddFromPeer(makeTestMessage(w, senders[1], target, 3, gasLimit, 1), honest); err != nil {
+		t.Fatal(err)
+	}
+
+	// messages leaving the pool release the limit of their peer
+	mp.Remove(senders[0], 0, true)
+	if err := mp.AddFromPeer(makeTestMessage(w, senders[2], target, 0, gasLimit, 1), spammer); err != nil {
+		t.Fatal(err)
+	}
+
+	// per window limit of the peer, 10 messages were admitted from it so far
+	mp.Remove(senders[0], 1, true)
+	mp.Remove(senders[0], 2, true)
+	if err := mp.AddFromPeer(makeTestMessage(w, senders[2], target, 1, gasLimit, 1), spammer); err != nil {
+		t.Fatal(err)
+	}
+	expectRejected(mp.AddFromPeer(makeTestMessage(w, senders[2], target, 2, gasLimit, 1), spammer))
+
+	// per sender limit of the messages pushed untrusted
+	for i := 2; i < 5; i++ {
+		if _, err := mp.PushUntrusted(makeTestMessage(w, senders[2], target, uint64(i), gasLimit, 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = mp.PushUntrusted(makeTestMessage(w, senders[2], target, 5, gasLimit, 1))
+	expectRejected(err)
+
+	// peers forwarding invalid messages are rejected
+	bad := makeTestMessage(w, senders[3], target, 0, gasLimit, 1)
+	bad.Signature.Data[0] ^= 0xff
+	for i := 0; i < 3; i++ {
+		if err := mp.AddFromPeer(bad, honest); err == nil || xerrors.Is(err, ErrAdmissionLimit) {
+			t.Fatalf("expected invalid message error, got %v", err)
+		}
+	}
+	expectRejected(mp.AddFromPeer(makeTestMessage(w, senders[3], target, 0, gasLimit, 1), honest))
+
+	pending, _ := mp.Pending()
+	if len(pending) != 11 {
+		t.Fatalf("expected 11 pending messages, got %d", len(pending))
+	}
+}
+
+func TestAdmissionWindow(t *testing.T) {
+	tf.UnitTest(t)
+
+	cfg := DefaultConfig()
+	cfg.MaxAdmittedPerWindow = 1
+	cfg.AdmissionWindow = time.Hour
+
+	a := newAdmission()
+	sender := mkAddress(1000)
+	m := &types.SignedMessage{Message: types.UnsignedMessage{From: sender}}
+	if err := a.check(cfg, sender, "", 0, false); err != nil {
+		t.Fatal(err)
+	}
+	a.admitted(cfg, m, "")
+	if err := a.check(cfg, sender, "", 0, false); !xerrors.Is(err, ErrAdmissionLimit) {
+		t.Fatalf("expected admission limit error, got %v", err)
+	}
+
+	// the window expired
+	a.senderAdmitted[sender].start = a.senderAdmitted[sender].start.Add(-time.Hour)
+	if err := a.check(cfg, sender, "", 0, false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRejectedFromPeer(t *testing.T) {
+	tf.UnitTest(t)
+
+	mp, tma := makeTestMpool()
+
+	cfg := mp.GetConfig()
+	cfg.MaxInvalidPerWindow = 1
+	cfg.AdmissionWindow = time.Hour
+	if err := mp.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	r := repo.NewInMemoryRepo()
+	backend, err := wallet.NewDSBackend(r.WalletDatastore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := wallet.New(backend)
+	sender, err := wallet.NewAddress(w, address.SECP256K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tma.setBalance(sender, 1) // in FIL
+	target := mkAddress(1001)
+	gasLimit := gasguess.Costs[gasguess.CostKey{Code: builtin2.StorageMarketActorCodeID, M: 2}]
+	p := peer.ID("peer")
+
+	// a valid message rejected for a local reason doesn't penalize the peer
+	valid := makeTestMessage(w, sender, target, 0, gasLimit, 1)
+	data, err := valid.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp.RejectedFromPeer(p, data)
+	if err := mp.AddFromPeer(valid, p); err != nil {
+		t.Fatal(err)
+	}
+
+	// a message which can't be decoded does
+	mp.RejectedFromPeer(p, []byte("garbage"))
+	if err := mp.AddFromPeer(makeTestMessage(w, sender, target, 1, gasLimit, 1), p); !xerrors.Is(err, ErrAdmissionLimit) {
+		t.Fatalf("expected admission limit error, got %v", err)
+	}
+}
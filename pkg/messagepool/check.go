@@ -0,0 +1,392 @@
+package messagepool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	stdbig "math/big"
+	"sort"
+
+	"github.com/filecoin-project/go-address"
+	tbig "github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/crypto/sigs"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// maxMessageSize is the maximum serialized size of a message accepted by the pool.
+const maxMessageSize = 32 * 1024
+
+var baseFeeUpperBoundFactor = tbig.NewInt(10)
+
+// CheckStatusCode identifies a check run on a message by MpoolCheckMessages.
+type CheckStatusCode int
+
+const (
+	_ CheckStatusCode = iota
+	// message checks
+	CheckStatusMessageSerialize
+	CheckStatusMessageSize
+	CheckStatusMessageValidity
+	CheckStatusMessageSignature
+	CheckStatusMessageMinGas
+	CheckStatusMessageMaxGas
+	CheckStatusMessageMinBaseFee
+	CheckStatusMessageBaseFee
+	CheckStatusMessageBaseFeeLowerBound
+	CheckStatusMessageBaseFeeUpperBound
+	CheckStatusMessageGetStateNonce
+	CheckStatusMessageNonce
+	CheckStatusMessageGetStateBalance
+	CheckStatusMessageBalance
+)
+
+var checkStatusNames = map[CheckStatusCode]string{
+	CheckStatusMessageSerialize:         "serialize",
+	CheckStatusMessageSize:              "size",
+	CheckStatusMessageValidity:          "validity",
+	CheckStatusMessageSignature:         "signature",
+	CheckStatusMessageMinGas:            "min gas",
+	CheckStatusMessageMaxGas:            "max gas",
+	CheckStatusMessageMinBaseFee:        "min base fee",
+	CheckStatusMessageBaseFee:           "base fee",
+	CheckStatusMessageBaseFeeLowerBound: "base fee lower bound",
+	CheckStatusMessageBaseFeeUpperBound: "base fee upper bound",
+	CheckStatusMessageGetStateNonce:     "state nonce",
+	CheckStatusMessageNonce:             "nonce",
+	CheckStatusMessageGetStateBalance:   "state balance",
+	CheckStatusMessageBalance:           "balance",
+}
+
+func (c CheckStatusCode) String() string {
+	if name, ok := checkStatusNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown check %d", int(c))
+}
+
+// CheckStatus is the result of a check. A check can pass with an error, which is then a warning.
+type CheckStatus struct {
+	Code CheckStatusCode
+	OK   bool
+	Err  string
+	Hint map[string]interface{}
+}
+
+// MessageCheckStatus is the result of a check run on the message with cid `Cid`.
+type MessageCheckStatus struct {
+	Cid cid.Cid
+	CheckStatus
+}
+
+// MessagePrototype is a message to check before it is signed and pushed. The nonce of the message
+// is only checked when ValidNonce is set.
+type MessagePrototype struct {
+	Message    types.UnsignedMessage
+	ValidNonce bool
+}
+
+// CheckMessages runs every check of the pool on the messages of `protos` before they are pushed.
+// The messages of a sender are checked in order, on top of its pending messages.
+func (mp *MessagePool) CheckMessages(ctx context.Context, protos []*MessagePrototype) ([][]MessageCheckStatus, error) {
+	flex := make([]bool, len(protos))
+	msgs := make([]*types.SignedMessage, len(protos))
+	for i, p := range protos {
+		flex[i] = !p.ValidNonce
+		msgs[i] = &types.SignedMessage{Message: p.Message}
+	}
+	return mp.checkMessages(ctx, msgs, false, flex)
+}
+
+// CheckPendingMessages runs every check of the pool on the pending messages of `from`.
+func (mp *MessagePool) CheckPendingMessages(ctx context.Context, from address.Address) ([][]MessageCheckStatus, error) {
+	var msgs []*types.SignedMessage
+	mp.lk.Lock()
+	mset, ok := mp.pending[from]
+	if ok {
+		for _, sm := range mset.msgs {
+			msgs = append(msgs, sm)
+		}
+	}
+	mp.lk.Unlock()
+
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(msgs, func(i, j int) bool {
+		return msgs[i].Message.Nonce < msgs[j].Message.Nonce
+	})
+
+	return mp.checkMessages(ctx, msgs, true, nil)
+}
+
+// CheckReplaceMessages runs every check of the pool on the pending messages of the senders of
+// `replace`, with the messages of `replace` in place of the pending messages with the same nonce.
+func (mp *MessagePool) CheckReplaceMessages(ctx context.Context, replace []*types.UnsignedMessage) ([][]MessageCheckStatus, error) {
+	msgMap := make(map[address.Address]map[uint64]*types.SignedMessage)
+	count := 0
+
+	mp.lk.Lock()
+	for _, m := range replace {
+		mmap, ok := msgMap[m.From]
+		if !ok {
+			mmap = make(map[uint64]*types.SignedMessage)
+			msgMap[m.From] = mmap
+			mset, ok := mp.pending[m.From]
+			if ok {
+				count += len(mset.msgs)
+				for _, sm := range mset.msgs {
+					mmap[sm.Message.Nonce] = sm
+				}
+			} else {
+				count++
+			}
+		}
+		mmap[m.Nonce] = &types.SignedMessage{Message: *m}
+	}
+	mp.lk.Unlock()
+
+	msgs := make([]*types.SignedMessage, 0, count)
+	start := 0
+	for _, mmap := range msgMap {
+		end := start + len(mmap)
+
+		for _, m := range mmap {
+			msgs = append(msgs, m)
+		}
+
+		sort.Slice(msgs[start:end], func(i, j int) bool {
+			return msgs[start+i].Message.Nonce < msgs[start+j].Message.Nonce
+		})
+
+		start = end
+	}
+
+	return mp.checkMessages(ctx, msgs, true, nil)
+}
+
+// checkMessages checks `msgs`, their signature is only checked if they are signed. When `interned`
+// is set the messages are already in the pool, otherwise they are checked on top of the pending
+// messages of their sender. `flexibleNonces` is either nil or tells for every message if its
+// nonce is not set yet.
+func (mp *MessagePool) checkMessages(ctx context.Context, msgs []*types.SignedMessage, interned bool, flexibleNonces []bool) ([][]MessageCheckStatus, error) {
+	mp.curTsLk.Lock()
+	curTs := mp.curTs
+	mp.curTsLk.Unlock()
+
+	epoch, err := curTs.Height()
+	if err != nil {
+		return nil, err
+	}
+
+	baseFee, err := mp.api.ChainComputeBaseFee(ctx, curTs)
+	if err != nil {
+		return nil, xerrors.Errorf("computing basefee: %v", err)
+	}
+
+	baseFeeLowerBound := getBaseFeeLowerBound(baseFee, baseFeeLowerBoundFactor)
+	baseFeeUpperBound := tbig.Mul(baseFee, baseFeeUpperBoundFactor)
+
+	type actorState struct {
+		nextNonce     uint64
+		requiredFunds *stdbig.Int
+	}
+
+	state := make(map[address.Address]*actorState)
+	balances := make(map[address.Address]tbig.Int)
+
+	result := make([][]MessageCheckStatus, len(msgs))
+
+	for i, sm := range msgs {
+		m := &sm.Message
+		signed := len(sm.Signature.Data) > 0
+
+		var mc cid.Cid
+		if signed {
+			mc, err = sm.Cid()
+		} else {
+			mc, err = m.Cid()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		appendCheck := func(code CheckStatusCode, ok bool, err string, hint map[string]interface{}) {
+			result[i] = append(result[i], MessageCheckStatus{
+				Cid:         mc,
+				CheckStatus: CheckStatus{Code: code, OK: ok, Err: err, Hint: hint},
+			})
+		}
+
+		// pre-check: actor nonce
+		st, ok := state[m.From]
+		if !ok {
+			mp.lk.Lock()
+			mset, ok := mp.pending[m.From]
+			if ok && !interned {
+				st = &actorState{nextNonce: mset.nextNonce, requiredFunds: new(stdbig.Int).Set(mset.requiredFunds)}
+				for _, pm := range mset.msgs {
+					st.requiredFunds.Add(st.requiredFunds, pm.Message.Value.Int)
+				}
+				mp.lk.Unlock()
+				state[m.From] = st
+				appendCheck(CheckStatusMessageGetStateNonce, true, "", map[string]interface{}{"nonce": st.nextNonce})
+			} else {
+				mp.lk.Unlock()
+
+				stateNonce, err := mp.getStateNonce(m.From, curTs)
+				if err != nil {
+					appendCheck(CheckStatusMessageGetStateNonce, false, fmt.Sprintf("error retrieving state nonce: %s", err), nil)
+					continue
+				}
+				st = &actorState{nextNonce: stateNonce, requiredFunds: new(stdbig.Int)}
+				state[m.From] = st
+				appendCheck(CheckStatusMessageGetStateNonce, true, "", map[string]interface{}{"nonce": stateNonce})
+			}
+		} else {
+			appendCheck(CheckStatusMessageGetStateNonce, true, "", nil)
+		}
+
+		// pre-check: actor balance
+		balance, ok := balances[m.From]
+		if !ok {
+			balance, err = mp.getStateBalance(m.From, curTs)
+			if err != nil {
+				appendCheck(CheckStatusMessageGetStateBalance, false, fmt.Sprintf("error retrieving state balance: %s", err), nil)
+				continue
+			}
+			balances[m.From] = balance
+		}
+		appendCheck(CheckStatusMessageGetStateBalance, true, "", map[string]interface{}{"balance": balance})
+
+		checkState := func() {
+			// 11. Message nonce, a gap up to MaxNonceGap is only a warning
+			hint := map[string]interface{}{"nextNonce": st.nextNonce}
+			switch {
+			case flexibleNonces != nil && flexibleNonces[i], m.Nonce == st.nextNonce:
+				appendCheck(CheckStatusMessageNonce, true, "", hint)
+				st.nextNonce++
+			case m.Nonce < st.nextNonce:
+				appendCheck(CheckStatusMessageNonce, false, fmt.Sprintf("message nonce lower than next nonce (%d)", st.nextNonce), hint)
+			case m.Nonce-st.nextNonce <= MaxNonceGap:
+				appendCheck(CheckStatusMessageNonce, true, fmt.Sprintf("message nonce has a gap of %d from next nonce (%d)", m.Nonce-st.nextNonce, st.nextNonce), hint)
+				st.nextNonce = m.Nonce + 1
+			default:
+				hint["maxNonceGap"] = MaxNonceGap
+				appendCheck(CheckStatusMessageNonce, false, fmt.Sprintf("message nonce has too big a gap from next nonce (%d)", st.nextNonce), hint)
+			}
+
+			// 12. Balance, including the pending messages of the sender
+			st.requiredFunds.Add(st.requiredFunds, m.RequiredFunds().Int)
+			st.requiredFunds.Add(st.requiredFunds, m.Value.Int)
+			hint = map[string]interface{}{"requiredFunds": tbig.Int{Int: new(stdbig.Int).Set(st.requiredFunds)}}
+			if balance.Int.Cmp(st.requiredFunds) < 0 {
+				appendCheck(CheckStatusMessageBalance, false, "insufficient balance", hint)
+			} else {
+				appendCheck(CheckStatusMessageBalance, true, "", hint)
+			}
+		}
+
+		// 1. Serialization
+		buf := new(bytes.Buffer)
+		if err := m.MarshalCBOR(buf); err != nil {
+			appendCheck(CheckStatusMessageSerialize, false, err.Error(), nil)
+		} else {
+			appendCheck(CheckStatusMessageSerialize, true, "", nil)
+		}
+		size := buf.Len()
+
+		// 2. Message size, accounting for the signature
+		if size > maxMessageSize-128 {
+			appendCheck(CheckStatusMessageSize, false, "message too big", map[string]interface{}{"maxSize": maxMessageSize - 128})
+		} else {
+			appendCheck(CheckStatusMessageSize, true, "", nil)
+		}
+
+		// 3. Gas limit vs block gas limit
+		if m.GasLimit > constants.BlockGasLimit {
+			appendCheck(CheckStatusMessageMaxGas, false, "GasLimit greater than block gas limit", map[string]interface{}{"maxGas": constants.BlockGasLimit})
+		} else {
+			appendCheck(CheckStatusMessageMaxGas, true, "", nil)
+		}
+
+		// 4. Syntactic validation
+		if err := m.ValidForBlockInclusion(0, constants.NewestNetworkVersion); err != nil {
+			// skip remaining checks if it is a syntactically invalid message
+			appendCheck(CheckStatusMessageValidity, false, fmt.Sprintf("syntactically invalid message: %s", err), nil)
+			continue
+		}
+		appendCheck(CheckStatusMessageValidity, true, "", nil)
+
+		// 5. Signature
+		if signed {
+			if err := verifySignature(sm); err != nil {
+				appendCheck(CheckStatusMessageSignature, false, fmt.Sprintf("invalid signature: %s", err), nil)
+			} else {
+				appendCheck(CheckStatusMessageSignature, true, "", nil)
+			}
+		}
+
+		// gas checks
+
+		// 6. Min Gas
+		chainLength := size
+		if signed {
+			chainLength = sm.ChainLength()
+		}
+		minGas := mp.gasPriceSchedule.PricelistByEpoch(epoch).OnChainMessage(chainLength)
+		if m.GasLimit < minGas.Total() {
+			appendCheck(CheckStatusMessageMinGas, false, "GasLimit less than epoch minimum gas", map[string]interface{}{"minGas": minGas.Total()})
+		} else {
+			appendCheck(CheckStatusMessageMinGas, true, "", map[string]interface{}{"minGas": minGas.Total()})
+		}
+
+		// 7. Min Base Fee
+		if m.GasFeeCap.LessThan(minimumBaseFee) {
+			appendCheck(CheckStatusMessageMinBaseFee, false, "GasFeeCap less than minimum base fee", map[string]interface{}{"minBaseFee": minimumBaseFee})
+			checkState()
+			continue
+		}
+		appendCheck(CheckStatusMessageMinBaseFee, true, "", nil)
+
+		// 8. Base Fee
+		if m.GasFeeCap.LessThan(baseFee) {
+			appendCheck(CheckStatusMessageBaseFee, false, "GasFeeCap less than current base fee", map[string]interface{}{"baseFee": baseFee})
+		} else {
+			appendCheck(CheckStatusMessageBaseFee, true, "", map[string]interface{}{"baseFee": baseFee})
+		}
+
+		// 9. Base Fee lower bound
+		hint := map[string]interface{}{"baseFeeLowerBound": baseFeeLowerBound, "baseFee": baseFee}
+		if m.GasFeeCap.LessThan(baseFeeLowerBound) {
+			appendCheck(CheckStatusMessageBaseFeeLowerBound, false, "GasFeeCap less than base fee lower bound for inclusion in next 20 epochs", hint)
+		} else {
+			appendCheck(CheckStatusMessageBaseFeeLowerBound, true, "", hint)
+		}
+
+		// 10. Base Fee upper bound, only a warning
+		hint = map[string]interface{}{"baseFeeUpperBound": baseFeeUpperBound, "baseFee": baseFee}
+		if m.GasFeeCap.LessThan(baseFeeUpperBound) {
+			appendCheck(CheckStatusMessageBaseFeeUpperBound, true, "GasFeeCap less than base fee upper bound for inclusion in next 20 epochs", hint)
+		} else {
+			appendCheck(CheckStatusMessageBaseFeeUpperBound, true, "", hint)
+		}
+
+		// stateful checks
+		checkState()
+	}
+
+	return result, nil
+}
+
+func verifySignature(sm *types.SignedMessage) error {
+	c, err := sm.Message.Cid()
+	if err != nil {
+		return err
+	}
+	return sigs.Verify(&sm.Signature, sm.Message.From, c.Bytes())
+}
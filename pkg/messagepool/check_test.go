@@ -0,0 +1,137 @@
+package messagepool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	tbig "github.com/filecoin-project/go-state-types/big"
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+
+	"github.com/filecoin-project/venus/pkg/messagepool/gasguess"
+	"github.com/filecoin-project/venus/pkg/repo"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/wallet"
+)
+
+func failedChecks(statuses []MessageCheckStatus) map[CheckStatusCode]MessageCheckStatus {
+	failed := make(map[CheckStatusCode]MessageCheckStatus)
+	for _, s := range statuses {
+		if !s.OK {
+			failed[s.Code] = s
+		}
+	}
+	return failed
+}
+
+func TestCheckMessages(t *testing.T) {
+	tf.UnitTest(t)
+
+	mp, tma := makeTestMpool()
+
+	r := repo.NewInMemoryRepo()
+	backend, err := wallet.NewDSBackend(r.WalletDatastore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := wallet.New(backend)
+
+	a1, err := wallet.NewAddress(w, address.SECP256K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := mkAddress(1001)
+	tma.setBalance(a1, 1) // in FIL
+
+	gasLimit := gasguess.Costs[gasguess.CostKey{Code: builtin2.StorageMarketActorCodeID, M: 2}]
+	for i := 0; i < 3; i++ {
+		if _, err := mp.Push(makeTestMessage(w, a1, target, uint64(i), gasLimit, 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the pending messages pass every check
+	pending, err := mp.CheckPendingMessages(context.Background(), a1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 3 {
+		t.Fatalf("expected checks of 3 messages, got %d", len(pending))
+	}
+	for i, statuses := range pending {
+		if failed := failedChecks(statuses); len(failed) > 0 {
+			t.Fatalf("expected pending message %d to pass every check, failed %v", i, failed)
+		}
+	}
+
+	next := makeTestMessage(w, a1, target, 3, gasLimit, 1).Message
+	gapped := next
+	// the next nonce is 4 once `next` is checked
+	gapped.Nonce = 4 + MaxNonceGap + 1
+	lowFeeCap := next
+	lowFeeCap.GasFeeCap = tbig.NewInt(10)
+	lowFeeCap.GasPremium = tbig.NewInt(1)
+	expensive := next
+	expensive.Value = tbig.Mul(tbig.NewInt(2), tma.balance[a1])
+
+	checks, err := mp.CheckMessages(context.Background(), []*MessagePrototype{
+		{Message: next, ValidNonce: true},
+		{Message: gapped, ValidNonce: true},
+		{Message: lowFeeCap},
+		{Message: expensive},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 4 {
+		t.Fatalf("expected checks of 4 messages, got %d", len(checks))
+	}
+
+	if failed := failedChecks(checks[0]); len(failed) > 0 {
+		t.Fatalf("expected next message to pass every check, failed %v", failed)
+	}
+
+	failed := failedChecks(checks[1])
+	if _, ok := failed[CheckStatusMessageNonce]; !ok || len(failed) != 1 {
+		t.Fatalf("expected the nonce check of the gapped message to fail, failed %v", failed)
+	}
+
+	failed = failedChecks(checks[2])
+	if _, ok := failed[CheckStatusMessageMinBaseFee]; !ok || len(failed) != 1 {
+		t.Fatalf("expected the min base fee check of the low fee cap message to fail, failed %v", failed)
+	}
+
+	failed = failedChecks(checks[3])
+	balance, ok := failed[CheckStatusMessageBalance]
+	if !ok || len(failed) != 1 {
+		t.Fatalf("expected the balance check of the expensive message to fail, failed %v", failed)
+	}
+	if _, ok := balance.Hint["requiredFunds"]; !ok {
+		t.Fatal("expected the required funds in the hint of the balance check")
+	}
+
+	// a replacement with a too low fee cap
+	replace := makeTestMessage(w, a1, target, 1, gasLimit, 1).Message
+	replace.GasFeeCap = tbig.NewInt(10)
+	replace.GasPremium = tbig.NewInt(1)
+	checks, err = mp.CheckReplaceMessages(context.Background(), []*types.UnsignedMessage{&replace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 3 {
+		t.Fatalf("expected checks of 3 messages, got %d", len(checks))
+	}
+	for i, statuses := range checks {
+		failed := failedChecks(statuses)
+		if i == 1 {
+			if _, ok := failed[CheckStatusMessageMinBaseFee]; !ok {
+				t.Fatalf("expected the min base fee check of the replacement to fail, failed %v", failed)
+			}
+			continue
+		}
+		if len(failed) > 0 {
+			t.Fatalf("expected message %d to pass every check, failed %v", i, failed)
+		}
+	}
+}
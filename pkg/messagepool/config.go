@@ -18,6 +18,14 @@ var (
 	PruneCooldownDefault      = time.Minute
 	GasLimitOverestimation    = 1.25
 
+	// the pending limit of a sender is the one of the untrusted senders of lotus, the other
+	// admission limits are disabled by default
+	MaxSenderPendingDefault     = 1000
+	MaxPeerPendingDefault       = 0
+	MaxAdmittedPerWindowDefault = 0
+	MaxInvalidPerWindowDefault  = 0
+	AdmissionWindowDefault      = time.Minute
+
 	ConfigKey = datastore.NewKey("/mpool/config")
 )
 
@@ -28,6 +36,21 @@ type MpoolConfig struct {
 	ReplaceByFeeRatio      float64
 	PruneCooldown          time.Duration
 	GasLimitOverestimation float64
+
+	// Admission limits of the messages received from the network or pushed untrusted,
+	// a zero value disables the limit.
+
+	// MaxSenderPending is the maximum number of pending messages of a sender
+	MaxSenderPending int
+	// MaxPeerPending is the maximum number of pending messages forwarded by a single peer
+	MaxPeerPending int
+	// MaxAdmittedPerWindow is the maximum number of messages admitted from a single peer
+	// or sender in an admission window
+	MaxAdmittedPerWindow int
+	// MaxInvalidPerWindow is the number of invalid messages a peer may forward in an
+	// admission window before its messages are rejected until the end of the window
+	MaxInvalidPerWindow int
+	AdmissionWindow     time.Duration
 }
 
 func (mc *MpoolConfig) Clone() *MpoolConfig {
@@ -77,6 +100,12 @@ func validateConfg(cfg *MpoolConfig) error {
 	if cfg.GasLimitOverestimation < 1 {
 		return fmt.Errorf("'GasLimitOverestimation' cannot be less than 1")
 	}
+	if cfg.MaxSenderPending < 0 || cfg.MaxPeerPending < 0 || cfg.MaxAdmittedPerWindow < 0 || cfg.MaxInvalidPerWindow < 0 {
+		return fmt.Errorf("admission limits cannot be negative")
+	}
+	if cfg.AdmissionWindow < 0 {
+		return fmt.Errorf("'AdmissionWindow' cannot be negative")
+	}
 	return nil
 }
 
@@ -104,5 +133,10 @@ func DefaultConfig() *MpoolConfig {
 		ReplaceByFeeRatio:      ReplaceByFeeRatioDefault,
 		PruneCooldown:          PruneCooldownDefault,
 		GasLimitOverestimation: GasLimitOverestimation,
+		MaxSenderPending:       MaxSenderPendingDefault,
+		MaxPeerPending:         MaxPeerPendingDefault,
+		MaxAdmittedPerWindow:   MaxAdmittedPerWindowDefault,
+		MaxInvalidPerWindow:    MaxInvalidPerWindowDefault,
+		AdmissionWindow:        AdmissionWindowDefault,
 	}
 }
@@ -0,0 +1,260 @@
+package messagepool
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	tbig "github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// ErrFeeBumpOverBudget is the error journaled when the replacement of a stuck message would
+// pay more than the max fee of its sender.
+var ErrFeeBumpOverBudget = errors.New("replacement exceeds the max fee of the sender")
+
+// MsgSigner signs a message with the key of its sender.
+type MsgSigner func(ctx context.Context, msg *types.UnsignedMessage) (*types.SignedMessage, error)
+
+// pendingSince is when a pending local message was first seen by the fee bumper.
+type pendingSince struct {
+	epoch   abi.ChainEpoch
+	baseFee tbig.Int
+	// overBudget is set once the message couldn't be replaced within the budget of its sender
+	overBudget bool
+}
+
+// FeeBumper replaces the local messages that stay pending for StuckEpochs epochs while the
+// base fee rises with messages paying a higher gas premium and fee cap, without the pending
+// messages of a sender exceeding the max fee configured for it. Every replacement is journaled.
+type FeeBumper struct {
+	mp   *MessagePool
+	sign MsgSigner
+
+	stuckEpochs   abi.ChainEpoch
+	defaultMaxFee abi.TokenAmount
+	maxFees       map[address.Address]abi.TokenAmount
+
+	lk      sync.Mutex
+	pending map[cid.Cid]*pendingSince
+
+	trigger chan struct{}
+}
+
+func newFeeBumper(mp *MessagePool, cfg *config.FeeBumpConfig, sign MsgSigner) (*FeeBumper, error) {
+	if cfg.StuckEpochs <= 0 {
+		return nil, xerrors.Errorf("invalid stuck epochs %d", cfg.StuckEpochs)
+	}
+
+	defaultMaxFee, err := types.ParseFIL(cfg.DefaultMaxFee)
+	if err != nil {
+		return nil, xerrors.Errorf("parsing default max fee %q: %v", cfg.DefaultMaxFee, err)
+	}
+
+	maxFees := make(map[address.Address]abi.TokenAmount, len(cfg.MaxFees))
+	for addrStr, feeStr := range cfg.MaxFees {
+		addr, err := address.NewFromString(addrStr)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing max fee address %q: %v", addrStr, err)
+		}
+		fee, err := types.ParseFIL(feeStr)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing max fee of %s %q: %v", addr, feeStr, err)
+		}
+		maxFees[addr] = abi.TokenAmount(fee)
+	}
+
+	return &FeeBumper{
+		mp:            mp,
+		sign:          sign,
+		stuckEpochs:   cfg.StuckEpochs,
+		defaultMaxFee: abi.TokenAmount(defaultMaxFee),
+		maxFees:       maxFees,
+		pending:       make(map[cid.Cid]*pendingSince),
+		trigger:       make(chan struct{}, 1),
+	}, nil
+}
+
+// EnableFeeBump starts replacing the stuck local messages of the pool as configured by `cfg`,
+// signing the replacements with `sign`. It checks for stuck messages on every head change.
+func (mp *MessagePool) EnableFeeBump(cfg *config.FeeBumpConfig, sign MsgSigner) error {
+	fb, err := newFeeBumper(mp, cfg, sign)
+	if err != nil {
+		return xerrors.Errorf("invalid fee bump config: %v", err)
+	}
+
+	mp.curTsLk.Lock()
+	if mp.feeBumper != nil {
+		mp.curTsLk.Unlock()
+		return xerrors.New("fee bump is already enabled")
+	}
+	mp.feeBumper = fb
+	mp.curTsLk.Unlock()
+
+	go fb.run()
+	return nil
+}
+
+func (fb *FeeBumper) headChange() {
+	select {
+	case fb.trigger <- struct{}{}:
+	default:
+	}
+}
+
+func (fb *FeeBumper) run() {
+	for {
+		select {
+		case <-fb.trigger:
+			if err := fb.bumpStuckMessages(context.TODO()); err != nil {
+				log.Errorf("error bumping the fees of stuck messages: %s", err)
+			}
+		case <-fb.mp.closer:
+			return
+		}
+	}
+}
+
+// bumpStuckMessages tracks the pending local messages and replaces those that are stuck.
+func (fb *FeeBumper) bumpStuckMessages(ctx context.Context) error {
+	fb.mp.curTsLk.Lock()
+	ts := fb.mp.curTs
+	height, err := ts.Height()
+	if err != nil {
+		fb.mp.curTsLk.Unlock()
+		return err
+	}
+	baseFee, err := fb.mp.api.ChainComputeBaseFee(ctx, ts)
+	if err != nil {
+		fb.mp.curTsLk.Unlock()
+		return xerrors.Errorf("computing basefee: %v", err)
+	}
+
+	var msgs []*types.SignedMessage
+	fb.mp.lk.Lock()
+	for actor := range fb.mp.localAddrs {
+		mset, ok := fb.mp.pending[actor]
+		if !ok {
+			continue
+		}
+		for _, m := range mset.msgs {
+			msgs = append(msgs, m)
+		}
+	}
+	fb.mp.lk.Unlock()
+	fb.mp.curTsLk.Unlock()
+
+	fb.lk.Lock()
+	defer fb.lk.Unlock()
+
+	// forget the messages that were included or replaced
+	pending := make(map[cid.Cid]*pendingSince, len(msgs))
+	// committed is the max fee of the pending messages of every sender
+	committed := make(map[address.Address]abi.TokenAmount)
+	var stuck []*types.SignedMessage
+	for _, m := range msgs {
+		if fee, ok := committed[m.Message.From]; ok {
+			committed[m.Message.From] = tbig.Add(fee, m.Message.RequiredFunds())
+		} else {
+			committed[m.Message.From] = m.Message.RequiredFunds()
+		}
+
+		c, err := m.Cid()
+		if err != nil {
+			return err
+		}
+		since, ok := fb.pending[c]
+		if !ok {
+			since = &pendingSince{epoch: height, baseFee: baseFee}
+		}
+		pending[c] = since
+
+		if !since.overBudget && height-since.epoch >= fb.stuckEpochs && baseFee.GreaterThan(since.baseFee) {
+			stuck = append(stuck, m)
+		}
+	}
+	fb.pending = pending
+
+	for _, m := range stuck {
+		c, _ := m.Cid()
+		// the budget left to the sender once the replaced message is no longer pending
+		budget := tbig.Sub(fb.maxFee(m.Message.From), tbig.Sub(committed[m.Message.From], m.Message.RequiredFunds()))
+		replacement, err := fb.replace(ctx, m, baseFee, budget)
+		fb.recordBump(m, replacement, err)
+		if err != nil {
+			if xerrors.Is(err, ErrFeeBumpOverBudget) {
+				fb.pending[c].overBudget = true
+			}
+			log.Warnf("failed to bump the fee of message %s (from %s, nonce %d): %s", c, m.Message.From, m.Message.Nonce, err)
+			continue
+		}
+
+		rc, _ := replacement.Cid()
+		log.Infof("replaced stuck message %s with %s (from %s, nonce %d, premium %s, fee cap %s)",
+			c, rc, m.Message.From, m.Message.Nonce, replacement.Message.GasPremium, replacement.Message.GasFeeCap)
+		// the replacement waits for stuck epochs again before being bumped
+		fb.pending[rc] = &pendingSince{epoch: height, baseFee: baseFee}
+		committed[m.Message.From] = tbig.Add(tbig.Sub(committed[m.Message.From], m.Message.RequiredFunds()), replacement.Message.RequiredFunds())
+	}
+	return nil
+}
+
+// replace signs and pushes the replacement of `m` with the min RBF premium and a fee cap
+// covering twice the current base fee. The replacement may pay at most `budget`, the error
+// is ErrFeeBumpOverBudget otherwise.
+func (fb *FeeBumper) replace(ctx context.Context, m *types.SignedMessage, baseFee tbig.Int, budget abi.TokenAmount) (*types.SignedMessage, error) {
+	msg := m.Message
+	msg.GasPremium = ComputeMinRBF(m.Message.GasPremium)
+
+	feeCap := tbig.Add(tbig.Mul(baseFee, tbig.NewInt(2)), msg.GasPremium)
+	if feeCap.LessThan(msg.GasFeeCap) {
+		feeCap = msg.GasFeeCap
+	}
+	msg.GasFeeCap = feeCap
+
+	if fee := msg.RequiredFunds(); fee.GreaterThan(budget) {
+		return nil, xerrors.Errorf("replacement max fee %s over the budget left to %s %s: %w",
+			types.FIL(fee), msg.From, types.FIL(budget), ErrFeeBumpOverBudget)
+	}
+
+	smsg, err := fb.sign(ctx, &msg)
+	if err != nil {
+		return nil, xerrors.Errorf("signing replacement: %v", err)
+	}
+	if _, err := fb.mp.Push(smsg); err != nil {
+		return nil, xerrors.Errorf("pushing replacement: %w", err)
+	}
+	return smsg, nil
+}
+
+// maxFee returns the max fee the pending messages of `addr` may pay.
+func (fb *FeeBumper) maxFee(addr address.Address) abi.TokenAmount {
+	if maxFee, ok := fb.maxFees[addr]; ok {
+		return maxFee
+	}
+	return fb.defaultMaxFee
+}
+
+// recordBump journals the replacement of `old`, the replaced message followed by its replacement
+// when it succeeded.
+func (fb *FeeBumper) recordBump(old, replacement *types.SignedMessage, err error) {
+	fb.mp.journal.RecordEvent(fb.mp.evtTypes[evtTypeMpoolBump], func() interface{} {
+		oc, _ := old.Cid()
+		msgs := []MessagePoolEvtMessage{{UnsignedMessage: old.Message, CID: oc}}
+		if replacement != nil {
+			rc, _ := replacement.Cid()
+			msgs = append(msgs, MessagePoolEvtMessage{UnsignedMessage: replacement.Message, CID: rc})
+		}
+		return MessagePoolEvt{
+			Action:   "bump",
+			Messages: msgs,
+			Error:    err,
+		}
+	})
+}
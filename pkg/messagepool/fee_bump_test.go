@@ -0,0 +1,167 @@
+package messagepool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	tbig "github.com/filecoin-project/go-state-types/big"
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/messagepool/gasguess"
+	"github.com/filecoin-project/venus/pkg/repo"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/wallet"
+)
+
+func TestFeeBumpStuckMessages(t *testing.T) {
+	tf.UnitTest(t)
+
+	mp, tma := makeTestMpool()
+
+	r := repo.NewInMemoryRepo()
+	backend, err := wallet.NewDSBackend(r.WalletDatastore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := wallet.New(backend)
+
+	a1, err := wallet.NewAddress(w, address.SECP256K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a2, err := wallet.NewAddress(w, address.SECP256K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a3, err := wallet.NewAddress(w, address.SECP256K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := mkAddress(1001)
+	tma.setBalance(a1, 1) // in FIL
+	tma.setBalance(a2, 1) // in FIL
+	tma.setBalance(a3, 1) // in FIL
+
+	sign := func(ctx context.Context, msg *types.UnsignedMessage) (*types.SignedMessage, error) {
+		c, err := msg.Cid()
+		if err != nil {
+			return nil, err
+		}
+		sig, err := w.WalletSign(ctx, msg.From, c.Bytes(), wallet.MsgMeta{})
+		if err != nil {
+			return nil, err
+		}
+		return &types.SignedMessage{Message: *msg, Signature: *sig}, nil
+	}
+
+	gasLimit := gasguess.Costs[gasguess.CostKey{Code: builtin2.StorageMarketActorCodeID, M: 2}]
+	m1 := makeTestMessage(w, a1, target, 0, gasLimit, 1)
+	m2 := makeTestMessage(w, a2, target, 0, gasLimit, 1)
+	m3 := makeTestMessage(w, a3, target, 0, gasLimit, 1)
+	m4 := makeTestMessage(w, a3, target, 1, gasLimit, 1)
+
+	// the budget of a3 covers the replacement of only one of its messages, at a base fee of 200
+	premium := ComputeMinRBF(m3.Message.GasPremium)
+	replacementFee := tbig.Mul(tbig.Add(tbig.NewInt(400), premium), tbig.NewInt(gasLimit))
+	a3Budget := tbig.Add(replacementFee, m4.Message.RequiredFunds())
+
+	fb, err := newFeeBumper(mp, &config.FeeBumpConfig{
+		StuckEpochs:   2,
+		DefaultMaxFee: "0.007",
+		MaxFees: map[string]string{
+			// a2 can't afford any replacement
+			a2.String(): "1attofil",
+			a3.String(): a3Budget.String() + "attofil",
+		},
+	}, sign)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range []*types.SignedMessage{m1, m2, m3, m4} {
+		if _, err := mp.Push(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assertPending := func(addr address.Address, expected *types.SignedMessage) *types.SignedMessage {
+		t.Helper()
+		pending, _ := mp.PendingFor(addr)
+		if len(pending) != 1 {
+			t.Fatalf("expected 1 pending message for %s, got %d", addr, len(pending))
+		}
+		if expected != nil {
+			ec, _ := expected.Cid()
+			pc, _ := pending[0].Cid()
+			if !ec.Equals(pc) {
+				t.Fatalf("expected pending message %s, got %s", ec, pc)
+			}
+		}
+		return pending[0]
+	}
+
+	// start tracking the messages
+	if err := fb.bumpStuckMessages(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// stuck, but the base fee didn't rise
+	tma.applyBlock(t, tma.nextBlockWithHeight(2))
+	if err := fb.bumpStuckMessages(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertPending(a1, m1)
+	assertPending(a2, m2)
+
+	tma.baseFee = tbig.NewInt(200)
+	if err := fb.bumpStuckMessages(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	replacement := assertPending(a1, nil)
+	if replacement.Message.Nonce != m1.Message.Nonce {
+		t.Fatalf("expected replacement nonce %d, got %d", m1.Message.Nonce, replacement.Message.Nonce)
+	}
+	if !replacement.Message.GasPremium.Equals(ComputeMinRBF(m1.Message.GasPremium)) {
+		t.Fatalf("expected replacement premium %s, got %s", ComputeMinRBF(m1.Message.GasPremium), replacement.Message.GasPremium)
+	}
+	if !replacement.Message.GasFeeCap.Equals(tbig.Add(tbig.NewInt(400), replacement.Message.GasPremium)) {
+		t.Fatalf("unexpected replacement fee cap %s", replacement.Message.GasFeeCap)
+	}
+
+	// over the budget of a2
+	assertPending(a2, m2)
+	c2, _ := m2.Cid()
+	if !fb.pending[c2].overBudget {
+		t.Fatal("expected message of a2 to be over budget")
+	}
+
+	// the replacement of a message of a3 leaves no budget for the other one
+	pending, _ := mp.PendingFor(a3)
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 pending messages for a3, got %d", len(pending))
+	}
+	var replaced, overBudget int
+	for _, m := range pending {
+		if m.Message.GasPremium.Equals(premium) {
+			replaced++
+			continue
+		}
+		c, _ := m.Cid()
+		if fb.pending[c].overBudget {
+			overBudget++
+		}
+	}
+	if replaced != 1 || overBudget != 1 {
+		t.Fatalf("expected 1 replaced and 1 over budget message of a3, got %d and %d", replaced, overBudget)
+	}
+
+	// the replacement waits for stuck epochs again
+	if err := fb.bumpStuckMessages(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertPending(a1, replacement)
+}
@@ -0,0 +1,263 @@
+package messagepool
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	tbig "github.com/filecoin-project/go-state-types/big"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/specactors/policy"
+)
+
+// DefaultInclusionProbability is the probability of inclusion targeted by the inclusion estimates
+// when the send spec of a message doesn't set one.
+const DefaultInclusionProbability = 0.9
+
+// InclusionEstimateLookback is the number of tipsets of fee history the inclusion estimates are based on,
+// at least twice the number of epochs the inclusion is targeted within.
+var InclusionEstimateLookback = 100
+
+// clearingPercentile is the percentile of the gas premiums included in a congested tipset that
+// a message must pay to be included in it.
+const clearingPercentile = 5
+
+// FeeHistory is the history of the base fee and of the gas premiums of the messages included in
+// a range of tipsets, from the oldest. Null rounds are skipped.
+type FeeHistory struct {
+	// Epochs are the heights of the tipsets
+	Epochs []abi.ChainEpoch
+	// BaseFees are the base fees paid by the messages included in the tipsets, followed by the base fee
+	// of the messages to be included on top of the newest tipset
+	BaseFees []abi.TokenAmount
+	// GasTargetRatios are the ratios of the gas limit of the messages included in the tipsets to the gas
+	// target of their blocks, the base fee rises above 1
+	GasTargetRatios []float64
+	// Premiums are the effective gas premiums of the messages included in the tipsets at the requested
+	// percentiles, weighted by gas limit
+	Premiums [][]abi.TokenAmount
+}
+
+// FeeHistory returns the fee history of the `blocks` tipsets up to the tipset `tsk`, the heaviest
+// tipset if empty. `percentiles` are ascending percentiles, between 0 and 100, of the gas premiums
+// to return for each tipset.
+func (mp *MessagePool) FeeHistory(ctx context.Context, blocks int, percentiles []float64, tsk block.TipSetKey) (*FeeHistory, error) {
+	if blocks <= 0 {
+		return nil, xerrors.Errorf("invalid number of blocks %d", blocks)
+	}
+	for i, p := range percentiles {
+		if p < 0 || p > 100 {
+			return nil, xerrors.Errorf("invalid percentile %f", p)
+		}
+		if i > 0 && p < percentiles[i-1] {
+			return nil, xerrors.Errorf("percentiles are not ascending")
+		}
+	}
+
+	var ts *block.TipSet
+	var err error
+	if tsk.IsEmpty() {
+		ts, err = mp.api.ChainHead()
+	} else {
+		ts, err = mp.api.LoadTipSet(tsk)
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %v", tsk, err)
+	}
+
+	nextBaseFee, err := mp.api.ChainComputeBaseFee(ctx, ts)
+	if err != nil {
+		return nil, xerrors.Errorf("computing base fee: %v", err)
+	}
+
+	tipsets := make([]*block.TipSet, 0, blocks)
+	for len(tipsets) < blocks {
+		h, err := ts.Height()
+		if err != nil {
+			return nil, err
+		}
+		if h == 0 {
+			break // genesis
+		}
+		tipsets = append(tipsets, ts)
+
+		tsPKey, err := ts.Parents()
+		if err != nil {
+			return nil, err
+		}
+		ts, err = mp.api.LoadTipSet(tsPKey)
+		if err != nil {
+			return nil, xerrors.Errorf("loading parent tipset: %v", err)
+		}
+	}
+
+	history := &FeeHistory{
+		Epochs:          make([]abi.ChainEpoch, len(tipsets)),
+		BaseFees:        make([]abi.TokenAmount, len(tipsets)+1),
+		GasTargetRatios: make([]float64, len(tipsets)),
+		Premiums:        make([][]abi.TokenAmount, len(tipsets)),
+	}
+	history.BaseFees[len(tipsets)] = nextBaseFee
+
+	for i, ts := range tipsets {
+		// from the oldest
+		idx := len(tipsets) - 1 - i
+
+		h, err := ts.Height()
+		if err != nil {
+			return nil, err
+		}
+		baseFee := ts.Blocks()[0].ParentBaseFee
+
+		msgs, err := mp.api.MessagesForTipset(ts)
+		if err != nil {
+			return nil, xerrors.Errorf("loading messages: %w", err)
+		}
+
+		var gasLimit int64
+		prices := make([]gasMeta, 0, len(msgs))
+		for _, msg := range msgs {
+			m := msg.VMMessage()
+			// the premium paid is capped by the fee cap
+			premium := tbig.Min(m.GasPremium, tbig.Sub(m.GasFeeCap, baseFee))
+			if premium.Sign() < 0 {
+				premium = tbig.Zero()
+			}
+			prices = append(prices, gasMeta{price: premium, limit: m.GasLimit})
+			gasLimit += m.GasLimit
+		}
+
+		history.Epochs[idx] = h
+		history.BaseFees[idx] = baseFee
+		history.GasTargetRatios[idx] = float64(gasLimit) / float64(constants.BlockGasTarget*int64(len(ts.Blocks())))
+		history.Premiums[idx] = premiumPercentiles(prices, percentiles)
+	}
+
+	return history, nil
+}
+
+// premiumPercentiles returns the gas premiums of `prices` at the ascending `percentiles`, weighted by gas limit.
+func premiumPercentiles(prices []gasMeta, percentiles []float64) []abi.TokenAmount {
+	out := make([]abi.TokenAmount, len(percentiles))
+	if len(prices) == 0 {
+		for i := range out {
+			out[i] = tbig.Zero()
+		}
+		return out
+	}
+
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].price.LessThan(prices[j].price)
+	})
+
+	var total int64
+	for _, price := range prices {
+		total += price.limit
+	}
+
+	idx, cumulated := 0, prices[0].limit
+	for i, p := range percentiles {
+		threshold := int64(float64(total) * p / 100)
+		for cumulated < threshold && idx < len(prices)-1 {
+			idx++
+			cumulated += prices[idx].limit
+		}
+		out[i] = prices[idx].price
+	}
+	return out
+}
+
+// GasEstimateInclusionFees estimates the gas premium and the fee cap for a message to be included
+// on top of the tipset `tsk`, the heaviest tipset if empty, within `epochs` epochs, at most the chain
+// finality, with probability `probability`, based on the fee history.
+//
+// A message is assumed to be included in a tipset filled under the gas target whatever its premium,
+// and in a congested tipset if it pays at least a low percentile of the premiums included in it. The
+// fee cap covers the growth of the base fee over `epochs` epochs seen in the history with the same probability.
+func (mp *MessagePool) GasEstimateInclusionFees(ctx context.Context, epochs abi.ChainEpoch, probability float64, tsk block.TipSetKey) (tbig.Int, tbig.Int, error) {
+	if epochs <= 0 || epochs > policy.ChainFinality {
+		return tbig.Int{}, tbig.Int{}, xerrors.Errorf("invalid number of inclusion epochs %d, expected between 1 and %d", epochs, policy.ChainFinality)
+	}
+	if probability <= 0 || probability > 1 {
+		return tbig.Int{}, tbig.Int{}, xerrors.Errorf("invalid inclusion probability %f", probability)
+	}
+
+	lookback := InclusionEstimateLookback
+	if lookback < 2*int(epochs) {
+		lookback = 2 * int(epochs)
+	}
+	history, err := mp.FeeHistory(ctx, lookback, []float64{clearingPercentile}, tsk)
+	if err != nil {
+		return tbig.Int{}, tbig.Int{}, xerrors.Errorf("getting fee history: %w", err)
+	}
+
+	// the probability of inclusion in each of the epochs
+	perEpoch := 1 - math.Pow(1-probability, 1/float64(epochs))
+
+	premium := tbig.NewInt(MinGasPremium)
+	if n := len(history.Epochs); n > 0 {
+		clearing := make([]tbig.Int, n)
+		for i := range clearing {
+			clearing[i] = tbig.Zero()
+			if history.GasTargetRatios[i] >= 1 {
+				clearing[i] = history.Premiums[i][0]
+			}
+		}
+		if p := quantile(clearing, perEpoch); p.GreaterThan(premium) {
+			premium = p
+		}
+	}
+
+	// growth factors of the base fee over the windows of `epochs` epochs, in 1/256
+	const precision = 1 << 8
+	growth := maxBaseFeeGrowth(epochs, precision)
+	var growths []tbig.Int
+	for i := 0; i+int(epochs) < len(history.BaseFees); i++ {
+		if history.BaseFees[i].Sign() <= 0 {
+			continue
+		}
+		highest := history.BaseFees[i]
+		for _, baseFee := range history.BaseFees[i+1 : i+int(epochs)+1] {
+			highest = tbig.Max(highest, baseFee)
+		}
+		growths = append(growths, tbig.Div(tbig.Mul(highest, tbig.NewInt(precision)), history.BaseFees[i]))
+	}
+	if len(growths) > 0 {
+		growth = tbig.Min(growth, quantile(growths, probability))
+	}
+
+	nextBaseFee := history.BaseFees[len(history.BaseFees)-1]
+	feeCap := tbig.Add(tbig.Div(tbig.Mul(nextBaseFee, growth), tbig.NewInt(precision)), premium)
+	return premium, feeCap, nil
+}
+
+// maxBaseFeeGrowth returns the growth factor, in 1/`precision`, of the base fee rising as fast as it
+// can for `epochs` epochs, that is (1 + 1/BaseFeeMaxChangeDenom)^epochs.
+func maxBaseFeeGrowth(epochs abi.ChainEpoch, precision int64) tbig.Int {
+	num, denom := tbig.NewInt(precision), tbig.NewInt(1)
+	for i := abi.ChainEpoch(0); i < epochs; i++ {
+		num = tbig.Mul(num, tbig.NewInt(constants.BaseFeeMaxChangeDenom+1))
+		denom = tbig.Mul(denom, tbig.NewInt(constants.BaseFeeMaxChangeDenom))
+	}
+	return tbig.Div(num, denom)
+}
+
+// quantile returns the value of `values` at the quantile `q`, between 0 and 1.
+func quantile(values []tbig.Int, q float64) tbig.Int {
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].LessThan(values[j])
+	})
+
+	idx := int(math.Ceil(q*float64(len(values)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(values) {
+		idx = len(values) - 1
+	}
+	return values[idx]
+}
@@ -0,0 +1,130 @@
+package messagepool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	tbig "github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/repo"
+	"github.com/filecoin-project/venus/pkg/specactors/policy"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/wallet"
+)
+
+func TestFeeHistory(t *testing.T) {
+	tf.UnitTest(t)
+
+	mp, tma := makeTestMpool()
+
+	r := repo.NewInMemoryRepo()
+	backend, err := wallet.NewDSBackend(r.WalletDatastore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := wallet.New(backend)
+
+	a1, err := wallet.NewAddress(w, address.SECP256K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := mkAddress(1001)
+
+	// every tipset is filled to the gas target by two messages paying premiums of p and 3p
+	gasLimit := int64(constants.BlockGasTarget / 2)
+	for i := 1; i <= 20; i++ {
+		p := uint64(i) * MinGasPremium
+		blk := tma.nextBlock()
+		tma.setBlockMessages(blk,
+			makeTestMessage(w, a1, target, uint64(2*i), gasLimit, p),
+			makeTestMessage(w, a1, target, uint64(2*i+1), gasLimit, 3*p),
+		)
+	}
+	head := tma.tipsets[len(tma.tipsets)-1]
+
+	history, err := mp.FeeHistory(context.Background(), 10, []float64{0, 50, 100}, head.Key())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Epochs) != 10 || len(history.BaseFees) != 11 || len(history.Premiums) != 10 {
+		t.Fatalf("expected history of 10 tipsets, got %d epochs, %d base fees and %d premiums",
+			len(history.Epochs), len(history.BaseFees), len(history.Premiums))
+	}
+	for i, epoch := range history.Epochs {
+		if epoch != abi.ChainEpoch(11+i) {
+			t.Fatalf("expected epoch %d, got %d", 11+i, epoch)
+		}
+		if history.GasTargetRatios[i] != 1 {
+			t.Fatalf("expected gas target ratio of 1, got %f", history.GasTargetRatios[i])
+		}
+		p := tbig.NewInt(int64(11+i) * MinGasPremium)
+		expected := []tbig.Int{p, p, tbig.Mul(p, tbig.NewInt(3))}
+		for j := range expected {
+			if !history.Premiums[i][j].Equals(expected[j]) {
+				t.Fatalf("expected premiums %v at epoch %d, got %v", expected, epoch, history.Premiums[i])
+			}
+		}
+	}
+	if !history.BaseFees[10].Equals(tma.baseFee) {
+		t.Fatalf("expected next base fee %s, got %s", tma.baseFee, history.BaseFees[10])
+	}
+
+	if _, err := mp.FeeHistory(context.Background(), 10, []float64{50, 10}, head.Key()); err == nil {
+		t.Fatal("expected error for descending percentiles")
+	}
+
+	// the history covers the 20 tipsets up to the genesis, their clearing premiums are i*MinGasPremium
+	premium, feeCap, err := mp.GasEstimateInclusionFees(context.Background(), 1, 0.5, head.Key())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := tbig.NewInt(10 * MinGasPremium); !premium.Equals(expected) {
+		t.Fatalf("expected premium %s, got %s", expected, premium)
+	}
+	// the base fee didn't change
+	if !feeCap.Equals(tbig.Add(tma.baseFee, premium)) {
+		t.Fatalf("expected fee cap %s, got %s", tbig.Add(tma.baseFee, premium), feeCap)
+	}
+
+	likely, _, err := mp.GasEstimateInclusionFees(context.Background(), 1, 0.99, head.Key())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := tbig.NewInt(20 * MinGasPremium); !likely.Equals(expected) {
+		t.Fatalf("expected premium %s, got %s", expected, likely)
+	}
+
+	// waiting for more epochs is cheaper
+	patient, _, err := mp.GasEstimateInclusionFees(context.Background(), 4, 0.5, head.Key())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := tbig.NewInt(4 * MinGasPremium); !patient.Equals(expected) {
+		t.Fatalf("expected premium %s, got %s", expected, patient)
+	}
+
+	if _, _, err := mp.GasEstimateInclusionFees(context.Background(), policy.ChainFinality+1, 0.5, head.Key()); err == nil {
+		t.Fatal("expected error for inclusion epochs over the chain finality")
+	}
+}
+
+func TestMaxBaseFeeGrowth(t *testing.T) {
+	tf.UnitTest(t)
+
+	// the base fee rises by 1/8 at most per epoch
+	for epochs, expected := range map[abi.ChainEpoch]int64{0: 256, 1: 288, 2: 324, 3: 364} {
+		if growth := maxBaseFeeGrowth(epochs, 256); !growth.Equals(tbig.NewInt(expected)) {
+			t.Fatalf("expected growth %d over %d epochs, got %s", expected, epochs, growth)
+		}
+	}
+
+	// the growth over the chain finality doesn't fit in an int64
+	prev := maxBaseFeeGrowth(policy.ChainFinality-1, 256)
+	growth := maxBaseFeeGrowth(policy.ChainFinality, 256)
+	if !growth.GreaterThan(prev) || growth.Int.IsInt64() {
+		t.Fatalf("unexpected growth %s over %d epochs", growth, policy.ChainFinality)
+	}
+}
@@ -167,11 +167,6 @@ func (mp *MessagePool) GasEstimateGasLimit(ctx context.Context, msgIn *types.Uns
 		return -1, xerrors.Errorf("getting tipset: %w", err)
 	}
 
-	msg := *msgIn
-	msg.GasLimit = constants.BlockGasLimit
-	msg.GasFeeCap = tbig.NewInt(int64(constants.MinimumBaseFee) + 1)
-	msg.GasPremium = tbig.NewInt(1)
-
 	fromA, err := mp.api.StateAccountKey(ctx, msgIn.From, currTs)
 	if err != nil {
 		return -1, xerrors.Errorf("getting key address: %w", err)
@@ -183,8 +178,19 @@ func (mp *MessagePool) GasEstimateGasLimit(ctx context.Context, msgIn *types.Uns
 		priorMsgs = append(priorMsgs, m)
 	}
 
+	return mp.gasEstimateGasLimit(ctx, msgIn, priorMsgs, ts)
+}
+
+// gasEstimateGasLimit estimates the gas used by `msgIn` applied after `priorMsgs` on top of `ts`.
+func (mp *MessagePool) gasEstimateGasLimit(ctx context.Context, msgIn *types.UnsignedMessage, priorMsgs []types.ChainMsg, ts *block.TipSet) (int64, error) {
+	msg := *msgIn
+	msg.GasLimit = constants.BlockGasLimit
+	msg.GasFeeCap = tbig.NewInt(int64(constants.MinimumBaseFee) + 1)
+	msg.GasPremium = tbig.NewInt(1)
+
 	// Try calling until we find a height with no migration.
 	var res *vm.Ret
+	var err error
 	for {
 		res, err = mp.gp.CallWithGas(ctx, &msg, priorMsgs, ts)
 		if err != fork.ErrExpensiveFork {
@@ -236,10 +242,35 @@ func (mp *MessagePool) GasEstimateMessageGas(ctx context.Context, msg *types.Uns
 		msg.GasLimit = int64(float64(gasLimit) * mp.GetConfig().GasLimitOverestimation)
 	}
 
+	if err := mp.gasEstimateFees(ctx, msg, spec); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// gasEstimateFees sets the gas premium and fee cap of `msg` left unset, capping its fee by the max fee of `spec`.
+func (mp *MessagePool) gasEstimateFees(ctx context.Context, msg *types.UnsignedMessage, spec *types.MessageSendSpec) error {
+	if spec != nil && spec.InclusionEpochs > 0 {
+		probability := spec.InclusionProbability
+		if probability == 0 {
+			probability = DefaultInclusionProbability
+		}
+		gasPremium, feeCap, err := mp.GasEstimateInclusionFees(ctx, spec.InclusionEpochs, probability, block.TipSetKey{})
+		if err != nil {
+			return xerrors.Errorf("estimating inclusion fees: %w", err)
+		}
+		if msg.GasPremium.Nil() || tbig.Cmp(msg.GasPremium, tbig.NewInt(0)) == 0 {
+			msg.GasPremium = gasPremium
+		}
+		if msg.GasFeeCap.Nil() || tbig.Cmp(msg.GasFeeCap, tbig.NewInt(0)) == 0 {
+			msg.GasFeeCap = feeCap
+		}
+	}
+
 	if msg.GasPremium.Nil() || tbig.Cmp(msg.GasPremium, tbig.NewInt(0)) == 0 {
 		gasPremium, err := mp.GasEstimateGasPremium(ctx, 10, msg.From, msg.GasLimit, block.TipSetKey{})
 		if err != nil {
-			return nil, xerrors.Errorf("estimating gas price: %w", err)
+			return xerrors.Errorf("estimating gas price: %w", err)
 		}
 		msg.GasPremium = gasPremium
 	}
@@ -247,7 +278,7 @@ func (mp *MessagePool) GasEstimateMessageGas(ctx context.Context, msg *types.Uns
 	if msg.GasFeeCap.Nil() || tbig.Cmp(msg.GasFeeCap, tbig.NewInt(0)) == 0 {
 		feeCap, err := mp.GasEstimateFeeCap(ctx, msg, 20, block.TipSetKey{})
 		if err != nil {
-			return nil, xerrors.Errorf("estimating fee cap: %w", err)
+			return xerrors.Errorf("estimating fee cap: %w", err)
 		}
 		msg.GasFeeCap = feeCap
 	}
@@ -258,5 +289,87 @@ func (mp *MessagePool) GasEstimateMessageGas(ctx context.Context, msg *types.Uns
 	}
 	CapGasFee(mp.GetMaxFee, msg, maxFee)
 
-	return msg, nil
+	return nil
+}
+
+// EstimateMessage is a message of a batch to estimate, with its send spec.
+type EstimateMessage struct {
+	Msg  *types.UnsignedMessage
+	Spec *types.MessageSendSpec
+}
+
+// EstimateResult is an estimated message of a batch, or the error of its estimation.
+type EstimateResult struct {
+	Msg *types.UnsignedMessage
+	Err string
+}
+
+// GasBatchEstimateMessageGas estimates the messages of a single sender to be sent in sequence with
+// consecutive nonces from `fromNonce`. Each message is estimated on top of the pending messages of the
+// sender and of the previous messages of the batch, so a message may use the effects of the previous ones.
+// A message failing its estimation doesn't take a nonce and isn't applied before the next messages.
+func (mp *MessagePool) GasBatchEstimateMessageGas(ctx context.Context, estimateMessages []*EstimateMessage, fromNonce uint64, tsk block.TipSetKey) ([]*EstimateResult, error) {
+	if len(estimateMessages) == 0 {
+		return nil, nil
+	}
+
+	if tsk.IsEmpty() {
+		ts, err := mp.api.ChainHead()
+		if err != nil {
+			return nil, xerrors.Errorf("getting head: %v", err)
+		}
+		tsk = ts.Key()
+	}
+	currTs, err := mp.api.ChainTipSet(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("getting tipset: %w", err)
+	}
+
+	fromA, err := mp.api.StateAccountKey(ctx, estimateMessages[0].Msg.From, currTs)
+	if err != nil {
+		return nil, xerrors.Errorf("getting key address: %w", err)
+	}
+	for _, em := range estimateMessages[1:] {
+		from, err := mp.api.StateAccountKey(ctx, em.Msg.From, currTs)
+		if err != nil {
+			return nil, xerrors.Errorf("getting key address: %w", err)
+		}
+		if from != fromA {
+			return nil, xerrors.Errorf("batch messages from different senders %s and %s", fromA, from)
+		}
+	}
+
+	pending, ts := mp.PendingFor(fromA)
+	priorMsgs := make([]types.ChainMsg, 0, len(pending)+len(estimateMessages))
+	for _, m := range pending {
+		// the batch replaces the pending messages from its first nonce
+		if m.Message.Nonce < fromNonce {
+			priorMsgs = append(priorMsgs, m)
+		}
+	}
+
+	results := make([]*EstimateResult, 0, len(estimateMessages))
+	for _, em := range estimateMessages {
+		msg := *em.Msg
+		msg.Nonce = fromNonce
+
+		if msg.GasLimit == 0 {
+			gasLimit, err := mp.gasEstimateGasLimit(ctx, &msg, priorMsgs, ts)
+			if err != nil {
+				results = append(results, &EstimateResult{Msg: &msg, Err: xerrors.Errorf("estimating gas used: %w", err).Error()})
+				continue
+			}
+			msg.GasLimit = int64(float64(gasLimit) * mp.GetConfig().GasLimitOverestimation)
+		}
+
+		if err := mp.gasEstimateFees(ctx, &msg, em.Spec); err != nil {
+			results = append(results, &EstimateResult{Msg: &msg, Err: err.Error()})
+			continue
+		}
+
+		priorMsgs = append(priorMsgs, &msg)
+		fromNonce++
+		results = append(results, &EstimateResult{Msg: &msg})
+	}
+	return results, nil
 }
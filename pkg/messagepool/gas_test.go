@@ -0,0 +1,115 @@
+package messagepool
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	tbig "github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/repo"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/vm"
+	"github.com/filecoin-project/venus/pkg/wallet"
+)
+
+// testGasPredictor uses 1000 gas per prior message and fails the messages to `failTo`
+// unless a prior message was sent to `dependsOn`.
+type testGasPredictor struct {
+	failTo    address.Address
+	dependsOn address.Address
+}
+
+func (gp *testGasPredictor) CallWithGas(_ context.Context, msg *types.UnsignedMessage, priorMsgs []types.ChainMsg, _ *block.TipSet) (*vm.Ret, error) {
+	ret := &vm.Ret{Receipt: types.MessageReceipt{ExitCode: exitcode.Ok, GasUsed: 1000 * int64(len(priorMsgs)+1)}}
+	if msg.To == gp.failTo {
+		ret.Receipt.ExitCode = exitcode.ErrNotFound
+		for _, m := range priorMsgs {
+			if m.VMMessage().To == gp.dependsOn {
+				ret.Receipt.ExitCode = exitcode.Ok
+			}
+		}
+	}
+	return ret, nil
+}
+
+type testActorProvider struct{}
+
+func (testActorProvider) GetActorAt(context.Context, *block.TipSet, address.Address) (*types.Actor, error) {
+	return nil, fmt.Errorf("actor not found")
+}
+
+func TestGasBatchEstimateMessageGas(t *testing.T) {
+	tf.UnitTest(t)
+
+	mp, _ := makeTestMpool()
+	create, use := mkAddress(1001), mkAddress(1002)
+	mp.gp = &testGasPredictor{failTo: use, dependsOn: create}
+	mp.ap = testActorProvider{}
+
+	r := repo.NewInMemoryRepo()
+	backend, err := wallet.NewDSBackend(r.WalletDatastore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := wallet.New(backend)
+	a1, err := wallet.NewAddress(w, address.SECP256K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a2, err := wallet.NewAddress(w, address.SECP256K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := &types.MessageSendSpec{MaxFee: types.NewAttoFILFromFIL(1)}
+	mkMsg := func(to address.Address) *EstimateMessage {
+		return &EstimateMessage{
+			Msg: &types.UnsignedMessage{
+				From:       a1,
+				To:         to,
+				Value:      tbig.Zero(),
+				GasFeeCap:  tbig.NewInt(1000),
+				GasPremium: tbig.NewInt(100),
+			},
+			Spec: spec,
+		}
+	}
+
+	tsk := mp.curTs.Key()
+	// using before creating fails
+	results, err := mp.GasBatchEstimateMessageGas(context.Background(), []*EstimateMessage{mkMsg(use), mkMsg(create), mkMsg(use)}, 5, tsk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[0].Err == "" {
+		t.Fatal("expected the estimation of the first message to fail")
+	}
+
+	// the failed message didn't take a nonce nor was applied before the next messages
+	overestimation := mp.GetConfig().GasLimitOverestimation
+	for i, res := range results[1:] {
+		if res.Err != "" {
+			t.Fatalf("expected the estimation of message %d to succeed, got %s", i+1, res.Err)
+		}
+		if res.Msg.Nonce != uint64(5+i) {
+			t.Fatalf("expected nonce %d, got %d", 5+i, res.Msg.Nonce)
+		}
+		if expected := int64(float64(1000*(i+1)) * overestimation); res.Msg.GasLimit != expected {
+			t.Fatalf("expected gas limit %d, got %d", expected, res.Msg.GasLimit)
+		}
+	}
+
+	other := mkMsg(create)
+	other.Msg.From = a2
+	if _, err := mp.GasBatchEstimateMessageGas(context.Background(), []*EstimateMessage{mkMsg(create), other}, 0, tsk); err == nil {
+		t.Fatal("expected error for a batch from different senders")
+	}
+}
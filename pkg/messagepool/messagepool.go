@@ -49,11 +49,15 @@ type MpoolChange int
 const (
 	MpoolAdd MpoolChange = iota
 	MpoolRemove
+	// MpoolReject is published when an untrusted message is rejected by the admission limits
+	MpoolReject
 )
 
 type MpoolUpdate struct {
 	Type    MpoolChange
 	Message *types.SignedMessage
+	// Error is the reason of the rejection of a message
+	Error string `json:",omitempty"`
 }
 
 var log = logging.Logger("messagepool")
@@ -106,6 +110,7 @@ const (
 	evtTypeMpoolAdd = iota
 	evtTypeMpoolRemove
 	evtTypeMpoolRepub
+	evtTypeMpoolBump
 )
 
 // MessagePoolEvt is the journal entry for message pool events.
@@ -184,7 +189,7 @@ type MessagePool struct {
 
 	sigValCache *lru.TwoQueueCache
 
-	evtTypes [3]journal.EventType
+	evtTypes [4]journal.EventType
 	journal  journal.Journal
 
 	gasPriceSchedule *gas.PricesSchedule
@@ -192,6 +197,11 @@ type MessagePool struct {
 	gp        gasPredictor
 	ap        actorProvider
 	GetMaxFee DefaultMaxFeeFunc
+
+	// feeBumper replaces stuck local messages when enabled, guarded by curTsLk
+	feeBumper *FeeBumper
+
+	admission *admission
 }
 
 type msgSet struct {
@@ -409,10 +419,12 @@ func New(api Provider, ds repo.Datastore, forkParams *config.ForkUpgradeConfig,
 		gp:            gp,
 		ap:            ap,
 		cfg:           cfg,
+		admission:     newAdmission(),
 		evtTypes: [...]journal.EventType{
 			evtTypeMpoolAdd:    j.RegisterEventType("mpool", "add"),
 			evtTypeMpoolRemove: j.RegisterEventType("mpool", "remove"),
 			evtTypeMpoolRepub:  j.RegisterEventType("mpool", "repub"),
+			evtTypeMpoolBump:   j.RegisterEventType("mpool", "bump"),
 		},
 		journal: j,
 
@@ -969,6 +981,7 @@ func (mp *MessagePool) getStateBalance(addr address.Address, ts *block.TipSet) (
 //  - strict checks are enabled
 //  - extra strict add checks are used when adding the messages to the msgSet
 //    that means: no nonce gaps, at most 10 pending messages for the actor
+//  - the admission limits of the pool are enforced for the sender
 func (mp *MessagePool) PushUntrusted(m *types.SignedMessage) (cid.Cid, error) {
 	err := mp.checkMessage(m)
 	if err != nil {
@@ -982,11 +995,17 @@ func (mp *MessagePool) PushUntrusted(m *types.SignedMessage) (cid.Cid, error) {
 	}()
 
 	mp.curTsLk.Lock()
+	cfg := mp.admissionConfig()
+	if err := mp.admit(cfg, m, ""); err != nil {
+		mp.curTsLk.Unlock()
+		return cid.Undef, err
+	}
 	publish, err := mp.addTs(m, mp.curTs, false, true)
 	if err != nil {
 		mp.curTsLk.Unlock()
 		return cid.Undef, err
 	}
+	mp.admission.admitted(cfg, m, "")
 	mp.curTsLk.Unlock()
 
 	if publish {
@@ -1037,6 +1056,7 @@ func (mp *MessagePool) remove(from address.Address, nonce uint64, applied bool)
 	// NB: This deletes any message with the given nonce. This makes sense
 	// as two messages with the same sender cannot have the same nonce
 	mset.rm(nonce, applied)
+	mp.admission.release(from, nonce)
 
 	if len(mset.msgs) == 0 {
 		delete(mp.pending, from)
@@ -1194,6 +1214,10 @@ func (mp *MessagePool) HeadChange(revert []*block.TipSet, apply []*block.TipSet)
 		}
 	}
 
+	if mp.feeBumper != nil {
+		mp.feeBumper.headChange()
+	}
+
 	for _, s := range rmsgs {
 		for _, msg := range s {
 			if err := mp.addSkipChecks(msg); err != nil {
@@ -1473,6 +1497,7 @@ func (mp *MessagePool) Clear(local bool) {
 
 		mp.pending = make(map[address.Address]*msgSet)
 		mp.republished = nil
+		mp.admission.clear()
 
 		return
 	}
@@ -1485,6 +1510,7 @@ func (mp *MessagePool) Clear(local bool) {
 		}
 		delete(mp.pending, a)
 	}
+	mp.admission.clear()
 }
 
 func getBaseFeeLowerBound(baseFee, factor tbig.Int) tbig.Int {
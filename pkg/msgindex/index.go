@@ -0,0 +1,567 @@
+package msgindex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	dsq "github.com/ipfs/go-datastore/query"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/repo"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+var log = logging.Logger("msgindex")
+
+// ErrNotIndexed is returned when a message is not in the index.
+var ErrNotIndexed = xerrors.New("message not indexed")
+
+var (
+	dsPrefix      = ds.NewKey("/msgindex")
+	msgsPrefix    = ds.NewKey("/msgs")
+	fromPrefix    = ds.NewKey("/from")
+	toPrefix      = ds.NewKey("/to")
+	tipsetsPrefix = ds.NewKey("/tipsets")
+	tailKey       = ds.NewKey("/tail")
+)
+
+// MsgInfo is the location of a message on chain along with its receipt.
+type MsgInfo struct {
+	Message cid.Cid
+	// TipSet is the tipset the message was executed in, the message is included in its parent
+	TipSet block.TipSetKey
+	Height abi.ChainEpoch
+	// Block is the first block including the message
+	Block cid.Cid
+	// Index is the position of the message in the messages executed in TipSet
+	Index   int
+	Receipt types.MessageReceipt
+}
+
+type chainReader interface {
+	GetHead() *block.TipSet
+	GetTipSet(block.TipSetKey) (*block.TipSet, error)
+	GetTipSetByHeight(context.Context, *block.TipSet, abi.ChainEpoch, bool) (*block.TipSet, error)
+	SubHeadChanges(context.Context) chan []*chain.HeadChange
+}
+
+type stateReader interface {
+	ResolveAddressAt(context.Context, *block.TipSet, address.Address) (address.Address, error)
+}
+
+type messageProvider interface {
+	LoadTipSetMessage(ctx context.Context, ts *block.TipSet) ([]block.BlockMessagesInfo, error)
+	LoadReceipts(context.Context, cid.Cid) ([]types.MessageReceipt, error)
+}
+
+// Index records where messages landed on chain, and the messages sent from and to every
+// address. It follows the head of the chain and undoes the reverted tipsets.
+type Index struct {
+	ds       ds.Batching
+	chain    chainReader
+	state    stateReader
+	messages messageProvider
+
+	// backfillEpochs limits the number of epochs indexed below the head on start, 0 for no limit
+	backfillEpochs abi.ChainEpoch
+
+	// lk serializes the updates of the head changes and of the backfill
+	lk sync.Mutex
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewIndex creates an index stored in the metadata datastore `dstore`.
+func NewIndex(dstore repo.Datastore, chainStore chainReader, chainState stateReader, messages messageProvider, backfillEpochs abi.ChainEpoch) *Index {
+	return &Index{
+		ds:             namespace.Wrap(dstore, dsPrefix),
+		chain:          chainStore,
+		state:          chainState,
+		messages:       messages,
+		backfillEpochs: backfillEpochs,
+	}
+}
+
+// Start follows the head changes, while indexing the tipsets between the last indexed one
+// and the head in the background.
+func (idx *Index) Start(ctx context.Context) {
+	ctx, idx.cancel = context.WithCancel(ctx)
+	changes := idx.chain.SubHeadChanges(ctx)
+
+	idx.wg.Add(1)
+	go func() {
+		defer idx.wg.Done()
+		for {
+			select {
+			case hcs, ok := <-changes:
+				if !ok {
+					return
+				}
+				if err := idx.processHeadChanges(ctx, hcs); err != nil {
+					log.Errorf("failed to index head changes: %s", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops following the head.
+func (idx *Index) Stop() {
+	if idx.cancel != nil {
+		idx.cancel()
+	}
+	idx.wg.Wait()
+}
+
+func (idx *Index) processHeadChanges(ctx context.Context, hcs []*chain.HeadChange) error {
+	for _, hc := range hcs {
+		var err error
+		switch hc.Type {
+		case chain.HCCurrent:
+			// backfilling a long chain must not hold back the new heads
+			idx.wg.Add(1)
+			go func(head *block.TipSet) {
+				defer idx.wg.Done()
+				if err := idx.Backfill(ctx, head); err != nil {
+					log.Errorf("failed to backfill message index: %s", err)
+				}
+			}(hc.Val)
+		case chain.HCApply:
+			idx.lk.Lock()
+			err = idx.apply(ctx, hc.Val)
+			idx.lk.Unlock()
+		case chain.HCRevert:
+			idx.lk.Lock()
+			err = idx.revert(ctx, hc.Val)
+			idx.lk.Unlock()
+		}
+		if err != nil {
+			return xerrors.Errorf("%s tipset %s: %w", hc.Type, hc.Val.Key(), err)
+		}
+	}
+	return nil
+}
+
+// Backfill indexes the tipsets from `head` down to the last indexed tipset, or to the
+// backfill limit if none was indexed yet. It stops early if `head` is reverted meanwhile.
+func (idx *Index) Backfill(ctx context.Context, head *block.TipSet) error {
+	limit := abi.ChainEpoch(0)
+	if idx.backfillEpochs > 0 && head.EnsureHeight() > idx.backfillEpochs {
+		limit = head.EnsureHeight() - idx.backfillEpochs
+	}
+
+	log.Infof("backfilling message index from %d", head.EnsureHeight())
+	ts := head
+	for ts.EnsureHeight() > limit {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		pts, err := idx.chain.GetTipSet(ts.EnsureParents())
+		if err != nil {
+			return err
+		}
+		done, err := idx.backfillTipSet(ctx, ts, pts)
+		if err != nil || done {
+			return err
+		}
+		ts = pts
+	}
+	return idx.setTail(ts.EnsureHeight())
+}
+
+// backfillTipSet indexes the messages included in `pts` and executed in `ts`, unless they
+// were indexed already, and returns whether the backfill is done.
+func (idx *Index) backfillTipSet(ctx context.Context, ts, pts *block.TipSet) (bool, error) {
+	idx.lk.Lock()
+	defer idx.lk.Unlock()
+
+	// the head changes followed meanwhile index the new chain
+	canonical, err := idx.isCanonical(ctx, ts.Key(), ts.EnsureHeight())
+	if err != nil {
+		return false, err
+	}
+	if !canonical {
+		log.Infof("stop backfilling message index at %d, the chain was reorged", ts.EnsureHeight())
+		return true, nil
+	}
+
+	indexed, found, err := idx.indexedAt(pts.EnsureHeight())
+	if err != nil {
+		return false, err
+	}
+	if found {
+		if indexed == pts.Key() {
+			// everything below was indexed already
+			return true, nil
+		}
+		// the chain was reorged while the index was not following it
+		old, err := idx.chain.GetTipSet(indexed)
+		if err != nil {
+			return false, err
+		}
+		if err := idx.unindexMessages(ctx, old); err != nil {
+			return false, err
+		}
+	}
+
+	if err := idx.apply(ctx, ts); err != nil {
+		// messages of tipsets imported from a snapshot may be missing
+		log.Warnf("stop backfilling message index at %d: %s", ts.EnsureHeight(), err)
+		return true, idx.setTail(ts.EnsureHeight())
+	}
+	return false, nil
+}
+
+// apply indexes the messages included in the parent of `ts` and executed in `ts`.
+func (idx *Index) apply(ctx context.Context, ts *block.TipSet) error {
+	if ts.EnsureHeight() == 0 {
+		return nil
+	}
+	pts, err := idx.chain.GetTipSet(ts.EnsureParents())
+	if err != nil {
+		return err
+	}
+	blkMsgs, err := idx.messages.LoadTipSetMessage(ctx, pts)
+	if err != nil {
+		return err
+	}
+	receipts, err := idx.messages.LoadReceipts(ctx, ts.At(0).ParentMessageReceipts)
+	if err != nil {
+		return err
+	}
+
+	batch, err := idx.ds.Batch()
+	if err != nil {
+		return err
+	}
+
+	lookupID := idx.idResolver(ctx, pts)
+	i := 0
+	err = forEachMessage(blkMsgs, func(blk *block.Block, mcid cid.Cid, msg *types.UnsignedMessage) error {
+		if i >= len(receipts) {
+			return xerrors.Errorf("no receipt for message %s at index %d", mcid, i)
+		}
+		b, err := json.Marshal(&MsgInfo{
+			Message: mcid,
+			TipSet:  ts.Key(),
+			Height:  ts.EnsureHeight(),
+			Block:   blk.Cid(),
+			Index:   i,
+			Receipt: receipts[i],
+		})
+		if err != nil {
+			return err
+		}
+		i++
+
+		if err := batch.Put(msgsPrefix.ChildString(mcid.String()), b); err != nil {
+			return err
+		}
+		from, to, err := lookupID(msg.From, msg.To)
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(addrKey(fromPrefix, from, pts.EnsureHeight(), mcid), to.Bytes()); err != nil {
+			return err
+		}
+		return batch.Put(addrKey(toPrefix, to, pts.EnsureHeight(), mcid), from.Bytes())
+	})
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(pts.Key())
+	if err != nil {
+		return err
+	}
+	if err := batch.Put(heightKey(pts.EnsureHeight()), b); err != nil {
+		return err
+	}
+	return batch.Commit()
+}
+
+// revert removes the messages executed in `ts` from the index.
+func (idx *Index) revert(ctx context.Context, ts *block.TipSet) error {
+	if ts.EnsureHeight() == 0 {
+		return nil
+	}
+	pts, err := idx.chain.GetTipSet(ts.EnsureParents())
+	if err != nil {
+		return err
+	}
+	return idx.unindexMessages(ctx, pts)
+}
+
+// unindexMessages removes the messages included in `ts` from the index.
+func (idx *Index) unindexMessages(ctx context.Context, ts *block.TipSet) error {
+	blkMsgs, err := idx.messages.LoadTipSetMessage(ctx, ts)
+	if err != nil {
+		return err
+	}
+
+	batch, err := idx.ds.Batch()
+	if err != nil {
+		return err
+	}
+
+	// the keys were built from the addresses resolved in the same state
+	lookupID := idx.idResolver(ctx, ts)
+	err = forEachMessage(blkMsgs, func(_ *block.Block, mcid cid.Cid, msg *types.UnsignedMessage) error {
+		if err := batch.Delete(msgsPrefix.ChildString(mcid.String())); err != nil {
+			return err
+		}
+		from, to, err := lookupID(msg.From, msg.To)
+		if err != nil {
+			return err
+		}
+		if err := batch.Delete(addrKey(fromPrefix, from, ts.EnsureHeight(), mcid)); err != nil {
+			return err
+		}
+		return batch.Delete(addrKey(toPrefix, to, ts.EnsureHeight(), mcid))
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := batch.Delete(heightKey(ts.EnsureHeight())); err != nil {
+		return err
+	}
+	return batch.Commit()
+}
+
+// GetMsgInfo returns where the message `mcid` landed on the current chain.
+func (idx *Index) GetMsgInfo(ctx context.Context, mcid cid.Cid) (*MsgInfo, error) {
+	b, err := idx.ds.Get(msgsPrefix.ChildString(mcid.String()))
+	if err != nil {
+		if err == ds.ErrNotFound {
+			return nil, ErrNotIndexed
+		}
+		return nil, err
+	}
+
+	mi := &MsgInfo{}
+	if err := json.Unmarshal(b, mi); err != nil {
+		return nil, err
+	}
+
+	// the head changes reverting the tipset may not be processed yet
+	canonical, err := idx.isCanonical(ctx, mi.TipSet, mi.Height)
+	if err != nil {
+		return nil, err
+	}
+	if !canonical {
+		return nil, ErrNotIndexed
+	}
+	return mi, nil
+}
+
+// Covers returns true if the messages included in the tipsets from `ts`, on the current
+// chain, down to the height `toHeight` are all indexed.
+func (idx *Index) Covers(ctx context.Context, ts *block.TipSet, toHeight abi.ChainEpoch) (bool, error) {
+	indexed, found, err := idx.indexedAt(ts.EnsureHeight())
+	if err != nil || !found || indexed != ts.Key() {
+		return false, err
+	}
+	// the index may still be following a reverted chain
+	canonical, err := idx.isCanonical(ctx, ts.Key(), ts.EnsureHeight())
+	if err != nil || !canonical {
+		return false, err
+	}
+
+	b, err := idx.ds.Get(tailKey)
+	if err != nil {
+		if err == ds.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	var tail abi.ChainEpoch
+	if err := json.Unmarshal(b, &tail); err != nil {
+		return false, err
+	}
+	return tail <= toHeight, nil
+}
+
+// ListMessages returns the messages sent from `from` and to `to`, included in tipsets
+// between the heights `fromHeight` and `toHeight`, the most recent first. Either address
+// may be undefined to match any address, a key address matches the messages of its actor
+// whichever address form they use.
+func (idx *Index) ListMessages(ctx context.Context, from, to address.Address, fromHeight, toHeight abi.ChainEpoch) ([]cid.Cid, error) {
+	from, to, err := idx.idResolver(ctx, idx.chain.GetHead())(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, addr, other := fromPrefix, from, to
+	if from == address.Undef {
+		prefix, addr, other = toPrefix, to, from
+	}
+	if addr == address.Undef {
+		return nil, xerrors.New("a sender or a recipient address is required")
+	}
+
+	res, err := idx.ds.Query(dsq.Query{
+		// the trailing slash keeps f01 from matching the keys of f010
+		Prefix: prefix.ChildString(addr.String()).String() + "/",
+		Orders: []dsq.Order{dsq.OrderByKeyDescending{}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close() //nolint:errcheck
+
+	var out []cid.Cid
+	for entry := range res.Next() {
+		if entry.Error != nil {
+			return nil, entry.Error
+		}
+
+		// keys are /<prefix>/<addr>/<height>/<cid>
+		k := ds.NewKey(entry.Key)
+		var h abi.ChainEpoch
+		if _, err := fmt.Sscanf(k.Parent().Name(), "%d", &h); err != nil {
+			return nil, xerrors.Errorf("invalid index key %s: %w", entry.Key, err)
+		}
+		if h > fromHeight {
+			continue
+		}
+		if h < toHeight {
+			break
+		}
+
+		if other != address.Undef {
+			counterparty, err := address.NewFromBytes(entry.Value)
+			if err != nil {
+				return nil, err
+			}
+			if counterparty != other {
+				continue
+			}
+		}
+
+		mcid, err := cid.Decode(k.Name())
+		if err != nil {
+			return nil, xerrors.Errorf("invalid index key %s: %w", entry.Key, err)
+		}
+		out = append(out, mcid)
+	}
+	return out, nil
+}
+
+// isCanonical returns whether the tipset `tsk` at the height `h` is on the current chain.
+func (idx *Index) isCanonical(ctx context.Context, tsk block.TipSetKey, h abi.ChainEpoch) (bool, error) {
+	head := idx.chain.GetHead()
+	if head.EnsureHeight() < h {
+		return false, nil
+	}
+	ts, err := idx.chain.GetTipSetByHeight(ctx, head, h, false)
+	if err != nil {
+		return false, err
+	}
+	return ts.Key() == tsk, nil
+}
+
+// idResolver returns a function resolving a sender and a recipient to their ID form in the
+// state of `ts`. An address without an actor in this state is kept as is.
+func (idx *Index) idResolver(ctx context.Context, ts *block.TipSet) func(from, to address.Address) (address.Address, address.Address, error) {
+	resolved := make(map[address.Address]address.Address)
+	lookupID := func(addr address.Address) (address.Address, error) {
+		if addr == address.Undef || addr.Protocol() == address.ID {
+			return addr, nil
+		}
+		if id, ok := resolved[addr]; ok {
+			return id, nil
+		}
+		id, err := idx.state.ResolveAddressAt(ctx, ts, addr)
+		if err != nil {
+			if !xerrors.Is(err, types.ErrActorNotFound) {
+				return address.Undef, xerrors.Errorf("resolving %s: %w", addr, err)
+			}
+			id = addr
+		}
+		resolved[addr] = id
+		return id, nil
+	}
+	return func(from, to address.Address) (address.Address, address.Address, error) {
+		fromID, err := lookupID(from)
+		if err != nil {
+			return address.Undef, address.Undef, err
+		}
+		toID, err := lookupID(to)
+		if err != nil {
+			return address.Undef, address.Undef, err
+		}
+		return fromID, toID, nil
+	}
+}
+
+func (idx *Index) indexedAt(h abi.ChainEpoch) (block.TipSetKey, bool, error) {
+	b, err := idx.ds.Get(heightKey(h))
+	if err != nil {
+		if err == ds.ErrNotFound {
+			return block.EmptyTSK, false, nil
+		}
+		return block.EmptyTSK, false, err
+	}
+
+	var tsk block.TipSetKey
+	if err := json.Unmarshal(b, &tsk); err != nil {
+		return block.EmptyTSK, false, err
+	}
+	return tsk, true, nil
+}
+
+func (idx *Index) setTail(h abi.ChainEpoch) error {
+	b, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return idx.ds.Put(tailKey, b)
+}
+
+// forEachMessage calls `cb` with the messages of a tipset in execution order, a message
+// included in several blocks is executed once, in the first one.
+func forEachMessage(blkMsgs []block.BlockMessagesInfo, cb func(*block.Block, cid.Cid, *types.UnsignedMessage) error) error {
+	seenMsgs := make(map[cid.Cid]struct{})
+	for _, bm := range blkMsgs {
+		for _, m := range append(bm.BlsMessages, bm.SecpkMessages...) {
+			mcid, err := m.Cid()
+			if err != nil {
+				return err
+			}
+			if _, ok := seenMsgs[mcid]; ok {
+				continue
+			}
+			seenMsgs[mcid] = struct{}{}
+			if err := cb(bm.Block, mcid, m.VMMessage()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// heightKey is padded for the keys to sort by height.
+func heightKey(h abi.ChainEpoch) ds.Key {
+	return tipsetsPrefix.ChildString(fmt.Sprintf("%020d", h))
+}
+
+func addrKey(prefix ds.Key, addr address.Address, h abi.ChainEpoch, mcid cid.Cid) ds.Key {
+	return prefix.ChildString(addr.String()).ChildString(fmt.Sprintf("%020d", h)).ChildString(mcid.String())
+}
@@ -0,0 +1,284 @@
+package msgindex
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/repo"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+type fakeChain struct {
+	*chain.Builder
+	head *block.TipSet
+}
+
+func (fc *fakeChain) GetHead() *block.TipSet {
+	return fc.head
+}
+
+func (fc *fakeChain) SubHeadChanges(context.Context) chan []*chain.HeadChange {
+	return nil
+}
+
+// fakeState resolves the key addresses in `ids`, whatever the tipset.
+type fakeState struct {
+	ids map[address.Address]address.Address
+}
+
+func (fs *fakeState) ResolveAddressAt(_ context.Context, _ *block.TipSet, addr address.Address) (address.Address, error) {
+	if addr.Protocol() == address.ID {
+		return addr, nil
+	}
+	id, ok := fs.ids[addr]
+	if !ok {
+		return address.Undef, xerrors.Errorf("resolve address %s: %w", addr, types.ErrActorNotFound)
+	}
+	return id, nil
+}
+
+type fakeMessages struct {
+	*chain.Builder
+}
+
+// LoadTipSetMessage returns the messages of every block of `ts`, a message included in
+// several blocks is returned for each of them.
+func (fm *fakeMessages) LoadTipSetMessage(ctx context.Context, ts *block.TipSet) ([]block.BlockMessagesInfo, error) {
+	var out []block.BlockMessagesInfo
+	for _, blk := range ts.Blocks() {
+		secpMsgs, blsMsgs, err := fm.LoadMetaMessages(ctx, blk.Messages)
+		if err != nil {
+			return nil, err
+		}
+
+		bm := block.BlockMessagesInfo{Block: blk}
+		for _, m := range blsMsgs {
+			bm.BlsMessages = append(bm.BlsMessages, m)
+		}
+		for _, m := range secpMsgs {
+			bm.SecpkMessages = append(bm.SecpkMessages, m)
+		}
+		out = append(out, bm)
+	}
+	return out, nil
+}
+
+// receipts returns the receipts of `n` executed messages, the gas used by each one is its index.
+func receipts(n int) []types.MessageReceipt {
+	out := make([]types.MessageReceipt, n)
+	for i := range out {
+		out[i].GasUsed = int64(i)
+	}
+	return out
+}
+
+func TestIndex(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	msgs := types.NewMsgs(2)
+	mcid0, err := msgs[0].Cid()
+	require.NoError(t, err)
+	mcid1, err := msgs[1].Cid()
+	require.NoError(t, err)
+
+	ts1 := builder.BuildOneOn(builder.Genesis(), func(b *chain.BlockBuilder) {
+		b.AddMessages(nil, msgs)
+	})
+	ts2 := builder.BuildOneOn(ts1, func(b *chain.BlockBuilder) {
+		b.SetParentReceipts(receipts(2))
+	})
+
+	idx := NewIndex(repo.NewInMemoryRepo().MetaDatastore(), &fakeChain{builder, ts2}, &fakeState{}, &fakeMessages{builder}, 0)
+	require.NoError(t, idx.Backfill(ctx, ts2))
+
+	mi, err := idx.GetMsgInfo(ctx, mcid1)
+	require.NoError(t, err)
+	assert.Equal(t, ts2.Key(), mi.TipSet)
+	assert.Equal(t, ts2.EnsureHeight(), mi.Height)
+	assert.Equal(t, ts1.At(0).Cid(), mi.Block)
+	assert.Equal(t, 1, mi.Index)
+	assert.Equal(t, int64(1), mi.Receipt.GasUsed)
+
+	found, err := idx.ListMessages(ctx, msgs[0].From, address.Undef, ts1.EnsureHeight(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, []cid.Cid{mcid0}, found)
+
+	found, err = idx.ListMessages(ctx, address.Undef, msgs[1].To, ts1.EnsureHeight(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, []cid.Cid{mcid1}, found)
+
+	found, err = idx.ListMessages(ctx, msgs[0].From, msgs[1].To, ts1.EnsureHeight(), 0)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	// messages included in the head are only indexed once it has a child
+	covers, err := idx.Covers(ctx, ts1, 0)
+	require.NoError(t, err)
+	assert.True(t, covers)
+	covers, err = idx.Covers(ctx, ts2, 0)
+	require.NoError(t, err)
+	assert.False(t, covers)
+
+	require.NoError(t, idx.revert(ctx, ts2))
+	_, err = idx.GetMsgInfo(ctx, mcid0)
+	assert.Equal(t, ErrNotIndexed, err)
+	found, err = idx.ListMessages(ctx, msgs[0].From, address.Undef, ts1.EnsureHeight(), 0)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+	covers, err = idx.Covers(ctx, ts1, 0)
+	require.NoError(t, err)
+	assert.False(t, covers)
+
+	// backfilling stops at the indexed genesis
+	require.NoError(t, idx.Backfill(ctx, ts2))
+	mi, err = idx.GetMsgInfo(ctx, mcid0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, mi.Index)
+}
+
+func TestIndexDuplicateMessages(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	msgs := types.NewMsgs(3)
+
+	// both blocks include the second message
+	ts1 := builder.Build(builder.Genesis(), 2, func(b *chain.BlockBuilder, i int) {
+		b.AddMessages(nil, msgs[i:i+2])
+	})
+	// the receipts are those of the 3 distinct messages
+	ts2 := builder.BuildOneOn(ts1, func(b *chain.BlockBuilder) {
+		b.SetParentReceipts(receipts(3))
+	})
+
+	idx := NewIndex(repo.NewInMemoryRepo().MetaDatastore(), &fakeChain{builder, ts2}, &fakeState{}, &fakeMessages{builder}, 0)
+	require.NoError(t, idx.Backfill(ctx, ts2))
+
+	indexes := map[int]bool{}
+	for _, msg := range msgs {
+		mcid, err := msg.Cid()
+		require.NoError(t, err)
+
+		mi, err := idx.GetMsgInfo(ctx, mcid)
+		require.NoError(t, err)
+		assert.Equal(t, int64(mi.Index), mi.Receipt.GasUsed)
+		indexes[mi.Index] = true
+
+		found, err := idx.ListMessages(ctx, msg.From, address.Undef, ts1.EnsureHeight(), 0)
+		require.NoError(t, err)
+		assert.Equal(t, []cid.Cid{mcid}, found)
+	}
+	assert.Equal(t, map[int]bool{0: true, 1: true, 2: true}, indexes)
+
+	// the message included twice is executed in the first block of the tipset
+	mcid1, err := msgs[1].Cid()
+	require.NoError(t, err)
+	mi, err := idx.GetMsgInfo(ctx, mcid1)
+	require.NoError(t, err)
+	assert.Equal(t, ts1.At(0).Cid(), mi.Block)
+}
+
+func TestIndexReorg(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	msgs := types.NewMsgs(1)
+	mcid, err := msgs[0].Cid()
+	require.NoError(t, err)
+
+	ts1 := builder.BuildOneOn(builder.Genesis(), func(b *chain.BlockBuilder) {
+		b.AddMessages(nil, msgs)
+	})
+	ts2 := builder.BuildOneOn(ts1, func(b *chain.BlockBuilder) {
+		b.SetParentReceipts(receipts(1))
+	})
+	fork := builder.AppendOn(ts1, 1)
+
+	fc := &fakeChain{builder, ts2}
+	idx := NewIndex(repo.NewInMemoryRepo().MetaDatastore(), fc, &fakeState{}, &fakeMessages{builder}, 0)
+	require.NoError(t, idx.Backfill(ctx, ts2))
+	_, err = idx.GetMsgInfo(ctx, mcid)
+	require.NoError(t, err)
+
+	// the tipset the message was executed in is reverted before the index follows the head
+	fc.head = fork
+	_, err = idx.GetMsgInfo(ctx, mcid)
+	assert.Equal(t, ErrNotIndexed, err)
+
+	// a reverted head isn't backfilled
+	idx = NewIndex(repo.NewInMemoryRepo().MetaDatastore(), fc, &fakeState{}, &fakeMessages{builder}, 0)
+	require.NoError(t, idx.Backfill(ctx, ts2))
+	fc.head = ts2
+	_, err = idx.GetMsgInfo(ctx, mcid)
+	assert.Equal(t, ErrNotIndexed, err)
+}
+
+func TestIndexResolvesAddresses(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	msgs := types.NewMsgs(2)
+	sender, recipient := msgs[0].From, msgs[1].To
+	senderID, recipientID := types.RequireIDAddress(t, 100), types.RequireIDAddress(t, 101)
+	other := types.NewForTestGetter()()
+
+	// the messages address the same actors in different forms
+	msgs[0].To = recipientID
+	msgs[1].From = senderID
+	mcid0, err := msgs[0].Cid()
+	require.NoError(t, err)
+	mcid1, err := msgs[1].Cid()
+	require.NoError(t, err)
+
+	// both blocks include the second message
+	ts1 := builder.Build(builder.Genesis(), 2, func(b *chain.BlockBuilder, i int) {
+		b.AddMessages(nil, msgs[i:])
+	})
+	ts2 := builder.BuildOneOn(ts1, func(b *chain.BlockBuilder) {
+		b.SetParentReceipts(receipts(2))
+	})
+
+	fs := &fakeState{ids: map[address.Address]address.Address{sender: senderID, recipient: recipientID}}
+	idx := NewIndex(repo.NewInMemoryRepo().MetaDatastore(), &fakeChain{builder, ts2}, fs, &fakeMessages{builder}, 0)
+	require.NoError(t, idx.Backfill(ctx, ts2))
+
+	for _, match := range []struct{ from, to address.Address }{
+		{sender, address.Undef},
+		{senderID, address.Undef},
+		{address.Undef, recipient},
+		{address.Undef, recipientID},
+		{sender, recipientID},
+		{senderID, recipient},
+	} {
+		found, err := idx.ListMessages(ctx, match.from, match.to, ts1.EnsureHeight(), 0)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []cid.Cid{mcid0, mcid1}, found, "from %s to %s", match.from, match.to)
+	}
+
+	found, err := idx.ListMessages(ctx, sender, other, ts1.EnsureHeight(), 0)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	// the keys removed are those of the resolved addresses
+	require.NoError(t, idx.revert(ctx, ts2))
+	found, err = idx.ListMessages(ctx, senderID, address.Undef, ts1.EnsureHeight(), 0)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+	found, err = idx.ListMessages(ctx, address.Undef, recipientID, ts1.EnsureHeight(), 0)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
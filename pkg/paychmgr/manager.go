@@ -0,0 +1,879 @@
+package paychmgr
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/filecoin-project/go-state-types/network"
+	init2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/init"
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/filecoin-project/venus/pkg/specactors"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/paych"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+var log = logging.Logger("paych")
+
+// ManagerAPI is the node functionality the payment channel manager relies on.
+type ManagerAPI interface {
+	StateNetworkVersion(context.Context, block.TipSetKey) (network.Version, error)
+	StateAccountKey(context.Context, address.Address, block.TipSetKey) (address.Address, error)
+	// GetPaychState returns the actor and the state of the channel `ch` at the heaviest tipset
+	GetPaychState(ctx context.Context, ch address.Address) (*types.Actor, paych.State, error)
+	// PushMessage signs and pushes a message from the local wallet
+	PushMessage(context.Context, *types.UnsignedMessage) (cid.Cid, error)
+	// WaitMsg waits for a message to land on chain and returns its receipt
+	WaitMsg(context.Context, cid.Cid) (*types.MessageReceipt, error)
+	WalletHas(context.Context, address.Address) (bool, error)
+	// SignVoucher signs the serialized voucher `data` with the key of `addr`
+	SignVoucher(context.Context, address.Address, []byte) (*crypto.Signature, error)
+}
+
+// VoucherCreateResult is the result of creating a voucher. When the channel doesn't
+// have enough funds to cover it, Voucher is nil and Shortfall is the missing amount.
+type VoucherCreateResult struct {
+	Voucher   *paych.SignedVoucher
+	Shortfall big.Int
+}
+
+// ChannelAvailableFunds describes the funds of a channel.
+type ChannelAvailableFunds struct {
+	Channel *address.Address
+	From    address.Address
+	To      address.Address
+	// ConfirmedAmt is the amount of funds which landed on chain
+	ConfirmedAmt big.Int
+	// PendingAmt is the amount of funds in messages waiting to land on chain
+	PendingAmt big.Int
+	// PendingWaitSentinel is the message to wait for with PaychGetWaitReady before
+	// the pending funds are available
+	PendingWaitSentinel *cid.Cid
+	// VoucherRedeemedAmt is the amount redeemed by the vouchers of the channel
+	VoucherRedeemedAmt big.Int
+}
+
+// laneState is the redeemed amount and nonce of a lane, merged from the chain state
+// and the vouchers of the store.
+type laneState struct {
+	redeemed big.Int
+	nonce    uint64
+}
+
+// msgWaiter is completed once the message it waits for landed on chain, or the wait failed.
+type msgWaiter struct {
+	done chan struct{}
+	err  error
+}
+
+// Manager creates and funds payment channels, and keeps track of their vouchers.
+type Manager struct {
+	ctx      context.Context
+	shutdown context.CancelFunc
+
+	api   ManagerAPI
+	store *Store
+
+	// lk guards the read-modify-write of the channels in the store
+	lk sync.Mutex
+	// msgWaiters are the waits in progress of the messages creating, funding or
+	// settling channels
+	msgWaiters map[cid.Cid]*msgWaiter
+}
+
+// NewManager creates a new payment channel manager.
+func NewManager(ctx context.Context, store *Store, api ManagerAPI) *Manager {
+	ctx, shutdown := context.WithCancel(ctx)
+	return &Manager{
+		ctx:        ctx,
+		shutdown:   shutdown,
+		api:        api,
+		store:      store,
+		msgWaiters: make(map[cid.Cid]*msgWaiter),
+	}
+}
+
+// Start resumes waiting for the messages sent before the node stopped.
+func (pm *Manager) Start() error {
+	pm.lk.Lock()
+	defer pm.lk.Unlock()
+
+	pending, err := pm.store.PendingMessages()
+	if err != nil {
+		return err
+	}
+
+	for _, mi := range pending {
+		pm.startWaiting(mi.ChannelID, mi.MsgCid, mi.Amount)
+	}
+	return nil
+}
+
+// Stop stops waiting for messages.
+func (pm *Manager) Stop() {
+	pm.shutdown()
+}
+
+// GetPaych returns the outbound channel from `from` to `to` and adds `amt` to its funds,
+// creating it if needed. The returned message must land on chain before the funds
+// can be used, see GetPaychWaitReady. The channel address is undefined until the
+// channel is created.
+func (pm *Manager) GetPaych(ctx context.Context, from, to address.Address, amt big.Int) (address.Address, cid.Cid, error) {
+	pm.lk.Lock()
+	defer pm.lk.Unlock()
+
+	ci, err := pm.store.OutboundActiveByFromTo(from, to)
+	switch {
+	case err == ErrChannelNotTracked:
+		return pm.createPaych(ctx, from, to, amt)
+	case err != nil:
+		return address.Undef, cid.Undef, err
+	case ci.CreateMsg != nil:
+		return address.Undef, cid.Undef, xerrors.Errorf("channel from %s to %s is being created by message %s, wait for it before adding funds", from, to, *ci.CreateMsg)
+	case ci.SettleMsg != nil:
+		return address.Undef, cid.Undef, xerrors.Errorf("channel from %s to %s is being settled by message %s", from, to, *ci.SettleMsg)
+	}
+
+	if amt.IsZero() {
+		return *ci.Channel, cid.Undef, nil
+	}
+
+	mcid, err := pm.api.PushMessage(ctx, &types.UnsignedMessage{
+		To:     *ci.Channel,
+		From:   ci.Control,
+		Value:  amt,
+		Method: 0,
+	})
+	if err != nil {
+		return address.Undef, cid.Undef, xerrors.Errorf("failed to add funds to channel: %w", err)
+	}
+
+	ci.AddFundsMsg = &mcid
+	ci.PendingAmount = big.Add(ci.PendingAmount, amt)
+	if err := pm.trackMessage(ci, mcid, amt); err != nil {
+		return address.Undef, cid.Undef, err
+	}
+	return *ci.Channel, mcid, nil
+}
+
+func (pm *Manager) createPaych(ctx context.Context, from, to address.Address, amt big.Int) (address.Address, cid.Cid, error) {
+	nv, err := pm.api.StateNetworkVersion(ctx, block.EmptyTSK)
+	if err != nil {
+		return address.Undef, cid.Undef, err
+	}
+
+	msg, err := paych.Message(specactors.VersionForNetwork(nv), from).Create(to, amt)
+	if err != nil {
+		return address.Undef, cid.Undef, err
+	}
+
+	mcid, err := pm.api.PushMessage(ctx, msg)
+	if err != nil {
+		return address.Undef, cid.Undef, xerrors.Errorf("failed to create channel: %w", err)
+	}
+
+	ci := &ChannelInfo{
+		ChannelID:     mcid.String(),
+		Control:       from,
+		Target:        to,
+		Direction:     DirOutbound,
+		Amount:        big.Zero(),
+		PendingAmount: amt,
+		CreateMsg:     &mcid,
+	}
+	if err := pm.trackMessage(ci, mcid, amt); err != nil {
+		return address.Undef, cid.Undef, err
+	}
+	return address.Undef, mcid, nil
+}
+
+// trackMessage stores the channel `ci` and the message `mcid` sending `amt` to it, and
+// waits for the message in the background.
+func (pm *Manager) trackMessage(ci *ChannelInfo, mcid cid.Cid, amt big.Int) error {
+	if err := pm.store.SaveNewMessage(ci.ChannelID, mcid, amt); err != nil {
+		return err
+	}
+	if err := pm.store.Put(ci); err != nil {
+		return err
+	}
+
+	pm.startWaiting(ci.ChannelID, mcid, amt)
+	return nil
+}
+
+// startWaiting waits for the message `mcid` in the background, pm.lk must be held.
+func (pm *Manager) startWaiting(channelID string, mcid cid.Cid, amt big.Int) *msgWaiter {
+	w := &msgWaiter{done: make(chan struct{})}
+	pm.msgWaiters[mcid] = w
+	go pm.waitForMessage(w, channelID, mcid, amt)
+	return w
+}
+
+// waitForMessage waits for the message `mcid` creating, funding or settling the channel
+// `channelID` to land on chain, updates the channel with the result and completes `w`.
+// A failed wait stays in msgWaiters until its error is returned by GetPaychWaitReady.
+func (pm *Manager) waitForMessage(w *msgWaiter, channelID string, mcid cid.Cid, amt big.Int) {
+	receipt, err := pm.api.WaitMsg(pm.ctx, mcid)
+
+	pm.lk.Lock()
+	defer pm.lk.Unlock()
+	defer close(w.done)
+
+	if err != nil {
+		if pm.ctx.Err() == nil {
+			log.Errorf("failed to wait for payment channel message %s: %s", mcid, err)
+		}
+		w.err = xerrors.Errorf("failed to wait for message %s: %w", mcid, err)
+		return
+	}
+	delete(pm.msgWaiters, mcid)
+
+	msgErr := pm.applyMessageResult(channelID, mcid, amt, receipt)
+	if msgErr != nil {
+		log.Warnf("payment channel message %s failed: %s", mcid, msgErr)
+	}
+
+	if err := pm.store.SaveMessageResult(mcid, msgErr); err != nil {
+		log.Errorf("failed to save result of payment channel message %s: %s", mcid, err)
+		w.err = err
+	}
+}
+
+func (pm *Manager) applyMessageResult(channelID string, mcid cid.Cid, amt big.Int, receipt *types.MessageReceipt) error {
+	ci, err := pm.store.ByChannelID(channelID)
+	if err != nil {
+		return err
+	}
+
+	ci.PendingAmount = big.Sub(ci.PendingAmount, amt)
+	if ci.CreateMsg != nil && *ci.CreateMsg == mcid {
+		ci.CreateMsg = nil
+	}
+	if ci.AddFundsMsg != nil && *ci.AddFundsMsg == mcid {
+		ci.AddFundsMsg = nil
+	}
+	settle := ci.SettleMsg != nil && *ci.SettleMsg == mcid
+	if settle {
+		ci.SettleMsg = nil
+	}
+
+	var msgErr error
+	if receipt.ExitCode != exitcode.Ok {
+		msgErr = xerrors.Errorf("message failed with exit code %d", receipt.ExitCode)
+	} else {
+		ci.Amount = big.Add(ci.Amount, amt)
+		if settle {
+			ci.Settling = true
+		}
+		if ci.Channel == nil {
+			var decodedReturn init2.ExecReturn
+			if err := decodedReturn.UnmarshalCBOR(bytes.NewReader(receipt.ReturnValue)); err != nil {
+				msgErr = xerrors.Errorf("failed to decode channel create return: %w", err)
+			} else {
+				ci.Channel = &decodedReturn.RobustAddress
+			}
+		}
+	}
+
+	if ci.Channel == nil {
+		// the channel was never created, forget it so that another one can be
+		if err := pm.store.RemoveChannel(channelID); err != nil {
+			return err
+		}
+		return msgErr
+	}
+
+	if err := pm.store.Put(ci); err != nil {
+		return err
+	}
+	return msgErr
+}
+
+// GetPaychWaitReady waits for the message `mcid`, returned by GetPaych or Settle, to land
+// on chain and returns the address of the channel it created, funded or settled.
+func (pm *Manager) GetPaychWaitReady(ctx context.Context, mcid cid.Cid) (address.Address, error) {
+	pm.lk.Lock()
+	mi, err := pm.store.GetMessage(mcid)
+	if err != nil {
+		pm.lk.Unlock()
+		return address.Undef, err
+	}
+
+	if !mi.Received {
+		w, ok := pm.msgWaiters[mcid]
+		if !ok {
+			// the previous wait failed and was reported, try again
+			w = pm.startWaiting(mi.ChannelID, mcid, mi.Amount)
+		}
+		pm.lk.Unlock()
+
+		select {
+		case <-w.done:
+		case <-ctx.Done():
+			return address.Undef, ctx.Err()
+		}
+
+		pm.lk.Lock()
+		if w.err != nil {
+			// the next call waits again
+			if pm.msgWaiters[mcid] == w {
+				delete(pm.msgWaiters, mcid)
+			}
+			pm.lk.Unlock()
+			return address.Undef, w.err
+		}
+
+		mi, err = pm.store.GetMessage(mcid)
+		if err != nil {
+			pm.lk.Unlock()
+			return address.Undef, err
+		}
+	}
+	defer pm.lk.Unlock()
+
+	if mi.Err != "" {
+		return address.Undef, xerrors.New(mi.Err)
+	}
+
+	ci, err := pm.store.ByChannelID(mi.ChannelID)
+	if err != nil {
+		return address.Undef, err
+	}
+	if ci.Channel == nil {
+		return address.Undef, xerrors.Errorf("channel of message %s was not created", mcid)
+	}
+	return *ci.Channel, nil
+}
+
+// AvailableFunds returns the funds of the channel `ch`.
+func (pm *Manager) AvailableFunds(ctx context.Context, ch address.Address) (*ChannelAvailableFunds, error) {
+	pm.lk.Lock()
+	defer pm.lk.Unlock()
+
+	ci, err := pm.store.ByAddress(ch)
+	if err != nil {
+		return nil, err
+	}
+	return pm.availableFunds(ctx, ci)
+}
+
+// AvailableFundsByFromTo returns the funds of the outbound channel from `from` to `to`.
+func (pm *Manager) AvailableFundsByFromTo(ctx context.Context, from, to address.Address) (*ChannelAvailableFunds, error) {
+	pm.lk.Lock()
+	defer pm.lk.Unlock()
+
+	ci, err := pm.store.OutboundActiveByFromTo(from, to)
+	if err == ErrChannelNotTracked {
+		return &ChannelAvailableFunds{
+			From:               from,
+			To:                 to,
+			ConfirmedAmt:       big.Zero(),
+			PendingAmt:         big.Zero(),
+			VoucherRedeemedAmt: big.Zero(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return pm.availableFunds(ctx, ci)
+}
+
+func (pm *Manager) availableFunds(ctx context.Context, ci *ChannelInfo) (*ChannelAvailableFunds, error) {
+	if ci.Direction == DirInbound {
+		if err := pm.refreshInboundAmount(ctx, ci); err != nil {
+			return nil, err
+		}
+	}
+
+	redeemed := big.Zero()
+	if ci.Channel != nil {
+		lanes, err := pm.laneStates(ctx, ci)
+		if err != nil {
+			return nil, err
+		}
+		for _, ls := range lanes {
+			redeemed = big.Add(redeemed, ls.redeemed)
+		}
+	}
+
+	sentinel := ci.CreateMsg
+	if sentinel == nil {
+		sentinel = ci.AddFundsMsg
+	}
+
+	return &ChannelAvailableFunds{
+		Channel:             ci.Channel,
+		From:                ci.from(),
+		To:                  ci.to(),
+		ConfirmedAmt:        ci.Amount,
+		PendingAmt:          ci.PendingAmount,
+		PendingWaitSentinel: sentinel,
+		VoucherRedeemedAmt:  redeemed,
+	}, nil
+}
+
+// ListChannels returns the addresses of the tracked channels.
+func (pm *Manager) ListChannels() ([]address.Address, error) {
+	pm.lk.Lock()
+	defer pm.lk.Unlock()
+
+	return pm.store.ListChannels()
+}
+
+// GetChannelInfo returns the tracked state of the channel `ch`.
+func (pm *Manager) GetChannelInfo(ch address.Address) (*ChannelInfo, error) {
+	pm.lk.Lock()
+	defer pm.lk.Unlock()
+
+	return pm.store.ByAddress(ch)
+}
+
+// AllocateLane allocates a new lane of the channel `ch`.
+func (pm *Manager) AllocateLane(ch address.Address) (uint64, error) {
+	pm.lk.Lock()
+	defer pm.lk.Unlock()
+
+	ci, err := pm.store.ByAddress(ch)
+	if err != nil {
+		return 0, err
+	}
+
+	lane := ci.NextLane
+	ci.NextLane++
+	return lane, pm.store.Put(ci)
+}
+
+// ListVouchers returns the vouchers of the channel `ch`.
+func (pm *Manager) ListVouchers(ch address.Address) ([]*VoucherInfo, error) {
+	pm.lk.Lock()
+	defer pm.lk.Unlock()
+
+	ci, err := pm.store.ByAddress(ch)
+	if err != nil {
+		return nil, err
+	}
+	return ci.Vouchers, nil
+}
+
+// CreateVoucher creates and signs a voucher of the outbound channel `ch`, its nonce
+// is the next nonce of its lane. The voucher is stored with the channel.
+func (pm *Manager) CreateVoucher(ctx context.Context, ch address.Address, voucher paych.SignedVoucher) (*VoucherCreateResult, error) {
+	pm.lk.Lock()
+	defer pm.lk.Unlock()
+
+	ci, err := pm.store.ByAddress(ch)
+	if err != nil {
+		return nil, err
+	}
+	if ci.Direction != DirOutbound {
+		return nil, xerrors.Errorf("cannot create voucher for inbound channel %s", ch)
+	}
+
+	lanes, err := pm.laneStates(ctx, ci)
+	if err != nil {
+		return nil, err
+	}
+
+	voucher.ChannelAddr = ch
+	voucher.Nonce = 1
+	if ls, ok := lanes[voucher.Lane]; ok {
+		voucher.Nonce = ls.nonce + 1
+	}
+
+	vb, err := voucher.SigningBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := pm.api.SignVoucher(ctx, ci.Control, vb)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to sign voucher: %w", err)
+	}
+	voucher.Signature = sig
+
+	if err := pm.checkVoucherValid(ctx, ci, &voucher); err != nil {
+		if shortfall, ok := err.(*ErrInsufficientFunds); ok {
+			return &VoucherCreateResult{Shortfall: shortfall.Shortfall}, nil
+		}
+		return nil, err
+	}
+
+	ci.Vouchers = append(ci.Vouchers, &VoucherInfo{Voucher: &voucher})
+	if voucher.Lane >= ci.NextLane {
+		ci.NextLane = voucher.Lane + 1
+	}
+	if err := pm.store.Put(ci); err != nil {
+		return nil, err
+	}
+	return &VoucherCreateResult{Voucher: &voucher, Shortfall: big.Zero()}, nil
+}
+
+// CheckVoucherValid checks that the voucher `sv` can be redeemed from the channel `ch`.
+func (pm *Manager) CheckVoucherValid(ctx context.Context, ch address.Address, sv *paych.SignedVoucher) error {
+	pm.lk.Lock()
+	defer pm.lk.Unlock()
+
+	ci, err := pm.inboundChannelInfo(ctx, ch)
+	if err != nil {
+		return err
+	}
+	return pm.checkVoucherValid(ctx, ci, sv)
+}
+
+// AddVoucherInbound checks the voucher `sv` received from the payer of the channel
+// `ch` and stores it. It returns the amount it adds to the previous voucher of its
+// lane, which must be at least `minDelta`.
+func (pm *Manager) AddVoucherInbound(ctx context.Context, ch address.Address, sv *paych.SignedVoucher, proof []byte, minDelta big.Int) (big.Int, error) {
+	pm.lk.Lock()
+	defer pm.lk.Unlock()
+
+	ci, err := pm.inboundChannelInfo(ctx, ch)
+	if err != nil {
+		return big.Zero(), err
+	}
+
+	for _, vi := range ci.Vouchers {
+		eq, err := vouchersEqual(vi.Voucher, sv)
+		if err != nil {
+			return big.Zero(), err
+		}
+		if eq {
+			// already added, possibly with a proof now
+			if proof != nil && vi.Proof == nil {
+				vi.Proof = proof
+				return big.Zero(), pm.store.Put(ci)
+			}
+			return big.Zero(), nil
+		}
+	}
+
+	if err := pm.checkVoucherValid(ctx, ci, sv); err != nil {
+		return big.Zero(), err
+	}
+
+	lanes, err := pm.laneStates(ctx, ci)
+	if err != nil {
+		return big.Zero(), err
+	}
+
+	delta := sv.Amount
+	if ls, ok := lanes[sv.Lane]; ok {
+		delta = big.Sub(sv.Amount, ls.redeemed)
+	}
+	if delta.LessThan(minDelta) {
+		return big.Zero(), xerrors.Errorf("voucher adds %s to its lane, less than the expected %s", delta, minDelta)
+	}
+
+	ci.Vouchers = append(ci.Vouchers, &VoucherInfo{Voucher: sv, Proof: proof})
+	if sv.Lane >= ci.NextLane {
+		ci.NextLane = sv.Lane + 1
+	}
+	return delta, pm.store.Put(ci)
+}
+
+// ErrInsufficientFunds is returned when the vouchers of a channel would redeem more
+// than its balance.
+type ErrInsufficientFunds struct {
+	Shortfall big.Int
+}
+
+func (e *ErrInsufficientFunds) Error() string {
+	return "not enough funds in channel to cover voucher, shortfall: " + e.Shortfall.String()
+}
+
+func (pm *Manager) checkVoucherValid(ctx context.Context, ci *ChannelInfo, sv *paych.SignedVoucher) error {
+	if ci.Channel == nil || sv.ChannelAddr != *ci.Channel {
+		return xerrors.Errorf("voucher channel address %s doesn't match channel", sv.ChannelAddr)
+	}
+	if sv.Signature == nil {
+		return xerrors.New("voucher is not signed")
+	}
+	if len(sv.Merges) != 0 {
+		return xerrors.New("vouchers with merges are not supported")
+	}
+
+	fromKey, err := pm.api.StateAccountKey(ctx, ci.from(), block.EmptyTSK)
+	if err != nil {
+		return err
+	}
+
+	vb, err := sv.SigningBytes()
+	if err != nil {
+		return err
+	}
+	if err := crypto.ValidateSignature(vb, fromKey, *sv.Signature); err != nil {
+		return xerrors.Errorf("invalid voucher signature: %w", err)
+	}
+
+	lanes, err := pm.laneStates(ctx, ci)
+	if err != nil {
+		return err
+	}
+
+	if ls, ok := lanes[sv.Lane]; ok {
+		if ls.nonce >= sv.Nonce {
+			return xerrors.Errorf("voucher nonce %d is not higher than lane nonce %d", sv.Nonce, ls.nonce)
+		}
+		if ls.redeemed.GreaterThan(sv.Amount) {
+			return xerrors.Errorf("voucher amount %s is lower than lane redeemed amount %s", sv.Amount, ls.redeemed)
+		}
+	}
+	lanes[sv.Lane] = &laneState{redeemed: sv.Amount, nonce: sv.Nonce}
+
+	total := big.Zero()
+	for _, ls := range lanes {
+		total = big.Add(total, ls.redeemed)
+	}
+
+	// redeemed funds only leave the channel when it is collected
+	act, _, err := pm.api.GetPaychState(ctx, *ci.Channel)
+	if err != nil {
+		return err
+	}
+	if shortfall := big.Sub(total, act.Balance); shortfall.GreaterThan(big.Zero()) {
+		return &ErrInsufficientFunds{Shortfall: shortfall}
+	}
+	return nil
+}
+
+// laneStates merges the lane states of the channel on chain with its vouchers.
+func (pm *Manager) laneStates(ctx context.Context, ci *ChannelInfo) (map[uint64]*laneState, error) {
+	lanes := make(map[uint64]*laneState)
+
+	_, st, err := pm.api.GetPaychState(ctx, *ci.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := st.ForEachLaneState(func(idx uint64, ls paych.LaneState) error {
+		redeemed, err := ls.Redeemed()
+		if err != nil {
+			return err
+		}
+		nonce, err := ls.Nonce()
+		if err != nil {
+			return err
+		}
+		lanes[idx] = &laneState{redeemed: redeemed, nonce: nonce}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, vi := range ci.Vouchers {
+		sv := vi.Voucher
+		ls, ok := lanes[sv.Lane]
+		if !ok {
+			lanes[sv.Lane] = &laneState{redeemed: sv.Amount, nonce: sv.Nonce}
+			continue
+		}
+		if sv.Nonce > ls.nonce {
+			ls.nonce = sv.Nonce
+			ls.redeemed = sv.Amount
+		}
+	}
+	return lanes, nil
+}
+
+// inboundChannelInfo returns the channel `ch`, and starts tracking it if it is an
+// inbound channel the node doesn't know yet.
+func (pm *Manager) inboundChannelInfo(ctx context.Context, ch address.Address) (*ChannelInfo, error) {
+	ci, err := pm.store.ByAddress(ch)
+	if err == nil {
+		if ci.Direction == DirInbound {
+			if err := pm.refreshInboundAmount(ctx, ci); err != nil {
+				return nil, err
+			}
+		}
+		return ci, nil
+	}
+	if err != ErrChannelNotTracked {
+		return nil, err
+	}
+
+	act, st, err := pm.api.GetPaychState(ctx, ch)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := st.From()
+	if err != nil {
+		return nil, err
+	}
+	to, err := st.To()
+	if err != nil {
+		return nil, err
+	}
+
+	toKey, err := pm.api.StateAccountKey(ctx, to, block.EmptyTSK)
+	if err != nil {
+		return nil, err
+	}
+	has, err := pm.api.WalletHas(ctx, toKey)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, xerrors.Errorf("cannot track inbound channel %s, its recipient %s is not in the wallet", ch, to)
+	}
+
+	ci = &ChannelInfo{
+		ChannelID:     ch.String(),
+		Channel:       &ch,
+		Control:       to,
+		Target:        from,
+		Direction:     DirInbound,
+		Amount:        act.Balance,
+		PendingAmount: big.Zero(),
+	}
+	if err := pm.store.Put(ci); err != nil {
+		return nil, err
+	}
+	return ci, nil
+}
+
+// refreshInboundAmount updates the funds of the inbound channel `ci` with its balance on
+// chain, the payer adds funds to the channel without the node knowing.
+func (pm *Manager) refreshInboundAmount(ctx context.Context, ci *ChannelInfo) error {
+	act, _, err := pm.api.GetPaychState(ctx, *ci.Channel)
+	if err != nil {
+		return err
+	}
+	if ci.Amount.Equals(act.Balance) {
+		return nil
+	}
+
+	ci.Amount = act.Balance
+	return pm.store.Put(ci)
+}
+
+// SubmitVoucher submits the voucher `sv` of the channel `ch` to the chain, to redeem it.
+func (pm *Manager) SubmitVoucher(ctx context.Context, ch address.Address, sv *paych.SignedVoucher, secret []byte) (cid.Cid, error) {
+	pm.lk.Lock()
+	defer pm.lk.Unlock()
+
+	ci, err := pm.store.ByAddress(ch)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	var stored *VoucherInfo
+	for _, vi := range ci.Vouchers {
+		eq, err := vouchersEqual(vi.Voucher, sv)
+		if err != nil {
+			return cid.Undef, err
+		}
+		if eq {
+			stored = vi
+			break
+		}
+	}
+	if stored == nil {
+		return cid.Undef, xerrors.New("voucher was not added to the channel")
+	}
+	if stored.Submitted {
+		return cid.Undef, xerrors.New("voucher has already been submitted")
+	}
+
+	mb, err := pm.messageBuilder(ctx, ci.Control)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	msg, err := mb.Update(ch, sv, secret)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	mcid, err := pm.api.PushMessage(ctx, msg)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	stored.Submitted = true
+	return mcid, pm.store.Put(ci)
+}
+
+// Settle settles the channel `ch`, it can be collected once the settling period is over.
+// The channel is settling once the returned message landed on chain, see GetPaychWaitReady.
+func (pm *Manager) Settle(ctx context.Context, ch address.Address) (cid.Cid, error) {
+	pm.lk.Lock()
+	defer pm.lk.Unlock()
+
+	ci, err := pm.store.ByAddress(ch)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	mb, err := pm.messageBuilder(ctx, ci.Control)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	msg, err := mb.Settle(ch)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	mcid, err := pm.api.PushMessage(ctx, msg)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	ci.SettleMsg = &mcid
+	if err := pm.trackMessage(ci, mcid, big.Zero()); err != nil {
+		return cid.Undef, err
+	}
+	return mcid, nil
+}
+
+// Collect sends the redeemed funds of the settled channel `ch` to its recipient, and the
+// rest back to its payer.
+func (pm *Manager) Collect(ctx context.Context, ch address.Address) (cid.Cid, error) {
+	pm.lk.Lock()
+	defer pm.lk.Unlock()
+
+	ci, err := pm.store.ByAddress(ch)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	mb, err := pm.messageBuilder(ctx, ci.Control)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	msg, err := mb.Collect(ch)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	return pm.api.PushMessage(ctx, msg)
+}
+
+func (pm *Manager) messageBuilder(ctx context.Context, from address.Address) (paych.MessageBuilder, error) {
+	nv, err := pm.api.StateNetworkVersion(ctx, block.EmptyTSK)
+	if err != nil {
+		return nil, err
+	}
+	return paych.Message(specactors.VersionForNetwork(nv), from), nil
+}
+
+func vouchersEqual(a, b *paych.SignedVoucher) (bool, error) {
+	ab, err := a.SigningBytes()
+	if err != nil {
+		return false, err
+	}
+	bb, err := b.SigningBytes()
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(ab, bb), nil
+}
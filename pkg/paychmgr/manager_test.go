@@ -0,0 +1,375 @@
+package paychmgr
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/filecoin-project/go-state-types/network"
+	init2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/init"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/filecoin-project/venus/pkg/repo"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/paych"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/paych/mock"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+type waitResult struct {
+	receipt *types.MessageReceipt
+	err     error
+}
+
+// testManagerAPI records the pushed messages, which land on chain when the test says so.
+type testManagerAPI struct {
+	lk      sync.Mutex
+	signer  types.MockSigner
+	actors  map[address.Address]*types.Actor
+	states  map[address.Address]paych.State
+	msgs    []*types.UnsignedMessage
+	results map[cid.Cid]chan waitResult
+}
+
+func newTestManagerAPI() *testManagerAPI {
+	signer, _ := types.NewMockSignersAndKeyInfo(2)
+	return &testManagerAPI{
+		signer:  signer,
+		actors:  make(map[address.Address]*types.Actor),
+		states:  make(map[address.Address]paych.State),
+		results: make(map[cid.Cid]chan waitResult),
+	}
+}
+
+var _ ManagerAPI = (*testManagerAPI)(nil)
+
+func (api *testManagerAPI) StateNetworkVersion(context.Context, block.TipSetKey) (network.Version, error) {
+	return network.Version10, nil
+}
+
+func (api *testManagerAPI) StateAccountKey(_ context.Context, addr address.Address, _ block.TipSetKey) (address.Address, error) {
+	return addr, nil
+}
+
+func (api *testManagerAPI) GetPaychState(_ context.Context, ch address.Address) (*types.Actor, paych.State, error) {
+	api.lk.Lock()
+	defer api.lk.Unlock()
+
+	act, ok := api.actors[ch]
+	if !ok {
+		return nil, nil, xerrors.Errorf("actor %s not found", ch)
+	}
+	return act, api.states[ch], nil
+}
+
+func (api *testManagerAPI) setChannel(ch address.Address, balance int64, st paych.State) {
+	api.lk.Lock()
+	defer api.lk.Unlock()
+
+	api.actors[ch] = &types.Actor{Balance: big.NewInt(balance)}
+	api.states[ch] = st
+}
+
+func (api *testManagerAPI) PushMessage(_ context.Context, msg *types.UnsignedMessage) (cid.Cid, error) {
+	api.lk.Lock()
+	defer api.lk.Unlock()
+
+	msg.Nonce = uint64(len(api.msgs))
+	api.msgs = append(api.msgs, msg)
+	return msg.Cid()
+}
+
+func (api *testManagerAPI) lastMessage() *types.UnsignedMessage {
+	api.lk.Lock()
+	defer api.lk.Unlock()
+
+	return api.msgs[len(api.msgs)-1]
+}
+
+func (api *testManagerAPI) result(mcid cid.Cid) chan waitResult {
+	api.lk.Lock()
+	defer api.lk.Unlock()
+
+	res, ok := api.results[mcid]
+	if !ok {
+		res = make(chan waitResult, 1)
+		api.results[mcid] = res
+	}
+	return res
+}
+
+// land lands the message `mcid` on chain with the exit code `code`.
+func (api *testManagerAPI) land(mcid cid.Cid, code exitcode.ExitCode, ret []byte) {
+	api.result(mcid) <- waitResult{receipt: &types.MessageReceipt{ExitCode: code, ReturnValue: ret}}
+}
+
+func (api *testManagerAPI) WaitMsg(ctx context.Context, mcid cid.Cid) (*types.MessageReceipt, error) {
+	select {
+	case res := <-api.result(mcid):
+		return res.receipt, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (api *testManagerAPI) WalletHas(ctx context.Context, addr address.Address) (bool, error) {
+	return api.signer.HasAddress(ctx, addr)
+}
+
+func (api *testManagerAPI) SignVoucher(ctx context.Context, addr address.Address, data []byte) (*crypto.Signature, error) {
+	return api.signer.SignBytes(ctx, data, addr)
+}
+
+func newTestManager(t *testing.T) (*Manager, *testManagerAPI) {
+	api := newTestManagerAPI()
+	pm := NewManager(context.Background(), NewStore(repo.NewInMemoryRepo().MetaDatastore()), api)
+	require.NoError(t, pm.Start())
+	t.Cleanup(pm.Stop)
+	return pm, api
+}
+
+func createReturn(t *testing.T, ch address.Address) []byte {
+	idAddr, err := address.NewIDAddress(100)
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, (&init2.ExecReturn{IDAddress: idAddr, RobustAddress: ch}).MarshalCBOR(buf))
+	return buf.Bytes()
+}
+
+func assertAmount(t *testing.T, expected int64, actual big.Int) {
+	assert.True(t, big.NewInt(expected).Equals(actual), "expected %d, got %s", expected, actual)
+}
+
+func TestPaychOutbound(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	pm, api := newTestManager(t)
+	from, to := api.signer.Addresses[0], api.signer.Addresses[1]
+	ch := types.NewForTestGetter()()
+
+	chAddr, createMsg, err := pm.GetPaych(ctx, from, to, big.NewInt(100))
+	require.NoError(t, err)
+	assert.Equal(t, address.Undef, chAddr)
+
+	api.setChannel(ch, 100, mock.NewMockPayChState(from, to, 0, map[uint64]paych.LaneState{}))
+	api.land(createMsg, exitcode.Ok, createReturn(t, ch))
+	chAddr, err = pm.GetPaychWaitReady(ctx, createMsg)
+	require.NoError(t, err)
+	assert.Equal(t, ch, chAddr)
+
+	// a failed wait is reported to the waiters, and retried by the next wait
+	_, addFundsMsg, err := pm.GetPaych(ctx, from, to, big.NewInt(50))
+	require.NoError(t, err)
+	api.result(addFundsMsg) <- waitResult{err: xerrors.New("wait failed")}
+	_, err = pm.GetPaychWaitReady(ctx, addFundsMsg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "wait failed")
+
+	api.setChannel(ch, 150, mock.NewMockPayChState(from, to, 0, map[uint64]paych.LaneState{}))
+	api.land(addFundsMsg, exitcode.Ok, nil)
+	chAddr, err = pm.GetPaychWaitReady(ctx, addFundsMsg)
+	require.NoError(t, err)
+	assert.Equal(t, ch, chAddr)
+
+	funds, err := pm.AvailableFunds(ctx, ch)
+	require.NoError(t, err)
+	assertAmount(t, 150, funds.ConfirmedAmt)
+	assertAmount(t, 0, funds.PendingAmt)
+	assert.Nil(t, funds.PendingWaitSentinel)
+
+	// the vouchers of a lane take the next nonces
+	lane, err := pm.AllocateLane(ch)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), lane)
+
+	for i, amt := range []int64{60, 100} {
+		res, err := pm.CreateVoucher(ctx, ch, paych.SignedVoucher{Lane: lane, Amount: big.NewInt(amt)})
+		require.NoError(t, err)
+		require.NotNil(t, res.Voucher)
+		assert.Equal(t, uint64(i+1), res.Voucher.Nonce)
+		assert.Equal(t, ch, res.Voucher.ChannelAddr)
+	}
+
+	// the vouchers of all the lanes are covered by the balance of the channel
+	res, err := pm.CreateVoucher(ctx, ch, paych.SignedVoucher{Lane: lane + 1, Amount: big.NewInt(60)})
+	require.NoError(t, err)
+	assert.Nil(t, res.Voucher)
+	assertAmount(t, 10, res.Shortfall)
+
+	funds, err = pm.AvailableFunds(ctx, ch)
+	require.NoError(t, err)
+	assertAmount(t, 100, funds.VoucherRedeemedAmt)
+
+	vouchers, err := pm.ListVouchers(ch)
+	require.NoError(t, err)
+	assert.Len(t, vouchers, 2)
+}
+
+func TestPaychCreateFails(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	pm, api := newTestManager(t)
+	from, to := api.signer.Addresses[0], api.signer.Addresses[1]
+
+	_, createMsg, err := pm.GetPaych(ctx, from, to, big.NewInt(100))
+	require.NoError(t, err)
+
+	api.land(createMsg, exitcode.ErrInsufficientFunds, nil)
+	_, err = pm.GetPaychWaitReady(ctx, createMsg)
+	require.Error(t, err)
+
+	// the channel is forgotten, another one can be created
+	_, err = pm.store.OutboundActiveByFromTo(from, to)
+	assert.Equal(t, ErrChannelNotTracked, err)
+
+	_, retryMsg, err := pm.GetPaych(ctx, from, to, big.NewInt(100))
+	require.NoError(t, err)
+	assert.NotEqual(t, createMsg, retryMsg)
+}
+
+func TestPaychInboundVouchers(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	pm, api := newTestManager(t)
+	from, to := api.signer.Addresses[0], api.signer.Addresses[1]
+	ch := types.NewForTestGetter()()
+
+	// lane 1 was redeemed up to 10 with nonce 2 on chain
+	api.setChannel(ch, 100, mock.NewMockPayChState(from, to, 0, map[uint64]paych.LaneState{
+		1: mock.NewMockLaneState(big.NewInt(10), 2),
+	}))
+
+	voucher := func(signer address.Address, lane, nonce uint64, amt int64) *paych.SignedVoucher {
+		sv := &paych.SignedVoucher{ChannelAddr: ch, Lane: lane, Nonce: nonce, Amount: big.NewInt(amt)}
+		vb, err := sv.SigningBytes()
+		require.NoError(t, err)
+		sv.Signature, err = api.signer.SignBytes(ctx, vb, signer)
+		require.NoError(t, err)
+		return sv
+	}
+
+	require.NoError(t, pm.CheckVoucherValid(ctx, ch, voucher(from, 0, 1, 50)))
+
+	other := voucher(from, 0, 1, 50)
+	other.ChannelAddr = types.NewForTestGetter()()
+	assert.Error(t, pm.CheckVoucherValid(ctx, ch, other), "wrong channel")
+	assert.Error(t, pm.CheckVoucherValid(ctx, ch, voucher(to, 0, 1, 50)), "not signed by the payer")
+	assert.Error(t, pm.CheckVoucherValid(ctx, ch, voucher(from, 1, 2, 20)), "nonce not above the lane nonce")
+	assert.Error(t, pm.CheckVoucherValid(ctx, ch, voucher(from, 1, 3, 5)), "amount below the redeemed amount")
+	require.NoError(t, pm.CheckVoucherValid(ctx, ch, voucher(from, 1, 3, 10)), "same amount with a higher nonce")
+
+	err := pm.CheckVoucherValid(ctx, ch, voucher(from, 2, 1, 95))
+	require.Error(t, err)
+	shortfall, ok := err.(*ErrInsufficientFunds)
+	require.True(t, ok)
+	assertAmount(t, 5, shortfall.Shortfall)
+
+	// the added vouchers are accounted in their lane
+	delta, err := pm.AddVoucherInbound(ctx, ch, voucher(from, 0, 1, 30), nil, big.Zero())
+	require.NoError(t, err)
+	assertAmount(t, 30, delta)
+
+	delta, err = pm.AddVoucherInbound(ctx, ch, voucher(from, 0, 2, 50), nil, big.Zero())
+	require.NoError(t, err)
+	assertAmount(t, 20, delta)
+
+	delta, err = pm.AddVoucherInbound(ctx, ch, voucher(from, 0, 2, 50), nil, big.Zero())
+	require.NoError(t, err)
+	assertAmount(t, 0, delta) // vouchers are added once
+
+	_, err = pm.AddVoucherInbound(ctx, ch, voucher(from, 0, 3, 55), nil, big.NewInt(10))
+	assert.Error(t, err, "delta below the minimum")
+
+	delta, err = pm.AddVoucherInbound(ctx, ch, voucher(from, 1, 3, 15), nil, big.Zero())
+	require.NoError(t, err)
+	assertAmount(t, 5, delta)
+
+	funds, err := pm.AvailableFunds(ctx, ch)
+	require.NoError(t, err)
+	assertAmount(t, 100, funds.ConfirmedAmt)
+	assertAmount(t, 65, funds.VoucherRedeemedAmt)
+
+	// the funds added by the payer are read from the chain
+	api.setChannel(ch, 200, mock.NewMockPayChState(from, to, 0, map[uint64]paych.LaneState{
+		1: mock.NewMockLaneState(big.NewInt(10), 2),
+	}))
+	funds, err = pm.AvailableFunds(ctx, ch)
+	require.NoError(t, err)
+	assertAmount(t, 200, funds.ConfirmedAmt)
+
+	require.NoError(t, pm.CheckVoucherValid(ctx, ch, voucher(from, 2, 1, 130)))
+}
+
+func TestPaychSettleCollect(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	pm, api := newTestManager(t)
+	from, to := api.signer.Addresses[0], api.signer.Addresses[1]
+	ch := types.NewForTestGetter()()
+
+	require.NoError(t, pm.store.Put(&ChannelInfo{
+		ChannelID:     ch.String(),
+		Channel:       &ch,
+		Control:       from,
+		Target:        to,
+		Direction:     DirOutbound,
+		Amount:        big.NewInt(100),
+		PendingAmount: big.Zero(),
+	}))
+
+	settleMsg, err := pm.Settle(ctx, ch)
+	require.NoError(t, err)
+	msg := api.lastMessage()
+	assert.Equal(t, ch, msg.To)
+	assert.Equal(t, paych.Methods.Settle, msg.Method)
+
+	// the channel is only settling once the message landed
+	ci, err := pm.GetChannelInfo(ch)
+	require.NoError(t, err)
+	assert.False(t, ci.Settling)
+	_, _, err = pm.GetPaych(ctx, from, to, big.NewInt(10))
+	assert.Error(t, err, "no funds are added to a channel being settled")
+
+	api.land(settleMsg, exitcode.ErrForbidden, nil)
+	_, err = pm.GetPaychWaitReady(ctx, settleMsg)
+	require.Error(t, err)
+	ci, err = pm.GetChannelInfo(ch)
+	require.NoError(t, err)
+	assert.False(t, ci.Settling)
+	assert.Nil(t, ci.SettleMsg)
+
+	settleMsg, err = pm.Settle(ctx, ch)
+	require.NoError(t, err)
+	api.land(settleMsg, exitcode.Ok, nil)
+	chAddr, err := pm.GetPaychWaitReady(ctx, settleMsg)
+	require.NoError(t, err)
+	assert.Equal(t, ch, chAddr)
+
+	ci, err = pm.GetChannelInfo(ch)
+	require.NoError(t, err)
+	assert.True(t, ci.Settling)
+	assertAmount(t, 100, ci.Amount)
+	_, err = pm.store.OutboundActiveByFromTo(from, to)
+	assert.Equal(t, ErrChannelNotTracked, err)
+
+	_, err = pm.Collect(ctx, ch)
+	require.NoError(t, err)
+	msg = api.lastMessage()
+	assert.Equal(t, ch, msg.To)
+	assert.Equal(t, from, msg.From)
+	assert.Equal(t, paych.Methods.Collect, msg.Method)
+}
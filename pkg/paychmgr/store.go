@@ -0,0 +1,292 @@
+package paychmgr
+
+import (
+	"encoding/json"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	dsq "github.com/ipfs/go-datastore/query"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/repo"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/paych"
+)
+
+// ErrChannelNotTracked is returned when a channel is not known to the store.
+var ErrChannelNotTracked = xerrors.New("channel not tracked")
+
+var (
+	dsPrefix       = ds.NewKey("/paych")
+	channelsPrefix = ds.NewKey("/channels")
+	msgsPrefix     = ds.NewKey("/msgs")
+)
+
+// Direction of the payments of a channel, as seen by this node.
+const (
+	DirInbound  = 1
+	DirOutbound = 2
+)
+
+// VoucherInfo is a voucher of a channel along with the proof to submit with it.
+type VoucherInfo struct {
+	Voucher *paych.SignedVoucher
+	Proof   []byte
+	// Submitted is true once the voucher has been submitted to the chain
+	Submitted bool
+}
+
+// ChannelInfo is the state of a payment channel tracked by the manager.
+type ChannelInfo struct {
+	// ChannelID uniquely identifies the channel in the store, the channel address is
+	// not known until the create message is on chain.
+	ChannelID string
+	// Channel is the address of the channel actor, nil until the channel is created
+	Channel *address.Address
+	// Control is the address of the local node's party of the channel
+	Control address.Address
+	// Target is the address of the remote party of the channel
+	Target address.Address
+	// Direction is either DirInbound or DirOutbound
+	Direction uint64
+	// Vouchers are the vouchers created (outbound) or received (inbound)
+	Vouchers []*VoucherInfo
+	// NextLane is the next lane number to allocate
+	NextLane uint64
+	// Amount is the amount of funds confirmed on chain, the balance of the channel for
+	// inbound channels
+	Amount big.Int
+	// PendingAmount is the amount of funds in messages waiting to land on chain
+	PendingAmount big.Int
+	// CreateMsg is the message creating the channel, nil once it landed on chain
+	CreateMsg *cid.Cid
+	// AddFundsMsg is the last message adding funds to the channel, nil once it
+	// landed on chain
+	AddFundsMsg *cid.Cid
+	// SettleMsg is the message settling the channel, nil once it landed on chain
+	SettleMsg *cid.Cid
+	// Settling is true once the message settling the channel landed on chain
+	Settling bool
+}
+
+// MsgInfo tracks a message creating, funding or settling a channel.
+type MsgInfo struct {
+	// ChannelID is the id of the channel the message creates or funds
+	ChannelID string
+	MsgCid    cid.Cid
+	// Amount is the amount of funds sent by the message
+	Amount big.Int
+	// Received is true once the message landed on chain
+	Received bool
+	// Err is the reason the message failed, if it did
+	Err string
+}
+
+// from returns the address of the party paying through the channel.
+func (ci *ChannelInfo) from() address.Address {
+	if ci.Direction == DirOutbound {
+		return ci.Control
+	}
+	return ci.Target
+}
+
+// to returns the address of the party paid through the channel.
+func (ci *ChannelInfo) to() address.Address {
+	if ci.Direction == DirOutbound {
+		return ci.Target
+	}
+	return ci.Control
+}
+
+// Store persists the state of payment channels in a datastore.
+type Store struct {
+	ds ds.Batching
+}
+
+// NewStore creates a store of channels in the metadata datastore `dstore`.
+func NewStore(dstore repo.Datastore) *Store {
+	return &Store{
+		ds: namespace.Wrap(dstore, dsPrefix),
+	}
+}
+
+// Put stores the channel `ci`, replacing its previous state.
+func (ps *Store) Put(ci *ChannelInfo) error {
+	b, err := json.Marshal(ci)
+	if err != nil {
+		return err
+	}
+	return ps.ds.Put(channelsPrefix.ChildString(ci.ChannelID), b)
+}
+
+// RemoveChannel forgets the channel with the id `channelID`.
+func (ps *Store) RemoveChannel(channelID string) error {
+	return ps.ds.Delete(channelsPrefix.ChildString(channelID))
+}
+
+// ByChannelID returns the channel with the id `channelID`.
+func (ps *Store) ByChannelID(channelID string) (*ChannelInfo, error) {
+	b, err := ps.ds.Get(channelsPrefix.ChildString(channelID))
+	if err != nil {
+		if err == ds.ErrNotFound {
+			return nil, ErrChannelNotTracked
+		}
+		return nil, err
+	}
+	return unmarshalChannelInfo(b)
+}
+
+// SaveNewMessage records the message `mcid`, sending `amt` to the channel `channelID`.
+func (ps *Store) SaveNewMessage(channelID string, mcid cid.Cid, amt big.Int) error {
+	return ps.putMessage(&MsgInfo{
+		ChannelID: channelID,
+		MsgCid:    mcid,
+		Amount:    amt,
+	})
+}
+
+// SaveMessageResult records that the message `mcid` landed on chain, and failed if
+// `msgErr` is not nil.
+func (ps *Store) SaveMessageResult(mcid cid.Cid, msgErr error) error {
+	mi, err := ps.GetMessage(mcid)
+	if err != nil {
+		return err
+	}
+
+	mi.Received = true
+	if msgErr != nil {
+		mi.Err = msgErr.Error()
+	}
+	return ps.putMessage(mi)
+}
+
+// GetMessage returns the message `mcid`.
+func (ps *Store) GetMessage(mcid cid.Cid) (*MsgInfo, error) {
+	b, err := ps.ds.Get(msgsPrefix.ChildString(mcid.String()))
+	if err != nil {
+		if err == ds.ErrNotFound {
+			return nil, xerrors.Errorf("message %s is not tracked", mcid)
+		}
+		return nil, err
+	}
+
+	mi := &MsgInfo{}
+	if err := json.Unmarshal(b, mi); err != nil {
+		return nil, err
+	}
+	return mi, nil
+}
+
+// PendingMessages returns the messages which have not landed on chain yet.
+func (ps *Store) PendingMessages() ([]*MsgInfo, error) {
+	res, err := ps.ds.Query(dsq.Query{Prefix: msgsPrefix.String()})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close() //nolint:errcheck
+
+	var pending []*MsgInfo
+	for entry := range res.Next() {
+		if entry.Error != nil {
+			return nil, entry.Error
+		}
+
+		mi := &MsgInfo{}
+		if err := json.Unmarshal(entry.Value, mi); err != nil {
+			return nil, xerrors.Errorf("failed to unmarshal message %s: %w", entry.Key, err)
+		}
+		if !mi.Received {
+			pending = append(pending, mi)
+		}
+	}
+	return pending, nil
+}
+
+func (ps *Store) putMessage(mi *MsgInfo) error {
+	b, err := json.Marshal(mi)
+	if err != nil {
+		return err
+	}
+	return ps.ds.Put(msgsPrefix.ChildString(mi.MsgCid.String()), b)
+}
+
+// ByAddress returns the channel at the address `ch`.
+func (ps *Store) ByAddress(ch address.Address) (*ChannelInfo, error) {
+	return ps.findChan(func(ci *ChannelInfo) bool {
+		return ci.Channel != nil && *ci.Channel == ch
+	})
+}
+
+// OutboundActiveByFromTo returns the outbound channel from `from` to `to` which is
+// not settling.
+func (ps *Store) OutboundActiveByFromTo(from address.Address, to address.Address) (*ChannelInfo, error) {
+	return ps.findChan(func(ci *ChannelInfo) bool {
+		return ci.Direction == DirOutbound && !ci.Settling && ci.Control == from && ci.Target == to
+	})
+}
+
+// ListChannels returns the addresses of the channels which have been created.
+func (ps *Store) ListChannels() ([]address.Address, error) {
+	cis, err := ps.findChans(func(ci *ChannelInfo) bool {
+		return ci.Channel != nil
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]address.Address, 0, len(cis))
+	for _, ci := range cis {
+		addrs = append(addrs, *ci.Channel)
+	}
+	return addrs, nil
+}
+
+func (ps *Store) findChan(filter func(*ChannelInfo) bool) (*ChannelInfo, error) {
+	cis, err := ps.findChans(filter, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(cis) == 0 {
+		return nil, ErrChannelNotTracked
+	}
+	return cis[0], nil
+}
+
+// findChans returns the channels matching `filter`, up to `max` of them if it is not 0.
+func (ps *Store) findChans(filter func(*ChannelInfo) bool, max int) ([]*ChannelInfo, error) {
+	res, err := ps.ds.Query(dsq.Query{Prefix: channelsPrefix.String()})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close() //nolint:errcheck
+
+	var matches []*ChannelInfo
+	for entry := range res.Next() {
+		if entry.Error != nil {
+			return nil, entry.Error
+		}
+
+		ci, err := unmarshalChannelInfo(entry.Value)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to unmarshal channel %s: %w", entry.Key, err)
+		}
+
+		if filter(ci) {
+			matches = append(matches, ci)
+			if max > 0 && len(matches) == max {
+				break
+			}
+		}
+	}
+	return matches, nil
+}
+
+func unmarshalChannelInfo(b []byte) (*ChannelInfo, error) {
+	ci := &ChannelInfo{}
+	if err := json.Unmarshal(b, ci); err != nil {
+		return nil, err
+	}
+	return ci, nil
+}
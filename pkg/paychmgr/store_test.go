@@ -0,0 +1,76 @@
+package paychmgr
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/repo"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestStore(t *testing.T) {
+	tf.UnitTest(t)
+
+	store := NewStore(repo.NewInMemoryRepo().MetaDatastore())
+	addrs := types.NewForTestGetter()
+	from, to, ch := addrs(), addrs(), addrs()
+	mcid := types.CidFromString(t, "create")
+
+	_, err := store.OutboundActiveByFromTo(from, to)
+	assert.Equal(t, ErrChannelNotTracked, err)
+
+	ci := &ChannelInfo{
+		ChannelID:     mcid.String(),
+		Control:       from,
+		Target:        to,
+		Direction:     DirOutbound,
+		Amount:        big.Zero(),
+		PendingAmount: big.NewInt(10),
+		CreateMsg:     &mcid,
+	}
+	require.NoError(t, store.SaveNewMessage(ci.ChannelID, mcid, big.NewInt(10)))
+	require.NoError(t, store.Put(ci))
+
+	found, err := store.OutboundActiveByFromTo(from, to)
+	require.NoError(t, err)
+	assert.Equal(t, ci.ChannelID, found.ChannelID)
+
+	// the channel is only listed once it has an address
+	chans, err := store.ListChannels()
+	require.NoError(t, err)
+	assert.Empty(t, chans)
+
+	pending, err := store.PendingMessages()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, mcid, pending[0].MsgCid)
+	assert.True(t, big.NewInt(10).Equals(pending[0].Amount))
+
+	ci.Channel = &ch
+	ci.CreateMsg = nil
+	require.NoError(t, store.Put(ci))
+	require.NoError(t, store.SaveMessageResult(mcid, nil))
+
+	pending, err = store.PendingMessages()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	mi, err := store.GetMessage(mcid)
+	require.NoError(t, err)
+	assert.True(t, mi.Received)
+	assert.Empty(t, mi.Err)
+
+	chans, err = store.ListChannels()
+	require.NoError(t, err)
+	assert.Equal(t, []address.Address{ch}, chans)
+
+	found, err = store.ByAddress(ch)
+	require.NoError(t, err)
+	assert.Equal(t, from, found.Control)
+	assert.Nil(t, found.CreateMsg)
+}
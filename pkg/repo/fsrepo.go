@@ -22,6 +22,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/splitstore"
 )
 
 const (
@@ -34,6 +35,7 @@ const (
 	walletDatastorePrefix = "wallet"
 	chainDatastorePrefix  = "chain"
 	metaDatastorePrefix   = "metadata"
+	splitstorePrefix      = "splitstore"
 	// dealsDatastorePrefix   = "deals"
 	snapshotStorePrefix    = "snapshots"
 	snapshotFilenamePrefix = "snapshot"
@@ -52,7 +54,7 @@ type FSRepo struct {
 	lk  sync.RWMutex
 	cfg *config.Config
 
-	ds        *blockstoreutil.BadgerBlockstore
+	ds        closableBlockstore
 	stagingDs Datastore
 	mds       *multistore.MultiStore
 	keystore  keystore.Keystore
@@ -66,6 +68,11 @@ type FSRepo struct {
 
 var _ Repo = (*FSRepo)(nil)
 
+type closableBlockstore interface {
+	blockstoreutil.Blockstore
+	io.Closer
+}
+
 // InitFSRepo initializes a new repo at the target path with the provided configuration.
 // The successful result creates a symlink at targetPath pointing to a sibling directory
 // named with a timestamp and repo version number.
@@ -412,13 +419,7 @@ func (r *FSRepo) readVersion() (uint, error) {
 func (r *FSRepo) openDatastore() error {
 	switch r.cfg.Datastore.Type {
 	case "badgerds":
-		path := filepath.Join(r.path, r.cfg.Datastore.Path)
-		opts, err := blockstoreutil.BadgerBlockstoreOptions(path, false)
-		if err != nil {
-			return err
-		}
-		opts.Prefix = bstore.BlockPrefix.String()
-		ds, err := blockstoreutil.Open(opts)
+		ds, err := openBadgerBlockstore(filepath.Join(r.path, r.cfg.Datastore.Path))
 		if err != nil {
 			return err
 		}
@@ -427,9 +428,65 @@ func (r *FSRepo) openDatastore() error {
 		return fmt.Errorf("unknown datastore type in config: %s", r.cfg.Datastore.Type)
 	}
 
+	if ssCfg := r.cfg.Datastore.SplitStore; ssCfg != nil && ssCfg.Enable {
+		ss, err := r.openSplitStore(r.ds, ssCfg)
+		if err != nil {
+			return errors.Wrap(err, "failed to open split store")
+		}
+		r.ds = ss
+	}
+
 	return nil
 }
 
+func openBadgerBlockstore(path string) (*blockstoreutil.BadgerBlockstore, error) {
+	opts, err := blockstoreutil.BadgerBlockstoreOptions(path, false)
+	if err != nil {
+		return nil, err
+	}
+	opts.Prefix = bstore.BlockPrefix.String()
+	return blockstoreutil.Open(opts)
+}
+
+// openSplitStore wraps the hot blockstore into a split store, with old objects moved
+// to a cold badger store or discarded.
+func (r *FSRepo) openSplitStore(hot closableBlockstore, cfg *config.SplitStoreConfig) (*splitstore.SplitStore, error) {
+	var cold blockstoreutil.Blockstore
+	switch cfg.ColdStoreType {
+	case "badgerds":
+		coldBs, err := openBadgerBlockstore(filepath.Join(r.path, cfg.ColdStorePath))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open cold store")
+		}
+		cold = coldBs
+	case "discard":
+	default:
+		return nil, fmt.Errorf("unknown cold store type in config: %s", cfg.ColdStoreType)
+	}
+
+	closeCold := func() {
+		if closer, ok := cold.(interface{ Close() error }); ok {
+			_ = closer.Close()
+		}
+	}
+
+	meta, err := badgerds.NewDatastore(filepath.Join(r.path, splitstorePrefix), badgerOptions())
+	if err != nil {
+		closeCold()
+		return nil, err
+	}
+
+	ss, err := splitstore.NewSplitStore(hot, cold, meta, splitstore.Config{
+		HotStoreFinalities: cfg.HotStoreFinalities,
+	})
+	if err != nil {
+		_ = meta.Close()
+		closeCold()
+		return nil, err
+	}
+	return ss, nil
+}
+
 func (r *FSRepo) openKeystore() error {
 	ksp := filepath.Join(r.path, "keystore")
 
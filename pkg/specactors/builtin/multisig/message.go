@@ -52,6 +52,12 @@ type MessageBuilder interface {
 type ProposalHashData = multisig3.ProposalHashData
 type ProposeReturn = multisig3.ProposeReturn
 
+// signer management params are the same in all versions, changes are proposed to the
+// multisig itself and executed once approved
+type AddSignerParams = multisig3.AddSignerParams
+type RemoveSignerParams = multisig3.RemoveSignerParams
+type SwapSignerParams = multisig3.SwapSignerParams
+
 func txnParams(id uint64, data *ProposalHashData) ([]byte, error) {
 	params := multisig3.TxnIDParams{ID: multisig3.TxnID(id)}
 	if data != nil {
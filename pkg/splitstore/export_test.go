@@ -0,0 +1,8 @@
+package splitstore
+
+import "github.com/filecoin-project/venus/pkg/block"
+
+// Compact runs a compaction up to `curTs` synchronously.
+func (s *SplitStore) Compact(curTs *block.TipSet) error {
+	return s.compact(curTs)
+}
@@ -0,0 +1,714 @@
+package splitstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	"github.com/ipfs/go-datastore/query"
+	dshelp "github.com/ipfs/go-ipfs-ds-help"
+	logging "github.com/ipfs/go-log/v2"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/specactors/policy"
+	"github.com/filecoin-project/venus/pkg/util/blockstoreutil"
+)
+
+var log = logging.Logger("splitstore")
+
+var (
+	// baseEpochKey is the key at which the boundary epoch of the last compaction is stored.
+	baseEpochKey = datastore.NewKey("/splitstore/baseEpoch")
+
+	// warmupKey is written once all objects which existed before the split store
+	// was enabled have been tracked.
+	warmupKey = datastore.NewKey("/splitstore/warmup")
+
+	// trackPrefix is the namespace of the write epoch of every object in the hot store.
+	trackPrefix = datastore.NewKey("/splitstore/track")
+
+	// markPrefix is the namespace of the objects marked live by the running compaction.
+	markPrefix = datastore.NewKey("/splitstore/mark")
+)
+
+// batchSize is the number of objects moved out of the hot store per transaction.
+const batchSize = 16384
+
+// ChainAccessor is the part of the chain store the split store needs to find
+// the objects which are still reachable from recent tipsets.
+type ChainAccessor interface {
+	GetHead() *block.TipSet
+	GetTipSet(key block.TipSetKey) (*block.TipSet, error)
+	WalkSnapshot(ctx context.Context, ts *block.TipSet, inclRecentRoots abi.ChainEpoch, skipOldMsgs bool, cb func(cid.Cid) error) error
+}
+
+// Config configures the retention and compaction of a SplitStore.
+type Config struct {
+	// HotStoreFinalities is the number of finalities behind the head whose state
+	// is kept in the hot store, at least 1.
+	HotStoreFinalities uint64
+}
+
+// SplitStore is a blockstore which keeps recent chain data in a hot store and
+// moves objects which are no longer reachable from the last HotStoreFinalities
+// finalities of the chain to a cold store, or discards them when no cold store
+// is configured.
+//
+// Every object written to the hot store is tracked with the epoch at which it was
+// written. Compaction runs in the background whenever the head advanced by one
+// finality past the previous compaction boundary, so sync is never stopped. It first
+// marks every object of the retained tipsets, their messages, receipts and states,
+// then moves every tracked object written before the boundary that was not marked.
+//
+// Only dag-cbor objects are tracked, other objects (e.g. imported files) always stay
+// in the hot store. Objects which existed before the split store was enabled are
+// assumed to be chain data and tracked at epoch 0.
+type SplitStore struct {
+	compacting int32 // set while a warmup or compaction is in progress
+
+	curEpoch  int64 // atomic
+	baseEpoch int64 // atomic
+
+	cfg Config
+
+	hot     blockstoreutil.Blockstore
+	cold    blockstoreutil.Blockstore
+	meta    datastore.Batching
+	tracker datastore.Batching
+	marks   datastore.Batching
+
+	// txnLk serializes writes with the removal of swept objects, so that an object
+	// re-written during compaction is never deleted from the hot store.
+	txnLk sync.RWMutex
+
+	chain ChainAccessor
+
+	ctx    context.Context
+	cancel func()
+	wg     sync.WaitGroup
+}
+
+var _ blockstoreutil.Blockstore = (*SplitStore)(nil)
+
+// NewSplitStore creates a split store on top of `hot`. `cold` may be nil, in which case
+// compacted objects are discarded. `meta` stores the tracking information.
+func NewSplitStore(hot, cold blockstoreutil.Blockstore, meta datastore.Batching, cfg Config) (*SplitStore, error) {
+	if cfg.HotStoreFinalities == 0 {
+		return nil, xerrors.New("the hot store must keep at least one finality")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &SplitStore{
+		cfg:     cfg,
+		hot:     hot,
+		cold:    cold,
+		meta:    meta,
+		tracker: namespace.Wrap(meta, trackPrefix),
+		marks:   namespace.Wrap(meta, markPrefix),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	bs, err := meta.Get(baseEpochKey)
+	switch err {
+	case nil:
+		s.baseEpoch = int64(bytesToEpoch(bs))
+	case datastore.ErrNotFound:
+	default:
+		return nil, xerrors.Errorf("failed to load base epoch: %w", err)
+	}
+
+	return s, nil
+}
+
+// Start tracks the chain head and tracks objects which were present before the
+// split store was enabled.
+func (s *SplitStore) Start(chain ChainAccessor) error {
+	s.chain = chain
+	atomic.StoreInt64(&s.curEpoch, int64(chain.GetHead().EnsureHeight()))
+
+	has, err := s.meta.Has(warmupKey)
+	if err != nil {
+		return xerrors.Errorf("failed to load warmup status: %w", err)
+	}
+
+	if !has {
+		atomic.StoreInt32(&s.compacting, 1)
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer atomic.StoreInt32(&s.compacting, 0)
+
+			if err := s.warmup(); err != nil {
+				log.Errorf("error warming up split store: %s", err)
+			}
+		}()
+	}
+
+	log.Infow("split store started", "base epoch", s.baseEpoch, "current epoch", s.curEpoch)
+	return nil
+}
+
+// HeadChange is a chain.ReorgNotifee which triggers compaction when the head moved far
+// enough past the previous compaction boundary.
+func (s *SplitStore) HeadChange(_, apply []*block.TipSet) error {
+	if len(apply) == 0 {
+		return nil
+	}
+
+	curTs := apply[len(apply)-1]
+	epoch := curTs.EnsureHeight()
+	atomic.StoreInt64(&s.curEpoch, int64(epoch))
+
+	if epoch-abi.ChainEpoch(atomic.LoadInt64(&s.baseEpoch)) <= s.retention()+policy.ChainFinality {
+		return nil
+	}
+
+	if !atomic.CompareAndSwapInt32(&s.compacting, 0, 1) {
+		// a compaction is already running
+		return nil
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer atomic.StoreInt32(&s.compacting, 0)
+
+		log.Infow("compacting split store", "epoch", epoch, "base epoch", atomic.LoadInt64(&s.baseEpoch))
+		start := time.Now()
+		if err := s.compact(curTs); err != nil {
+			log.Errorf("error compacting split store: %s", err)
+			return
+		}
+		log.Infow("compaction done", "took", time.Since(start))
+	}()
+
+	return nil
+}
+
+func (s *SplitStore) retention() abi.ChainEpoch {
+	return abi.ChainEpoch(s.cfg.HotStoreFinalities) * policy.ChainFinality
+}
+
+// warmup tracks every object of the hot store which is not tracked yet at epoch 0.
+func (s *SplitStore) warmup() error {
+	log.Info("tracking existing objects of the hot store")
+
+	keys, err := s.hot.AllKeysChan(s.ctx)
+	if err != nil {
+		return xerrors.Errorf("failed to list hot store keys: %w", err)
+	}
+
+	var count int
+	batch, err := s.tracker.Batch()
+	if err != nil {
+		return err
+	}
+	for c := range keys {
+		key := trackKey(c)
+		has, err := s.tracker.Has(key)
+		if err != nil {
+			return err
+		}
+		if has {
+			continue
+		}
+
+		if err := batch.Put(key, epochToBytes(0)); err != nil {
+			return err
+		}
+
+		count++
+		if count%batchSize == 0 {
+			if err := batch.Commit(); err != nil {
+				return xerrors.Errorf("failed to commit tracking batch: %w", err)
+			}
+			if batch, err = s.tracker.Batch(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := batch.Commit(); err != nil {
+		return xerrors.Errorf("failed to commit tracking batch: %w", err)
+	}
+
+	log.Infow("tracked existing objects", "count", count)
+	return s.meta.Put(warmupKey, []byte{1})
+}
+
+func (s *SplitStore) compact(curTs *block.TipSet) error {
+	boundary := curTs.EnsureHeight() - s.retention()
+
+	// the marks are kept in the meta datastore rather than in memory, they are
+	// dropped once the compaction is done, or left over by an interrupted one
+	if err := s.clearMarks(); err != nil {
+		return xerrors.Errorf("failed to clear marks: %w", err)
+	}
+	defer func() {
+		if err := s.clearMarks(); err != nil {
+			log.Warnf("error clearing marks: %s", err)
+		}
+	}()
+
+	// mark every object reachable from the retained part of the chain
+	marks, err := newMarkBatch(s.marks)
+	if err != nil {
+		return err
+	}
+	err = s.chain.WalkSnapshot(s.ctx, curTs, s.retention(), true, marks.mark)
+	if err != nil {
+		return xerrors.Errorf("failed to mark live objects: %w", err)
+	}
+
+	// snapshots only include the roots of the receipts
+	if err := s.markReceipts(curTs, boundary, marks); err != nil {
+		return xerrors.Errorf("failed to mark receipts: %w", err)
+	}
+	if err := marks.commit(); err != nil {
+		return xerrors.Errorf("failed to commit marks: %w", err)
+	}
+	log.Infow("marked live objects", "count", marks.count, "boundary", boundary)
+
+	// sweep unmarked objects written before the boundary
+	res, err := s.tracker.Query(query.Query{})
+	if err != nil {
+		return xerrors.Errorf("failed to query tracked objects: %w", err)
+	}
+	defer res.Close() //nolint:errcheck
+
+	var moved int
+	var cold []cid.Cid
+	for r := range res.Next() {
+		if r.Error != nil {
+			return xerrors.Errorf("failed to iterate tracked objects: %w", r.Error)
+		}
+
+		if bytesToEpoch(r.Value) >= boundary {
+			continue
+		}
+
+		c, err := cidFromTrackKey(r.Key)
+		if err != nil {
+			log.Warnf("error parsing tracked key %s: %s", r.Key, err)
+			continue
+		}
+
+		marked, err := s.marks.Has(trackKey(c))
+		if err != nil {
+			return xerrors.Errorf("failed to load mark of %s: %w", c, err)
+		}
+		if marked {
+			continue
+		}
+
+		cold = append(cold, c)
+		if len(cold) >= batchSize {
+			n, err := s.moveToCold(cold, boundary)
+			if err != nil {
+				return err
+			}
+			moved += n
+			cold = cold[:0]
+		}
+
+		if err := s.ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.moveToCold(cold, boundary)
+	if err != nil {
+		return err
+	}
+	moved += n
+	log.Infow("moved objects out of the hot store", "count", moved, "discarded", s.cold == nil)
+
+	atomic.StoreInt64(&s.baseEpoch, int64(boundary))
+	return s.meta.Put(baseEpochKey, epochToBytes(boundary))
+}
+
+// markReceipts marks every object of the receipts of the tipsets from `curTs` down to `boundary`.
+func (s *SplitStore) markReceipts(curTs *block.TipSet, boundary abi.ChainEpoch, marks *markBatch) error {
+	walked := cid.NewSet()
+	for ts := curTs; ts.EnsureHeight() > boundary; {
+		for _, blk := range ts.Blocks() {
+			if !walked.Visit(blk.ParentMessageReceipts) {
+				continue
+			}
+			if err := s.markLinks(blk.ParentMessageReceipts, marks); err != nil {
+				return err
+			}
+		}
+
+		if err := s.ctx.Err(); err != nil {
+			return err
+		}
+
+		if ts.EnsureHeight() == 0 {
+			break
+		}
+		parent, err := s.chain.GetTipSet(ts.EnsureParents())
+		if err != nil {
+			return xerrors.Errorf("failed to load parent of %s: %w", ts.Key(), err)
+		}
+		ts = parent
+	}
+	return nil
+}
+
+// markLinks marks `root` and every object it links to.
+func (s *SplitStore) markLinks(root cid.Cid, marks *markBatch) error {
+	if root.Prefix().Codec != cid.DagCBOR {
+		return nil
+	}
+	if err := marks.mark(root); err != nil {
+		return err
+	}
+
+	blk, err := s.Get(root)
+	if err != nil {
+		return xerrors.Errorf("failed to load %s: %w", root, err)
+	}
+
+	var links []cid.Cid
+	err = cbg.ScanForLinks(bytes.NewReader(blk.RawData()), func(c cid.Cid) {
+		links = append(links, c)
+	})
+	if err != nil {
+		return xerrors.Errorf("failed to scan links of %s: %w", root, err)
+	}
+
+	for _, c := range links {
+		if err := s.markLinks(c, marks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearMarks removes every mark.
+func (s *SplitStore) clearMarks() error {
+	res, err := s.marks.Query(query.Query{KeysOnly: true})
+	if err != nil {
+		return err
+	}
+	defer res.Close() //nolint:errcheck
+
+	batch, err := s.marks.Batch()
+	if err != nil {
+		return err
+	}
+	var count int
+	for r := range res.Next() {
+		if r.Error != nil {
+			return r.Error
+		}
+		if err := batch.Delete(datastore.RawKey(r.Key)); err != nil {
+			return err
+		}
+
+		count++
+		if count%batchSize == 0 {
+			if err := batch.Commit(); err != nil {
+				return err
+			}
+			if batch, err = s.marks.Batch(); err != nil {
+				return err
+			}
+		}
+	}
+	return batch.Commit()
+}
+
+// markBatch writes marks to the datastore in batches.
+type markBatch struct {
+	ds    datastore.Batching
+	batch datastore.Batch
+	count int
+}
+
+func newMarkBatch(ds datastore.Batching) (*markBatch, error) {
+	batch, err := ds.Batch()
+	if err != nil {
+		return nil, err
+	}
+	return &markBatch{ds: ds, batch: batch}, nil
+}
+
+func (m *markBatch) mark(c cid.Cid) error {
+	if err := m.batch.Put(trackKey(c), []byte{}); err != nil {
+		return err
+	}
+
+	m.count++
+	if m.count%batchSize == 0 {
+		if err := m.commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *markBatch) commit() error {
+	if err := m.batch.Commit(); err != nil {
+		return err
+	}
+
+	var err error
+	m.batch, err = m.ds.Batch()
+	return err
+}
+
+// moveToCold copies `cids` to the cold store, if there is one, and removes them from the hot store.
+func (s *SplitStore) moveToCold(cids []cid.Cid, boundary abi.ChainEpoch) (int, error) {
+	if len(cids) == 0 {
+		return 0, nil
+	}
+
+	s.txnLk.Lock()
+	defer s.txnLk.Unlock()
+
+	batch := make([]blocks.Block, 0, len(cids))
+	toDelete := make([]cid.Cid, 0, len(cids))
+	for _, c := range cids {
+		// the object may have been written again since it was selected
+		val, err := s.tracker.Get(trackKey(c))
+		if err != nil {
+			if err == datastore.ErrNotFound {
+				continue
+			}
+			return 0, xerrors.Errorf("failed to load tracked epoch of %s: %w", c, err)
+		}
+		if bytesToEpoch(val) >= boundary {
+			continue
+		}
+
+		if s.cold != nil {
+			blk, err := s.hot.Get(c)
+			if err != nil {
+				if err == blockstoreutil.ErrNotFound {
+					// already gone, only drop the tracking entry
+					toDelete = append(toDelete, c)
+					continue
+				}
+				return 0, xerrors.Errorf("failed to load %s from hot store: %w", c, err)
+			}
+			batch = append(batch, blk)
+		}
+		toDelete = append(toDelete, c)
+	}
+
+	if s.cold != nil && len(batch) > 0 {
+		if err := s.cold.PutMany(batch); err != nil {
+			return 0, xerrors.Errorf("failed to write objects to cold store: %w", err)
+		}
+	}
+
+	tbatch, err := s.tracker.Batch()
+	if err != nil {
+		return 0, err
+	}
+	for _, c := range toDelete {
+		if err := s.hot.DeleteBlock(c); err != nil && err != blockstoreutil.ErrNotFound {
+			return 0, xerrors.Errorf("failed to delete %s from hot store: %w", c, err)
+		}
+		if err := tbatch.Delete(trackKey(c)); err != nil {
+			return 0, err
+		}
+	}
+	if err := tbatch.Commit(); err != nil {
+		return 0, xerrors.Errorf("failed to commit tracking batch: %w", err)
+	}
+
+	return len(toDelete), nil
+}
+
+func (s *SplitStore) track(blks ...blocks.Block) error {
+	epoch := epochToBytes(abi.ChainEpoch(atomic.LoadInt64(&s.curEpoch)))
+	batch, err := s.tracker.Batch()
+	if err != nil {
+		return err
+	}
+	for _, blk := range blks {
+		if blk.Cid().Prefix().Codec != cid.DagCBOR {
+			continue
+		}
+		if err := batch.Put(trackKey(blk.Cid()), epoch); err != nil {
+			return err
+		}
+	}
+	return batch.Commit()
+}
+
+// DeleteBlock implements blockstore.DeleteBlock.
+func (s *SplitStore) DeleteBlock(c cid.Cid) error {
+	s.txnLk.Lock()
+	defer s.txnLk.Unlock()
+
+	if err := s.hot.DeleteBlock(c); err != nil {
+		return err
+	}
+	if err := s.tracker.Delete(trackKey(c)); err != nil {
+		return err
+	}
+	if s.cold != nil {
+		return s.cold.DeleteBlock(c)
+	}
+	return nil
+}
+
+// Has implements blockstore.Has.
+func (s *SplitStore) Has(c cid.Cid) (bool, error) {
+	has, err := s.hot.Has(c)
+	if err != nil || has || s.cold == nil {
+		return has, err
+	}
+
+	return s.cold.Has(c)
+}
+
+// Get implements blockstore.Get.
+func (s *SplitStore) Get(c cid.Cid) (blocks.Block, error) {
+	blk, err := s.hot.Get(c)
+	if err == blockstoreutil.ErrNotFound && s.cold != nil {
+		return s.cold.Get(c)
+	}
+
+	return blk, err
+}
+
+// GetSize implements blockstore.GetSize.
+func (s *SplitStore) GetSize(c cid.Cid) (int, error) {
+	size, err := s.hot.GetSize(c)
+	if err == blockstoreutil.ErrNotFound && s.cold != nil {
+		return s.cold.GetSize(c)
+	}
+
+	return size, err
+}
+
+// Put implements blockstore.Put.
+func (s *SplitStore) Put(blk blocks.Block) error {
+	s.txnLk.RLock()
+	defer s.txnLk.RUnlock()
+
+	if err := s.hot.Put(blk); err != nil {
+		return err
+	}
+
+	return s.track(blk)
+}
+
+// PutMany implements blockstore.PutMany.
+func (s *SplitStore) PutMany(blks []blocks.Block) error {
+	s.txnLk.RLock()
+	defer s.txnLk.RUnlock()
+
+	if err := s.hot.PutMany(blks); err != nil {
+		return err
+	}
+
+	return s.track(blks...)
+}
+
+// AllKeysChan implements blockstore.AllKeysChan, listing the keys of the hot store
+// followed by the keys of the cold store.
+func (s *SplitStore) AllKeysChan(ctx context.Context) (<-chan cid.Cid, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	hotKeys, err := s.hot.AllKeysChan(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	var coldKeys <-chan cid.Cid
+	if s.cold != nil {
+		coldKeys, err = s.cold.AllKeysChan(ctx)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+
+	ch := make(chan cid.Cid)
+	go func() {
+		defer cancel()
+		defer close(ch)
+
+		for _, in := range []<-chan cid.Cid{hotKeys, coldKeys} {
+			for c := range in {
+				select {
+				case ch <- c:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// HashOnRead implements blockstore.HashOnRead.
+func (s *SplitStore) HashOnRead(enabled bool) {
+	s.hot.HashOnRead(enabled)
+	if s.cold != nil {
+		s.cold.HashOnRead(enabled)
+	}
+}
+
+// Close stops any running compaction and closes the underlying stores.
+func (s *SplitStore) Close() error {
+	s.cancel()
+	s.wg.Wait()
+
+	for _, c := range []interface{}{s.hot, s.cold, s.meta} {
+		if closer, ok := c.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// trackKey keys objects by multihash, as the badger blockstore does.
+func trackKey(c cid.Cid) datastore.Key {
+	return dshelp.MultihashToDsKey(c.Hash())
+}
+
+func cidFromTrackKey(key string) (cid.Cid, error) {
+	mh, err := dshelp.BinaryFromDsKey(datastore.RawKey(key))
+	if err != nil {
+		return cid.Undef, err
+	}
+	return cid.NewCidV1(cid.Raw, mh), nil
+}
+
+func epochToBytes(epoch abi.ChainEpoch) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(epoch))
+	return buf
+}
+
+func bytesToEpoch(buf []byte) abi.ChainEpoch {
+	if len(buf) != 8 {
+		return 0
+	}
+	return abi.ChainEpoch(binary.BigEndian.Uint64(buf))
+}
@@ -0,0 +1,219 @@
+package splitstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	fbig "github.com/filecoin-project/go-state-types/big"
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/splitstore"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/util/blockstoreutil"
+)
+
+func newBlock(t *testing.T, data string) blocks.Block {
+	c, err := cid.Prefix{
+		Version:  1,
+		Codec:    cid.DagCBOR,
+		MhType:   multihash.BLAKE2B_MIN + 31,
+		MhLength: -1,
+	}.Sum([]byte(data))
+	require.NoError(t, err)
+
+	blk, err := blocks.NewBlockWithCid([]byte(data), c)
+	require.NoError(t, err)
+	return blk
+}
+
+func TestSplitStoreReadsFromHotAndCold(t *testing.T) {
+	tf.UnitTest(t)
+
+	hot := blockstoreutil.NewBlockstore(dssync.MutexWrap(ds.NewMapDatastore()))
+	cold := blockstoreutil.NewBlockstore(dssync.MutexWrap(ds.NewMapDatastore()))
+	meta := dssync.MutexWrap(ds.NewMapDatastore())
+
+	ss, err := splitstore.NewSplitStore(hot, cold, meta, splitstore.Config{HotStoreFinalities: 2})
+	require.NoError(t, err)
+	defer ss.Close() // nolint: errcheck
+
+	hotBlk := newBlock(t, "hot")
+	coldBlk := newBlock(t, "cold")
+
+	require.NoError(t, ss.Put(hotBlk))
+	require.NoError(t, cold.Put(coldBlk))
+
+	// writes only go to the hot store
+	has, err := hot.Has(hotBlk.Cid())
+	require.NoError(t, err)
+	assert.True(t, has)
+	has, err = cold.Has(hotBlk.Cid())
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	for _, blk := range []blocks.Block{hotBlk, coldBlk} {
+		has, err := ss.Has(blk.Cid())
+		require.NoError(t, err)
+		assert.True(t, has)
+
+		got, err := ss.Get(blk.Cid())
+		require.NoError(t, err)
+		assert.Equal(t, blk.RawData(), got.RawData())
+
+		size, err := ss.GetSize(blk.Cid())
+		require.NoError(t, err)
+		assert.Equal(t, len(blk.RawData()), size)
+	}
+
+	has, err = ss.Has(newBlock(t, "missing").Cid())
+	require.NoError(t, err)
+	assert.False(t, has)
+}
+
+func TestSplitStoreRejectsNoRetention(t *testing.T) {
+	tf.UnitTest(t)
+
+	hot := blockstoreutil.NewBlockstore(dssync.MutexWrap(ds.NewMapDatastore()))
+	_, err := splitstore.NewSplitStore(hot, nil, dssync.MutexWrap(ds.NewMapDatastore()), splitstore.Config{HotStoreFinalities: 0})
+	assert.Error(t, err)
+}
+
+// testChain is a chain store with a fixed head.
+type testChain struct {
+	*chain.Store
+	head *block.TipSet
+}
+
+func (c *testChain) GetHead() *block.TipSet {
+	return c.head
+}
+
+func TestSplitStoreCompaction(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	hot := blockstoreutil.NewBlockstore(dssync.MutexWrap(ds.NewMapDatastore()))
+	cold := blockstoreutil.NewBlockstore(dssync.MutexWrap(ds.NewMapDatastore()))
+	meta := dssync.MutexWrap(ds.NewMapDatastore())
+
+	// one finality (900 epochs) is kept in the hot store
+	ss, err := splitstore.NewSplitStore(hot, cold, meta, splitstore.Config{HotStoreFinalities: 1})
+	require.NoError(t, err)
+	defer ss.Close() // nolint: errcheck
+
+	cst := cbor.NewCborStore(ss)
+	mstore := chain.NewMessageStore(ss)
+	rm := types.NewReceiptMaker()
+	miner, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	putState := func(leaf string) (cid.Cid, cid.Cid) {
+		leafCid, err := cst.Put(ctx, leaf)
+		require.NoError(t, err)
+		root, err := cst.Put(ctx, []cid.Cid{leafCid})
+		require.NoError(t, err)
+		return root, leafCid
+	}
+	putMessages := func(msgs ...*types.UnsignedMessage) cid.Cid {
+		c, err := mstore.StoreMessages(ctx, []*types.SignedMessage{}, msgs)
+		require.NoError(t, err)
+		return c
+	}
+	putReceipts := func(n int) cid.Cid {
+		receipts := make([]types.MessageReceipt, n)
+		for i := range receipts {
+			receipts[i] = rm.NewReceipt()
+		}
+		c, err := mstore.StoreReceipts(ctx, receipts)
+		require.NoError(t, err)
+		return c
+	}
+	putTipSet := func(parents block.TipSetKey, height abi.ChainEpoch, stateRoot, receipts, msgs cid.Cid) *block.TipSet {
+		blk := &block.Block{
+			Miner:                 miner,
+			Parents:               parents,
+			ParentWeight:          fbig.Zero(),
+			Height:                height,
+			ParentStateRoot:       stateRoot,
+			ParentMessageReceipts: receipts,
+			Messages:              msgs,
+			ParentBaseFee:         abi.NewTokenAmount(100),
+		}
+		_, err := cst.Put(ctx, blk)
+		require.NoError(t, err)
+		ts, err := block.NewTipSet(blk)
+		require.NoError(t, err)
+		return ts
+	}
+
+	msgs := types.NewMsgs(2)
+	emptyMsgs, emptyReceipts := putMessages(), putReceipts(0)
+
+	genState, genLeaf := putState("genesis")
+	genesis := putTipSet(block.NewTipSetKey(), 0, genState, emptyReceipts, emptyMsgs)
+
+	oldState, oldLeaf := putState("old")
+	oldMsgs, oldReceipts := putMessages(msgs[0]), putReceipts(3)
+	old := putTipSet(genesis.Key(), 10, oldState, oldReceipts, oldMsgs)
+
+	midState, _ := putState("mid")
+	mid := putTipSet(old.Key(), 2000, midState, emptyReceipts, emptyMsgs)
+
+	// the receipts don't fit in the root node of their AMT
+	headState, headLeaf := putState("head")
+	headMsgs, headReceipts := putMessages(msgs[1]), putReceipts(100)
+	head := putTipSet(mid.Key(), 2001, headState, headReceipts, headMsgs)
+
+	chainStore := chain.NewStore(dssync.MutexWrap(ds.NewMapDatastore()), cst, ss, chain.NewStatusReporter(), config.DefaultForkUpgradeParam, genesis.At(0).Cid())
+	require.NoError(t, ss.Start(&testChain{Store: chainStore, head: head}))
+
+	// written at the current epoch, after the boundary
+	recent := newBlock(t, "recent")
+	require.NoError(t, ss.Put(recent))
+
+	require.NoError(t, ss.Compact(head))
+
+	assertIn := func(bs blockstoreutil.Blockstore, expected bool, cids ...cid.Cid) {
+		for _, c := range cids {
+			has, err := bs.Has(c)
+			require.NoError(t, err)
+			assert.Equal(t, expected, has, c.String())
+		}
+	}
+
+	oldMsgCid, err := msgs[0].Cid()
+	require.NoError(t, err)
+	headMsgCid, err := msgs[1].Cid()
+	require.NoError(t, err)
+
+	// the headers, the genesis state and the state, messages and receipts of the last finality stay hot
+	retained := []cid.Cid{
+		genesis.At(0).Cid(), old.At(0).Cid(), mid.At(0).Cid(), head.At(0).Cid(),
+		genState, genLeaf, midState, headState, headLeaf,
+		emptyMsgs, headMsgs, headMsgCid, emptyReceipts, headReceipts,
+		recent.Cid(),
+	}
+	assertIn(hot, true, retained...)
+	receipts, err := chain.NewMessageStore(hot).LoadReceipts(ctx, headReceipts)
+	require.NoError(t, err)
+	assert.Len(t, receipts, 100)
+
+	// older objects are moved to the cold store
+	moved := []cid.Cid{oldState, oldLeaf, oldMsgs, oldMsgCid, oldReceipts}
+	assertIn(hot, false, moved...)
+	assertIn(cold, true, moved...)
+	assertIn(ss, true, moved...)
+}
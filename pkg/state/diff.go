@@ -0,0 +1,94 @@
+package state
+
+import (
+	"github.com/filecoin-project/go-address"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/specactors/adt"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin"
+	notinit "github.com/filecoin-project/venus/pkg/specactors/builtin/init"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/market"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/miner"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/multisig"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/power"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// ActorStateChanges is the typed diff of the state of a builtin actor.
+// Only the field matching the kind of the actor is set.
+type ActorStateChanges struct {
+	Sectors             *miner.SectorChanges                `json:",omitempty"`
+	DealStates          *market.DealStateChanges            `json:",omitempty"`
+	Claims              *power.ClaimChanges                 `json:",omitempty"`
+	AddressMap          *notinit.AddressMapChanges          `json:",omitempty"`
+	PendingTransactions *multisig.PendingTransactionChanges `json:",omitempty"`
+}
+
+// DiffActorState computes the typed diff between two versions of the actor at `addr`.
+// Actors without a typed diff (accounts, payment channels, ...) return an empty result.
+func DiffActorState(store adt.Store, addr address.Address, pre, cur *types.Actor) (*ActorStateChanges, error) {
+	out := new(ActorStateChanges)
+	if pre.Head.Equals(cur.Head) {
+		return out, nil
+	}
+
+	var err error
+	switch {
+	case builtin.IsStorageMinerActor(cur.Code):
+		var preState, curState miner.State
+		if preState, err = miner.Load(store, pre); err != nil {
+			return nil, errors.Wrap(err, "loading previous miner state")
+		}
+		if curState, err = miner.Load(store, cur); err != nil {
+			return nil, errors.Wrap(err, "loading current miner state")
+		}
+		out.Sectors, err = miner.DiffSectors(preState, curState)
+	case builtin.IsMultisigActor(cur.Code):
+		var preState, curState multisig.State
+		if preState, err = multisig.Load(store, pre); err != nil {
+			return nil, errors.Wrap(err, "loading previous multisig state")
+		}
+		if curState, err = multisig.Load(store, cur); err != nil {
+			return nil, errors.Wrap(err, "loading current multisig state")
+		}
+		out.PendingTransactions, err = multisig.DiffPendingTransactions(preState, curState)
+	case addr == market.Address:
+		var preState, curState market.State
+		if preState, err = market.Load(store, pre); err != nil {
+			return nil, errors.Wrap(err, "loading previous market state")
+		}
+		if curState, err = market.Load(store, cur); err != nil {
+			return nil, errors.Wrap(err, "loading current market state")
+		}
+		var preDeals, curDeals market.DealStates
+		if preDeals, err = preState.States(); err != nil {
+			return nil, errors.Wrap(err, "loading previous deal states")
+		}
+		if curDeals, err = curState.States(); err != nil {
+			return nil, errors.Wrap(err, "loading current deal states")
+		}
+		out.DealStates, err = market.DiffDealStates(preDeals, curDeals)
+	case addr == power.Address:
+		var preState, curState power.State
+		if preState, err = power.Load(store, pre); err != nil {
+			return nil, errors.Wrap(err, "loading previous power state")
+		}
+		if curState, err = power.Load(store, cur); err != nil {
+			return nil, errors.Wrap(err, "loading current power state")
+		}
+		out.Claims, err = power.DiffClaims(preState, curState)
+	case addr == notinit.Address:
+		var preState, curState notinit.State
+		if preState, err = notinit.Load(store, pre); err != nil {
+			return nil, errors.Wrap(err, "loading previous init state")
+		}
+		if curState, err = notinit.Load(store, cur); err != nil {
+			return nil, errors.Wrap(err, "loading current init state")
+		}
+		out.AddressMap, err = notinit.DiffAddressMap(preState, curState)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "diffing state of actor %s", addr)
+	}
+	return out, nil
+}
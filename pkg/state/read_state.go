@@ -0,0 +1,214 @@
+package state
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"github.com/filecoin-project/go-address"
+	builtin0 "github.com/filecoin-project/specs-actors/actors/builtin"
+	adt0 "github.com/filecoin-project/specs-actors/actors/util/adt"
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	adt2 "github.com/filecoin-project/specs-actors/v2/actors/util/adt"
+	builtin3 "github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	adt3 "github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+	"github.com/ipfs/go-cid"
+	cbornode "github.com/ipfs/go-ipld-cbor"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/pkg/errors"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/venus/pkg/specactors"
+	"github.com/filecoin-project/venus/pkg/specactors/adt"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// ReadActorState decodes the state of the builtin actor `act` with the loader of its
+// actor version. The result marshals to the JSON of the versioned state.
+func ReadActorState(store adt.Store, act *types.Actor) (interface{}, error) {
+	st, err := builtin.Load(store, act)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading state of actor with code %s", act.Code)
+	}
+	return st, nil
+}
+
+// ReadActorStateExpanded is ReadActorState with every object linked from the top level
+// of the state loaded in place of its cid: HAMTs become a map of their decoded keys to their
+// values, AMTs a map of their indexes to their values, and other objects are inlined.
+// Values are decoded as generic CBOR since their types aren't known here.
+func ReadActorStateExpanded(store adt.Store, act *types.Actor) (map[string]interface{}, error) {
+	st, err := ReadActorState(store, act)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(st)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, errors.Wrap(err, "state of actor is not a JSON object")
+	}
+
+	version := actorVersion(act.Code)
+	for name, val := range fields {
+		root, ok := jsonLink(val)
+		if !ok {
+			continue
+		}
+		expanded, err := expandLink(store, version, root)
+		if err != nil {
+			return nil, errors.Wrapf(err, "expanding field %s (%s)", name, root)
+		}
+		fields[name] = expanded
+	}
+	return fields, nil
+}
+
+func actorVersion(code cid.Cid) specactors.Version {
+	switch {
+	case builtin0.IsBuiltinActor(code):
+		return specactors.Version0
+	case builtin2.IsBuiltinActor(code):
+		return specactors.Version2
+	default:
+		return specactors.Version3
+	}
+}
+
+// jsonLink returns the cid of a decoded JSON value of the form {"/": "<cid>"}.
+func jsonLink(val interface{}) (cid.Cid, bool) {
+	obj, ok := val.(map[string]interface{})
+	if !ok || len(obj) != 1 {
+		return cid.Undef, false
+	}
+	s, ok := obj["/"].(string)
+	if !ok {
+		return cid.Undef, false
+	}
+	c, err := cid.Decode(s)
+	if err != nil {
+		return cid.Undef, false
+	}
+	return c, true
+}
+
+// expandLink loads the object at `root`. Its shape tells HAMTs (a two element array
+// starting with the bitfield) from AMTs (an array of the height and count, prefixed
+// by the bit width from v3 on); anything else, or a collection that fails to load,
+// is returned as a generic object.
+func expandLink(store adt.Store, version specactors.Version, root cid.Cid) (interface{}, error) {
+	var raw cbg.Deferred
+	if err := store.Get(store.Context(), root, &raw); err != nil {
+		return nil, err
+	}
+
+	r := bytes.NewReader(raw.Raw)
+	maj, length, err := cbg.CborReadHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if maj == cbg.MajArray {
+		elemMaj, elemVal, err := cbg.CborReadHeader(r)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case length == 2 && elemMaj == cbg.MajByteString:
+			if m, err := expandHamt(store, version, root); err == nil {
+				return m, nil
+			}
+		case (length == 3 || length == 4) && elemMaj == cbg.MajUnsignedInt:
+			if a, err := expandAmt(store, version, root, int(elemVal)); err == nil {
+				return a, nil
+			}
+		}
+	}
+	return decodeGeneric(raw.Raw)
+}
+
+func expandHamt(store adt.Store, version specactors.Version, root cid.Cid) (map[string]interface{}, error) {
+	var m adt.Map
+	var err error
+	switch version {
+	case specactors.Version0:
+		m, err = adt0.AsMap(store, root)
+	case specactors.Version2:
+		m, err = adt2.AsMap(store, root)
+	default:
+		// iterating doesn't depend on the bit width
+		m, err = adt3.AsMap(store, root, builtin3.DefaultHamtBitwidth)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]interface{})
+	var val cbg.Deferred
+	err = m.ForEach(&val, func(key string) error {
+		node, err := decodeGeneric(val.Raw)
+		if err != nil {
+			return err
+		}
+		out[hamtKeyString(key)] = node
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func expandAmt(store adt.Store, version specactors.Version, root cid.Cid, bitwidth int) (map[string]interface{}, error) {
+	var a adt.Array
+	var err error
+	switch version {
+	case specactors.Version0:
+		a, err = adt0.AsArray(store, root)
+	case specactors.Version2:
+		a, err = adt2.AsArray(store, root)
+	default:
+		a, err = adt3.AsArray(store, root, bitwidth)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]interface{})
+	var val cbg.Deferred
+	err = a.ForEach(&val, func(idx int64) error {
+		node, err := decodeGeneric(val.Raw)
+		if err != nil {
+			return err
+		}
+		out[strconv.FormatInt(idx, 10)] = node
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// hamtKeyString renders the key of a builtin actor HAMT: a cid, an address or a varint.
+func hamtKeyString(key string) string {
+	if n, c, err := cid.CidFromBytes([]byte(key)); err == nil && n == len(key) {
+		return c.String()
+	}
+	if addr, err := address.NewFromBytes([]byte(key)); err == nil {
+		return addr.String()
+	}
+	if v, n := binary.Uvarint([]byte(key)); n == len(key) {
+		return strconv.FormatUint(v, 10)
+	}
+	return hex.EncodeToString([]byte(key))
+}
+
+func decodeGeneric(raw []byte) (*cbornode.Node, error) {
+	return cbornode.Decode(raw, mh.BLAKE2B_MIN+31, -1)
+}
@@ -0,0 +1,68 @@
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	adt2 "github.com/filecoin-project/specs-actors/v2/actors/util/adt"
+	cbornode "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/venus/pkg/specactors"
+	"github.com/filecoin-project/venus/pkg/specactors/adt"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/util/blockstoreutil"
+)
+
+func TestExpandLink(t *testing.T) {
+	tf.UnitTest(t)
+	store := adt.WrapStore(context.Background(), cbornode.NewCborStore(blockstoreutil.NewTemporarySync()))
+	seven := cbg.CborInt(7)
+
+	assertValue := func(v interface{}) {
+		node, ok := v.(*cbornode.Node)
+		require.True(t, ok)
+		js, err := node.MarshalJSON()
+		require.NoError(t, err)
+		assert.Equal(t, "7", string(js))
+	}
+
+	addr, err := address.NewIDAddress(100)
+	require.NoError(t, err)
+	hamt := adt2.MakeEmptyMap(store)
+	require.NoError(t, hamt.Put(abi.AddrKey(addr), &seven))
+	require.NoError(t, hamt.Put(abi.UIntKey(5), &seven))
+	root, err := hamt.Root()
+	require.NoError(t, err)
+
+	expanded, err := expandLink(store, specactors.Version2, root)
+	require.NoError(t, err)
+	entries, ok := expanded.(map[string]interface{})
+	require.True(t, ok)
+	require.Len(t, entries, 2)
+	assertValue(entries[addr.String()])
+	assertValue(entries["5"])
+
+	amt := adt2.MakeEmptyArray(store)
+	require.NoError(t, amt.Set(3, &seven))
+	root, err = amt.Root()
+	require.NoError(t, err)
+
+	expanded, err = expandLink(store, specactors.Version2, root)
+	require.NoError(t, err)
+	entries, ok = expanded.(map[string]interface{})
+	require.True(t, ok)
+	require.Len(t, entries, 1)
+	assertValue(entries["3"])
+
+	// other objects are inlined as is
+	root, err = store.Put(context.Background(), &seven)
+	require.NoError(t, err)
+	expanded, err = expandLink(store, specactors.Version2, root)
+	require.NoError(t, err)
+	assertValue(expanded)
+}
@@ -28,6 +28,11 @@ var DefaultDefaultMaxFee = MustParseFIL("0.007")
 
 type MessageSendSpec struct {
 	MaxFee abi.TokenAmount
+	// InclusionEpochs, if not zero, estimates the gas premium and fee cap of the message from the fee
+	// history for its inclusion within that many epochs with probability InclusionProbability,
+	// the default probability of the message pool if zero
+	InclusionEpochs      abi.ChainEpoch `json:",omitempty"`
+	InclusionProbability float64        `json:",omitempty"`
 }
 
 var DefaultMessageSendSpec = MessageSendSpec{
@@ -69,3 +69,18 @@ func (builder *RPCBuilder) Build() *jsonrpc.RPCServer {
 	}
 	return server
 }
+
+// APIs returns the API implementations of the services, in the order they were added.
+func (builder *RPCBuilder) APIs() []interface{} {
+	return builder.apiStruct
+}
+
+// BuildProxy returns a server of the methods of `proxy` instead of the ones of the services,
+// e.g. a proxy checking the permissions of the callers before calling the services.
+func (builder *RPCBuilder) BuildProxy(proxy interface{}) *jsonrpc.RPCServer {
+	server := jsonrpc.NewServer()
+	for _, nameSpace := range builder.namespace {
+		server.Register(nameSpace, proxy)
+	}
+	return server
+}
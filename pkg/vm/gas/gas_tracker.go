@@ -90,3 +90,24 @@ func (t *GasTracker) TryCharge(gasCharge GasCharge) bool {
 	t.GasUsed += toUse
 	return true
 }
+
+// BeginSubcall starts recording the execution trace of the internal message `msg`, the gas
+// charges of its invocation go To the trace of the subcall. The returned function ends the
+// subcall and attaches its trace, along with `receipt` if the invocation returned, To the
+// trace of the caller.
+func (t *GasTracker) BeginSubcall(msg *types2.UnsignedMessage) func(receipt *types2.MessageReceipt) {
+	parentTrace := t.ExecutionTrace
+	t.ExecutionTrace = types2.ExecutionTrace{Msg: msg}
+	start, startGas := time.Now(), t.GasUsed
+
+	return func(receipt *types2.MessageReceipt) {
+		subcall := t.ExecutionTrace
+		if receipt != nil {
+			receipt.GasUsed = t.GasUsed - startGas
+			subcall.MsgRct = receipt
+		}
+		subcall.Duration = time.Since(start)
+		parentTrace.Subcalls = append(parentTrace.Subcalls, subcall)
+		t.ExecutionTrace = parentTrace
+	}
+}
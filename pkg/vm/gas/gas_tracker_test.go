@@ -0,0 +1,79 @@
+package gas
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestNestedSubcallTraces(t *testing.T) {
+	tf.UnitTest(t)
+
+	mkMsg := func(from, to uint64) *types.UnsignedMessage {
+		fromAddr, err := address.NewIDAddress(from)
+		require.NoError(t, err)
+		toAddr, err := address.NewIDAddress(to)
+		require.NoError(t, err)
+		return &types.UnsignedMessage{From: fromAddr, To: toAddr, Method: abi.MethodNum(to)}
+	}
+
+	top := mkMsg(100, 101)
+	tracker := NewGasTracker(1e6)
+	tracker.ExecutionTrace.Msg = top
+	tracker.TryCharge(NewGasCharge("top", 10, 0))
+
+	// 101 sends to 102, which sends to 103 and then to 104 which aborts
+	end102 := tracker.BeginSubcall(mkMsg(101, 102))
+	tracker.TryCharge(NewGasCharge("102", 20, 0))
+
+	end103 := tracker.BeginSubcall(mkMsg(102, 103))
+	tracker.TryCharge(NewGasCharge("103", 30, 0))
+	end103(&types.MessageReceipt{ExitCode: exitcode.Ok, ReturnValue: []byte{3}})
+
+	end104 := tracker.BeginSubcall(mkMsg(102, 104))
+	tracker.TryCharge(NewGasCharge("104", 40, 0))
+	end104(nil)
+
+	end102(&types.MessageReceipt{ExitCode: exitcode.ErrForbidden})
+
+	end105 := tracker.BeginSubcall(mkMsg(101, 105))
+	end105(&types.MessageReceipt{ExitCode: exitcode.Ok})
+
+	trace := tracker.ExecutionTrace
+	assert.Equal(t, top, trace.Msg)
+	require.Len(t, trace.GasCharges, 1)
+	assert.Equal(t, "top", trace.GasCharges[0].Name)
+	require.Len(t, trace.Subcalls, 2)
+
+	call102 := trace.Subcalls[0]
+	assert.Equal(t, abi.MethodNum(102), call102.Msg.Method)
+	assert.Equal(t, exitcode.ErrForbidden, call102.MsgRct.ExitCode)
+	// the gas used by a subcall includes the gas used by its own subcalls
+	assert.Equal(t, int64(90), call102.MsgRct.GasUsed)
+	require.Len(t, call102.GasCharges, 1)
+	assert.Equal(t, "102", call102.GasCharges[0].Name)
+	require.Len(t, call102.Subcalls, 2)
+
+	call103 := call102.Subcalls[0]
+	assert.Equal(t, abi.MethodNum(103), call103.Msg.Method)
+	assert.Equal(t, []byte{3}, call103.MsgRct.ReturnValue)
+	assert.Equal(t, int64(30), call103.MsgRct.GasUsed)
+	assert.Empty(t, call103.Subcalls)
+
+	// an aborted subcall is traced without receipt
+	call104 := call102.Subcalls[1]
+	assert.Equal(t, abi.MethodNum(104), call104.Msg.Method)
+	assert.Nil(t, call104.MsgRct)
+	require.Len(t, call104.GasCharges, 1)
+	assert.Equal(t, "104", call104.GasCharges[0].Name)
+
+	assert.Equal(t, abi.MethodNum(105), trace.Subcalls[1].Msg.Method)
+	assert.Equal(t, int64(0), trace.Subcalls[1].MsgRct.GasUsed)
+}
@@ -0,0 +1,114 @@
+package state
+
+import (
+	"bytes"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/specactors/adt"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// ActorChanges lists the actors that were added, modified or removed between two state trees.
+type ActorChanges struct {
+	Added    []ActorChange
+	Modified []ActorModification
+	Removed  []ActorChange
+}
+
+type ActorChange struct {
+	Address address.Address
+	Actor   types.Actor
+}
+
+type ActorModification struct {
+	Address address.Address
+	From    types.Actor
+	To      types.Actor
+}
+
+// DiffActors walks the actors HAMTs of both trees and reports every actor whose
+// balance, nonce, code or head differs. Only flushed state is compared.
+func DiffActors(pre, cur *State) (*ActorChanges, error) {
+	results := new(ActorChanges)
+
+	preRoot, err := pre.root.Root()
+	if err != nil {
+		return nil, err
+	}
+	curRoot, err := cur.root.Root()
+	if err != nil {
+		return nil, err
+	}
+	if preRoot.Equals(curRoot) {
+		return results, nil
+	}
+
+	if err := adt.DiffAdtMap(pre.root, cur.root, &actorDiffer{results}); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+type actorDiffer struct {
+	Results *ActorChanges
+}
+
+func (d *actorDiffer) AsKey(key string) (abi.Keyer, error) {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return nil, xerrors.Errorf("invalid address (%x) found in state tree key: %v", []byte(key), err)
+	}
+	return abi.AddrKey(addr), nil
+}
+
+func (d *actorDiffer) Add(key string, val *cbg.Deferred) error {
+	change, err := decodeActorChange(key, val)
+	if err != nil {
+		return err
+	}
+	d.Results.Added = append(d.Results.Added, change)
+	return nil
+}
+
+func (d *actorDiffer) Modify(key string, from, to *cbg.Deferred) error {
+	fromChange, err := decodeActorChange(key, from)
+	if err != nil {
+		return err
+	}
+	toChange, err := decodeActorChange(key, to)
+	if err != nil {
+		return err
+	}
+	d.Results.Modified = append(d.Results.Modified, ActorModification{
+		Address: fromChange.Address,
+		From:    fromChange.Actor,
+		To:      toChange.Actor,
+	})
+	return nil
+}
+
+func (d *actorDiffer) Remove(key string, val *cbg.Deferred) error {
+	change, err := decodeActorChange(key, val)
+	if err != nil {
+		return err
+	}
+	d.Results.Removed = append(d.Results.Removed, change)
+	return nil
+}
+
+func decodeActorChange(key string, val *cbg.Deferred) (ActorChange, error) {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return ActorChange{}, xerrors.Errorf("invalid address (%x) found in state tree key: %v", []byte(key), err)
+	}
+
+	var act types.Actor
+	if err := act.UnmarshalCBOR(bytes.NewReader(val.Raw)); err != nil {
+		return ActorChange{}, xerrors.Errorf("decoding actor %s: %v", addr, err)
+	}
+	return ActorChange{Address: addr, Actor: act}, nil
+}
@@ -169,3 +169,59 @@ func TestStateTreeConsistency(t *testing.T) {
 	}
 
 }
+
+func TestDiffActors(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	bs := repo.NewInMemoryRepo().Datastore()
+	cst := cbor.NewCborStore(bs)
+	tree, err := NewState(cst, StateTreeVersion1)
+	require.NoError(t, err)
+
+	randomCid, err := cid.Decode("bafy2bzacecu7n7wbtogznrtuuvf73dsz7wasgyneqasksdblxupnyovmtwxxu")
+	require.NoError(t, err)
+
+	var addrs []address.Address
+	for i := 100; i < 103; i++ {
+		a, err := address.NewIDAddress(uint64(i))
+		require.NoError(t, err)
+		addrs = append(addrs, a)
+	}
+	newActor := func(nonce uint64) *types.Actor {
+		return &types.Actor{Code: randomCid, Head: randomCid, Balance: abi.NewTokenAmount(10), Nonce: nonce}
+	}
+
+	require.NoError(t, tree.SetActor(ctx, addrs[0], newActor(0)))
+	require.NoError(t, tree.SetActor(ctx, addrs[1], newActor(0)))
+	preRoot, err := tree.Flush(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, tree.SetActor(ctx, addrs[1], newActor(1)))
+	require.NoError(t, tree.DeleteActor(ctx, addrs[0]))
+	require.NoError(t, tree.SetActor(ctx, addrs[2], newActor(0)))
+	curRoot, err := tree.Flush(ctx)
+	require.NoError(t, err)
+
+	pre, err := LoadState(ctx, cst, preRoot)
+	require.NoError(t, err)
+	cur, err := LoadState(ctx, cst, curRoot)
+	require.NoError(t, err)
+
+	changes, err := DiffActors(pre, cur)
+	require.NoError(t, err)
+	require.Len(t, changes.Added, 1)
+	assert.Equal(t, addrs[2], changes.Added[0].Address)
+	require.Len(t, changes.Modified, 1)
+	assert.Equal(t, addrs[1], changes.Modified[0].Address)
+	assert.Equal(t, uint64(0), changes.Modified[0].From.Nonce)
+	assert.Equal(t, uint64(1), changes.Modified[0].To.Nonce)
+	require.Len(t, changes.Removed, 1)
+	assert.Equal(t, addrs[0], changes.Removed[0].Address)
+
+	changes, err = DiffActors(cur, cur)
+	require.NoError(t, err)
+	assert.Empty(t, changes.Added)
+	assert.Empty(t, changes.Modified)
+	assert.Empty(t, changes.Removed)
+}
@@ -202,9 +202,11 @@ func (ctx *invocationContext) invoke() (ret []byte, errcode exitcode.ExitCode) {
 					"gasLimit", ctx.gasTank.GasAvailable)
 				ret = []byte{} // The Empty here should never be used, but slightly safer than zero Value.
 				errcode = p.Code()
+				ctx.gasTank.ExecutionTrace.Error = p.String()
 			default:
 				errcode = 1
 				ret = []byte{}
+				ctx.gasTank.ExecutionTrace.Error = fmt.Sprintf("spec actors failure: %s", r)
 				// do not trap unknown panics
 				vmlog.Errorf("spec actors failure: %s", r)
 				//debug.PrintStack()
@@ -456,10 +458,29 @@ func (ctx *invocationContext) Send(toAddr address.Address, methodNum abi.MethodN
 		Params: params,
 	}
 
+	// the gas tracker is shared by nested invocations, record the subcall in a trace of its own
+	// and attach it To the trace of this invocation once done.
+	var receipt *types.MessageReceipt
+	endSubcall := ctx.gasTank.BeginSubcall(&types.UnsignedMessage{
+		From:   from,
+		To:     toAddr,
+		Value:  value,
+		Method: methodNum,
+		Params: traceParams(params),
+	})
+	defer func() {
+		// the new context may abort, the trace is attached in any case
+		endSubcall(receipt)
+	}()
+
 	// 1. build new context
 	newCtx := newInvocationContext(ctx.vm, ctx.gasIpld, ctx.topLevel, newMsg, ctx.gasTank, ctx.randSource, ctx)
 	// 2. invoke
 	ret, code := newCtx.invoke()
+	receipt = &types.MessageReceipt{
+		ExitCode:    code,
+		ReturnValue: ret,
+	}
 	if code == 0 {
 		_ = ctx.gasTank.TryCharge(gasOnActorExec)
 		if err := out.UnmarshalCBOR(bytes.NewReader(ret)); err != nil {
@@ -469,6 +490,22 @@ func (ctx *invocationContext) Send(toAddr address.Address, methodNum abi.MethodN
 	return code
 }
 
+// traceParams serializes the parameters of an internal message for its execution trace.
+func traceParams(params interface{}) []byte {
+	switch p := params.(type) {
+	case []byte:
+		return p
+	case cbor.Marshaler:
+		buf := new(bytes.Buffer)
+		if err := p.MarshalCBOR(buf); err != nil {
+			return nil
+		}
+		return buf.Bytes()
+	default:
+		return nil
+	}
+}
+
 /// Balance implements runtime.InvocationContext.
 func (ctx *invocationContext) Balance() abi.TokenAmount {
 	toActor, found, err := ctx.vm.State.GetActor(ctx.vm.context, ctx.originMsg.To)
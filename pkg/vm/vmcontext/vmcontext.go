@@ -403,14 +403,23 @@ func (vm *VM) applyImplicitMessage(imsg VmMessage) (*Ret, error) {
 		return nil, fmt.Errorf("invalid exit code %d during implicit message execution: From %s, To %s, Method %d, Value %s, Params %v",
 			code, imsg.From, imsg.To, imsg.Method, imsg.Value, imsg.Params)
 	}
+	receipt := types.MessageReceipt{
+		ExitCode:    code,
+		ReturnValue: ret,
+		GasUsed:     0,
+	}
+	gasTank.ExecutionTrace.Msg = &types.UnsignedMessage{
+		From:   imsg.From,
+		To:     imsg.To,
+		Value:  imsg.Value,
+		Method: imsg.Method,
+		Params: traceParams(imsg.Params),
+	}
+	gasTank.ExecutionTrace.MsgRct = &receipt
 	return &Ret{
 		GasTracker: gasTank,
 		OutPuts:    gas.GasOutputs{},
-		Receipt: types.MessageReceipt{
-			ExitCode:    code,
-			ReturnValue: ret,
-			GasUsed:     0,
-		},
+		Receipt:    receipt,
 	}, nil
 }
 
@@ -454,8 +463,22 @@ func (vm *VM) MutateState(ctx context.Context, addr address.Address, fn interfac
 	return nil
 }
 
-// applyMessage applies the message To the current stateView.
+// applyMessage applies the message To the current stateView and completes its execution trace.
 func (vm *VM) applyMessage(msg *types.UnsignedMessage, onChainMsgSize int) (*Ret, error) {
+	start := time.Now()
+	ret, err := vm.execMessage(msg, onChainMsgSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ret.GasTracker.ExecutionTrace.Msg = msg
+	ret.GasTracker.ExecutionTrace.MsgRct = &ret.Receipt
+	ret.GasTracker.ExecutionTrace.Duration = time.Since(start)
+	return ret, nil
+}
+
+// execMessage executes the message on the current stateView.
+func (vm *VM) execMessage(msg *types.UnsignedMessage, onChainMsgSize int) (*Ret, error) {
 	vm.SetCurrentEpoch(vm.vmOption.Epoch)
 	// This Method does not actually execute the message itself,
 	// but rather deals with the pre/post processing of a message.
@@ -1,6 +1,8 @@
 package wallet
 
 import (
+	"context"
+
 	"github.com/filecoin-project/go-address"
 
 	"github.com/filecoin-project/venus/pkg/crypto"
@@ -31,3 +33,10 @@ type Importer interface {
 	// into the backend
 	ImportKey(ki *crypto.KeyInfo) error
 }
+
+// MetaSigner is a specialization of a wallet backend that is told what it signs,
+// e.g. a remote signer enforcing its own signing policy.
+type MetaSigner interface {
+	// SignBytesWithMeta signs data like Backend.SignBytes, `meta` describes the data.
+	SignBytesWithMeta(ctx context.Context, data []byte, addr address.Address, meta MsgMeta) (*crypto.Signature, error)
+}
@@ -0,0 +1,450 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	ds "github.com/ipfs/go-datastore"
+	dsq "github.com/ipfs/go-datastore/query"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/filecoin-project/venus/pkg/repo"
+)
+
+// EncryptedBackendType is the reflect type of the EncryptedBackend.
+var EncryptedBackendType = reflect.TypeOf(&EncryptedBackend{})
+
+// ErrWalletLocked is returned when private keys are needed while the wallet is locked.
+var ErrWalletLocked = errors.New("wallet is locked")
+
+// ErrNoPassphrase is returned when unlocking a wallet which has no passphrase set yet.
+var ErrNoPassphrase = errors.New("wallet passphrase is not set")
+
+// passphraseKey is where the key derivation parameters are stored, it can never
+// collide with an address.
+var passphraseKey = ds.NewKey("_passphrase")
+
+// passphraseCheck is sealed with the derived key to verify the passphrase on unlock.
+var passphraseCheck = []byte("venus wallet passphrase")
+
+const (
+	scryptN   = 1 << 15
+	scryptR   = 8
+	scryptP   = 1
+	scryptLen = 32
+	saltLen   = 32
+)
+
+type passphraseInfo struct {
+	Salt  []byte
+	N     int
+	R     int
+	P     int
+	Check []byte
+}
+
+// EncryptedBackend is a wallet backend storing addresses in a datastore, with the key
+// infos encrypted using AES-GCM and a key derived from a passphrase with scrypt.
+// Addresses can be listed at any time, but the backend must be unlocked to create,
+// import, export or sign with a key.
+type EncryptedBackend struct {
+	lk sync.RWMutex
+
+	ds repo.Datastore
+
+	cache map[address.Address]struct{}
+
+	// info is nil until a passphrase is set
+	info *passphraseInfo
+	// key is the key derived from the passphrase, nil while locked
+	key       []byte
+	lockTimer *time.Timer
+	// unlocks counts the unlocks, so that the timer of a previous unlock which fired
+	// concurrently with a new unlock doesn't lock the backend
+	unlocks uint64
+}
+
+var _ Backend = (*EncryptedBackend)(nil)
+var _ Importer = (*EncryptedBackend)(nil)
+
+// NewEncryptedBackend constructs a new locked backend using the passed in datastore.
+func NewEncryptedBackend(dstore repo.Datastore) (*EncryptedBackend, error) {
+	result, err := dstore.Query(dsq.Query{
+		KeysOnly: true,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query datastore")
+	}
+
+	list, err := result.Rest()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read query results")
+	}
+
+	cache := make(map[address.Address]struct{})
+	for _, el := range list {
+		if el.Key == passphraseKey.String() {
+			continue
+		}
+		parsedAddr, err := address.NewFromString(strings.Trim(el.Key, "/"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "trying to restore invalid address: %s", el.Key)
+		}
+		cache[parsedAddr] = struct{}{}
+	}
+
+	backend := &EncryptedBackend{
+		ds:    dstore,
+		cache: cache,
+	}
+
+	infob, err := dstore.Get(passphraseKey)
+	switch err {
+	case nil:
+		backend.info = &passphraseInfo{}
+		if err := json.Unmarshal(infob, backend.info); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal passphrase info")
+		}
+	case ds.ErrNotFound:
+	default:
+		return nil, errors.Wrap(err, "failed to load passphrase info")
+	}
+
+	return backend, nil
+}
+
+// HasPassphrase returns true once a passphrase has been set.
+func (backend *EncryptedBackend) HasPassphrase() bool {
+	backend.lk.RLock()
+	defer backend.lk.RUnlock()
+
+	return backend.info != nil
+}
+
+// SetPassphrase sets the passphrase of a wallet which has none yet. Keys stored in
+// plain text, e.g. by a DSBackend on the same datastore, are encrypted with it.
+// The backend stays locked.
+func (backend *EncryptedBackend) SetPassphrase(passphrase []byte) error {
+	if len(passphrase) == 0 {
+		return errors.New("passphrase must not be empty")
+	}
+
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+
+	if backend.info != nil {
+		return errors.New("wallet passphrase is already set")
+	}
+
+	info := &passphraseInfo{
+		Salt: make([]byte, saltLen),
+		N:    scryptN,
+		R:    scryptR,
+		P:    scryptP,
+	}
+	if _, err := io.ReadFull(rand.Reader, info.Salt); err != nil {
+		return err
+	}
+
+	key, err := deriveKey(passphrase, info)
+	if err != nil {
+		return err
+	}
+
+	info.Check, err = seal(key, passphraseCheck, nil)
+	if err != nil {
+		return err
+	}
+
+	batch, err := backend.ds.Batch()
+	if err != nil {
+		return err
+	}
+
+	for addr := range backend.cache {
+		kib, err := backend.ds.Get(ds.NewKey(addr.String()))
+		if err != nil {
+			return errors.Wrapf(err, "failed to fetch private key of %s", addr)
+		}
+
+		enc, err := seal(key, kib, addr.Bytes())
+		if err != nil {
+			return err
+		}
+
+		if err := batch.Put(ds.NewKey(addr.String()), enc); err != nil {
+			return err
+		}
+	}
+
+	infob, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	if err := batch.Put(passphraseKey, infob); err != nil {
+		return err
+	}
+
+	if err := batch.Commit(); err != nil {
+		return errors.Wrap(err, "failed to encrypt wallet")
+	}
+
+	backend.info = info
+	return nil
+}
+
+// Unlock makes the private keys usable until `timeout` elapsed or Lock is called.
+// A zero timeout keeps the backend unlocked until Lock is called.
+func (backend *EncryptedBackend) Unlock(passphrase []byte, timeout time.Duration) error {
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+
+	if backend.info == nil {
+		return ErrNoPassphrase
+	}
+
+	key, err := deriveKey(passphrase, backend.info)
+	if err != nil {
+		return err
+	}
+
+	check, err := open(key, backend.info.Check, nil)
+	if err != nil || !bytes.Equal(check, passphraseCheck) {
+		return errors.New("invalid passphrase")
+	}
+
+	backend.stopLockTimer()
+	backend.key = key
+	backend.unlocks++
+	if timeout > 0 {
+		unlock := backend.unlocks
+		backend.lockTimer = time.AfterFunc(timeout, func() {
+			backend.autoLock(unlock)
+		})
+	}
+
+	return nil
+}
+
+// Lock forgets the derived key, the backend can't use private keys until unlocked again.
+func (backend *EncryptedBackend) Lock() {
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+
+	backend.lock()
+}
+
+// autoLock locks the backend when the timeout of the unlock `unlock` elapsed, unless
+// the backend was unlocked again since.
+func (backend *EncryptedBackend) autoLock(unlock uint64) {
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+
+	if backend.unlocks != unlock {
+		return
+	}
+	backend.lock()
+}
+
+// lock must be called with the lock held.
+func (backend *EncryptedBackend) lock() {
+	for i := range backend.key {
+		backend.key[i] = 0
+	}
+	backend.key = nil
+
+	backend.stopLockTimer()
+}
+
+// stopLockTimer must be called with the lock held.
+func (backend *EncryptedBackend) stopLockTimer() {
+	if backend.lockTimer != nil {
+		backend.lockTimer.Stop()
+		backend.lockTimer = nil
+	}
+}
+
+// Locked returns true while private keys can't be used.
+func (backend *EncryptedBackend) Locked() bool {
+	backend.lk.RLock()
+	defer backend.lk.RUnlock()
+
+	return backend.key == nil
+}
+
+// ImportKey loads the address in `ai` and KeyInfo `ki` into the backend
+func (backend *EncryptedBackend) ImportKey(ki *crypto.KeyInfo) error {
+	return backend.putKeyInfo(ki)
+}
+
+// Addresses returns a list of all addresses that are stored in this backend.
+func (backend *EncryptedBackend) Addresses() []address.Address {
+	backend.lk.RLock()
+	defer backend.lk.RUnlock()
+
+	var cpy []address.Address
+	for addr := range backend.cache {
+		cpy = append(cpy, addr)
+	}
+	return cpy
+}
+
+// HasAddress checks if the passed in address is stored in this backend.
+// Safe for concurrent access.
+func (backend *EncryptedBackend) HasAddress(addr address.Address) bool {
+	backend.lk.RLock()
+	defer backend.lk.RUnlock()
+
+	_, ok := backend.cache[addr]
+	return ok
+}
+
+// NewAddress creates a new address and stores it.
+// Safe for concurrent access.
+func (backend *EncryptedBackend) NewAddress(protocol address.Protocol) (address.Address, error) {
+	var ki crypto.KeyInfo
+	var err error
+	switch protocol {
+	case address.BLS:
+		ki, err = crypto.NewBLSKeyFromSeed(rand.Reader)
+	case address.SECP256K1:
+		ki, err = crypto.NewSecpKeyFromSeed(rand.Reader)
+	default:
+		return address.Undef, errors.Errorf("Unknown address protocol %d", protocol)
+	}
+	if err != nil {
+		return address.Undef, err
+	}
+
+	if err := backend.putKeyInfo(&ki); err != nil {
+		return address.Undef, err
+	}
+	return ki.Address()
+}
+
+func (backend *EncryptedBackend) putKeyInfo(ki *crypto.KeyInfo) error {
+	a, err := ki.Address()
+	if err != nil {
+		return err
+	}
+
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+
+	if backend.key == nil {
+		return ErrWalletLocked
+	}
+
+	buf := new(bytes.Buffer)
+	err = ki.MarshalCBOR(buf)
+	if err != nil {
+		return err
+	}
+
+	enc, err := seal(backend.key, buf.Bytes(), a.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if err := backend.ds.Put(ds.NewKey(a.String()), enc); err != nil {
+		return errors.Wrap(err, "failed to store new address")
+	}
+
+	backend.cache[a] = struct{}{}
+	return nil
+}
+
+// SignBytes cryptographically signs `data` using the private key of `addr`.
+// It fails while the backend is locked.
+func (backend *EncryptedBackend) SignBytes(data []byte, addr address.Address) (*crypto.Signature, error) {
+	ki, err := backend.GetKeyInfo(addr)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Sign(data, ki.PrivateKey, ki.SigType)
+}
+
+// GetKeyInfo will return the private & public keys associated with address `addr`
+// iff backend contains the addr. It fails while the backend is locked.
+func (backend *EncryptedBackend) GetKeyInfo(addr address.Address) (*crypto.KeyInfo, error) {
+	backend.lk.RLock()
+	defer backend.lk.RUnlock()
+
+	if _, ok := backend.cache[addr]; !ok {
+		return nil, errors.New("backend does not contain address")
+	}
+
+	if backend.key == nil {
+		return nil, ErrWalletLocked
+	}
+
+	enc, err := backend.ds.Get(ds.NewKey(addr.String()))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch private key from backend")
+	}
+
+	kib, err := open(backend.key, enc, addr.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt private key")
+	}
+
+	ki := &crypto.KeyInfo{}
+	if err := ki.UnmarshalCBOR(bytes.NewReader(kib)); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal keyinfo from backend")
+	}
+
+	return ki, nil
+}
+
+func deriveKey(passphrase []byte, info *passphraseInfo) ([]byte, error) {
+	return scrypt.Key(passphrase, info.Salt, info.N, info.R, info.P, scryptLen)
+}
+
+// seal encrypts `plaintext` with AES-GCM, the random nonce is prepended to the output.
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts and authenticates data produced by seal.
+func open(key, data, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("encrypted data too short")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
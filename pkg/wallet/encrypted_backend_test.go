@@ -0,0 +1,127 @@
+package wallet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/crypto"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestEncryptedBackendLockUnlock(t *testing.T) {
+	tf.UnitTest(t)
+
+	ds := datastore.NewMapDatastore()
+	defer func() {
+		require.NoError(t, ds.Close())
+	}()
+
+	eb, err := NewEncryptedBackend(ds)
+	require.NoError(t, err)
+
+	t.Log("can't unlock before a passphrase is set")
+	assert.Equal(t, ErrNoPassphrase, eb.Unlock([]byte("pass"), 0))
+
+	require.NoError(t, eb.SetPassphrase([]byte("pass")))
+	assert.Error(t, eb.SetPassphrase([]byte("other")))
+
+	t.Log("can't create addresses while locked")
+	assert.True(t, eb.Locked())
+	_, err = eb.NewAddress(address.SECP256K1)
+	assert.Equal(t, ErrWalletLocked, err)
+
+	t.Log("wrong passphrase is refused")
+	assert.Error(t, eb.Unlock([]byte("wrong"), 0))
+	assert.True(t, eb.Locked())
+
+	require.NoError(t, eb.Unlock([]byte("pass"), 0))
+	addr, err := eb.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+
+	data := []byte("data")
+	sig, err := eb.SignBytes(data, addr)
+	require.NoError(t, err)
+	assert.NoError(t, crypto.ValidateSignature(data, addr, *sig))
+
+	t.Log("signing is refused once locked")
+	eb.Lock()
+	_, err = eb.SignBytes(data, addr)
+	assert.Equal(t, ErrWalletLocked, err)
+
+	t.Log("address and passphrase are restored in a new backend")
+	eb2, err := NewEncryptedBackend(ds)
+	require.NoError(t, err)
+	assert.True(t, eb2.HasAddress(addr))
+	assert.True(t, eb2.HasPassphrase())
+	require.NoError(t, eb2.Unlock([]byte("pass"), 0))
+	_, err = eb2.GetKeyInfo(addr)
+	assert.NoError(t, err)
+}
+
+func TestEncryptedBackendUnlockTimeout(t *testing.T) {
+	tf.UnitTest(t)
+
+	eb, err := NewEncryptedBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	require.NoError(t, eb.SetPassphrase([]byte("pass")))
+
+	require.NoError(t, eb.Unlock([]byte("pass"), 50*time.Millisecond))
+	assert.False(t, eb.Locked())
+	assert.Eventually(t, eb.Locked, time.Second, 10*time.Millisecond)
+}
+
+func TestEncryptedBackendUnlockReplacesTimeout(t *testing.T) {
+	tf.UnitTest(t)
+
+	eb, err := NewEncryptedBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	require.NoError(t, eb.SetPassphrase([]byte("pass")))
+
+	require.NoError(t, eb.Unlock([]byte("pass"), time.Hour))
+	first := eb.unlocks
+	require.NoError(t, eb.Unlock([]byte("pass"), 0))
+
+	// the timer of the first unlock firing late doesn't lock the backend
+	eb.autoLock(first)
+	assert.False(t, eb.Locked())
+	assert.Nil(t, eb.lockTimer)
+
+	eb.Lock()
+	assert.True(t, eb.Locked())
+}
+
+func TestEncryptedBackendEncryptsExistingKeys(t *testing.T) {
+	tf.UnitTest(t)
+
+	ds := datastore.NewMapDatastore()
+	defer func() {
+		require.NoError(t, ds.Close())
+	}()
+
+	dsb, err := NewDSBackend(ds)
+	require.NoError(t, err)
+	addr, err := dsb.NewAddress(address.BLS)
+	require.NoError(t, err)
+	ki, err := dsb.GetKeyInfo(addr)
+	require.NoError(t, err)
+
+	eb, err := NewEncryptedBackend(ds)
+	require.NoError(t, err)
+	require.NoError(t, eb.SetPassphrase([]byte("pass")))
+
+	t.Log("private key is not stored in plain text anymore")
+	stored, err := ds.Get(datastore.NewKey(addr.String()))
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(stored, ki.PrivateKey))
+
+	require.NoError(t, eb.Unlock([]byte("pass"), 0))
+	got, err := eb.GetKeyInfo(addr)
+	require.NoError(t, err)
+	assert.True(t, ki.Equals(got))
+}
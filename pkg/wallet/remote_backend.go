@@ -0,0 +1,137 @@
+package wallet
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-jsonrpc"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/crypto"
+)
+
+var log = logging.Logger("wallet")
+
+// RemoteBackendType is the reflect type of the RemoteBackend.
+var RemoteBackendType = reflect.TypeOf(&RemoteBackend{})
+
+// remoteCallTimeout bounds the calls made by methods of the Backend interface, which
+// don't take a context.
+const remoteCallTimeout = 30 * time.Second
+
+// RemoteSignerAPI is the JSON-RPC API, in the "Filecoin" namespace, a remote signing
+// service must serve to be used by a RemoteBackend.
+type RemoteSignerAPI struct {
+	WalletList func(context.Context) ([]address.Address, error)
+	WalletHas  func(context.Context, address.Address) (bool, error)
+	WalletSign func(context.Context, address.Address, []byte, MsgMeta) (*crypto.Signature, error)
+}
+
+// RemoteBackend is a wallet backend forwarding signing requests to a remote signing
+// service, so that the private keys are never held by the node.
+type RemoteBackend struct {
+	api    RemoteSignerAPI
+	closer jsonrpc.ClientCloser
+}
+
+var _ Backend = (*RemoteBackend)(nil)
+var _ MetaSigner = (*RemoteBackend)(nil)
+
+// NewRemoteBackend connects to the signing service at `url`, e.g. ws://127.0.0.1:5678/rpc/v0.
+// `header` is sent with every request and usually carries the authorization token.
+func NewRemoteBackend(ctx context.Context, url string, header http.Header) (*RemoteBackend, error) {
+	backend := &RemoteBackend{}
+	closer, err := jsonrpc.NewClient(ctx, url, "Filecoin", &backend.api, header)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to remote signer %s", url)
+	}
+	backend.closer = closer
+	return backend, nil
+}
+
+// NewRemoteBackendFromInfo connects to the signing service described by `info`, in the
+// form <token>:<multiaddr>, e.g. eyJhbGc...:/ip4/127.0.0.1/tcp/5678/http.
+func NewRemoteBackendFromInfo(ctx context.Context, info string) (*RemoteBackend, error) {
+	sep := strings.Index(info, ":/")
+	if sep < 0 {
+		return nil, errors.Errorf("invalid remote signer info %q, expected <token>:<multiaddr>", info)
+	}
+
+	maddr, err := multiaddr.NewMultiaddr(info[sep+1:])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid remote signer address")
+	}
+
+	_, addr, err := manet.DialArgs(maddr)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	if token := info[:sep]; token != "" {
+		header.Add("Authorization", "Bearer "+token)
+	}
+
+	return NewRemoteBackend(ctx, "ws://"+addr+"/rpc/v0", header)
+}
+
+// Close closes the connection to the signing service.
+func (backend *RemoteBackend) Close() {
+	backend.closer()
+}
+
+// Addresses returns the addresses of the signing service, or none if it can't be reached.
+func (backend *RemoteBackend) Addresses() []address.Address {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteCallTimeout)
+	defer cancel()
+
+	addrs, err := backend.api.WalletList(ctx)
+	if err != nil {
+		log.Errorf("failed to list remote signer addresses: %s", err)
+		return nil
+	}
+	return addrs
+}
+
+// HasAddress checks if the signing service holds the key of `addr`.
+func (backend *RemoteBackend) HasAddress(addr address.Address) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteCallTimeout)
+	defer cancel()
+
+	has, err := backend.api.WalletHas(ctx, addr)
+	if err != nil {
+		log.Errorf("failed to query remote signer for %s: %s", addr, err)
+		return false
+	}
+	return has
+}
+
+// SignBytes asks the signing service to sign `data` with the key of `addr`.
+func (backend *RemoteBackend) SignBytes(data []byte, addr address.Address) (*crypto.Signature, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteCallTimeout)
+	defer cancel()
+
+	return backend.SignBytesWithMeta(ctx, data, addr, MsgMeta{Type: MTUnknown})
+}
+
+// SignBytesWithMeta asks the signing service to sign `data` with the key of `addr`,
+// `meta` lets the service check what it signs.
+func (backend *RemoteBackend) SignBytesWithMeta(ctx context.Context, data []byte, addr address.Address, meta MsgMeta) (*crypto.Signature, error) {
+	sig, err := backend.api.WalletSign(ctx, addr, data, meta)
+	if err != nil {
+		return nil, errors.Wrap(err, "remote signer failed to sign")
+	}
+	return sig, nil
+}
+
+// GetKeyInfo always fails, private keys never leave the signing service.
+func (backend *RemoteBackend) GetKeyInfo(addr address.Address) (*crypto.KeyInfo, error) {
+	return nil, errors.New("remote signer does not expose private keys")
+}
@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/crypto"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+// standInSigner serves the RemoteSignerAPI from a local datastore backend.
+type standInSigner struct {
+	backend  *DSBackend
+	lastMeta MsgMeta
+}
+
+func (s *standInSigner) WalletList(context.Context) ([]address.Address, error) {
+	return s.backend.Addresses(), nil
+}
+
+func (s *standInSigner) WalletHas(_ context.Context, addr address.Address) (bool, error) {
+	return s.backend.HasAddress(addr), nil
+}
+
+func (s *standInSigner) WalletSign(_ context.Context, addr address.Address, data []byte, meta MsgMeta) (*crypto.Signature, error) {
+	s.lastMeta = meta
+	return s.backend.SignBytes(data, addr)
+}
+
+func TestRemoteBackend(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+
+	local, err := NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	addr, err := local.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+
+	signer := &standInSigner{backend: local}
+	rpcServer := jsonrpc.NewServer()
+	rpcServer.Register("Filecoin", signer)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rpcServer.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	header := http.Header{}
+	header.Add("Authorization", "Bearer secret")
+	remote, err := NewRemoteBackend(ctx, "ws://"+srv.Listener.Addr().String()+"/rpc/v0", header)
+	require.NoError(t, err)
+	defer remote.Close()
+
+	assert.Equal(t, []address.Address{addr}, remote.Addresses())
+	assert.True(t, remote.HasAddress(addr))
+
+	t.Log("signing goes through the wallet with the message meta")
+	w := New(remote)
+	data := []byte("data")
+	meta := MsgMeta{Type: MTChainMsg, Extra: []byte("extra")}
+	sig, err := w.WalletSign(ctx, addr, data, meta)
+	require.NoError(t, err)
+	assert.NoError(t, crypto.ValidateSignature(data, addr, *sig))
+	assert.Equal(t, meta, signer.lastMeta)
+
+	t.Log("private keys are not exposed")
+	_, err = w.Export([]address.Address{addr})
+	assert.Error(t, err)
+
+	t.Log("connecting without the token fails")
+	_, err = NewRemoteBackend(ctx, "ws://"+srv.Listener.Addr().String()+"/rpc/v0", http.Header{})
+	assert.Error(t, err)
+}
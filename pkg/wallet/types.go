@@ -14,7 +14,10 @@ const (
 	// Signing a deal proposal. signing raw cbor proposal bytes (MsgMeta.Extra is empty)
 	MTDealProposal = "dealproposal"
 
-	// TODO: Deals, Vouchers, VRF
+	// Signing a payment channel voucher. signing raw cbor voucher bytes (MsgMeta.Extra is empty)
+	MTSignedVoucher = "signedvoucher"
+
+	// TODO: Deals, VRF
 )
 
 type MsgMeta struct {
@@ -105,15 +105,30 @@ func (w *Wallet) SignBytes(data []byte, addr address.Address) (*crypto.Signature
 	return backend.SignBytes(data, addr)
 }
 
+// localBackends returns the backends storing keys in the repo, either in plain text
+// or encrypted.
+func (w *Wallet) localBackends() []Backend {
+	if backends := w.Backends(DSBackendType); len(backends) > 0 {
+		return backends
+	}
+	return w.Backends(EncryptedBackendType)
+}
+
 // NewAddress creates a new account address on the default wallet backend.
 func NewAddress(w *Wallet, p address.Protocol) (address.Address, error) {
-	backends := w.Backends(DSBackendType)
+	backends := w.localBackends()
 	if len(backends) == 0 {
 		return address.Undef, fmt.Errorf("missing default ds backend")
 	}
 
-	backend := (backends[0]).(*DSBackend)
-	return backend.NewAddress(p)
+	switch backend := backends[0].(type) {
+	case *DSBackend:
+		return backend.NewAddress(p)
+	case *EncryptedBackend:
+		return backend.NewAddress(p)
+	default:
+		return address.Undef, fmt.Errorf("unexpected default backend %T", backend)
+	}
 }
 
 // GetPubKeyForAddress returns the public key in the keystore associated with
@@ -152,7 +167,7 @@ func (w *Wallet) keyInfoForAddr(addr address.Address) (*crypto.KeyInfo, error) {
 
 // Import adds the given keyinfos to the wallet
 func (w *Wallet) Import(kinfos ...*crypto.KeyInfo) ([]address.Address, error) {
-	dsb := w.Backends(DSBackendType)
+	dsb := w.localBackends()
 	if len(dsb) != 1 {
 		return nil, fmt.Errorf("expected exactly one datastore wallet backend")
 	}
@@ -205,5 +220,8 @@ func (w *Wallet) WalletSign(ctx context.Context, addr address.Address, msg []byt
 		return nil, xerrors.Errorf("signing using key '%s': %w", addr.String(), ErrKeyInfoNotFound)
 	}
 
+	if ms, ok := ki.(MetaSigner); ok {
+		return ms.SignBytesWithMeta(ctx, msg, addr, meta)
+	}
 	return ki.SignBytes(msg, addr)
 }